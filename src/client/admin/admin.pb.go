@@ -548,18 +548,19 @@ func (m *Op1_11) GetJob() *pps4.CreateJobRequest {
 }
 
 type Op1_12 struct {
-	Object               *pfs5.PutObjectRequest      `protobuf:"bytes,2,opt,name=object,proto3" json:"object,omitempty"`
-	CreateObject         *pfs5.CreateObjectRequest   `protobuf:"bytes,9,opt,name=create_object,json=createObject,proto3" json:"create_object,omitempty"`
-	Tag                  *pfs5.TagObjectRequest      `protobuf:"bytes,3,opt,name=tag,proto3" json:"tag,omitempty"`
-	Block                *pfs5.PutBlockRequest       `protobuf:"bytes,10,opt,name=block,proto3" json:"block,omitempty"`
-	Repo                 *pfs5.CreateRepoRequest     `protobuf:"bytes,4,opt,name=repo,proto3" json:"repo,omitempty"`
-	Commit               *pfs5.BuildCommitRequest    `protobuf:"bytes,5,opt,name=commit,proto3" json:"commit,omitempty"`
-	Branch               *pfs5.CreateBranchRequest   `protobuf:"bytes,6,opt,name=branch,proto3" json:"branch,omitempty"`
-	Pipeline             *pps5.CreatePipelineRequest `protobuf:"bytes,7,opt,name=pipeline,proto3" json:"pipeline,omitempty"`
-	Job                  *pps5.CreateJobRequest      `protobuf:"bytes,8,opt,name=job,proto3" json:"job,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}                    `json:"-"`
-	XXX_unrecognized     []byte                      `json:"-"`
-	XXX_sizecache        int32                       `json:"-"`
+	Object               *pfs5.PutObjectRequest       `protobuf:"bytes,2,opt,name=object,proto3" json:"object,omitempty"`
+	CreateObject         *pfs5.CreateObjectRequest    `protobuf:"bytes,9,opt,name=create_object,json=createObject,proto3" json:"create_object,omitempty"`
+	Tag                  *pfs5.TagObjectRequest       `protobuf:"bytes,3,opt,name=tag,proto3" json:"tag,omitempty"`
+	Block                *pfs5.PutBlockRequest        `protobuf:"bytes,10,opt,name=block,proto3" json:"block,omitempty"`
+	Repo                 *pfs5.CreateRepoRequest      `protobuf:"bytes,4,opt,name=repo,proto3" json:"repo,omitempty"`
+	Commit               *pfs5.BuildCommitRequest     `protobuf:"bytes,5,opt,name=commit,proto3" json:"commit,omitempty"`
+	Branch               *pfs5.CreateBranchRequest    `protobuf:"bytes,6,opt,name=branch,proto3" json:"branch,omitempty"`
+	CommitTag            *pfs5.CreateCommitTagRequest `protobuf:"bytes,11,opt,name=commit_tag,json=commitTag,proto3" json:"commit_tag,omitempty"`
+	Pipeline             *pps5.CreatePipelineRequest  `protobuf:"bytes,7,opt,name=pipeline,proto3" json:"pipeline,omitempty"`
+	Job                  *pps5.CreateJobRequest       `protobuf:"bytes,8,opt,name=job,proto3" json:"job,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}                     `json:"-"`
+	XXX_unrecognized     []byte                       `json:"-"`
+	XXX_sizecache        int32                        `json:"-"`
 }
 
 func (m *Op1_12) Reset()         { *m = Op1_12{} }
@@ -644,6 +645,13 @@ func (m *Op1_12) GetBranch() *pfs5.CreateBranchRequest {
 	return nil
 }
 
+func (m *Op1_12) GetCommitTag() *pfs5.CreateCommitTagRequest {
+	if m != nil {
+		return m.CommitTag
+	}
+	return nil
+}
+
 func (m *Op1_12) GetPipeline() *pps5.CreatePipelineRequest {
 	if m != nil {
 		return m.Pipeline
@@ -751,7 +759,8 @@ type ExtractRequest struct {
 	URL string `protobuf:"bytes,1,opt,name=URL,proto3" json:"URL,omitempty"`
 	// NoObjects, if true, will cause extract to omit objects (and tags)
 	NoObjects bool `protobuf:"varint,2,opt,name=no_objects,json=noObjects,proto3" json:"no_objects,omitempty"`
-	// NoRepos, if true, will cause extract to omit repos, commits and branches.
+	// NoRepos, if true, will cause extract to omit repos, commits, branches and
+	// commit tags.
 	NoRepos bool `protobuf:"varint,3,opt,name=no_repos,json=noRepos,proto3" json:"no_repos,omitempty"`
 	// NoPipelines, if true, will cause extract to omit pipelines.
 	NoPipelines          bool     `protobuf:"varint,4,opt,name=no_pipelines,json=noPipelines,proto3" json:"no_pipelines,omitempty"`
@@ -997,72 +1006,73 @@ func init() {
 func init() { proto.RegisterFile("client/admin/admin.proto", fileDescriptor_6597bb2f2302afbd) }
 
 var fileDescriptor_6597bb2f2302afbd = []byte{
-	// 1034 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x97, 0x41, 0x6f, 0xe3, 0x44,
-	0x18, 0x86, 0xeb, 0xa4, 0x49, 0xd3, 0x69, 0x5a, 0x56, 0xa3, 0xb6, 0xb8, 0xe9, 0xb6, 0xdd, 0x8d,
-	0x90, 0x76, 0x59, 0x16, 0x3b, 0x93, 0xdd, 0xa5, 0x36, 0x50, 0xa4, 0x4d, 0xdb, 0x43, 0x10, 0x52,
-	0x2b, 0x0b, 0x2e, 0x08, 0x29, 0x4a, 0x9c, 0x69, 0xea, 0x92, 0x78, 0x86, 0xd8, 0x41, 0xf4, 0xc4,
-	0x0f, 0x43, 0xe2, 0xcc, 0x91, 0x5f, 0x50, 0x50, 0x4e, 0xdc, 0xf9, 0x03, 0xc8, 0xe3, 0xf1, 0x64,
-	0x3c, 0xb1, 0x1b, 0x92, 0x83, 0x2b, 0xd7, 0xf3, 0xbe, 0xdf, 0x7c, 0xdf, 0xfb, 0x58, 0xad, 0x07,
-	0xe8, 0xee, 0xd0, 0xc3, 0x7e, 0x68, 0x76, 0xfb, 0x23, 0xcf, 0x8f, 0x7f, 0x1a, 0x74, 0x4c, 0x42,
-	0x02, 0x4b, 0xec, 0x97, 0xda, 0xe1, 0x80, 0x90, 0xc1, 0x10, 0x9b, 0xec, 0x61, 0x6f, 0x72, 0x63,
-	0xe2, 0x11, 0x0d, 0xef, 0x63, 0x4d, 0x6d, 0x77, 0x40, 0x06, 0x84, 0xdd, 0x9a, 0xd1, 0x1d, 0x7f,
-	0x7a, 0x92, 0xaa, 0xf9, 0x33, 0xea, 0x9c, 0x9a, 0xf4, 0x26, 0x88, 0xae, 0x47, 0x04, 0x34, 0x88,
-	0xae, 0x3c, 0x81, 0xb5, 0xa8, 0x82, 0xb5, 0xa8, 0x82, 0xbd, 0xa8, 0x82, 0xad, 0x54, 0x78, 0xa6,
-	0x0a, 0x50, 0x43, 0x29, 0x91, 0xa9, 0x58, 0x50, 0x03, 0x2d, 0xac, 0x81, 0x94, 0x1a, 0xbb, 0x5c,
-	0x91, 0xf6, 0x89, 0xa7, 0xb2, 0xb6, 0xfe, 0x7b, 0x01, 0x94, 0xae, 0x28, 0xea, 0x9c, 0x42, 0x04,
-	0xca, 0xa4, 0x77, 0x87, 0xdd, 0x50, 0x2f, 0x3c, 0xd3, 0x5e, 0x6e, 0x35, 0x0f, 0x0c, 0x7a, 0x13,
-	0x74, 0x50, 0xe7, 0xd4, 0xb8, 0x9e, 0x84, 0x57, 0x6c, 0xc5, 0xc1, 0x3f, 0x4d, 0x70, 0x10, 0x3a,
-	0x5c, 0x08, 0x3f, 0x01, 0xc5, 0xb0, 0x3b, 0xd0, 0x8b, 0x8a, 0xfe, 0xdb, 0xee, 0x20, 0xad, 0x8f,
-	0x54, 0xd0, 0x00, 0xeb, 0x63, 0x4c, 0x89, 0xbe, 0xce, 0xd4, 0x35, 0xa1, 0x3e, 0x1f, 0xe3, 0x6e,
-	0x88, 0x1d, 0x4c, 0x49, 0x22, 0x67, 0x3a, 0xf8, 0x06, 0x94, 0x5d, 0x32, 0x1a, 0x79, 0xa1, 0x5e,
-	0x62, 0x8e, 0x43, 0xe1, 0x68, 0x4d, 0xbc, 0x61, 0xff, 0x9c, 0xad, 0x89, 0x8e, 0x62, 0x29, 0x7c,
-	0x0b, 0xca, 0xbd, 0x71, 0xd7, 0x77, 0x6f, 0xf5, 0x32, 0x33, 0x3d, 0x55, 0xb6, 0x69, 0xb1, 0x45,
-	0xe1, 0x8a, 0xb5, 0xf0, 0x73, 0x50, 0xa1, 0x1e, 0xc5, 0x43, 0xcf, 0xc7, 0xfa, 0x06, 0xf3, 0x1d,
-	0x1b, 0x94, 0xca, 0xbe, 0x6b, 0xbe, 0x9c, 0x38, 0x85, 0x5e, 0x04, 0x68, 0xe5, 0x06, 0x68, 0x2d,
-	0x19, 0xa0, 0xb5, 0x54, 0x80, 0xd6, 0xd2, 0x01, 0x5a, 0xab, 0x04, 0x68, 0xad, 0x18, 0xa0, 0xb5,
-	0x30, 0xc0, 0x87, 0x62, 0x1c, 0xa0, 0x9d, 0x1b, 0xa0, 0x9d, 0x1f, 0xe0, 0x7b, 0xb0, 0xed, 0xb2,
-	0xfa, 0x1d, 0xee, 0xdc, 0x4c, 0x75, 0x6d, 0xf3, 0xdd, 0xd3, 0xe6, 0xaa, 0x2b, 0x3d, 0xcc, 0x66,
-	0x60, 0xe7, 0x32, 0x28, 0xf5, 0x86, 0xc4, 0xfd, 0x51, 0x07, 0x4c, 0xae, 0xcb, 0x1d, 0xb6, 0xa2,
-	0x85, 0x44, 0x1d, 0xcb, 0x72, 0x98, 0xd9, 0x4b, 0x33, 0xb3, 0x57, 0x61, 0x66, 0xaf, 0xc8, 0xcc,
-	0x5e, 0xc4, 0x2c, 0xca, 0xec, 0x8e, 0xf4, 0xf4, 0x4a, 0x92, 0x59, 0xca, 0xf6, 0x35, 0xe9, 0x89,
-	0xcc, 0xee, 0x48, 0xaf, 0xfe, 0x4f, 0x11, 0x94, 0x23, 0xc0, 0xa8, 0x01, 0x9b, 0x0a, 0xe1, 0x24,
-	0x10, 0xd4, 0xc8, 0x47, 0xdc, 0xca, 0x46, 0x7c, 0x34, 0xb3, 0x2e, 0x66, 0xfc, 0x5a, 0x66, 0x2c,
-	0x6d, 0x9a, 0x0d, 0xd9, 0x4c, 0x43, 0x3e, 0x48, 0x35, 0x99, 0x45, 0xd9, 0x4c, 0x51, 0x3e, 0x54,
-	0x3b, 0x9b, 0xc7, 0xfc, 0x56, 0xc1, 0xfc, 0x74, 0x66, 0x79, 0x84, 0xf3, 0x3b, 0x85, 0xf3, 0x5c,
-	0x04, 0xd9, 0xa0, 0xbf, 0x98, 0x03, 0x7d, 0xc2, 0x89, 0x09, 0x63, 0x3e, 0xe9, 0xd7, 0x32, 0xe9,
-	0x9a, 0xea, 0xcb, 0x45, 0x8d, 0xf2, 0x51, 0xa3, 0xd5, 0x51, 0xa3, 0x95, 0x51, 0xa3, 0x25, 0x51,
-	0xa3, 0x25, 0x51, 0xa3, 0xe5, 0x51, 0xa3, 0x95, 0x50, 0xa3, 0x55, 0x51, 0xa3, 0x15, 0x51, 0xa3,
-	0x1c, 0xd4, 0xbf, 0x25, 0xa8, 0x9b, 0xf0, 0x53, 0x05, 0xf5, 0x5e, 0xd4, 0x6c, 0x3e, 0xe5, 0xb3,
-	0x6c, 0xca, 0xec, 0x6f, 0xe9, 0xff, 0x00, 0xfc, 0x42, 0x06, 0x1c, 0x6f, 0x95, 0xcd, 0xf6, 0x55,
-	0x9a, 0xed, 0x6e, 0xd2, 0x55, 0x16, 0xd6, 0x57, 0x29, 0xac, 0xfb, 0x52, 0x2b, 0xf3, 0x44, 0x4d,
-	0x85, 0xe8, 0x87, 0x4c, 0xfd, 0x08, 0xcc, 0x86, 0x02, 0x53, 0x9e, 0x34, 0x9b, 0xe3, 0x67, 0x73,
-	0x1c, 0x19, 0x8f, 0x85, 0x08, 0x5f, 0xc8, 0x08, 0xf7, 0x24, 0x8b, 0x4a, 0xef, 0x2f, 0x0d, 0x14,
-	0xae, 0x28, 0x7c, 0x0e, 0x4a, 0x24, 0xfa, 0xf8, 0xd3, 0x35, 0xe6, 0xa8, 0x1a, 0xf1, 0xe7, 0x3c,
-	0xfb, 0x20, 0x74, 0xd6, 0x09, 0x45, 0xa7, 0x89, 0xc4, 0xe2, 0x6c, 0x65, 0x89, 0xc5, 0x24, 0x56,
-	0x22, 0xb1, 0x39, 0x13, 0x59, 0x62, 0x33, 0x89, 0x0d, 0x3f, 0x02, 0x65, 0xc2, 0xfe, 0x05, 0xf0,
-	0x84, 0xb7, 0x25, 0x0d, 0x6a, 0x38, 0x91, 0x1f, 0x35, 0x84, 0x0a, 0xf1, 0x64, 0x53, 0x2a, 0x14,
-	0xab, 0x90, 0x50, 0x35, 0x79, 0x9c, 0x29, 0x55, 0x33, 0x56, 0x35, 0xeb, 0xbf, 0x82, 0x9d, 0xcb,
-	0x5f, 0xc2, 0x71, 0x57, 0xbc, 0x14, 0xf0, 0x09, 0x28, 0x7e, 0xe7, 0x7c, 0xc3, 0x46, 0xdd, 0x74,
-	0xa2, 0x5b, 0x78, 0x04, 0x80, 0x4f, 0xf8, 0x5b, 0x18, 0xb0, 0x01, 0x2b, 0xce, 0xa6, 0x4f, 0xe2,
-	0x77, 0x29, 0x80, 0x07, 0xa0, 0xe2, 0x93, 0x4e, 0xc4, 0x3c, 0x60, 0xa3, 0x55, 0x9c, 0x0d, 0x9f,
-	0x44, 0xef, 0x43, 0x00, 0x9f, 0x83, 0xaa, 0x4f, 0x3a, 0x49, 0xee, 0x01, 0x9b, 0xaa, 0xe2, 0x6c,
-	0xf9, 0x24, 0x61, 0x13, 0xd4, 0xcf, 0xc1, 0x3e, 0x6f, 0x40, 0xe1, 0x05, 0x3f, 0x96, 0xe8, 0x6a,
-	0x7c, 0x84, 0x08, 0x95, 0xd0, 0xcd, 0x3e, 0x8e, 0xce, 0xc0, 0x8e, 0x83, 0x83, 0x90, 0x8c, 0x85,
-	0xf9, 0x00, 0x14, 0x08, 0xe5, 0xb6, 0x4d, 0x31, 0xb9, 0x53, 0x20, 0x34, 0x19, 0xb0, 0x20, 0x06,
-	0xac, 0xff, 0x00, 0xb6, 0xce, 0x87, 0x93, 0x20, 0xc4, 0xe3, 0xb6, 0x7f, 0x43, 0xe0, 0x3e, 0x28,
-	0x78, 0xfd, 0x38, 0x80, 0x56, 0x79, 0xfa, 0x70, 0x52, 0x68, 0x5f, 0x38, 0x05, 0xaf, 0x0f, 0xdf,
-	0x81, 0xed, 0x3e, 0xa6, 0x43, 0x72, 0x3f, 0xc2, 0x7e, 0xd8, 0xf1, 0xfa, 0x71, 0x89, 0xd6, 0x93,
-	0xe9, 0xc3, 0x49, 0xf5, 0x42, 0x2c, 0xb4, 0x2f, 0x9c, 0xea, 0x4c, 0xd6, 0xee, 0x37, 0xff, 0xd5,
-	0x40, 0xf1, 0xfd, 0x75, 0x1b, 0x9a, 0x60, 0x83, 0x4f, 0x0a, 0xf7, 0x78, 0x47, 0xe9, 0xe8, 0x6b,
-	0xb3, 0x46, 0xeb, 0x6b, 0x0d, 0x0d, 0x9e, 0x81, 0x0f, 0x94, 0x68, 0xe0, 0x51, 0xda, 0xa8, 0x44,
-	0x96, 0x2a, 0x00, 0xbf, 0x04, 0x1b, 0x3c, 0x14, 0xb1, 0x5f, 0x3a, 0xa4, 0xda, 0xbe, 0x11, 0x9f,
-	0x41, 0x8d, 0xe4, 0x0c, 0x6a, 0x5c, 0x46, 0x67, 0xd0, 0xfa, 0xda, 0x4b, 0x0d, 0x7e, 0x05, 0x76,
-	0xda, 0x7e, 0x40, 0xb1, 0x1b, 0xf2, 0x68, 0x60, 0x8e, 0xba, 0x06, 0x79, 0x71, 0x29, 0xc2, 0xfa,
-	0x5a, 0xeb, 0xec, 0x8f, 0xe9, 0xb1, 0xf6, 0xe7, 0xf4, 0x58, 0xfb, 0x7b, 0x7a, 0xac, 0x7d, 0x6f,
-	0x0e, 0xbc, 0xf0, 0x76, 0xd2, 0x33, 0x5c, 0x32, 0x32, 0x69, 0xd7, 0xbd, 0xbd, 0xef, 0xe3, 0xb1,
-	0x7c, 0x17, 0x8c, 0x5d, 0x53, 0x3e, 0xb0, 0xf5, 0xca, 0x6c, 0x93, 0x37, 0xff, 0x05, 0x00, 0x00,
-	0xff, 0xff, 0x88, 0x96, 0x62, 0x4f, 0x47, 0x0f, 0x00, 0x00,
+	// 1053 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x97, 0xc1, 0x6f, 0xe3, 0x44,
+	0x14, 0xc6, 0x6b, 0xa7, 0x49, 0xd3, 0x69, 0x5a, 0x56, 0xa3, 0xb6, 0xb8, 0xe9, 0xb6, 0xdd, 0x8d,
+	0x90, 0x76, 0x59, 0x16, 0x3b, 0x93, 0xdd, 0xa5, 0x36, 0x50, 0xa4, 0x4d, 0xbb, 0x87, 0x20, 0xa4,
+	0x56, 0xd6, 0x72, 0x41, 0x48, 0x51, 0xe2, 0x4c, 0x53, 0x97, 0xc4, 0x33, 0xc4, 0x0e, 0xa2, 0x27,
+	0xfe, 0x33, 0xce, 0x1c, 0xf9, 0x0b, 0x0a, 0xca, 0x89, 0x3b, 0x07, 0xae, 0xc8, 0xe3, 0xb1, 0x63,
+	0x8f, 0xed, 0x7a, 0xe3, 0x43, 0x2a, 0xd7, 0xf3, 0x7d, 0x6f, 0xde, 0xfb, 0x7e, 0xa3, 0xc4, 0x06,
+	0x8a, 0x35, 0xb1, 0xb1, 0xe3, 0x69, 0x83, 0xd1, 0xd4, 0x76, 0x82, 0xbf, 0x2a, 0x9d, 0x11, 0x8f,
+	0xc0, 0x2a, 0xfb, 0xa7, 0x79, 0x38, 0x26, 0x64, 0x3c, 0xc1, 0x1a, 0xbb, 0x39, 0x9c, 0x5f, 0x6b,
+	0x78, 0x4a, 0xbd, 0xbb, 0x40, 0xd3, 0xdc, 0x1d, 0x93, 0x31, 0x61, 0x97, 0x9a, 0x7f, 0xc5, 0xef,
+	0x9e, 0x24, 0x6a, 0xfe, 0x82, 0xfa, 0xa7, 0x1a, 0xbd, 0x76, 0xfd, 0xcf, 0x03, 0x02, 0xea, 0xfa,
+	0x9f, 0x3c, 0x81, 0x5e, 0x54, 0x41, 0x2f, 0xaa, 0x60, 0x14, 0x55, 0x30, 0x84, 0x0a, 0x4f, 0x44,
+	0x01, 0x6a, 0x0b, 0x25, 0x32, 0x15, 0x05, 0x35, 0x50, 0x61, 0x0d, 0x24, 0xd4, 0xd8, 0xe5, 0x8a,
+	0xa4, 0x2f, 0xba, 0x1b, 0xd7, 0xb6, 0x7e, 0x97, 0x41, 0xf5, 0x92, 0xa2, 0xfe, 0x29, 0x44, 0xa0,
+	0x46, 0x86, 0xb7, 0xd8, 0xf2, 0x14, 0xf9, 0x89, 0xf4, 0x7c, 0xab, 0x73, 0xa0, 0xd2, 0x6b, 0xb7,
+	0x8f, 0xfa, 0xa7, 0xea, 0xd5, 0xdc, 0xbb, 0x64, 0x2b, 0x26, 0xfe, 0x79, 0x8e, 0x5d, 0xcf, 0xe4,
+	0x42, 0xf8, 0x19, 0xa8, 0x78, 0x83, 0xb1, 0x52, 0x11, 0xf4, 0xef, 0x07, 0xe3, 0xa4, 0xde, 0x57,
+	0x41, 0x15, 0xac, 0xcf, 0x30, 0x25, 0xca, 0x3a, 0x53, 0x37, 0x23, 0xf5, 0xf9, 0x0c, 0x0f, 0x3c,
+	0x6c, 0x62, 0x4a, 0x42, 0x39, 0xd3, 0xc1, 0x57, 0xa0, 0x66, 0x91, 0xe9, 0xd4, 0xf6, 0x94, 0x2a,
+	0x73, 0x1c, 0x46, 0x8e, 0xee, 0xdc, 0x9e, 0x8c, 0xce, 0xd9, 0x5a, 0xd4, 0x51, 0x20, 0x85, 0xaf,
+	0x41, 0x6d, 0x38, 0x1b, 0x38, 0xd6, 0x8d, 0x52, 0x63, 0xa6, 0xc7, 0xc2, 0x36, 0x5d, 0xb6, 0x18,
+	0xb9, 0x02, 0x2d, 0xfc, 0x12, 0xd4, 0xa9, 0x4d, 0xf1, 0xc4, 0x76, 0xb0, 0xb2, 0xc1, 0x7c, 0xc7,
+	0x2a, 0xa5, 0x71, 0xdf, 0x15, 0x5f, 0x0e, 0x9d, 0x91, 0x3e, 0x0a, 0x50, 0xcf, 0x0d, 0x50, 0x5f,
+	0x31, 0x40, 0x7d, 0xa5, 0x00, 0xf5, 0x95, 0x03, 0xd4, 0xcb, 0x04, 0xa8, 0x97, 0x0c, 0x50, 0x2f,
+	0x0c, 0xf0, 0xbe, 0x12, 0x04, 0x68, 0xe4, 0x06, 0x68, 0xe4, 0x07, 0xf8, 0x16, 0x6c, 0x5b, 0xac,
+	0x7e, 0x9f, 0x3b, 0x37, 0x13, 0x5d, 0x1b, 0x7c, 0xf7, 0xa4, 0xb9, 0x61, 0xc5, 0x6e, 0x66, 0x33,
+	0x30, 0x72, 0x19, 0x54, 0x87, 0x13, 0x62, 0xfd, 0xa4, 0x00, 0x26, 0x57, 0xe2, 0x1d, 0x76, 0xfd,
+	0x85, 0x50, 0x1d, 0xc8, 0x72, 0x98, 0x19, 0x2b, 0x33, 0x33, 0xca, 0x30, 0x33, 0x4a, 0x32, 0x33,
+	0x8a, 0x98, 0xf9, 0x99, 0xdd, 0x92, 0xa1, 0x52, 0x0f, 0x33, 0x4b, 0xd8, 0xbe, 0x25, 0xc3, 0x28,
+	0xb3, 0x5b, 0x32, 0x6c, 0xfd, 0x53, 0x01, 0x35, 0x1f, 0x30, 0x6a, 0xc3, 0x8e, 0x40, 0x38, 0x0c,
+	0x04, 0xb5, 0xf3, 0x11, 0x77, 0xb3, 0x11, 0x1f, 0x2d, 0xad, 0xc5, 0x8c, 0x5f, 0xc6, 0x19, 0xc7,
+	0x36, 0xcd, 0x86, 0xac, 0x25, 0x21, 0x1f, 0x24, 0x9a, 0xcc, 0xa2, 0xac, 0x25, 0x28, 0x1f, 0x8a,
+	0x9d, 0xa5, 0x31, 0xbf, 0x16, 0x30, 0x3f, 0x5e, 0x5a, 0x1e, 0xe0, 0xfc, 0x46, 0xe0, 0x9c, 0x8a,
+	0x20, 0x1b, 0xf4, 0x57, 0x29, 0xd0, 0x27, 0x9c, 0x18, 0x6a, 0x17, 0x92, 0x7e, 0x19, 0x27, 0xdd,
+	0x14, 0x7d, 0xb9, 0xa8, 0x51, 0x3e, 0x6a, 0x54, 0x1e, 0x35, 0x2a, 0x8d, 0x1a, 0xad, 0x88, 0x1a,
+	0xad, 0x88, 0x1a, 0xad, 0x8e, 0x1a, 0x95, 0x42, 0x8d, 0xca, 0xa2, 0x46, 0x25, 0x51, 0xa3, 0x1c,
+	0xd4, 0xff, 0x85, 0xa8, 0x3b, 0xf0, 0x73, 0x01, 0xf5, 0x9e, 0xdf, 0x6c, 0x3e, 0xe5, 0xb3, 0x6c,
+	0xca, 0xec, 0xbb, 0xf4, 0x03, 0x00, 0x3f, 0x8b, 0x03, 0x0e, 0xb6, 0xca, 0x66, 0xfb, 0x22, 0xc9,
+	0x76, 0x37, 0xec, 0x2a, 0x0b, 0xeb, 0x8b, 0x04, 0xd6, 0xfd, 0x58, 0x2b, 0x69, 0xa2, 0x9a, 0x40,
+	0xf4, 0x63, 0xa6, 0x7e, 0x00, 0x66, 0x5b, 0x80, 0x19, 0x9f, 0x34, 0xef, 0xbb, 0x19, 0x04, 0xde,
+	0xbe, 0x3f, 0xea, 0xd6, 0xf2, 0xac, 0x71, 0x57, 0xb0, 0xcf, 0xfb, 0xc1, 0x38, 0x34, 0x6e, 0x5a,
+	0xe1, 0x1d, 0xf8, 0x45, 0xea, 0x0c, 0x30, 0x96, 0x85, 0xf8, 0x9f, 0xc5, 0xf1, 0xef, 0xc5, 0x2c,
+	0x22, 0xf9, 0xbf, 0x24, 0x20, 0x5f, 0x52, 0xf8, 0x14, 0x54, 0x89, 0xff, 0xe0, 0xa8, 0x48, 0xcc,
+	0xd1, 0x50, 0x83, 0x57, 0x01, 0xf6, 0x30, 0x69, 0xae, 0x13, 0x8a, 0x4e, 0x43, 0x89, 0xae, 0xc8,
+	0x29, 0x89, 0xce, 0x24, 0x7a, 0x28, 0x31, 0x94, 0x4a, 0x4a, 0x62, 0x30, 0x89, 0x01, 0x3f, 0x01,
+	0x35, 0xc2, 0x7e, 0x3e, 0x38, 0x9d, 0xed, 0x98, 0x06, 0xb5, 0x4d, 0xdf, 0x8f, 0xda, 0x91, 0x0a,
+	0x29, 0xd5, 0xb4, 0x0a, 0x05, 0x2a, 0x14, 0xa9, 0x3a, 0x4a, 0x2d, 0xad, 0xea, 0x04, 0xaa, 0x4e,
+	0xeb, 0x37, 0xb0, 0xf3, 0xee, 0x57, 0x6f, 0x36, 0x88, 0x0e, 0x14, 0x7c, 0x04, 0x2a, 0xdf, 0x9b,
+	0xdf, 0xb1, 0x51, 0x37, 0x4d, 0xff, 0x12, 0x1e, 0x01, 0xe0, 0x10, 0x7e, 0x82, 0x5d, 0x36, 0x60,
+	0xdd, 0xdc, 0x74, 0x48, 0x70, 0x0e, 0x5d, 0x78, 0x00, 0xea, 0x0e, 0xe9, 0xfb, 0xe7, 0xc5, 0x65,
+	0xa3, 0xd5, 0xcd, 0x0d, 0x87, 0xf8, 0x67, 0xc9, 0x85, 0x4f, 0x41, 0xc3, 0x21, 0xfd, 0x30, 0x77,
+	0x97, 0x4d, 0x55, 0x37, 0xb7, 0x1c, 0x12, 0xb2, 0x71, 0x5b, 0xe7, 0x60, 0x9f, 0x37, 0x20, 0xf0,
+	0x82, 0x9f, 0xc6, 0xe8, 0x4a, 0x7c, 0x04, 0x1f, 0x55, 0xa4, 0x5b, 0x3e, 0x58, 0x9d, 0x81, 0x1d,
+	0x13, 0xbb, 0x1e, 0x99, 0x45, 0xe6, 0x03, 0x20, 0x13, 0xca, 0x6d, 0x9b, 0xd1, 0xe4, 0xa6, 0x4c,
+	0x68, 0x38, 0xa0, 0x1c, 0x0d, 0xd8, 0xfa, 0x11, 0x6c, 0x9d, 0x4f, 0xe6, 0xae, 0x87, 0x67, 0x3d,
+	0xe7, 0x9a, 0xc0, 0x7d, 0x20, 0xdb, 0xa3, 0x20, 0x80, 0x6e, 0x6d, 0x71, 0x7f, 0x22, 0xf7, 0x2e,
+	0x4c, 0xd9, 0x1e, 0xc1, 0x37, 0x60, 0x7b, 0x84, 0xe9, 0x84, 0xdc, 0x4d, 0xb1, 0xe3, 0xf5, 0xed,
+	0x51, 0x50, 0xa2, 0xfb, 0x68, 0x71, 0x7f, 0xd2, 0xb8, 0x88, 0x16, 0x7a, 0x17, 0x66, 0x63, 0x29,
+	0xeb, 0x8d, 0x3a, 0xff, 0x4a, 0xa0, 0xf2, 0xf6, 0xaa, 0x07, 0x35, 0xb0, 0xc1, 0x27, 0x85, 0x7b,
+	0xbc, 0xa3, 0x64, 0xf4, 0xcd, 0x65, 0xa3, 0xad, 0xb5, 0xb6, 0x04, 0xcf, 0xc0, 0x47, 0x42, 0x34,
+	0xf0, 0x28, 0x69, 0x14, 0x22, 0x4b, 0x14, 0x80, 0x5f, 0x83, 0x0d, 0x1e, 0x4a, 0xb4, 0x5f, 0x32,
+	0xa4, 0xe6, 0xbe, 0x1a, 0xbc, 0xbf, 0xaa, 0xe1, 0xfb, 0xab, 0xfa, 0xce, 0x7f, 0x7f, 0x6d, 0xad,
+	0x3d, 0x97, 0xe0, 0x37, 0x60, 0xa7, 0xe7, 0xb8, 0x14, 0x5b, 0x1e, 0x8f, 0x06, 0xe6, 0xa8, 0x9b,
+	0x90, 0x17, 0x8f, 0x45, 0xd8, 0x5a, 0xeb, 0x9e, 0xfd, 0xb1, 0x38, 0x96, 0xfe, 0x5c, 0x1c, 0x4b,
+	0x7f, 0x2f, 0x8e, 0xa5, 0x1f, 0xb4, 0xb1, 0xed, 0xdd, 0xcc, 0x87, 0xaa, 0x45, 0xa6, 0x1a, 0x1d,
+	0x58, 0x37, 0x77, 0x23, 0x3c, 0x8b, 0x5f, 0xb9, 0x33, 0x4b, 0x8b, 0xbf, 0xec, 0x0d, 0x6b, 0x6c,
+	0x93, 0x57, 0xff, 0x0f, 0x00, 0xe0, 0x2e, 0xe5, 0xb3, 0x83, 0x0f, 0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -1942,6 +1952,18 @@ func (m *Op1_12) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 		i -= len(m.XXX_unrecognized)
 		copy(dAtA[i:], m.XXX_unrecognized)
 	}
+	if m.CommitTag != nil {
+		{
+			size, err := m.CommitTag.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintAdmin(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x5a
+	}
 	if m.Block != nil {
 		{
 			size, err := m.Block.MarshalToSizedBuffer(dAtA[:i])
@@ -2611,6 +2633,10 @@ func (m *Op1_12) Size() (n int) {
 		l = m.Block.Size()
 		n += 1 + l + sovAdmin(uint64(l))
 	}
+	if m.CommitTag != nil {
+		l = m.CommitTag.Size()
+		n += 1 + l + sovAdmin(uint64(l))
+	}
 	if m.XXX_unrecognized != nil {
 		n += len(m.XXX_unrecognized)
 	}
@@ -4767,6 +4793,42 @@ func (m *Op1_12) Unmarshal(dAtA []byte) error {
 				return err
 			}
 			iNdEx = postIndex
+		case 11:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field CommitTag", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthAdmin
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthAdmin
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.CommitTag == nil {
+				m.CommitTag = &pfs5.CreateCommitTagRequest{}
+			}
+			if err := m.CommitTag.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipAdmin(dAtA[iNdEx:])
@@ -3,8 +3,13 @@ package client
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"sync"
+	"time"
 
 	"github.com/gogo/protobuf/types"
 	"github.com/pachyderm/pachyderm/src/client/pfs"
@@ -34,6 +39,14 @@ func NewCommit(repoName string, commitID string) *pfs.Commit {
 	}
 }
 
+// NewCommitTag creates a pfs.CommitTag.
+func NewCommitTag(repoName string, tagName string) *pfs.CommitTag {
+	return &pfs.CommitTag{
+		Repo: NewRepo(repoName),
+		Name: tagName,
+	}
+}
+
 // NewCommitProvenance creates a pfs.CommitProvenance.
 func NewCommitProvenance(repoName string, branchName string, commitID string) *pfs.CommitProvenance {
 	return &pfs.CommitProvenance{
@@ -153,6 +166,35 @@ func (c APIClient) DeleteRepo(repoName string, force bool) error {
 	return grpcutil.ScrubGRPC(err)
 }
 
+// RenameRepo renames a repo. Branches, commits, provenance, ACLs and the
+// inputs of pipelines that read from the repo are all updated to use the new
+// name.
+func (c APIClient) RenameRepo(repoName string, newName string) error {
+	_, err := c.PfsAPIClient.RenameRepo(
+		c.Ctx(),
+		&pfs.RenameRepoRequest{
+			Repo:    NewRepo(repoName),
+			NewName: newName,
+		},
+	)
+	return grpcutil.ScrubGRPC(err)
+}
+
+// ForkRepo creates the repo 'newRepoName', whose initial commit on 'branch'
+// shares the contents of the head of 'branch' in 'repoName'. If 'branch' is
+// "", master is used.
+func (c APIClient) ForkRepo(repoName string, newRepoName string, branch string) error {
+	_, err := c.PfsAPIClient.ForkRepo(
+		c.Ctx(),
+		&pfs.ForkRepoRequest{
+			Repo:    NewRepo(repoName),
+			NewRepo: NewRepo(newRepoName),
+			Branch:  branch,
+		},
+	)
+	return grpcutil.ScrubGRPC(err)
+}
+
 // StartCommit begins the process of committing data to a Repo. Once started
 // you can write to the Commit with PutFile and when all the data has been
 // written you must finish the Commit with FinishCommit. NOTE, data is not
@@ -301,11 +343,18 @@ func (c APIClient) ListCommit(repoName string, to string, from string, number ui
 // `reverse` lists the commits from oldest to newest, rather than newest to oldest
 // all commits that match the aforementioned criteria are passed to f.
 func (c APIClient) ListCommitF(repoName string, to string, from string, number uint64, reverse bool, f func(*pfs.CommitInfo) error) error {
+	return c.ListCommitFilterF(repoName, to, from, number, reverse, nil, f)
+}
+
+// ListCommitFilterF is like ListCommitF, but only passes commits that have all
+// of the key/value pairs in 'annotations' to f.
+func (c APIClient) ListCommitFilterF(repoName string, to string, from string, number uint64, reverse bool, annotations map[string]string, f func(*pfs.CommitInfo) error) error {
 	req := &pfs.ListCommitRequest{
 		// repoName may be "", but the repo object must exist
-		Repo:    NewRepo(repoName),
-		Number:  number,
-		Reverse: reverse,
+		Repo:        NewRepo(repoName),
+		Number:      number,
+		Reverse:     reverse,
+		Annotations: annotations,
 	}
 	if from != "" {
 		req.From = NewCommit(repoName, from)
@@ -401,6 +450,85 @@ func (c APIClient) DeleteBranch(repoName string, branch string, force bool) erro
 	return grpcutil.ScrubGRPC(err)
 }
 
+// RenameBranch renames a branch. Commits, provenance and the inputs of
+// pipelines that read from the branch are all updated to use the new name.
+func (c APIClient) RenameBranch(repoName string, branch string, newName string) error {
+	_, err := c.PfsAPIClient.RenameBranch(
+		c.Ctx(),
+		&pfs.RenameBranchRequest{
+			Branch:  NewBranch(repoName, branch),
+			NewName: newName,
+		},
+	)
+	return grpcutil.ScrubGRPC(err)
+}
+
+// MergeBranch merges the head of branch 'from' into branch 'to' in the same
+// repo, and returns the resulting merge commit. Paths that were changed on both
+// branches are resolved using 'strategy'; with pfs.MergeStrategy_FAIL they
+// cause the merge to fail.
+func (c APIClient) MergeBranch(repoName string, from string, to string, strategy pfs.MergeStrategy, description string) (*pfs.Commit, error) {
+	commit, err := c.PfsAPIClient.MergeBranch(
+		c.Ctx(),
+		&pfs.MergeBranchRequest{
+			From:        NewBranch(repoName, from),
+			To:          NewBranch(repoName, to),
+			Strategy:    strategy,
+			Description: description,
+		},
+	)
+	return commit, grpcutil.ScrubGRPC(err)
+}
+
+// CreateCommitTag creates an immutable tag named 'tag' for the given commit.
+// The tag can then be used anywhere a commit ID is accepted.
+func (c APIClient) CreateCommitTag(repoName string, tag string, commitID string) error {
+	_, err := c.PfsAPIClient.CreateCommitTag(
+		c.Ctx(),
+		&pfs.CreateCommitTagRequest{
+			Tag:    NewCommitTag(repoName, tag),
+			Commit: NewCommit(repoName, commitID),
+		},
+	)
+	return grpcutil.ScrubGRPC(err)
+}
+
+// InspectCommitTag returns information on a specific commit tag.
+func (c APIClient) InspectCommitTag(repoName string, tag string) (*pfs.CommitTagInfo, error) {
+	tagInfo, err := c.PfsAPIClient.InspectCommitTag(
+		c.Ctx(),
+		&pfs.InspectCommitTagRequest{
+			Tag: NewCommitTag(repoName, tag),
+		},
+	)
+	return tagInfo, grpcutil.ScrubGRPC(err)
+}
+
+// ListCommitTag lists the commit tags in a Repo.
+func (c APIClient) ListCommitTag(repoName string) ([]*pfs.CommitTagInfo, error) {
+	tagInfos, err := c.PfsAPIClient.ListCommitTag(
+		c.Ctx(),
+		&pfs.ListCommitTagRequest{
+			Repo: NewRepo(repoName),
+		},
+	)
+	if err != nil {
+		return nil, grpcutil.ScrubGRPC(err)
+	}
+	return tagInfos.CommitTagInfo, nil
+}
+
+// DeleteCommitTag deletes a commit tag, but leaves the commit itself intact.
+func (c APIClient) DeleteCommitTag(repoName string, tag string) error {
+	_, err := c.PfsAPIClient.DeleteCommitTag(
+		c.Ctx(),
+		&pfs.DeleteCommitTagRequest{
+			Tag: NewCommitTag(repoName, tag),
+		},
+	)
+	return grpcutil.ScrubGRPC(err)
+}
+
 // DeleteCommit deletes a commit.
 func (c APIClient) DeleteCommit(repoName string, commitID string) error {
 	_, err := c.PfsAPIClient.DeleteCommit(
@@ -412,6 +540,58 @@ func (c APIClient) DeleteCommit(repoName string, commitID string) error {
 	return grpcutil.ScrubGRPC(err)
 }
 
+// RevertCommit creates a new commit on 'branch' that undoes the changes made
+// in the given commit. If 'branch' is "", the commit's own branch is used.
+func (c APIClient) RevertCommit(repoName string, commitID string, branch string, description string) (*pfs.Commit, error) {
+	request := &pfs.RevertCommitRequest{
+		Commit:      NewCommit(repoName, commitID),
+		Description: description,
+	}
+	if branch != "" {
+		request.Branch = NewBranch(repoName, branch)
+	}
+	commit, err := c.PfsAPIClient.RevertCommit(c.Ctx(), request)
+	if err != nil {
+		return nil, grpcutil.ScrubGRPC(err)
+	}
+	return commit, nil
+}
+
+// CherryPickCommit creates a new commit on 'branch' that re-applies the
+// changes made in the given commit.
+func (c APIClient) CherryPickCommit(repoName string, commitID string, branch string, description string) (*pfs.Commit, error) {
+	commit, err := c.PfsAPIClient.CherryPickCommit(
+		c.Ctx(),
+		&pfs.CherryPickCommitRequest{
+			Commit:      NewCommit(repoName, commitID),
+			Branch:      NewBranch(repoName, branch),
+			Description: description,
+		},
+	)
+	if err != nil {
+		return nil, grpcutil.ScrubGRPC(err)
+	}
+	return commit, nil
+}
+
+// SquashCommits collapses the commits from 'from' to 'to' (inclusive) into a
+// single commit, which keeps the ID of 'to'. If 'description' is "", the
+// squashed commit keeps the description of 'to'.
+func (c APIClient) SquashCommits(repoName string, from string, to string, description string) (*pfs.Commit, error) {
+	commit, err := c.PfsAPIClient.SquashCommits(
+		c.Ctx(),
+		&pfs.SquashCommitsRequest{
+			From:        NewCommit(repoName, from),
+			To:          NewCommit(repoName, to),
+			Description: description,
+		},
+	)
+	if err != nil {
+		return nil, grpcutil.ScrubGRPC(err)
+	}
+	return commit, nil
+}
+
 // FlushCommit returns an iterator that returns commits that have the
 // specified `commits` as provenance.  Note that the iterator can block if
 // jobs have not successfully completed. This in effect waits for all of the
@@ -572,6 +752,39 @@ func (c APIClient) SubscribeCommitF(repo, branch string, prov *pfs.CommitProvena
 	}
 }
 
+// SubscribeFile calls 'f' with each change to the files matching the glob
+// pattern 'pattern' (or in a directory that matches it) in finished commits
+// on 'branch', as they come in. If 'from' is set, only changes made by commits
+// created since it are returned. Return errutil.ErrBreak from 'f' to stop.
+func (c APIClient) SubscribeFile(repo, branch, pattern, from string, f func(*pfs.FileChange) error) error {
+	ctx, cancel := context.WithCancel(c.Ctx())
+	defer cancel()
+	req := &pfs.SubscribeFileRequest{
+		File: NewFile(repo, branch, pattern),
+	}
+	if from != "" {
+		req.From = NewCommit(repo, from)
+	}
+	stream, err := c.PfsAPIClient.SubscribeFile(ctx, req)
+	if err != nil {
+		return grpcutil.ScrubGRPC(err)
+	}
+	for {
+		change, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return grpcutil.ScrubGRPC(err)
+		}
+		if err := f(change); err != nil {
+			if errors.Is(err, errutil.ErrBreak) {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
 // PutObjectAsync puts a value into the object store asynchronously.
 func (c APIClient) PutObjectAsync(tags []*pfs.Tag) (*PutObjectWriteCloserAsync, error) {
 	w, err := c.newPutObjectWriteCloserAsync(tags)
@@ -936,15 +1149,42 @@ type PutFileClient interface {
 	// overwrite the entire file, specify an index of 0.
 	PutFileOverwrite(repoName string, commitID string, path string, reader io.Reader, overwriteIndex int64) (_ int, retErr error)
 
+	// PutFileMetadata is like PutFile, but it also attaches the user-defined
+	// key/value pairs in 'metadata' to the file, overwriting existing keys. If
+	// overwrite is true, the file's contents and metadata are replaced rather
+	// than appended to.
+	PutFileMetadata(repoName string, commitID string, path string, reader io.Reader, overwrite bool, metadata map[string]string) (_ int, retErr error)
+
+	// PutFileUnpack reads an archive in 'format' from 'reader', and puts each
+	// file in it under 'path' (with its path in the archive). The archive is
+	// unpacked by pachd. If overwrite is true, each file replaces any
+	// existing file at its path rather than being appended to it.
+	PutFileUnpack(repoName string, commitID string, path string, format pfs.ArchiveFormat, overwrite bool, reader io.Reader) (_ int, retErr error)
+
 	// PutFileSplit writes a file to PFS from a reader.
 	// delimiter is used to tell PFS how to break the input into blocks.
 	PutFileSplit(repoName string, commitID string, path string, delimiter pfs.Delimiter, targetFileDatums int64, targetFileBytes int64, headerRecords int64, overwrite bool, reader io.Reader) (_ int, retErr error)
 
+	// PutFileSplitRegex is like PutFileSplit with the REGEX delimiter: each
+	// record begins with a line that matches 'regex'.
+	PutFileSplitRegex(repoName string, commitID string, path string, regex string, targetFileDatums int64, targetFileBytes int64, headerRecords int64, overwrite bool, reader io.Reader) (_ int, retErr error)
+
+	// PutFileSplitMetadata is like PutFileSplit (or PutFileSplitRegex, if
+	// delimiter is REGEX), but it also attaches the user-defined key/value
+	// pairs in 'metadata' to each of the files the data is split into.
+	PutFileSplitMetadata(repoName string, commitID string, path string, delimiter pfs.Delimiter, regex string, targetFileDatums int64, targetFileBytes int64, headerRecords int64, overwrite bool, metadata map[string]string, reader io.Reader) (_ int, retErr error)
+
 	// PutFileURL puts a file using the content found at a URL.
 	// The URL is sent to the server which performs the request.
 	// recursive allows for recursive scraping of some types URLs. For example on s3:// urls.
 	PutFileURL(repoName string, commitID string, path string, url string, recursive bool, overwrite bool) error
 
+	// PutSymlink creates a symlink at 'path' that points to 'target',
+	// replacing any regular file that's already there. Relative targets are
+	// relative to the directory containing the symlink, and absolute targets
+	// are relative to the root of the commit.
+	PutSymlink(repoName string, commitID string, path string, target string) error
+
 	// DeleteFile deletes a file from a Commit.
 	// DeleteFile leaves a tombstone in the Commit, assuming the file isn't written
 	// to later attempting to get the file from the finished commit will result in
@@ -1024,6 +1264,55 @@ func (c *putFileClient) PutFileOverwrite(repoName string, commitID string, path
 	return int(written), grpcutil.ScrubGRPC(err)
 }
 
+// PutFileMetadata is like PutFile, but it also attaches the user-defined
+// key/value pairs in 'metadata' to the file, overwriting existing keys. If
+// overwrite is true, the file's contents and metadata are replaced rather than
+// appended to.
+func (c *putFileClient) PutFileMetadata(repoName string, commitID string, path string, reader io.Reader, overwrite bool, metadata map[string]string) (_ int, retErr error) {
+	var overwriteIndex *pfs.OverwriteIndex
+	if overwrite {
+		overwriteIndex = &pfs.OverwriteIndex{}
+	}
+	writer, err := c.newPutFileWriteCloser(repoName, commitID, path, pfs.Delimiter_NONE, 0, 0, 0, overwriteIndex)
+	if err != nil {
+		return 0, grpcutil.ScrubGRPC(err)
+	}
+	writer.request.Metadata = metadata
+	defer func() {
+		if err := writer.Close(); err != nil && retErr == nil {
+			retErr = err
+		}
+	}()
+	written, err := io.Copy(writer, reader)
+	return int(written), grpcutil.ScrubGRPC(err)
+}
+
+// PutFileUnpack reads an archive in 'format' from 'reader', and puts each file
+// in it under 'path'. See PutFileClient.PutFileUnpack.
+func (c *putFileClient) PutFileUnpack(repoName string, commitID string, path string, format pfs.ArchiveFormat, overwrite bool, reader io.Reader) (_ int, retErr error) {
+	if format == pfs.ArchiveFormat_RAW {
+		return 0, errors.New("an archive format must be specified to unpack")
+	}
+	var overwriteIndex *pfs.OverwriteIndex
+	if overwrite {
+		overwriteIndex = &pfs.OverwriteIndex{}
+	}
+	writer, err := c.newPutFileWriteCloser(repoName, commitID, path, pfs.Delimiter_NONE, 0, 0, 0, overwriteIndex)
+	if err != nil {
+		return 0, grpcutil.ScrubGRPC(err)
+	}
+	writer.request.Unpack = format
+	defer func() {
+		if err := writer.Close(); err != nil && retErr == nil {
+			retErr = err
+		}
+	}()
+	buf := grpcutil.GetBuffer()
+	defer grpcutil.PutBuffer(buf)
+	written, err := io.CopyBuffer(writer, reader, buf)
+	return int(written), grpcutil.ScrubGRPC(err)
+}
+
 //PutFileSplit writes a file to PFS from a reader
 // delimiter is used to tell PFS how to break the input into blocks
 func (c *putFileClient) PutFileSplit(repoName string, commitID string, path string, delimiter pfs.Delimiter, targetFileDatums int64, targetFileBytes int64, headerRecords int64, overwrite bool, reader io.Reader) (_ int, retErr error) {
@@ -1042,6 +1331,58 @@ func (c *putFileClient) PutFileSplit(repoName string, commitID string, path stri
 	return int(written), grpcutil.ScrubGRPC(err)
 }
 
+// PutFileSplitRegex is like PutFileSplit with the REGEX delimiter: each record
+// begins with a line that matches 'regex'.
+func (c *putFileClient) PutFileSplitRegex(repoName string, commitID string, path string, regex string, targetFileDatums int64, targetFileBytes int64, headerRecords int64, overwrite bool, reader io.Reader) (_ int, retErr error) {
+	return c.PutFileSplitMetadata(repoName, commitID, path, pfs.Delimiter_REGEX, regex, targetFileDatums, targetFileBytes, headerRecords, overwrite, nil, reader)
+}
+
+// PutFileSplitMetadata is like PutFileSplit (or PutFileSplitRegex, if
+// delimiter is REGEX), but it also attaches the user-defined key/value pairs
+// in 'metadata' to each of the files the data is split into.
+func (c *putFileClient) PutFileSplitMetadata(repoName string, commitID string, path string, delimiter pfs.Delimiter, regex string, targetFileDatums int64, targetFileBytes int64, headerRecords int64, overwrite bool, metadata map[string]string, reader io.Reader) (_ int, retErr error) {
+	var overwriteIndex *pfs.OverwriteIndex
+	if overwrite {
+		overwriteIndex = &pfs.OverwriteIndex{}
+	}
+	writer, err := c.newPutFileWriteCloser(repoName, commitID, path, delimiter, targetFileDatums, targetFileBytes, headerRecords, overwriteIndex)
+	if err != nil {
+		return 0, grpcutil.ScrubGRPC(err)
+	}
+	writer.request.DelimiterRegex = regex
+	writer.request.Metadata = metadata
+	defer func() {
+		if err := writer.Close(); err != nil && retErr == nil {
+			retErr = err
+		}
+	}()
+	buf := grpcutil.GetBuffer()
+	defer grpcutil.PutBuffer(buf)
+	written, err := io.CopyBuffer(writer, reader, buf)
+	return int(written), grpcutil.ScrubGRPC(err)
+}
+
+// PutFileSplitRegex is like PutFileSplit with the REGEX delimiter: each record
+// begins with a line that matches 'regex'.
+func (c APIClient) PutFileSplitRegex(repoName string, commitID string, path string, regex string, targetFileDatums int64, targetFileBytes int64, headerRecords int64, overwrite bool, reader io.Reader) (_ int, retErr error) {
+	pfc, err := c.newOneoffPutFileClient()
+	if err != nil {
+		return 0, err
+	}
+	return pfc.PutFileSplitRegex(repoName, commitID, path, regex, targetFileDatums, targetFileBytes, headerRecords, overwrite, reader)
+}
+
+// PutFileSplitMetadata is like PutFileSplit (or PutFileSplitRegex, if
+// delimiter is REGEX), but it also attaches the user-defined key/value pairs
+// in 'metadata' to each of the files the data is split into.
+func (c APIClient) PutFileSplitMetadata(repoName string, commitID string, path string, delimiter pfs.Delimiter, regex string, targetFileDatums int64, targetFileBytes int64, headerRecords int64, overwrite bool, metadata map[string]string, reader io.Reader) (_ int, retErr error) {
+	pfc, err := c.newOneoffPutFileClient()
+	if err != nil {
+		return 0, err
+	}
+	return pfc.PutFileSplitMetadata(repoName, commitID, path, delimiter, regex, targetFileDatums, targetFileBytes, headerRecords, overwrite, metadata, reader)
+}
+
 // PutFileURL puts a file using the content found at a URL.
 // The URL is sent to the server which performs the request.
 // recursive allow for recursive scraping of some types URLs for example on s3:// urls.
@@ -1070,6 +1411,29 @@ func (c *putFileClient) PutFileURL(repoName string, commitID string, path string
 	return nil
 }
 
+// PutSymlink creates a symlink at 'path' that points to 'target', replacing
+// any regular file that's already there. Relative targets are relative to the
+// directory containing the symlink, and absolute targets are relative to the
+// root of the commit.
+func (c *putFileClient) PutSymlink(repoName string, commitID string, path string, target string) (retErr error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.oneoff {
+		defer func() {
+			if err := grpcutil.ScrubGRPC(c.Close()); err != nil && retErr == nil {
+				retErr = err
+			}
+		}()
+	}
+	if err := c.c.Send(&pfs.PutFileRequest{
+		File:    NewFile(repoName, commitID, path),
+		Symlink: target,
+	}); err != nil {
+		return grpcutil.ScrubGRPC(err)
+	}
+	return nil
+}
+
 func (c *putFileClient) DeleteFile(repoName string, commitID string, path string) (retErr error) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -1141,6 +1505,29 @@ func (c APIClient) PutFileOverwrite(repoName string, commitID string, path strin
 	return pfc.PutFileOverwrite(repoName, commitID, path, reader, overwriteIndex)
 }
 
+// PutFileMetadata is like PutFile, but it also attaches the user-defined
+// key/value pairs in 'metadata' to the file, overwriting existing keys. If
+// overwrite is true, the file's contents and metadata are replaced rather than
+// appended to.
+func (c APIClient) PutFileMetadata(repoName string, commitID string, path string, reader io.Reader, overwrite bool, metadata map[string]string) (_ int, retErr error) {
+	pfc, err := c.newOneoffPutFileClient()
+	if err != nil {
+		return 0, err
+	}
+	return pfc.PutFileMetadata(repoName, commitID, path, reader, overwrite, metadata)
+}
+
+// PutFileUnpack reads an archive in 'format' from 'reader', and puts each file
+// in it under 'path' (with its path in the archive). The archive is unpacked
+// by pachd.
+func (c APIClient) PutFileUnpack(repoName string, commitID string, path string, format pfs.ArchiveFormat, overwrite bool, reader io.Reader) (_ int, retErr error) {
+	pfc, err := c.newOneoffPutFileClient()
+	if err != nil {
+		return 0, err
+	}
+	return pfc.PutFileUnpack(repoName, commitID, path, format, overwrite, reader)
+}
+
 //PutFileSplit writes a file to PFS from a reader
 // delimiter is used to tell PFS how to break the input into blocks
 func (c APIClient) PutFileSplit(repoName string, commitID string, path string, delimiter pfs.Delimiter, targetFileDatums int64, targetFileBytes int64, headerRecords int64, overwrite bool, reader io.Reader) (_ int, retErr error) {
@@ -1163,6 +1550,18 @@ func (c APIClient) PutFileURL(repoName string, commitID string, path string, url
 	return pfc.PutFileURL(repoName, commitID, path, url, recursive, overwrite)
 }
 
+// PutSymlink creates a symlink at 'path' that points to 'target', replacing
+// any regular file that's already there. Relative targets are relative to the
+// directory containing the symlink, and absolute targets are relative to the
+// root of the commit.
+func (c APIClient) PutSymlink(repoName string, commitID string, path string, target string) error {
+	pfc, err := c.newOneoffPutFileClient()
+	if err != nil {
+		return err
+	}
+	return pfc.PutSymlink(repoName, commitID, path, target)
+}
+
 // CopyFile copys a file from one pfs location to another. It can be used on
 // directories or regular files.
 func (c APIClient) CopyFile(srcRepo, srcCommit, srcPath, dstRepo, dstCommit, dstPath string, overwrite bool) error {
@@ -1177,6 +1576,20 @@ func (c APIClient) CopyFile(srcRepo, srcCommit, srcPath, dstRepo, dstCommit, dst
 	return nil
 }
 
+// MoveFile moves a file or directory from 'srcPath' to 'dstPath' in the same
+// commit. The old path is removed and the new path is created atomically, and
+// the file's content isn't copied. 'dstPath' must not already exist.
+func (c APIClient) MoveFile(repoName, commitID, srcPath, dstPath string) error {
+	if _, err := c.PfsAPIClient.MoveFile(c.Ctx(),
+		&pfs.MoveFileRequest{
+			Src: NewFile(repoName, commitID, srcPath),
+			Dst: NewFile(repoName, commitID, dstPath),
+		}); err != nil {
+		return grpcutil.ScrubGRPC(err)
+	}
+	return nil
+}
+
 // GetFile returns the contents of a file at a specific Commit.
 // offset specifies a number of bytes that should be skipped in the beginning of the file.
 // size limits the total amount of data returned, note you will get fewer bytes
@@ -1197,6 +1610,31 @@ func (c APIClient) GetFile(repoName string, commitID string, path string, offset
 	return nil
 }
 
+// GetFileArchive writes every file under 'path' (or under the paths matching
+// it, if it's a glob pattern) to 'writer' as an archive in 'format'. Entries
+// keep their full path in the commit and the time at which they were
+// committed.
+func (c APIClient) GetFileArchive(repoName string, commitID string, path string, format pfs.ArchiveFormat, writer io.Writer) error {
+	if c.limiter != nil {
+		c.limiter.Acquire()
+		defer c.limiter.Release()
+	}
+	apiGetFileClient, err := c.PfsAPIClient.GetFile(
+		c.Ctx(),
+		&pfs.GetFileRequest{
+			File:    NewFile(repoName, commitID, path),
+			Archive: format,
+		},
+	)
+	if err != nil {
+		return grpcutil.ScrubGRPC(err)
+	}
+	if err := grpcutil.WriteFromStreamingBytesClient(apiGetFileClient, writer); err != nil {
+		return grpcutil.ScrubGRPC(err)
+	}
+	return nil
+}
+
 // GetFileReader returns a reader for the contents of a file at a specific Commit.
 // offset specifies a number of bytes that should be skipped in the beginning of the file.
 // size limits the total amount of data returned, note you will get fewer bytes
@@ -1369,6 +1807,51 @@ func (c APIClient) GlobFileF(repoName string, commitID string, pattern string, f
 	}
 }
 
+// GrepFile returns the lines of the files matching the glob pattern 'path' in
+// a given commit whose content matches the regular expression 'pattern'. The
+// regular expression syntax is documented here:
+// https://golang.org/pkg/regexp/syntax/
+func (c APIClient) GrepFile(repoName string, commitID string, path string, pattern string) ([]*pfs.GrepFileResponse, error) {
+	var result []*pfs.GrepFileResponse
+	if err := c.GrepFileF(repoName, commitID, path, pattern, func(resp *pfs.GrepFileResponse) error {
+		result = append(result, resp)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GrepFileF is like GrepFile, but calls f with each matching line rather than
+// returning them. Matching lines from the same file are passed to f together
+// and in order, but files may be searched in any order.
+func (c APIClient) GrepFileF(repoName string, commitID string, path string, pattern string, f func(resp *pfs.GrepFileResponse) error) error {
+	gs, err := c.PfsAPIClient.GrepFile(
+		c.Ctx(),
+		&pfs.GrepFileRequest{
+			File:    NewFile(repoName, commitID, path),
+			Pattern: pattern,
+		},
+	)
+	if err != nil {
+		return grpcutil.ScrubGRPC(err)
+	}
+	for {
+		resp, err := gs.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return grpcutil.ScrubGRPC(err)
+		}
+		if err := f(resp); err != nil {
+			if errors.Is(err, errutil.ErrBreak) {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
 // DiffFile returns the difference between 2 paths, old path may be omitted in
 // which case the parent of the new path will be used. DiffFile return 2 values
 // (unless it returns an error) the first value is files present under new
@@ -1394,6 +1877,32 @@ func (c APIClient) DiffFile(newRepoName, newCommitID, newPath, oldRepoName,
 	return resp.NewFiles, resp.OldFiles, nil
 }
 
+// DiffFileContent is like DiffFile, but returns line-level diffs of the content
+// of the files that were created, deleted or modified. Files larger than
+// 'sizeLimit' bytes (or 1MiB, if 'sizeLimit' is 0) and binary files aren't
+// diffed, and are marked as such in the result.
+func (c APIClient) DiffFileContent(newRepoName, newCommitID, newPath, oldRepoName,
+	oldCommitID, oldPath string, shallow bool, sizeLimit int64) ([]*pfs.FileContentDiff, error) {
+	var oldFile *pfs.File
+	if oldRepoName != "" {
+		oldFile = NewFile(oldRepoName, oldCommitID, oldPath)
+	}
+	resp, err := c.PfsAPIClient.DiffFile(
+		c.Ctx(),
+		&pfs.DiffFileRequest{
+			NewFile:          NewFile(newRepoName, newCommitID, newPath),
+			OldFile:          oldFile,
+			Shallow:          shallow,
+			Content:          true,
+			ContentSizeLimit: sizeLimit,
+		},
+	)
+	if err != nil {
+		return nil, grpcutil.ScrubGRPC(err)
+	}
+	return resp.ContentDiffs, nil
+}
+
 // WalkFn is the type of the function called for each file in Walk.
 // Returning a non-nil error from WalkFn will result in Walk aborting and
 // returning said error.
@@ -1438,6 +1947,267 @@ func (c APIClient) DeleteFile(repoName string, commitID string, path string) err
 	return pfc.DeleteFile(repoName, commitID, path)
 }
 
+// PurgeFile removes the files matching the glob pattern 'path' from every
+// commit in 'repoName'. If 'deleteOutputs' is set, output commits derived from
+// the rewritten commits are deleted, otherwise they're only listed in the
+// response.
+func (c APIClient) PurgeFile(repoName string, path string, deleteOutputs bool) (*pfs.PurgeFileResponse, error) {
+	resp, err := c.PfsAPIClient.PurgeFile(
+		c.Ctx(),
+		&pfs.PurgeFileRequest{
+			Repo:          NewRepo(repoName),
+			Path:          path,
+			DeleteOutputs: deleteOutputs,
+		},
+	)
+	if err != nil {
+		return nil, grpcutil.ScrubGRPC(err)
+	}
+	return resp, nil
+}
+
+// StartUpload starts a resumable upload session for the file at 'path'. The
+// file's data is sent with PutChunk and written to the commit by
+// CompleteUpload, which is when the commit must be open.
+func (c APIClient) StartUpload(repoName string, commitID string, path string, overwrite bool) (*pfs.UploadInfo, error) {
+	uploadInfo, err := c.PfsAPIClient.StartUpload(
+		c.Ctx(),
+		&pfs.StartUploadRequest{
+			File:      NewFile(repoName, commitID, path),
+			Overwrite: overwrite,
+		},
+	)
+	if err != nil {
+		return nil, grpcutil.ScrubGRPC(err)
+	}
+	return uploadInfo, nil
+}
+
+// PutChunk uploads the data in 'r' to the upload session 'uploadID', as the
+// chunk of the file starting at 'offset'. Putting a chunk at the same offset
+// again replaces it, so a failed PutChunk can simply be retried.
+func (c APIClient) PutChunk(uploadID string, offset int64, _r io.Reader) (_ *pfs.UploadInfo, retErr error) {
+	r := grpcutil.ReaderWrapper{_r}
+	putChunkClient, err := c.PfsAPIClient.PutChunk(c.Ctx())
+	if err != nil {
+		return nil, grpcutil.ScrubGRPC(err)
+	}
+	buf := grpcutil.GetBuffer()
+	defer grpcutil.PutBuffer(buf)
+	request := &pfs.PutChunkRequest{
+		UploadID: uploadID,
+		Offset:   offset,
+	}
+	// The first request is always sent, as it carries the upload ID and offset
+	for sent := false; ; {
+		n, err := r.Read(buf)
+		if n > 0 || !sent {
+			request.Value = buf[:n]
+			if err := putChunkClient.Send(request); err != nil {
+				if errors.Is(err, io.EOF) {
+					break // the server returned an error, which CloseAndRecv gets
+				}
+				return nil, grpcutil.ScrubGRPC(err)
+			}
+			request = &pfs.PutChunkRequest{}
+			sent = true
+		}
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			putChunkClient.CloseSend()
+			return nil, grpcutil.ScrubGRPC(err)
+		}
+	}
+	uploadInfo, err := putChunkClient.CloseAndRecv()
+	if err != nil {
+		return nil, grpcutil.ScrubGRPC(err)
+	}
+	return uploadInfo, nil
+}
+
+// InspectUpload returns info about the upload session 'uploadID'.
+func (c APIClient) InspectUpload(uploadID string) (*pfs.UploadInfo, error) {
+	uploadInfo, err := c.PfsAPIClient.InspectUpload(
+		c.Ctx(),
+		&pfs.InspectUploadRequest{
+			UploadID: uploadID,
+		},
+	)
+	if err != nil {
+		return nil, grpcutil.ScrubGRPC(err)
+	}
+	return uploadInfo, nil
+}
+
+// ListUpload returns the upload sessions to files in 'repoName'. If
+// 'repoName' is empty, the sessions in every repo are returned.
+func (c APIClient) ListUpload(repoName string) ([]*pfs.UploadInfo, error) {
+	request := &pfs.ListUploadRequest{}
+	if repoName != "" {
+		request.Repo = NewRepo(repoName)
+	}
+	uploadInfos, err := c.PfsAPIClient.ListUpload(c.Ctx(), request)
+	if err != nil {
+		return nil, grpcutil.ScrubGRPC(err)
+	}
+	return uploadInfos.UploadInfo, nil
+}
+
+// CompleteUpload writes the data uploaded to the session 'uploadID' to its
+// file and deletes the session. Every byte of the file must have been
+// uploaded.
+func (c APIClient) CompleteUpload(uploadID string) error {
+	_, err := c.PfsAPIClient.CompleteUpload(
+		c.Ctx(),
+		&pfs.CompleteUploadRequest{
+			UploadID: uploadID,
+		},
+	)
+	return grpcutil.ScrubGRPC(err)
+}
+
+// DeleteUpload abandons the upload session 'uploadID'.
+func (c APIClient) DeleteUpload(uploadID string) error {
+	_, err := c.PfsAPIClient.DeleteUpload(
+		c.Ctx(),
+		&pfs.DeleteUploadRequest{
+			UploadID: uploadID,
+		},
+	)
+	return grpcutil.ScrubGRPC(err)
+}
+
+// uploadState is the progress of a PutFileResumable call, which it persists
+// so that a later call can resume the upload. SourceSize and SourceModTime
+// identify the data being uploaded, so that an upload isn't resumed with data
+// that's changed since it started. Completing is set before the upload is
+// completed, so that a later call knows whether a missing session was
+// completed or abandoned.
+type uploadState struct {
+	UploadID      string    `json:"upload_id"`
+	Repo          string    `json:"repo"`
+	Commit        string    `json:"commit"`
+	Path          string    `json:"path"`
+	Offset        int64     `json:"offset"`
+	SourceSize    int64     `json:"source_size"`
+	SourceModTime time.Time `json:"source_mod_time"`
+	Completing    bool      `json:"completing"`
+}
+
+// PutFileResumable writes the data in 'reader' to the file at 'path' in
+// chunks of pfs.UploadChunkSize, using an upload session. Its progress is
+// saved in the file at 'statePath', so if it's interrupted, calling it again
+// with the same arguments resumes the upload where it stopped instead of
+// starting over. The state file is removed once the upload completes. If
+// 'reader' is a file (i.e. it has a Stat method), its modification time is
+// checked along with its size before an upload is resumed.
+func (c APIClient) PutFileResumable(repoName string, commitID string, path string, reader io.ReadSeeker, overwrite bool, statePath string) error {
+	sourceSize, err := reader.Seek(0, io.SeekEnd)
+	if err != nil {
+		return errors.EnsureStack(err)
+	}
+	var sourceModTime time.Time
+	if f, ok := reader.(interface{ Stat() (os.FileInfo, error) }); ok {
+		fileInfo, err := f.Stat()
+		if err != nil {
+			return errors.EnsureStack(err)
+		}
+		sourceModTime = fileInfo.ModTime()
+	}
+
+	state := &uploadState{}
+	if data, err := ioutil.ReadFile(statePath); err == nil {
+		if err := json.Unmarshal(data, state); err != nil {
+			return errors.Wrapf(err, "could not parse upload state in %s", statePath)
+		}
+	} else if !os.IsNotExist(err) {
+		return errors.EnsureStack(err)
+	}
+	if state.UploadID != "" && (state.Repo != repoName || state.Commit != commitID || state.Path != path) {
+		return errors.Errorf("upload state in %s is for %s@%s:%s, not %s@%s:%s",
+			statePath, state.Repo, state.Commit, state.Path, repoName, commitID, path)
+	}
+	if state.UploadID != "" && (state.SourceSize != sourceSize || !state.SourceModTime.Equal(sourceModTime)) {
+		return errors.Errorf("the data being uploaded has changed since upload %s started, remove %s to start over",
+			state.UploadID, statePath)
+	}
+	var uploadInfo *pfs.UploadInfo
+	if state.UploadID != "" {
+		var err error
+		uploadInfo, err = c.InspectUpload(state.UploadID)
+		if err != nil && !errutil.IsNotFoundError(err) {
+			return err
+		}
+		if uploadInfo == nil && state.Completing {
+			// The session was completed, but the state file wasn't removed
+			return errors.EnsureStack(os.Remove(statePath))
+		}
+	}
+	if uploadInfo == nil {
+		var err error
+		uploadInfo, err = c.StartUpload(repoName, commitID, path, overwrite)
+		if err != nil {
+			return err
+		}
+	}
+	state = &uploadState{
+		UploadID:      uploadInfo.ID,
+		Repo:          repoName,
+		Commit:        commitID,
+		Path:          path,
+		Offset:        uploadInfo.UploadedBytes(),
+		SourceSize:    sourceSize,
+		SourceModTime: sourceModTime,
+	}
+	if err := writeUploadState(statePath, state); err != nil {
+		return err
+	}
+	if _, err := reader.Seek(state.Offset, io.SeekStart); err != nil {
+		return errors.EnsureStack(err)
+	}
+	for {
+		uploadInfo, err := c.PutChunk(state.UploadID, state.Offset, io.LimitReader(reader, pfs.UploadChunkSize))
+		if err != nil {
+			return err
+		}
+		offset := uploadInfo.UploadedBytes()
+		if offset == state.Offset {
+			break // reader is exhausted
+		}
+		state.Offset = offset
+		if err := writeUploadState(statePath, state); err != nil {
+			return err
+		}
+	}
+	state.Completing = true
+	if err := writeUploadState(statePath, state); err != nil {
+		return err
+	}
+	if err := c.CompleteUpload(state.UploadID); err != nil {
+		return err
+	}
+	return errors.EnsureStack(os.Remove(statePath))
+}
+
+// writeUploadState atomically replaces the upload state in 'statePath' with
+// 'state'.
+func writeUploadState(statePath string, state *uploadState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return errors.EnsureStack(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(statePath), 0755); err != nil {
+		return errors.EnsureStack(err)
+	}
+	tmpPath := statePath + ".tmp"
+	if err := ioutil.WriteFile(tmpPath, data, 0644); err != nil {
+		return errors.EnsureStack(err)
+	}
+	return errors.EnsureStack(os.Rename(tmpPath, statePath))
+}
+
 type putFileWriteCloser struct {
 	request *pfs.PutFileRequest
 	sent    bool
@@ -1449,7 +2219,25 @@ type putFileWriteCloser struct {
 // prevent the completion of fsck. Errors that do prevent completion will be
 // returned from the function.
 func (c APIClient) Fsck(fix bool, cb func(*pfs.FsckResponse) error) error {
-	fsckClient, err := c.PfsAPIClient.Fsck(c.Ctx(), &pfs.FsckRequest{Fix: fix})
+	return c.fsck(&pfs.FsckRequest{Fix: fix}, cb)
+}
+
+// FsckDeep is like Fsck, except that it also verifies that the objects and
+// blocks referenced by every commit are in object storage, and have the
+// expected content. 'parallelism' objects are checked at once (or a default
+// number, if it's 0), and reads from object storage are limited to
+// 'bytesPerSecond' (unless it's 0).
+func (c APIClient) FsckDeep(fix bool, parallelism, bytesPerSecond int64, cb func(*pfs.FsckResponse) error) error {
+	return c.fsck(&pfs.FsckRequest{
+		Fix:                fix,
+		Deep:               true,
+		DeepParallelism:    parallelism,
+		DeepBytesPerSecond: bytesPerSecond,
+	}, cb)
+}
+
+func (c APIClient) fsck(request *pfs.FsckRequest, cb func(*pfs.FsckResponse) error) error {
+	fsckClient, err := c.PfsAPIClient.Fsck(c.Ctx(), request)
 	if err != nil {
 		return grpcutil.ScrubGRPC(err)
 	}
@@ -6,11 +6,15 @@ import (
 	"encoding/hex"
 	"fmt"
 	"hash"
+	"strings"
 )
 
 var (
 	// ChunkSize is the size of file chunks when resumable upload is used
 	ChunkSize = int64(512 * 1024 * 1024) // 512 MB
+	// UploadChunkSize is the size of the chunks sent by resumable uploads
+	// (see UploadInfo)
+	UploadChunkSize = int64(64 * 1024 * 1024) // 64 MB
 )
 
 // FullID prints repoName/CommitID
@@ -18,6 +22,49 @@ func (c *Commit) FullID() string {
 	return fmt.Sprintf("%s/%s", c.Repo.Name, c.ID)
 }
 
+// UploadedBytes returns the number of bytes at the start of the file that
+// have been uploaded to 'u', i.e. the offset at which an interrupted upload
+// should resume.
+func (u *UploadInfo) UploadedBytes() int64 {
+	var size int64
+	for _, chunk := range u.Chunks {
+		if chunk.Offset != size {
+			break
+		}
+		size += chunk.SizeBytes
+	}
+	return size
+}
+
+// ParseArchiveFormat parses the name of an ArchiveFormat, as used by pachctl
+// and the HTTP API: "tar", "tar.gz" (or "tgz") or "zip".
+func ParseArchiveFormat(s string) (ArchiveFormat, error) {
+	switch strings.ToLower(s) {
+	case "tar":
+		return ArchiveFormat_TAR, nil
+	case "tar.gz", "tgz":
+		return ArchiveFormat_TAR_GZ, nil
+	case "zip":
+		return ArchiveFormat_ZIP, nil
+	default:
+		return ArchiveFormat_RAW, fmt.Errorf("unrecognized archive format %q, must be one of {tar,tar.gz,zip}", s)
+	}
+}
+
+// Extension returns the file extension used for archives in format 'f'.
+func (f ArchiveFormat) Extension() string {
+	switch f {
+	case ArchiveFormat_TAR:
+		return ".tar"
+	case ArchiveFormat_TAR_GZ:
+		return ".tar.gz"
+	case ArchiveFormat_ZIP:
+		return ".zip"
+	default:
+		return ""
+	}
+}
+
 // NewHash returns a hash that PFS uses internally to compute checksums.
 func NewHash() hash.Hash {
 	return sha512.New()
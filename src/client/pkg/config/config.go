@@ -30,6 +30,12 @@ func configPath() string {
 	return defaultConfigPath
 }
 
+// UploadStateDir returns the directory in which pachctl saves the progress of
+// resumable uploads. It's next to the config file.
+func UploadStateDir() string {
+	return filepath.Join(filepath.Dir(configPath()), "uploads")
+}
+
 // ActiveContext gets the active context in the config
 func (c *Config) ActiveContext(errorOnNoActive bool) (string, *Context, error) {
 	if c.V2 == nil {
@@ -307,6 +307,10 @@ func (c *pfsBuilderClient) DeleteBranch(ctx context.Context, req *pfs.DeleteBran
 	c.tb.requests = append(c.tb.requests, &transaction.TransactionRequest{DeleteBranch: req})
 	return nil, nil
 }
+func (c *pfsBuilderClient) MoveFile(ctx context.Context, req *pfs.MoveFileRequest, opts ...grpc.CallOption) (*types.Empty, error) {
+	c.tb.requests = append(c.tb.requests, &transaction.TransactionRequest{MoveFile: req})
+	return nil, nil
+}
 func (c *ppsBuilderClient) UpdateJobState(ctx context.Context, req *pps.UpdateJobStateRequest, opts ...grpc.CallOption) (*types.Empty, error) {
 	c.tb.requests = append(c.tb.requests, &transaction.TransactionRequest{UpdateJobState: req})
 	return nil, nil
@@ -338,15 +342,42 @@ func (c *pfsBuilderClient) FlushCommit(ctx context.Context, req *pfs.FlushCommit
 func (c *pfsBuilderClient) SubscribeCommit(ctx context.Context, req *pfs.SubscribeCommitRequest, opts ...grpc.CallOption) (pfs.API_SubscribeCommitClient, error) {
 	return nil, unsupportedError("SubscribeCommit")
 }
+func (c *pfsBuilderClient) SubscribeFile(ctx context.Context, req *pfs.SubscribeFileRequest, opts ...grpc.CallOption) (pfs.API_SubscribeFileClient, error) {
+	return nil, unsupportedError("SubscribeFile")
+}
 func (c *pfsBuilderClient) BuildCommit(ctx context.Context, req *pfs.BuildCommitRequest, opts ...grpc.CallOption) (*pfs.Commit, error) {
 	return nil, unsupportedError("BuildCommit")
 }
+func (c *pfsBuilderClient) RevertCommit(ctx context.Context, req *pfs.RevertCommitRequest, opts ...grpc.CallOption) (*pfs.Commit, error) {
+	return nil, unsupportedError("RevertCommit")
+}
+func (c *pfsBuilderClient) CherryPickCommit(ctx context.Context, req *pfs.CherryPickCommitRequest, opts ...grpc.CallOption) (*pfs.Commit, error) {
+	return nil, unsupportedError("CherryPickCommit")
+}
+func (c *pfsBuilderClient) SquashCommits(ctx context.Context, req *pfs.SquashCommitsRequest, opts ...grpc.CallOption) (*pfs.Commit, error) {
+	return nil, unsupportedError("SquashCommits")
+}
 func (c *pfsBuilderClient) InspectBranch(ctx context.Context, req *pfs.InspectBranchRequest, opts ...grpc.CallOption) (*pfs.BranchInfo, error) {
 	return nil, unsupportedError("InspectBranch")
 }
 func (c *pfsBuilderClient) ListBranch(ctx context.Context, req *pfs.ListBranchRequest, opts ...grpc.CallOption) (*pfs.BranchInfos, error) {
 	return nil, unsupportedError("ListBranch")
 }
+func (c *pfsBuilderClient) MergeBranch(ctx context.Context, req *pfs.MergeBranchRequest, opts ...grpc.CallOption) (*pfs.Commit, error) {
+	return nil, unsupportedError("MergeBranch")
+}
+func (c *pfsBuilderClient) CreateCommitTag(ctx context.Context, req *pfs.CreateCommitTagRequest, opts ...grpc.CallOption) (*types.Empty, error) {
+	return nil, unsupportedError("CreateCommitTag")
+}
+func (c *pfsBuilderClient) InspectCommitTag(ctx context.Context, req *pfs.InspectCommitTagRequest, opts ...grpc.CallOption) (*pfs.CommitTagInfo, error) {
+	return nil, unsupportedError("InspectCommitTag")
+}
+func (c *pfsBuilderClient) ListCommitTag(ctx context.Context, req *pfs.ListCommitTagRequest, opts ...grpc.CallOption) (*pfs.CommitTagInfos, error) {
+	return nil, unsupportedError("ListCommitTag")
+}
+func (c *pfsBuilderClient) DeleteCommitTag(ctx context.Context, req *pfs.DeleteCommitTagRequest, opts ...grpc.CallOption) (*types.Empty, error) {
+	return nil, unsupportedError("DeleteCommitTag")
+}
 func (c *pfsBuilderClient) PutFile(ctx context.Context, opts ...grpc.CallOption) (pfs.API_PutFileClient, error) {
 	return nil, unsupportedError("PutFile")
 }
@@ -374,12 +405,36 @@ func (c *pfsBuilderClient) GlobFile(ctx context.Context, req *pfs.GlobFileReques
 func (c *pfsBuilderClient) GlobFileStream(ctx context.Context, req *pfs.GlobFileRequest, opts ...grpc.CallOption) (pfs.API_GlobFileStreamClient, error) {
 	return nil, unsupportedError("GlobFileStream")
 }
+func (c *pfsBuilderClient) GrepFile(ctx context.Context, req *pfs.GrepFileRequest, opts ...grpc.CallOption) (pfs.API_GrepFileClient, error) {
+	return nil, unsupportedError("GrepFile")
+}
 func (c *pfsBuilderClient) DiffFile(ctx context.Context, req *pfs.DiffFileRequest, opts ...grpc.CallOption) (*pfs.DiffFileResponse, error) {
 	return nil, unsupportedError("DiffFile")
 }
 func (c *pfsBuilderClient) DeleteFile(ctx context.Context, req *pfs.DeleteFileRequest, opts ...grpc.CallOption) (*types.Empty, error) {
 	return nil, unsupportedError("DeleteFile")
 }
+func (c *pfsBuilderClient) PurgeFile(ctx context.Context, req *pfs.PurgeFileRequest, opts ...grpc.CallOption) (*pfs.PurgeFileResponse, error) {
+	return nil, unsupportedError("PurgeFile")
+}
+func (c *pfsBuilderClient) StartUpload(ctx context.Context, req *pfs.StartUploadRequest, opts ...grpc.CallOption) (*pfs.UploadInfo, error) {
+	return nil, unsupportedError("StartUpload")
+}
+func (c *pfsBuilderClient) PutChunk(ctx context.Context, opts ...grpc.CallOption) (pfs.API_PutChunkClient, error) {
+	return nil, unsupportedError("PutChunk")
+}
+func (c *pfsBuilderClient) InspectUpload(ctx context.Context, req *pfs.InspectUploadRequest, opts ...grpc.CallOption) (*pfs.UploadInfo, error) {
+	return nil, unsupportedError("InspectUpload")
+}
+func (c *pfsBuilderClient) ListUpload(ctx context.Context, req *pfs.ListUploadRequest, opts ...grpc.CallOption) (*pfs.UploadInfos, error) {
+	return nil, unsupportedError("ListUpload")
+}
+func (c *pfsBuilderClient) CompleteUpload(ctx context.Context, req *pfs.CompleteUploadRequest, opts ...grpc.CallOption) (*types.Empty, error) {
+	return nil, unsupportedError("CompleteUpload")
+}
+func (c *pfsBuilderClient) DeleteUpload(ctx context.Context, req *pfs.DeleteUploadRequest, opts ...grpc.CallOption) (*types.Empty, error) {
+	return nil, unsupportedError("DeleteUpload")
+}
 func (c *pfsBuilderClient) DeleteAll(ctx context.Context, req *types.Empty, opts ...grpc.CallOption) (*types.Empty, error) {
 	return nil, unsupportedError("DeleteAll")
 }
@@ -401,6 +456,15 @@ func (c *pfsBuilderClient) ListFileV2(ctx context.Context, req *pfs.ListFileRequ
 func (c *pfsBuilderClient) GlobFileV2(ctx context.Context, req *pfs.GlobFileRequest, opts ...grpc.CallOption) (pfs.API_GlobFileV2Client, error) {
 	return nil, unsupportedError("GlobFileV2")
 }
+func (c *pfsBuilderClient) RenameBranch(ctx context.Context, req *pfs.RenameBranchRequest, opts ...grpc.CallOption) (*types.Empty, error) {
+	return nil, unsupportedError("RenameBranch")
+}
+func (c *pfsBuilderClient) RenameRepo(ctx context.Context, req *pfs.RenameRepoRequest, opts ...grpc.CallOption) (*types.Empty, error) {
+	return nil, unsupportedError("RenameRepo")
+}
+func (c *pfsBuilderClient) ForkRepo(ctx context.Context, req *pfs.ForkRepoRequest, opts ...grpc.CallOption) (*types.Empty, error) {
+	return nil, unsupportedError("ForkRepo")
+}
 
 func (c *objectBuilderClient) PutObject(ctx context.Context, opts ...grpc.CallOption) (pfs.ObjectAPI_PutObjectClient, error) {
 	return nil, unsupportedError("PutObject")
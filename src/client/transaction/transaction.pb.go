@@ -78,6 +78,7 @@ type TransactionRequest struct {
 	DeleteCommit         *pfs.DeleteCommitRequest   `protobuf:"bytes,5,opt,name=delete_commit,json=deleteCommit,proto3" json:"delete_commit,omitempty"`
 	CreateBranch         *pfs.CreateBranchRequest   `protobuf:"bytes,6,opt,name=create_branch,json=createBranch,proto3" json:"create_branch,omitempty"`
 	DeleteBranch         *pfs.DeleteBranchRequest   `protobuf:"bytes,7,opt,name=delete_branch,json=deleteBranch,proto3" json:"delete_branch,omitempty"`
+	MoveFile             *pfs.MoveFileRequest       `protobuf:"bytes,12,opt,name=move_file,json=moveFile,proto3" json:"move_file,omitempty"`
 	UpdateJobState       *pps.UpdateJobStateRequest `protobuf:"bytes,11,opt,name=update_job_state,json=updateJobState,proto3" json:"update_job_state,omitempty"`
 	DeleteAll            *DeleteAllRequest          `protobuf:"bytes,10,opt,name=delete_all,json=deleteAll,proto3" json:"delete_all,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}                   `json:"-"`
@@ -167,6 +168,13 @@ func (m *TransactionRequest) GetDeleteBranch() *pfs.DeleteBranchRequest {
 	return nil
 }
 
+func (m *TransactionRequest) GetMoveFile() *pfs.MoveFileRequest {
+	if m != nil {
+		return m.MoveFile
+	}
+	return nil
+}
+
 func (m *TransactionRequest) GetUpdateJobState() *pps.UpdateJobStateRequest {
 	if m != nil {
 		return m.UpdateJobState
@@ -680,55 +688,57 @@ func init() {
 }
 
 var fileDescriptor_363f2adee3615c0c = []byte{
-	// 761 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x96, 0x4d, 0x6f, 0xd3, 0x4c,
-	0x10, 0xc7, 0xf3, 0xd2, 0x27, 0x7d, 0x32, 0x2e, 0x34, 0x5d, 0x50, 0xea, 0x06, 0xfa, 0x22, 0xb7,
-	0x45, 0x3d, 0x39, 0x52, 0x01, 0x21, 0x95, 0x17, 0xa9, 0x69, 0x00, 0x05, 0x71, 0x40, 0x6e, 0x69,
-	0x51, 0x41, 0x8a, 0x1c, 0x7b, 0x93, 0x18, 0x25, 0x5e, 0xe3, 0xdd, 0x1c, 0x7a, 0xe3, 0xb3, 0x71,
-	0xe2, 0xc8, 0x27, 0x40, 0x28, 0xe2, 0x83, 0xa0, 0x7d, 0x49, 0xba, 0x76, 0xe2, 0x02, 0xa2, 0x87,
-	0x48, 0xd6, 0x7f, 0xe6, 0x37, 0x33, 0x3b, 0x33, 0xbb, 0x2d, 0xec, 0x78, 0x83, 0x00, 0x87, 0xac,
-	0xce, 0x62, 0x37, 0xa4, 0xae, 0xc7, 0x02, 0x12, 0xea, 0xdf, 0x76, 0x14, 0x13, 0x46, 0x90, 0xa1,
-	0x49, 0xb5, 0x3b, 0x3d, 0x42, 0x7a, 0x03, 0x5c, 0x17, 0xa6, 0xce, 0xa8, 0x5b, 0xc7, 0xc3, 0x88,
-	0x5d, 0x48, 0xcf, 0xda, 0x66, 0xda, 0xc8, 0x82, 0x21, 0xa6, 0xcc, 0x1d, 0x46, 0xca, 0xe1, 0x76,
-	0x8f, 0xf4, 0x88, 0xf8, 0xac, 0xf3, 0xaf, 0x89, 0xaa, 0xca, 0x88, 0xba, 0x94, 0xff, 0xd2, 0x6a,
-	0x44, 0xf9, 0x4f, 0xaa, 0x16, 0x82, 0x4a, 0x13, 0x0f, 0x30, 0xc3, 0x87, 0x83, 0x81, 0x83, 0x3f,
-	0x8d, 0x30, 0x65, 0xd6, 0x97, 0x05, 0x40, 0x27, 0x97, 0x35, 0x2a, 0x19, 0x3d, 0x02, 0xc3, 0x8b,
-	0xb1, 0xcb, 0x70, 0x3b, 0xc6, 0x11, 0x31, 0xf3, 0x5b, 0xf9, 0x3d, 0x63, 0xbf, 0x6a, 0xf3, 0x0c,
-	0x47, 0x42, 0x77, 0x70, 0x44, 0x94, 0xb3, 0x03, 0xde, 0x54, 0xe2, 0xa0, 0x2f, 0x72, 0x48, 0xb0,
-	0xa0, 0x81, 0x32, 0x77, 0x02, 0xf4, 0xa7, 0x12, 0x3a, 0x80, 0x25, 0xca, 0xdc, 0x98, 0xb5, 0x3d,
-	0x32, 0x1c, 0x06, 0xcc, 0x2c, 0x0a, 0x72, 0x55, 0x90, 0xc7, 0xdc, 0x70, 0x24, 0xf4, 0x09, 0x6a,
-	0xd0, 0x4b, 0x0d, 0x3d, 0x85, 0x1b, 0xdd, 0x20, 0x0c, 0x68, 0x7f, 0x02, 0x2f, 0x08, 0xd8, 0x14,
-	0xf0, 0x0b, 0x61, 0x49, 0xd2, 0x4b, 0x5d, 0x4d, 0xe4, 0xb8, 0xaa, 0x59, 0xe1, 0xff, 0x69, 0xb8,
-	0xac, 0x3a, 0x85, 0xfb, 0x9a, 0xc8, 0x71, 0xd5, 0xab, 0x4e, 0xec, 0x86, 0x5e, 0xdf, 0x2c, 0x69,
-	0xb8, 0xec, 0x56, 0x43, 0x18, 0xa6, 0xb8, 0xa7, 0x89, 0x5a, 0x76, 0x85, 0x2f, 0xce, 0x64, 0x4f,
-	0xe1, 0xbe, 0x26, 0xa2, 0x26, 0x54, 0x46, 0x91, 0xcf, 0xb3, 0x7f, 0x24, 0x9d, 0x36, 0x65, 0x2e,
-	0xc3, 0xa6, 0x21, 0x22, 0xd4, 0x6c, 0x3e, 0xfa, 0xb7, 0xc2, 0xf8, 0x8a, 0x74, 0x8e, 0x99, 0x98,
-	0x91, 0x8c, 0x71, 0x73, 0x94, 0x90, 0xd1, 0x13, 0x50, 0xb3, 0x68, 0xbb, 0x83, 0x81, 0x09, 0x82,
-	0x5f, 0xb7, 0xf5, 0x7d, 0x4e, 0x6f, 0x8e, 0x53, 0xf6, 0x27, 0x8a, 0x75, 0x00, 0xb7, 0x12, 0x3b,
-	0x44, 0x23, 0x12, 0x52, 0x8c, 0xb6, 0xa1, 0xa4, 0x1a, 0x2a, 0xd7, 0xc0, 0x90, 0x1d, 0x91, 0xad,
-	0x54, 0x26, 0x6b, 0x17, 0x0c, 0x8d, 0x45, 0x55, 0x28, 0x04, 0xbe, 0xd8, 0xb7, 0x72, 0xa3, 0x34,
-	0xfe, 0xbe, 0x59, 0x68, 0x35, 0x9d, 0x42, 0xe0, 0x5b, 0x9f, 0x0b, 0xb0, 0xac, 0xf9, 0xb5, 0xc2,
-	0x2e, 0x5f, 0x19, 0xfd, 0x7a, 0xa9, 0x25, 0x35, 0x13, 0x55, 0xeb, 0x65, 0xe9, 0xce, 0xe8, 0x31,
-	0xfc, 0x1f, 0xcb, 0x83, 0x50, 0xb3, 0xb0, 0x55, 0xdc, 0x33, 0xf6, 0x37, 0x33, 0x41, 0x75, 0xe0,
-	0x29, 0x80, 0x9e, 0x41, 0x39, 0x56, 0x87, 0xa4, 0x66, 0x51, 0xd0, 0x5b, 0xd9, 0xb4, 0x74, 0x74,
-	0x2e, 0x11, 0xf4, 0x00, 0x16, 0xc5, 0xfa, 0x62, 0x5f, 0x6d, 0x6a, 0xcd, 0x96, 0xb7, 0xdf, 0x9e,
-	0xdc, 0x7e, 0xfb, 0x64, 0x72, 0xfb, 0x9d, 0x89, 0xab, 0xf5, 0x1e, 0x2a, 0xa9, 0x0e, 0x50, 0xf4,
-	0x12, 0x2a, 0x5a, 0xde, 0x76, 0x10, 0x76, 0xf9, 0x65, 0xe5, 0x05, 0xdd, 0xcd, 0x2a, 0x88, 0x83,
-	0xce, 0x32, 0x4b, 0x0a, 0xd6, 0x29, 0xac, 0x36, 0x5c, 0xe6, 0xf5, 0xe7, 0xbc, 0x05, 0x7a, 0xab,
-	0xf2, 0x7f, 0xd9, 0x2a, 0x6b, 0x0d, 0x56, 0xc5, 0xed, 0x9d, 0x75, 0xb2, 0xce, 0x60, 0xad, 0x15,
-	0xd2, 0x08, 0x7b, 0x73, 0x8c, 0xff, 0x32, 0x5b, 0xeb, 0x14, 0x4c, 0xb9, 0xad, 0xd7, 0x1c, 0xd7,
-	0x84, 0xea, 0xeb, 0x80, 0xce, 0x3b, 0xca, 0x29, 0x98, 0xf2, 0x99, 0xb9, 0xde, 0x8c, 0xfb, 0x3f,
-	0x17, 0xa0, 0x78, 0xf8, 0xa6, 0x85, 0xde, 0x41, 0x25, 0x3d, 0x1d, 0xb4, 0x93, 0x08, 0x91, 0x31,
-	0xbc, 0xda, 0x95, 0x6b, 0x60, 0xe5, 0xd0, 0x09, 0x54, 0xd2, 0xf3, 0x49, 0x45, 0xce, 0x18, 0x5f,
-	0x2d, 0xf3, 0x08, 0x56, 0x0e, 0x7d, 0x00, 0x34, 0x3b, 0x5a, 0x74, 0x2f, 0x41, 0x64, 0xce, 0xfe,
-	0x0f, 0x6a, 0x5e, 0x99, 0x99, 0x2f, 0xda, 0x9d, 0xf3, 0x5a, 0xcd, 0x89, 0x5d, 0x9d, 0xb9, 0x69,
-	0xcf, 0xf9, 0x1f, 0x61, 0x2b, 0x87, 0xce, 0x60, 0x39, 0x35, 0x5d, 0xb4, 0x9d, 0x88, 0x39, 0x7f,
-	0xf6, 0xb5, 0xf5, 0xab, 0xaa, 0xa5, 0x56, 0x0e, 0x9d, 0xc3, 0xca, 0xcc, 0x72, 0xa4, 0xca, 0xcd,
-	0x5a, 0x9e, 0xdf, 0xb6, 0xa2, 0x09, 0xe5, 0xe9, 0xc3, 0x8c, 0xae, 0x7e, 0xb0, 0xb3, 0x8f, 0xde,
-	0x38, 0xfa, 0x3a, 0xde, 0xc8, 0x7f, 0x1b, 0x6f, 0xe4, 0x7f, 0x8c, 0x37, 0xf2, 0xe7, 0x0f, 0x7b,
-	0x01, 0xeb, 0x8f, 0x3a, 0xb6, 0x47, 0x86, 0xf5, 0xc8, 0xf5, 0xfa, 0x17, 0x3e, 0x8e, 0xf5, 0x2f,
-	0x1a, 0x7b, 0xf5, 0xd9, 0x7f, 0x7e, 0x3a, 0x25, 0x11, 0xf6, 0xfe, 0xaf, 0x00, 0x00, 0x00, 0xff,
-	0xff, 0xaf, 0xd5, 0x4d, 0xee, 0x19, 0x09, 0x00, 0x00,
+	// 788 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x96, 0x6d, 0x6f, 0xd3, 0x48,
+	0x10, 0xc7, 0xf3, 0xd0, 0x4b, 0x9b, 0x71, 0xef, 0x9a, 0xee, 0x55, 0xa9, 0x9b, 0xbb, 0x3e, 0xc8,
+	0x6d, 0x4f, 0x7d, 0xe5, 0xe8, 0x0a, 0x08, 0xa9, 0x3c, 0x48, 0x4d, 0x43, 0x51, 0x10, 0x48, 0xc8,
+	0x2d, 0x2d, 0x2a, 0x48, 0x91, 0x63, 0x6f, 0x12, 0x23, 0xdb, 0x6b, 0xbc, 0x9b, 0x4a, 0x7d, 0xc7,
+	0xc7, 0xe3, 0x25, 0x9f, 0x00, 0xa1, 0x88, 0xaf, 0xc0, 0x7b, 0xe4, 0xf5, 0x3a, 0x5d, 0x3b, 0x71,
+	0x01, 0xd1, 0x17, 0x96, 0x56, 0xff, 0x99, 0xdf, 0xee, 0xec, 0xcc, 0xec, 0x24, 0xb0, 0x63, 0xb9,
+	0x0e, 0xf6, 0x59, 0x93, 0x85, 0xa6, 0x4f, 0x4d, 0x8b, 0x39, 0xc4, 0x97, 0xd7, 0x7a, 0x10, 0x12,
+	0x46, 0x90, 0x22, 0x49, 0x8d, 0x7f, 0x06, 0x84, 0x0c, 0x5c, 0xdc, 0xe4, 0xa6, 0xde, 0xa8, 0xdf,
+	0xc4, 0x5e, 0xc0, 0xae, 0x62, 0xcf, 0xc6, 0x66, 0xd6, 0xc8, 0x1c, 0x0f, 0x53, 0x66, 0x7a, 0x81,
+	0x70, 0x58, 0x19, 0x90, 0x01, 0xe1, 0xcb, 0x66, 0xb4, 0x4a, 0x54, 0x11, 0x46, 0xd0, 0xa7, 0xd1,
+	0x97, 0x55, 0x03, 0x1a, 0x7d, 0xb1, 0xaa, 0x21, 0xa8, 0xb5, 0xb1, 0x8b, 0x19, 0x3e, 0x74, 0x5d,
+	0x03, 0xbf, 0x1f, 0x61, 0xca, 0xb4, 0x6f, 0x73, 0x80, 0x4e, 0xaf, 0x63, 0x14, 0x32, 0xba, 0x0f,
+	0x8a, 0x15, 0x62, 0x93, 0xe1, 0x6e, 0x88, 0x03, 0xa2, 0x16, 0xb7, 0x8a, 0x7b, 0xca, 0x7e, 0x5d,
+	0x8f, 0x4e, 0x38, 0xe2, 0xba, 0x81, 0x03, 0x22, 0x9c, 0x0d, 0xb0, 0x26, 0x52, 0x04, 0xda, 0xfc,
+	0x8c, 0x18, 0x2c, 0x49, 0x60, 0x7c, 0x76, 0x0a, 0xb4, 0x27, 0x12, 0x3a, 0x80, 0x45, 0xca, 0xcc,
+	0x90, 0x75, 0x2d, 0xe2, 0x79, 0x0e, 0x53, 0xcb, 0x9c, 0x5c, 0xe5, 0xe4, 0x49, 0x64, 0x38, 0xe2,
+	0x7a, 0x82, 0x2a, 0xf4, 0x5a, 0x43, 0x8f, 0xe0, 0xcf, 0xbe, 0xe3, 0x3b, 0x74, 0x98, 0xc0, 0x73,
+	0x1c, 0x56, 0x39, 0x7c, 0xcc, 0x2d, 0x69, 0x7a, 0xb1, 0x2f, 0x89, 0x11, 0x2e, 0x62, 0x16, 0xf8,
+	0x1f, 0x12, 0x1e, 0x47, 0x9d, 0xc1, 0x6d, 0x49, 0x8c, 0x70, 0x91, 0xab, 0x5e, 0x68, 0xfa, 0xd6,
+	0x50, 0xad, 0x48, 0x78, 0x9c, 0xad, 0x16, 0x37, 0x4c, 0x70, 0x4b, 0x12, 0xa5, 0xd3, 0x05, 0x3e,
+	0x3f, 0x75, 0x7a, 0x06, 0xb7, 0x25, 0x11, 0xfd, 0x0f, 0x55, 0x8f, 0x5c, 0xe2, 0x6e, 0xdf, 0x71,
+	0xb1, 0xba, 0xc8, 0xd1, 0x15, 0x8e, 0xbe, 0x20, 0x97, 0xf8, 0xd8, 0x71, 0x71, 0x82, 0x2d, 0x78,
+	0x42, 0x40, 0x6d, 0xa8, 0x8d, 0x02, 0x3b, 0x0a, 0xf8, 0x1d, 0xe9, 0x75, 0x29, 0x33, 0x19, 0x56,
+	0x15, 0x4e, 0x36, 0xf4, 0xa8, 0x5b, 0x5e, 0x71, 0xe3, 0x33, 0xd2, 0x3b, 0x61, 0x26, 0x9b, 0xf0,
+	0x7f, 0x8d, 0x52, 0x32, 0x7a, 0x08, 0xa2, 0x7c, 0x5d, 0xd3, 0x75, 0x55, 0xe0, 0xfc, 0xba, 0x2e,
+	0x3f, 0x81, 0x6c, 0xb3, 0x19, 0x55, 0x3b, 0x51, 0xb4, 0x03, 0xf8, 0x3b, 0xd5, 0x76, 0x34, 0x20,
+	0x3e, 0xc5, 0x68, 0x1b, 0x2a, 0xa2, 0x06, 0x71, 0xe7, 0x28, 0x71, 0x12, 0xe3, 0xec, 0x0b, 0x93,
+	0xb6, 0x0b, 0x8a, 0xc4, 0xa2, 0x3a, 0x94, 0x1c, 0x9b, 0xb7, 0x68, 0xb5, 0x55, 0x19, 0x7f, 0xde,
+	0x2c, 0x75, 0xda, 0x46, 0xc9, 0xb1, 0xb5, 0x0f, 0x25, 0x58, 0x92, 0xfc, 0x3a, 0x7e, 0x3f, 0xea,
+	0x32, 0xf9, 0x45, 0x8a, 0xbe, 0x56, 0x53, 0x51, 0xcb, 0x61, 0xc9, 0xce, 0xe8, 0x01, 0x2c, 0x84,
+	0xf1, 0x45, 0xa8, 0x5a, 0xda, 0x2a, 0xef, 0x29, 0xfb, 0x9b, 0xb9, 0x60, 0x92, 0xf3, 0x04, 0x40,
+	0x8f, 0xa1, 0x1a, 0x8a, 0x4b, 0x52, 0xb5, 0xcc, 0xe9, 0xad, 0x7c, 0x3a, 0x76, 0x34, 0xae, 0x11,
+	0x74, 0x17, 0xe6, 0x79, 0xc7, 0x63, 0x5b, 0x34, 0x77, 0x43, 0x8f, 0x07, 0x86, 0x9e, 0x0c, 0x0c,
+	0xfd, 0x34, 0x19, 0x18, 0x46, 0xe2, 0xaa, 0xbd, 0x81, 0x5a, 0x26, 0x03, 0x14, 0x3d, 0x85, 0x9a,
+	0x74, 0x6e, 0xd7, 0xf1, 0xfb, 0xd1, 0xfb, 0x8e, 0x02, 0xfa, 0x37, 0x2f, 0xa0, 0x08, 0x34, 0x96,
+	0x58, 0x5a, 0xd0, 0xce, 0x60, 0xb5, 0x65, 0x32, 0x6b, 0x38, 0x63, 0x7c, 0xc8, 0xa9, 0x2a, 0xfe,
+	0x62, 0xaa, 0xb4, 0x35, 0x58, 0xe5, 0x0f, 0x7e, 0xda, 0x49, 0x3b, 0x87, 0xb5, 0x8e, 0x4f, 0x03,
+	0x6c, 0xcd, 0x30, 0xfe, 0x4e, 0x6d, 0xb5, 0x33, 0x50, 0xe3, 0x6e, 0xbd, 0xe5, 0x7d, 0x55, 0xa8,
+	0x3f, 0x77, 0xe8, 0xac, 0xab, 0x9c, 0x81, 0x1a, 0x4f, 0xa6, 0xdb, 0x3d, 0x71, 0xff, 0xeb, 0x1c,
+	0x94, 0x0f, 0x5f, 0x76, 0xd0, 0x6b, 0xa8, 0x65, 0xab, 0x83, 0x76, 0x52, 0x5b, 0xe4, 0x14, 0xaf,
+	0x71, 0x63, 0x1b, 0x68, 0x05, 0x74, 0x0a, 0xb5, 0x6c, 0x7d, 0x32, 0x3b, 0xe7, 0x94, 0xaf, 0x91,
+	0x7b, 0x05, 0xad, 0x80, 0xde, 0x02, 0x9a, 0x2e, 0x2d, 0xfa, 0x2f, 0x45, 0xe4, 0xd6, 0xfe, 0x27,
+	0x62, 0x5e, 0x9e, 0xaa, 0x2f, 0xda, 0x9d, 0x31, 0xad, 0x66, 0xec, 0x5d, 0x9f, 0x7a, 0x69, 0x4f,
+	0xa2, 0xdf, 0x6d, 0xad, 0x80, 0xce, 0x61, 0x29, 0x53, 0x5d, 0xb4, 0x9d, 0xda, 0x73, 0x76, 0xed,
+	0x1b, 0xeb, 0x37, 0x45, 0x4b, 0xb5, 0x02, 0xba, 0x80, 0xe5, 0xa9, 0xe6, 0xc8, 0x84, 0x9b, 0xd7,
+	0x3c, 0x3f, 0x4c, 0x45, 0x1b, 0xaa, 0x93, 0xc1, 0x8c, 0x6e, 0x1e, 0xd8, 0xf9, 0x57, 0x6f, 0x1d,
+	0x7d, 0x1c, 0x6f, 0x14, 0x3f, 0x8d, 0x37, 0x8a, 0x5f, 0xc6, 0x1b, 0xc5, 0x8b, 0x7b, 0x03, 0x87,
+	0x0d, 0x47, 0x3d, 0xdd, 0x22, 0x5e, 0x33, 0x30, 0xad, 0xe1, 0x95, 0x8d, 0x43, 0x79, 0x45, 0x43,
+	0xab, 0x39, 0xfd, 0x7f, 0xa9, 0x57, 0xe1, 0xdb, 0xde, 0xf9, 0x3e, 0x00, 0xa6, 0xb1, 0x1a, 0xc1,
+	0x4c, 0x09, 0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -1080,6 +1090,18 @@ func (m *TransactionRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 		i -= len(m.XXX_unrecognized)
 		copy(dAtA[i:], m.XXX_unrecognized)
 	}
+	if m.MoveFile != nil {
+		{
+			size, err := m.MoveFile.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintTransaction(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x62
+	}
 	if m.UpdateJobState != nil {
 		{
 			size, err := m.UpdateJobState.MarshalToSizedBuffer(dAtA[:i])
@@ -1661,6 +1683,10 @@ func (m *TransactionRequest) Size() (n int) {
 		l = m.UpdateJobState.Size()
 		n += 1 + l + sovTransaction(uint64(l))
 	}
+	if m.MoveFile != nil {
+		l = m.MoveFile.Size()
+		n += 1 + l + sovTransaction(uint64(l))
+	}
 	if m.XXX_unrecognized != nil {
 		n += len(m.XXX_unrecognized)
 	}
@@ -2252,6 +2278,42 @@ func (m *TransactionRequest) Unmarshal(dAtA []byte) error {
 				return err
 			}
 			iNdEx = postIndex
+		case 12:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MoveFile", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTransaction
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthTransaction
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthTransaction
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.MoveFile == nil {
+				m.MoveFile = &pfs.MoveFileRequest{}
+			}
+			if err := m.MoveFile.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipTransaction(dAtA[iNdEx:])
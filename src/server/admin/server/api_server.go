@@ -170,8 +170,11 @@ func (a *apiServer) Extract(request *admin.ExtractRequest, extractServer admin.A
 			ri := ris[len(ris)-1-i]
 			if err := writeOp(&admin.Op{Op1_12: &admin.Op1_12{
 				Repo: &pfs.CreateRepoRequest{
-					Repo:        ri.Repo,
-					Description: ri.Description,
+					Repo:         ri.Repo,
+					Description:  ri.Description,
+					Retention:    ri.Retention,
+					Quota:        ri.Quota,
+					Immutability: ri.Immutability,
 				}},
 			}); err != nil {
 				return err
@@ -188,16 +191,17 @@ func (a *apiServer) Extract(request *admin.ExtractRequest, extractServer admin.A
 				ci.Finished = types.TimestampNow()
 			}
 			return writeOp(&admin.Op{Op1_12: &admin.Op1_12{Commit: &pfs.BuildCommitRequest{
-				Origin:     ci.Origin,
-				Parent:     ci.ParentCommit,
-				Tree:       ci.Tree,
-				ID:         ci.Commit.ID,
-				Trees:      ci.Trees,
-				Datums:     ci.Datums,
-				SizeBytes:  ci.SizeBytes,
-				Provenance: ci.Provenance,
-				Started:    ci.Started,
-				Finished:   ci.Finished,
+				Origin:      ci.Origin,
+				Parent:      ci.ParentCommit,
+				Tree:        ci.Tree,
+				ID:          ci.Commit.ID,
+				Trees:       ci.Trees,
+				Datums:      ci.Datums,
+				SizeBytes:   ci.SizeBytes,
+				Provenance:  ci.Provenance,
+				Started:     ci.Started,
+				Finished:    ci.Finished,
+				Annotations: ci.Annotations,
 			}}})
 		}); err != nil {
 			return err
@@ -222,6 +226,20 @@ func (a *apiServer) Extract(request *admin.ExtractRequest, extractServer admin.A
 				return err
 			}
 		}
+		tis, err := pachClient.ListCommitTag("")
+		if err != nil {
+			return err
+		}
+		for _, ti := range tis {
+			if err := writeOp(&admin.Op{Op1_12: &admin.Op1_12{
+				CommitTag: &pfs.CreateCommitTagRequest{
+					Tag:    ti.Tag,
+					Commit: ti.Commit,
+				},
+			}}); err != nil {
+				return err
+			}
+		}
 	}
 	if !request.NoPipelines {
 		pis, err := pachClient.ListPipeline()
@@ -680,6 +698,10 @@ func (r *restoreCtx) applyOp(op *admin.Op1_12) error {
 		if _, err := c.PfsAPIClient.CreateBranch(ctx, op.Branch); err != nil && !errutil.IsAlreadyExistError(err) {
 			return errors.Wrapf(grpcutil.ScrubGRPC(err), "error creating branch")
 		}
+	case op.CommitTag != nil:
+		if _, err := c.PfsAPIClient.CreateCommitTag(ctx, op.CommitTag); err != nil && !errutil.IsAlreadyExistError(err) {
+			return errors.Wrapf(grpcutil.ScrubGRPC(err), "error creating commit tag")
+		}
 	case op.Pipeline != nil:
 		sanitizePipeline(op.Pipeline)
 		if _, err := c.PpsAPIClient.CreatePipeline(ctx, op.Pipeline); err != nil && !errutil.IsAlreadyExistError(err) {
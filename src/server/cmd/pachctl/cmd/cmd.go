@@ -66,6 +66,7 @@ __pachctl_get_commit() {
 	fi
 	__pachctl_get_object "list commit $1" 2
 	__pachctl_get_object "list branch $1" 1
+	__pachctl_get_object "list tag $1" 1
 }
 
 # Performs completion of the standard format <repo>@<branch-or-commit>
@@ -195,28 +196,31 @@ __custom_func() {
 				__pachctl_get_repo
 			fi
 			;;
-		pachctl_update_repo | pachctl_inspect_repo | pachctl_delete_repo | pachctl_list_branch | pachctl_list_commit)
+		pachctl_update_repo | pachctl_inspect_repo | pachctl_delete_repo | pachctl_rename_repo | pachctl_fork_repo | pachctl_purge_file | pachctl_list_branch | pachctl_list_commit | pachctl_list_tag)
 			if __is_active_arg 0; then
 				__pachctl_get_repo
 			fi
 			;;
-		pachctl_delete_branch | pachctl_subscribe_commit)
+		pachctl_delete_branch | pachctl_rename_branch | pachctl_subscribe_commit)
 			if __is_active_arg 0; then
 				__pachctl_get_repo_branch
 			fi
 			;;
-		pachctl_finish_commit | pachctl_inspect_commit | pachctl_delete_commit | pachctl_create_branch | pachctl_start_commit)
+		pachctl_merge_branch)
+			__pachctl_get_repo_branch
+			;;
+		pachctl_finish_commit | pachctl_inspect_commit | pachctl_delete_commit | pachctl_create_branch | pachctl_start_commit | pachctl_revert_commit | pachctl_cherry-pick_commit | pachctl_squash_commit)
 			if __is_active_arg 0; then
 				__pachctl_get_repo_commit
 			fi
 			;;
-		pachctl_get_file | pachctl_inspect_file | pachctl_list_file | pachctl_delete_file | pachctl_glob_file | pachctl_put_file)
+		pachctl_get_file | pachctl_inspect_file | pachctl_list_file | pachctl_delete_file | pachctl_glob_file | pachctl_grep_file | pachctl_put_file)
 			# completion splits the ':' character into its own argument
 			if __is_active_arg 0 1 2; then
 				__pachctl_get_repo_commit_path
 			fi
 			;;
-		pachctl_copy_file | pachctl_diff_file)
+		pachctl_copy_file | pachctl_move_file | pachctl_diff_file)
 			__pachctl_get_repo_commit_path
 			;;
 		pachctl_inspect_job | pachctl_delete_job | pachctl_stop_job | pachctl_list_datum | pachctl_restart_datum)
@@ -782,12 +786,66 @@ This resets the cluster to its initial state.`,
 	}
 	subcommands = append(subcommands, cmdutil.CreateAlias(globDocs, "glob"))
 
+	grepDocs := &cobra.Command{
+		Short: "Search the content of Pachyderm resources for a regular expression.",
+		Long:  "Search the content of Pachyderm resources for a regular expression.",
+	}
+	subcommands = append(subcommands, cmdutil.CreateAlias(grepDocs, "grep"))
+
 	diffDocs := &cobra.Command{
 		Short: "Show the differences between two Pachyderm resources.",
 		Long:  "Show the differences between two Pachyderm resources.",
 	}
 	subcommands = append(subcommands, cmdutil.CreateAlias(diffDocs, "diff"))
 
+	mergeDocs := &cobra.Command{
+		Short: "Combine the changes in two Pachyderm resources.",
+		Long:  "Combine the changes in two Pachyderm resources.",
+	}
+	subcommands = append(subcommands, cmdutil.CreateAlias(mergeDocs, "merge"))
+
+	moveDocs := &cobra.Command{
+		Short: "Move a Pachyderm resource.",
+		Long:  "Move a Pachyderm resource.",
+	}
+	subcommands = append(subcommands, cmdutil.CreateAlias(moveDocs, "move"))
+
+	forkDocs := &cobra.Command{
+		Short: "Create a copy-on-write copy of a Pachyderm resource.",
+		Long:  "Create a copy-on-write copy of a Pachyderm resource.",
+	}
+	subcommands = append(subcommands, cmdutil.CreateAlias(forkDocs, "fork"))
+
+	renameDocs := &cobra.Command{
+		Short: "Rename a Pachyderm resource.",
+		Long:  "Rename a Pachyderm resource.",
+	}
+	subcommands = append(subcommands, cmdutil.CreateAlias(renameDocs, "rename"))
+
+	revertDocs := &cobra.Command{
+		Short: "Undo the changes made in a Pachyderm resource.",
+		Long:  "Undo the changes made in a Pachyderm resource.",
+	}
+	subcommands = append(subcommands, cmdutil.CreateAlias(revertDocs, "revert"))
+
+	cherryPickDocs := &cobra.Command{
+		Short: "Apply the changes made in a Pachyderm resource to another resource.",
+		Long:  "Apply the changes made in a Pachyderm resource to another resource.",
+	}
+	subcommands = append(subcommands, cmdutil.CreateAlias(cherryPickDocs, "cherry-pick"))
+
+	squashDocs := &cobra.Command{
+		Short: "Collapse a range of Pachyderm resources into one.",
+		Long:  "Collapse a range of Pachyderm resources into one.",
+	}
+	subcommands = append(subcommands, cmdutil.CreateAlias(squashDocs, "squash"))
+
+	purgeDocs := &cobra.Command{
+		Short: "Permanently remove data from a Pachyderm resource's history.",
+		Long:  "Permanently remove data from a Pachyderm resource's history.",
+	}
+	subcommands = append(subcommands, cmdutil.CreateAlias(purgeDocs, "purge"))
+
 	stopDocs := &cobra.Command{
 		Short: "Cancel an ongoing task.",
 		Long:  "Cancel an ongoing task.",
@@ -864,6 +922,7 @@ func applyRootUsageFunc(rootCmd *cobra.Command) {
 			"tag":
 			// These are ignored - they will show up in the help topics section
 		case
+			"cherry-pick",
 			"copy",
 			"create",
 			"delete",
@@ -871,12 +930,19 @@ func applyRootUsageFunc(rootCmd *cobra.Command) {
 			"edit",
 			"finish",
 			"flush",
+			"fork",
 			"get",
 			"glob",
+			"grep",
 			"inspect",
 			"list",
+			"merge",
+			"move",
 			"put",
+			"rename",
 			"restart",
+			"revert",
+			"squash",
 			"start",
 			"stop",
 			"subscribe",
@@ -888,6 +954,7 @@ func applyRootUsageFunc(rootCmd *cobra.Command) {
 			"extract",
 			"restore",
 			"garbage-collect",
+			"purge",
 			"update-dash",
 			"auth",
 			"enterprise":
@@ -11,10 +11,12 @@ import (
 
 	"github.com/pachyderm/pachyderm/src/client"
 	"github.com/pachyderm/pachyderm/src/client/auth"
+	"github.com/pachyderm/pachyderm/src/client/pfs"
 	"github.com/pachyderm/pachyderm/src/server/pkg/errutil"
 
 	"github.com/gogo/protobuf/types"
 	"github.com/julienschmidt/httprouter"
+	log "github.com/sirupsen/logrus"
 	"golang.org/x/net/context"
 	"google.golang.org/grpc/metadata"
 )
@@ -85,6 +87,18 @@ func (s *server) getFileHandler(w http.ResponseWriter, r *http.Request, ps httpr
 		httpError(w, err)
 		return
 	}
+	if archive := r.URL.Query().Get("archive"); archive != "" {
+		format, err := pfs.ParseArchiveFormat(archive)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if fileName == "" {
+			fileName = ps.ByName("repoName")
+		}
+		s.getFileArchive(c, w, ps.ByName("repoName"), ps.ByName("commitID"), ps.ByName("filePath"), format, fileName+format.Extension())
+		return
+	}
 	content, err := c.GetFileReadSeeker(ps.ByName("repoName"), ps.ByName("commitID"), ps.ByName("filePath"))
 	if err != nil {
 		httpError(w, err)
@@ -98,6 +112,45 @@ func (s *server) getFileHandler(w http.ResponseWriter, r *http.Request, ps httpr
 	http.ServeContent(w, r, fileName, modtime, content)
 }
 
+// getFileArchive streams the files under (or matching) 'filePath' to 'w' as
+// an archive named 'archiveName'. Errors are only reported with an HTTP
+// status if they happen before any of the archive has been sent.
+func (s *server) getFileArchive(c *client.APIClient, w http.ResponseWriter, repoName, commitID, filePath string, format pfs.ArchiveFormat, archiveName string) {
+	aw := &archiveResponseWriter{w: w, archiveName: archiveName, format: format}
+	if err := c.GetFileArchive(repoName, commitID, filePath, format, aw); err != nil {
+		if !aw.started {
+			httpError(w, err)
+			return
+		}
+		log.Errorf("error streaming archive of %s@%s:%s: %v", repoName, commitID, filePath, err)
+	}
+}
+
+// archiveResponseWriter sets the headers of an archive download when the
+// first bytes of the archive are written.
+type archiveResponseWriter struct {
+	w           http.ResponseWriter
+	archiveName string
+	format      pfs.ArchiveFormat
+	started     bool
+}
+
+func (a *archiveResponseWriter) Write(p []byte) (int, error) {
+	if !a.started {
+		a.started = true
+		contentType := "application/x-tar"
+		switch a.format {
+		case pfs.ArchiveFormat_TAR_GZ:
+			contentType = "application/gzip"
+		case pfs.ArchiveFormat_ZIP:
+			contentType = "application/zip"
+		}
+		a.w.Header().Set("Content-Type", contentType)
+		a.w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%v\"", a.archiveName))
+	}
+	return a.w.Write(p)
+}
+
 func (s *server) serviceHandler(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
 	c := s.getPachClient()
 	serviceName := ps.ByName("serviceName")
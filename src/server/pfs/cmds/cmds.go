@@ -2,6 +2,8 @@ package cmds
 
 import (
 	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -12,12 +14,16 @@ import (
 	"path/filepath"
 	"strings"
 	gosync "sync"
+	"time"
 
 	prompt "github.com/c-bata/go-prompt"
+	units "github.com/docker/go-units"
 	"github.com/gogo/protobuf/jsonpb"
+	"github.com/gogo/protobuf/types"
 	"github.com/pachyderm/pachyderm/src/client"
 	"github.com/pachyderm/pachyderm/src/client/limit"
 	pfsclient "github.com/pachyderm/pachyderm/src/client/pfs"
+	"github.com/pachyderm/pachyderm/src/client/pkg/config"
 	"github.com/pachyderm/pachyderm/src/client/pkg/errors"
 	"github.com/pachyderm/pachyderm/src/client/pkg/grpcutil"
 	"github.com/pachyderm/pachyderm/src/server/cmd/pachctl/shell"
@@ -69,6 +75,15 @@ or type (e.g. csv, binary, images, etc).`,
 	commands = append(commands, cmdutil.CreateDocsAlias(repoDocs, "repo", " repo$"))
 
 	var description string
+	var keepCommits int64
+	var keepDuration string
+	var maxSize string
+	var maxCommits int64
+	var appendOnly bool
+	var legalHoldUntil string
+	var clearRetention bool
+	var clearQuota bool
+	var clearImmutability bool
 	createRepo := &cobra.Command{
 		Use:   "{{alias}} <repo>",
 		Short: "Create a new repo.",
@@ -79,13 +94,28 @@ or type (e.g. csv, binary, images, etc).`,
 				return err
 			}
 			defer c.Close()
+			retention, err := retentionPolicy(keepCommits, keepDuration)
+			if err != nil {
+				return err
+			}
+			quota, err := repoQuota(maxSize, maxCommits)
+			if err != nil {
+				return err
+			}
+			immutability, err := immutabilityPolicy(appendOnly, legalHoldUntil)
+			if err != nil {
+				return err
+			}
 
 			err = txncmds.WithActiveTransaction(c, func(c *client.APIClient) error {
 				_, err = c.PfsAPIClient.CreateRepo(
 					c.Ctx(),
 					&pfsclient.CreateRepoRequest{
-						Repo:        client.NewRepo(args[0]),
-						Description: description,
+						Repo:         client.NewRepo(args[0]),
+						Description:  description,
+						Retention:    retention,
+						Quota:        quota,
+						Immutability: immutability,
 					},
 				)
 				return err
@@ -94,6 +124,12 @@ or type (e.g. csv, binary, images, etc).`,
 		}),
 	}
 	createRepo.Flags().StringVarP(&description, "description", "d", "", "A description of the repo.")
+	createRepo.Flags().Int64Var(&keepCommits, "keep-commits", 0, "Keep the last N commits on each branch; older commits are deleted in the background unless they're the provenance of a live output commit.")
+	createRepo.Flags().StringVar(&keepDuration, "keep-duration", "", "Keep commits finished within this duration (e.g. 168h); older commits are deleted in the background unless they're the provenance of a live output commit.")
+	createRepo.Flags().StringVar(&maxSize, "max-size", "", "The maximum size of a commit in the repo (e.g. 100GB); writes that would exceed it fail. Only the repo's owners can set this.")
+	createRepo.Flags().Int64Var(&maxCommits, "max-commits", 0, "The maximum number of commits in the repo; new commits that would exceed it fail. Only the repo's owners can set this.")
+	createRepo.Flags().BoolVar(&appendOnly, "append-only", false, "Make the repo append-only: files can't be overwritten or deleted, and commits and the repo itself can't be deleted. Only the repo's owners can set this.")
+	createRepo.Flags().StringVar(&legalHoldUntil, "legal-hold-until", "", "Place an append-only repo under a legal hold until this time (RFC 3339, e.g. 2030-01-02T15:04:05Z); until then, no one can make the repo writable again.")
 	commands = append(commands, cmdutil.CreateAlias(createRepo, "create repo"))
 
 	updateRepo := &cobra.Command{
@@ -106,14 +142,47 @@ or type (e.g. csv, binary, images, etc).`,
 				return err
 			}
 			defer c.Close()
+			retention, err := retentionPolicy(keepCommits, keepDuration)
+			if err != nil {
+				return err
+			}
+			if clearRetention {
+				if retention != nil {
+					return errors.Errorf("--clear-retention cannot be used with --keep-commits or --keep-duration")
+				}
+				retention = &pfsclient.RetentionPolicy{}
+			}
+			quota, err := repoQuota(maxSize, maxCommits)
+			if err != nil {
+				return err
+			}
+			if clearQuota {
+				if quota != nil {
+					return errors.Errorf("--clear-quota cannot be used with --max-size or --max-commits")
+				}
+				quota = &pfsclient.RepoQuota{}
+			}
+			immutability, err := immutabilityPolicy(appendOnly, legalHoldUntil)
+			if err != nil {
+				return err
+			}
+			if clearImmutability {
+				if immutability != nil {
+					return errors.Errorf("--clear-immutability cannot be used with --append-only or --legal-hold-until")
+				}
+				immutability = &pfsclient.ImmutabilityPolicy{}
+			}
 
 			err = txncmds.WithActiveTransaction(c, func(c *client.APIClient) error {
 				_, err = c.PfsAPIClient.CreateRepo(
 					c.Ctx(),
 					&pfsclient.CreateRepoRequest{
-						Repo:        client.NewRepo(args[0]),
-						Description: description,
-						Update:      true,
+						Repo:         client.NewRepo(args[0]),
+						Description:  description,
+						Update:       true,
+						Retention:    retention,
+						Quota:        quota,
+						Immutability: immutability,
 					},
 				)
 				return err
@@ -122,6 +191,15 @@ or type (e.g. csv, binary, images, etc).`,
 		}),
 	}
 	updateRepo.Flags().StringVarP(&description, "description", "d", "", "A description of the repo.")
+	updateRepo.Flags().Int64Var(&keepCommits, "keep-commits", 0, "Keep the last N commits on each branch; older commits are deleted in the background unless they're the provenance of a live output commit.")
+	updateRepo.Flags().StringVar(&keepDuration, "keep-duration", "", "Keep commits finished within this duration (e.g. 168h); older commits are deleted in the background unless they're the provenance of a live output commit.")
+	updateRepo.Flags().BoolVar(&clearRetention, "clear-retention", false, "Remove the repo's retention policy. If neither this nor --keep-commits or --keep-duration is set, the policy is left unchanged.")
+	updateRepo.Flags().StringVar(&maxSize, "max-size", "", "The maximum size of a commit in the repo (e.g. 100GB); writes that would exceed it fail. Only the repo's owners can set this.")
+	updateRepo.Flags().Int64Var(&maxCommits, "max-commits", 0, "The maximum number of commits in the repo; new commits that would exceed it fail. Only the repo's owners can set this.")
+	updateRepo.Flags().BoolVar(&clearQuota, "clear-quota", false, "Remove the repo's quota. If neither this nor --max-size or --max-commits is set, the quota is left unchanged.")
+	updateRepo.Flags().BoolVar(&appendOnly, "append-only", false, "Make the repo append-only: files can't be overwritten or deleted, and commits and the repo itself can't be deleted. Only the repo's owners can set this.")
+	updateRepo.Flags().StringVar(&legalHoldUntil, "legal-hold-until", "", "Place an append-only repo under a legal hold until this time (RFC 3339, e.g. 2030-01-02T15:04:05Z); until then, no one can make the repo writable again.")
+	updateRepo.Flags().BoolVar(&clearImmutability, "clear-immutability", false, "Make an append-only repo writable again; this fails while the repo is under a legal hold. If neither this nor --append-only or --legal-hold-until is set, the policy is left unchanged.")
 	shell.RegisterCompletionFunc(updateRepo, shell.RepoCompletion)
 	commands = append(commands, cmdutil.CreateAlias(updateRepo, "update repo"))
 
@@ -232,6 +310,47 @@ or type (e.g. csv, binary, images, etc).`,
 	shell.RegisterCompletionFunc(deleteRepo, shell.RepoCompletion)
 	commands = append(commands, cmdutil.CreateAlias(deleteRepo, "delete repo"))
 
+	renameRepo := &cobra.Command{
+		Use:   "{{alias}} <repo> <new-name>",
+		Short: "Rename a repo.",
+		Long:  "Rename a repo. Its branches, commits, provenance and ACL move to the new name, and pipelines that read from the repo are updated to read from the new name. Pipeline output repos can't be renamed.",
+		Run: cmdutil.RunFixedArgs(2, func(args []string) error {
+			c, err := client.NewOnUserMachine("user")
+			if err != nil {
+				return err
+			}
+			defer c.Close()
+			return c.RenameRepo(args[0], args[1])
+		}),
+	}
+	shell.RegisterCompletionFunc(renameRepo, shell.RepoCompletion)
+	commands = append(commands, cmdutil.CreateAlias(renameRepo, "rename repo"))
+
+	var forkBranch string
+	forkRepo := &cobra.Command{
+		Use:   "{{alias}} <repo> <new-repo>",
+		Short: "Create a copy-on-write copy of a repo.",
+		Long:  "Create a new repo whose initial commit has the same contents as the head of a branch in an existing repo. No data is copied, and files put or deleted in either repo don't affect the other.",
+		Example: `
+# Fork the master branch of repo "data" into a new repo "sandbox"
+$ {{alias}} data sandbox
+
+# Fork the "staging" branch of repo "data" into a new repo "sandbox"
+$ {{alias}} data sandbox --branch staging`,
+		Run: cmdutil.RunFixedArgs(2, func(args []string) error {
+			c, err := client.NewOnUserMachine("user")
+			if err != nil {
+				return err
+			}
+			defer c.Close()
+			return c.ForkRepo(args[0], args[1], forkBranch)
+		}),
+	}
+	forkRepo.Flags().StringVarP(&forkBranch, "branch", "b", "master", "The branch to fork; it's created in the new repo with the same name.")
+	forkRepo.MarkFlagCustom("branch", "__pachctl_get_branch $(__parse_repo ${nouns[0]})")
+	shell.RegisterCompletionFunc(forkRepo, shell.RepoCompletion)
+	commands = append(commands, cmdutil.CreateAlias(forkRepo, "fork repo"))
+
 	commitDocs := &cobra.Command{
 		Short: "Docs for commits.",
 		Long: `Commits are atomic transactions on the content of a repo.
@@ -249,6 +368,7 @@ Commits can be created with another commit as a parent.`,
 	commands = append(commands, cmdutil.CreateDocsAlias(commitDocs, "commit", " commit$"))
 
 	var parent string
+	var annotations cmdutil.RepeatedStringArg
 	startCommit := &cobra.Command{
 		Use:   "{{alias}} <repo>@<branch-or-commit>",
 		Short: "Start a new commit.",
@@ -269,6 +389,10 @@ $ {{alias}} test -p XXX`,
 			if err != nil {
 				return err
 			}
+			commitAnnotations, err := cmdutil.ParseKeyValues(annotations)
+			if err != nil {
+				return err
+			}
 			c, err := client.NewOnUserMachine("user")
 			if err != nil {
 				return err
@@ -284,6 +408,7 @@ $ {{alias}} test -p XXX`,
 						Branch:      branch.Name,
 						Parent:      client.NewCommit(branch.Repo.Name, parent),
 						Description: description,
+						Annotations: commitAnnotations,
 					},
 				)
 				return err
@@ -298,6 +423,7 @@ $ {{alias}} test -p XXX`,
 	startCommit.MarkFlagCustom("parent", "__pachctl_get_commit $(__parse_repo ${nouns[0]})")
 	startCommit.Flags().StringVarP(&description, "message", "m", "", "A description of this commit's contents")
 	startCommit.Flags().StringVar(&description, "description", "", "A description of this commit's contents (synonym for --message)")
+	startCommit.Flags().Var(&annotations, "annotation", "An annotation for this commit, of the form key=value. May be specified multiple times.")
 	shell.RegisterCompletionFunc(startCommit, shell.BranchCompletion)
 	commands = append(commands, cmdutil.CreateAlias(startCommit, "start commit"))
 
@@ -310,6 +436,10 @@ $ {{alias}} test -p XXX`,
 			if err != nil {
 				return err
 			}
+			commitAnnotations, err := cmdutil.ParseKeyValues(annotations)
+			if err != nil {
+				return err
+			}
 			c, err := client.NewOnUserMachine("user")
 			if err != nil {
 				return err
@@ -322,6 +452,7 @@ $ {{alias}} test -p XXX`,
 					&pfsclient.FinishCommitRequest{
 						Commit:      commit,
 						Description: description,
+						Annotations: commitAnnotations,
 					},
 				)
 				return err
@@ -331,6 +462,7 @@ $ {{alias}} test -p XXX`,
 	}
 	finishCommit.Flags().StringVarP(&description, "message", "m", "", "A description of this commit's contents (overwrites any existing commit description)")
 	finishCommit.Flags().StringVar(&description, "description", "", "A description of this commit's contents (synonym for --message)")
+	finishCommit.Flags().Var(&annotations, "annotation", "An annotation for this commit, of the form key=value; added to any annotations set when the commit was started. May be specified multiple times.")
 	shell.RegisterCompletionFunc(finishCommit, shell.BranchCompletion)
 	commands = append(commands, cmdutil.CreateAlias(finishCommit, "finish commit"))
 
@@ -373,6 +505,7 @@ $ {{alias}} test -p XXX`,
 
 	var from string
 	var number int
+	var filters cmdutil.RepeatedStringArg
 	listCommit := &cobra.Command{
 		Use:   "{{alias}} <repo>[@<branch>]",
 		Short: "Return all commits on a repo.",
@@ -388,7 +521,10 @@ $ {{alias}} foo@master
 $ {{alias}} foo@master -n 20
 
 # return commits in repo "foo" since commit XXX
-$ {{alias}} foo@master --from XXX`,
+$ {{alias}} foo@master --from XXX
+
+# return commits in repo "foo" with the annotation "batch=42"
+$ {{alias}} foo --filter batch=42`,
 		Run: cmdutil.RunFixedArgs(1, func(args []string) (retErr error) {
 			c, err := client.NewOnUserMachine("user")
 			if err != nil {
@@ -400,14 +536,18 @@ $ {{alias}} foo@master --from XXX`,
 			if err != nil {
 				return err
 			}
+			filterAnnotations, err := cmdutil.ParseKeyValues(filters)
+			if err != nil {
+				return err
+			}
 
 			if raw {
-				return c.ListCommitF(branch.Repo.Name, branch.Name, from, uint64(number), false, func(ci *pfsclient.CommitInfo) error {
+				return c.ListCommitFilterF(branch.Repo.Name, branch.Name, from, uint64(number), false, filterAnnotations, func(ci *pfsclient.CommitInfo) error {
 					return marshaller.Marshal(os.Stdout, ci)
 				})
 			}
 			writer := tabwriter.NewWriter(os.Stdout, pretty.CommitHeader)
-			if err := c.ListCommitF(branch.Repo.Name, branch.Name, from, uint64(number), false, func(ci *pfsclient.CommitInfo) error {
+			if err := c.ListCommitFilterF(branch.Repo.Name, branch.Name, from, uint64(number), false, filterAnnotations, func(ci *pfsclient.CommitInfo) error {
 				pretty.PrintCommitInfo(writer, ci, fullTimestamps)
 				return nil
 			}); err != nil {
@@ -418,6 +558,7 @@ $ {{alias}} foo@master --from XXX`,
 	}
 	listCommit.Flags().StringVarP(&from, "from", "f", "", "list all commits since this commit")
 	listCommit.Flags().IntVarP(&number, "number", "n", 0, "list only this many commits; if set to zero, list all commits")
+	listCommit.Flags().Var(&filters, "filter", "list only commits with this annotation, of the form key=value. May be specified multiple times, in which case commits must have all of the given annotations.")
 	listCommit.MarkFlagCustom("from", "__pachctl_get_commit $(__parse_repo ${nouns[0]})")
 	listCommit.Flags().AddFlagSet(rawFlags)
 	listCommit.Flags().AddFlagSet(fullTimestampsFlags)
@@ -579,6 +720,112 @@ $ {{alias}} test@master --new`,
 	shell.RegisterCompletionFunc(deleteCommit, shell.BranchCompletion)
 	commands = append(commands, cmdutil.CreateAlias(deleteCommit, "delete commit"))
 
+	var targetBranch string
+	revertCommit := &cobra.Command{
+		Use:   "{{alias}} <repo>@<branch-or-commit>",
+		Short: "Undo the changes made in a commit.",
+		Long:  "Create a new commit that undoes the changes made in a commit, relative to its parent. The new commit is created on the commit's branch, unless another branch is given with --branch.",
+		Example: `
+# Undo the changes made in the head commit of branch "master" in repo "test"
+$ {{alias}} test@master
+
+# Undo the changes made in commit XXX on branch "release"
+$ {{alias}} test@XXX --branch release`,
+		Run: cmdutil.RunFixedArgs(1, func(args []string) error {
+			commit, err := cmdutil.ParseCommit(args[0])
+			if err != nil {
+				return err
+			}
+			c, err := client.NewOnUserMachine("user")
+			if err != nil {
+				return err
+			}
+			defer c.Close()
+
+			newCommit, err := c.RevertCommit(commit.Repo.Name, commit.ID, targetBranch, description)
+			if err != nil {
+				return err
+			}
+			fmt.Println(newCommit.ID)
+			return nil
+		}),
+	}
+	revertCommit.Flags().StringVarP(&targetBranch, "branch", "b", "", "The branch to create the new commit on, defaults to the branch of the reverted commit.")
+	revertCommit.MarkFlagCustom("branch", "__pachctl_get_branch $(__parse_repo ${nouns[0]})")
+	revertCommit.Flags().StringVarP(&description, "message", "m", "", "A description of the new commit's contents")
+	revertCommit.Flags().StringVar(&description, "description", "", "A description of the new commit's contents (synonym for --message)")
+	shell.RegisterCompletionFunc(revertCommit, shell.BranchCompletion)
+	commands = append(commands, cmdutil.CreateAlias(revertCommit, "revert commit"))
+
+	cherryPickCommit := &cobra.Command{
+		Use:   "{{alias}} <repo>@<branch-or-commit> <branch>",
+		Short: "Apply the changes made in a commit to a branch.",
+		Long:  "Create a new commit on a branch that re-applies the changes made in a commit, relative to its parent.",
+		Example: `
+# Apply the changes made in commit XXX of repo "test" to branch "release"
+$ {{alias}} test@XXX release`,
+		Run: cmdutil.RunFixedArgs(2, func(args []string) error {
+			commit, err := cmdutil.ParseCommit(args[0])
+			if err != nil {
+				return err
+			}
+			c, err := client.NewOnUserMachine("user")
+			if err != nil {
+				return err
+			}
+			defer c.Close()
+
+			newCommit, err := c.CherryPickCommit(commit.Repo.Name, commit.ID, args[1], description)
+			if err != nil {
+				return err
+			}
+			fmt.Println(newCommit.ID)
+			return nil
+		}),
+	}
+	cherryPickCommit.Flags().StringVarP(&description, "message", "m", "", "A description of the new commit's contents")
+	cherryPickCommit.Flags().StringVar(&description, "description", "", "A description of the new commit's contents (synonym for --message)")
+	shell.RegisterCompletionFunc(cherryPickCommit, shell.BranchCompletion)
+	commands = append(commands, cmdutil.CreateAlias(cherryPickCommit, "cherry-pick commit"))
+
+	squashCommit := &cobra.Command{
+		Use:   "{{alias}} <repo>@<from>..<to>",
+		Short: "Collapse a range of commits into a single commit.",
+		Long:  "Collapse a linear range of finished commits into a single commit with the final commit's contents. The squashed commit keeps the ID of the last commit in the range, and the other commits are deleted.",
+		Example: `
+# Squash the commits from XXX to YYY (inclusive) of repo "test"
+$ {{alias}} test@XXX..YYY
+
+# Squash the commits from XXX to the head of branch "master" of repo "test"
+$ {{alias}} test@XXX..master`,
+		Run: cmdutil.RunFixedArgs(1, func(args []string) error {
+			commit, err := cmdutil.ParseCommit(args[0])
+			if err != nil {
+				return err
+			}
+			parts := strings.Split(commit.ID, "..")
+			if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+				return errors.Errorf("invalid commit range %q, expected <repo>@<from>..<to>", args[0])
+			}
+			c, err := client.NewOnUserMachine("user")
+			if err != nil {
+				return err
+			}
+			defer c.Close()
+
+			squashed, err := c.SquashCommits(commit.Repo.Name, parts[0], parts[1], description)
+			if err != nil {
+				return err
+			}
+			fmt.Println(squashed.ID)
+			return nil
+		}),
+	}
+	squashCommit.Flags().StringVarP(&description, "message", "m", "", "A description of the squashed commit's contents, defaults to the description of the last commit in the range")
+	squashCommit.Flags().StringVar(&description, "description", "", "A description of the squashed commit's contents (synonym for --message)")
+	shell.RegisterCompletionFunc(squashCommit, shell.BranchCompletion)
+	commands = append(commands, cmdutil.CreateAlias(squashCommit, "squash commit"))
+
 	branchDocs := &cobra.Command{
 		Short: "Docs for branches.",
 		Long: `A branch in Pachyderm is an alias for a Commit ID.
@@ -714,6 +961,196 @@ Any pachctl command that can take a Commit ID, can take a branch name instead.`,
 	shell.RegisterCompletionFunc(deleteBranch, shell.BranchCompletion)
 	commands = append(commands, cmdutil.CreateAlias(deleteBranch, "delete branch"))
 
+	renameBranch := &cobra.Command{
+		Use:   "{{alias}} <repo>@<branch> <new-name>",
+		Short: "Rename a branch.",
+		Long:  "Rename a branch. Commits and provenance that refer to the branch are updated, and pipelines that read from the branch are updated to read from the new name. Pipeline output branches can't be renamed.",
+		Example: `
+# Rename branch "master" in repo "test" to "main"
+$ {{alias}} test@master main`,
+		Run: cmdutil.RunFixedArgs(2, func(args []string) error {
+			branch, err := cmdutil.ParseBranch(args[0])
+			if err != nil {
+				return err
+			}
+			c, err := client.NewOnUserMachine("user")
+			if err != nil {
+				return err
+			}
+			defer c.Close()
+			return c.RenameBranch(branch.Repo.Name, branch.Name, args[1])
+		}),
+	}
+	shell.RegisterCompletionFunc(renameBranch, shell.BranchCompletion)
+	commands = append(commands, cmdutil.CreateAlias(renameBranch, "rename branch"))
+
+	var strategy string
+	mergeBranch := &cobra.Command{
+		Use:   "{{alias}} <repo>@<from-branch> <repo>@<to-branch>",
+		Short: "Merge one branch into another.",
+		Long:  "Merge the changes made on one branch into another branch of the same repo. The changes made on each branch since their most recent common ancestor are combined in a new commit on the second branch, whose parents are the heads of both branches.",
+		Example: `
+# Merge the changes on branch "feature" into branch "master" in repo "test"
+$ {{alias}} test@feature test@master
+
+# Merge branch "feature" into "master", keeping the version on "feature" of any
+# file that was changed on both branches
+$ {{alias}} test@feature test@master --strategy theirs`,
+		Run: cmdutil.RunFixedArgs(2, func(args []string) error {
+			from, err := cmdutil.ParseBranch(args[0])
+			if err != nil {
+				return err
+			}
+			to, err := cmdutil.ParseBranch(args[1])
+			if err != nil {
+				return err
+			}
+			if from.Repo.Name != to.Repo.Name {
+				return errors.Errorf("cannot merge branches in different repos (%s and %s)", from.Repo.Name, to.Repo.Name)
+			}
+			var mergeStrategy pfsclient.MergeStrategy
+			switch strategy {
+			case "fail":
+				mergeStrategy = pfsclient.MergeStrategy_FAIL
+			case "ours":
+				mergeStrategy = pfsclient.MergeStrategy_OURS
+			case "theirs":
+				mergeStrategy = pfsclient.MergeStrategy_THEIRS
+			default:
+				return errors.Errorf("unrecognized merge strategy '%s'; only accepts one of "+
+					"{fail,ours,theirs}", strategy)
+			}
+			c, err := client.NewOnUserMachine("user")
+			if err != nil {
+				return err
+			}
+			defer c.Close()
+
+			commit, err := c.MergeBranch(to.Repo.Name, from.Name, to.Name, mergeStrategy, description)
+			if err != nil {
+				return err
+			}
+			fmt.Println(commit.ID)
+			return nil
+		}),
+	}
+	mergeBranch.Flags().StringVar(&strategy, "strategy", "fail", "How to resolve files that were changed on both branches. Permissible values are `fail` (abort the merge), `ours` (keep the version on <to-branch>) and `theirs` (take the version on <from-branch>).")
+	mergeBranch.Flags().StringVarP(&description, "message", "m", "", "A description of the merge commit's contents")
+	mergeBranch.Flags().StringVar(&description, "description", "", "A description of the merge commit's contents (synonym for --message)")
+	shell.RegisterCompletionFunc(mergeBranch, shell.BranchCompletion)
+	commands = append(commands, cmdutil.CreateAlias(mergeBranch, "merge branch"))
+
+	createTag := &cobra.Command{
+		Use:   "{{alias}} <repo>@<tag> <branch-or-commit>",
+		Short: "Create an immutable tag for a commit.",
+		Long:  "Create an immutable tag for a commit. Unlike a branch, a tag always refers to the same commit, and can be used anywhere a commit ID is accepted.",
+		Example: `
+# Tag the current head of branch "master" in repo "images" as "v1.2"
+$ {{alias}} images@v1.2 master
+
+# Tag a specific commit
+$ {{alias}} images@v1.2 0001a0100b1c10d01111e001fg00h00i`,
+		Run: cmdutil.RunFixedArgs(2, func(args []string) error {
+			tag, err := cmdutil.ParseBranch(args[0])
+			if err != nil {
+				return err
+			}
+			c, err := client.NewOnUserMachine("user")
+			if err != nil {
+				return err
+			}
+			defer c.Close()
+
+			return c.CreateCommitTag(tag.Repo.Name, tag.Name, args[1])
+		}),
+	}
+	shell.RegisterCompletionFunc(createTag, shell.RepoCompletion)
+	commands = append(commands, cmdutil.CreateAlias(createTag, "create tag"))
+
+	inspectTag := &cobra.Command{
+		Use:   "{{alias}} <repo>@<tag>",
+		Short: "Return info about a commit tag.",
+		Long:  "Return info about a commit tag.",
+		Run: cmdutil.RunFixedArgs(1, func(args []string) error {
+			tag, err := cmdutil.ParseBranch(args[0])
+			if err != nil {
+				return err
+			}
+			c, err := client.NewOnUserMachine("user")
+			if err != nil {
+				return err
+			}
+			defer c.Close()
+
+			tagInfo, err := c.InspectCommitTag(tag.Repo.Name, tag.Name)
+			if err != nil {
+				return err
+			}
+			if raw {
+				return marshaller.Marshal(os.Stdout, tagInfo)
+			}
+			return pretty.PrintDetailedCommitTagInfo(tagInfo)
+		}),
+	}
+	inspectTag.Flags().AddFlagSet(rawFlags)
+	shell.RegisterCompletionFunc(inspectTag, shell.RepoCompletion)
+	commands = append(commands, cmdutil.CreateAlias(inspectTag, "inspect tag"))
+
+	listTag := &cobra.Command{
+		Use:   "{{alias}} <repo>",
+		Short: "Return all commit tags on a repo.",
+		Long:  "Return all commit tags on a repo.",
+		Run: cmdutil.RunFixedArgs(1, func(args []string) error {
+			c, err := client.NewOnUserMachine("user")
+			if err != nil {
+				return err
+			}
+			defer c.Close()
+			tagInfos, err := c.ListCommitTag(args[0])
+			if err != nil {
+				return err
+			}
+			if raw {
+				for _, tagInfo := range tagInfos {
+					if err := marshaller.Marshal(os.Stdout, tagInfo); err != nil {
+						return err
+					}
+				}
+				return nil
+			}
+			writer := tabwriter.NewWriter(os.Stdout, pretty.CommitTagHeader)
+			for _, tagInfo := range tagInfos {
+				pretty.PrintCommitTag(writer, tagInfo, fullTimestamps)
+			}
+			return writer.Flush()
+		}),
+	}
+	listTag.Flags().AddFlagSet(rawFlags)
+	listTag.Flags().AddFlagSet(fullTimestampsFlags)
+	shell.RegisterCompletionFunc(listTag, shell.RepoCompletion)
+	commands = append(commands, cmdutil.CreateAlias(listTag, "list tag"))
+
+	deleteTag := &cobra.Command{
+		Use:   "{{alias}} <repo>@<tag>",
+		Short: "Delete a commit tag.",
+		Long:  "Delete a commit tag, while leaving the commit intact.",
+		Run: cmdutil.RunFixedArgs(1, func(args []string) error {
+			tag, err := cmdutil.ParseBranch(args[0])
+			if err != nil {
+				return err
+			}
+			c, err := client.NewOnUserMachine("user")
+			if err != nil {
+				return err
+			}
+			defer c.Close()
+
+			return c.DeleteCommitTag(tag.Repo.Name, tag.Name)
+		}),
+	}
+	shell.RegisterCompletionFunc(deleteTag, shell.RepoCompletion)
+	commands = append(commands, cmdutil.CreateAlias(deleteTag, "delete tag"))
+
 	fileDocs := &cobra.Command{
 		Short: "Docs for files.",
 		Long: `Files are the lowest level data objects in Pachyderm.
@@ -729,12 +1166,17 @@ from commits with 'get file'.`,
 	var inputFile string
 	var parallelism int
 	var split string
+	var splitRegex string
 	var targetFileDatums uint
 	var targetFileBytes uint
 	var headerRecords uint
+	var metadata cmdutil.RepeatedStringArg
 	var putFileCommit bool
 	var overwrite bool
 	var compress bool
+	var resume bool
+	var untar bool
+	var unzip bool
 	putFile := &cobra.Command{
 		Use:   "{{alias}} <repo>@<branch-or-commit>[:<path/to/file>]",
 		Short: "Put a file into the filesystem.",
@@ -767,9 +1209,32 @@ $ {{alias}} repo@branch:/path -f http://host/path
 # Put the data from a URL as repo/branch/path:
 $ {{alias}} repo@branch -f http://host/path
 
+# Put a file from the local filesystem as repo/branch/path, with user-defined
+# metadata attached to it:
+$ {{alias}} repo@branch:/path -f file --metadata content-type=text/csv
+
 # Put the data from an S3 bucket as repo/branch/s3_object:
 $ {{alias}} repo@branch -r -f s3://my_bucket
 
+# Put a large file from the local filesystem as repo/branch/path, resuming the
+# upload where it stopped if an earlier 'put file --resume' was interrupted:
+$ {{alias}} repo@branch:/path -f file --resume
+
+# Put the files in a tar (or tar.gz) archive under repo/branch/path; the
+# archive is unpacked by pachd:
+$ {{alias}} repo@branch:/path -f files.tar.gz --untar
+
+# Put the files in a zip archive read from stdin at the top level:
+$ cat files.zip | {{alias}} repo@branch:/ --unzip
+
+# Split a parquet file into files of at least 100000 rows each, which are put
+# in the directory repo/branch/path:
+$ {{alias}} repo@branch:/path -f data.parquet --split parquet --target-file-datums 100000
+
+# Split a log file into files of 1000 entries each, where each entry begins with
+# a line that starts with a date (and may span several lines):
+$ {{alias}} repo@branch:/logs -f app.log --split-regex '^\d{4}-\d{2}-\d{2}' --target-file-datums 1000
+
 # Put several files or URLs that are listed in file.
 # Files and URLs should be newline delimited.
 $ {{alias}} repo@branch -i file
@@ -783,6 +1248,16 @@ $ {{alias}} repo@branch -i http://host/path`,
 			if err != nil {
 				return err
 			}
+			fileMetadata, err := cmdutil.ParseKeyValues(metadata)
+			if err != nil {
+				return err
+			}
+			if splitRegex != "" {
+				if split != "" {
+					return errors.Errorf("--split and --split-regex cannot both be used")
+				}
+				split = "regex"
+			}
 			opts := []client.Option{client.WithMaxConcurrentStreams(parallelism)}
 			if compress {
 				opts = append(opts, client.WithGZIPCompression())
@@ -792,6 +1267,16 @@ $ {{alias}} repo@branch -i http://host/path`,
 				return err
 			}
 			defer c.Close()
+			if resume {
+				if len(filePaths) != 1 || inputFile != "" || recursive || split != "" || fileMetadata != nil {
+					return errors.Errorf("--resume can only be used to put a single file, without --input-file, --recursive, --split or --metadata")
+				}
+				path := file.Path
+				if path == "" {
+					path = joinPaths("", filePaths[0])
+				}
+				return putFileResumable(c, file.Commit.Repo.Name, file.Commit.ID, path, filePaths[0], overwrite)
+			}
 
 			// load data into pachyderm
 			pfc, err := c.NewPutFileClient()
@@ -849,6 +1334,21 @@ $ {{alias}} repo@branch -i http://host/path`,
 				sources = filePaths
 			}
 
+			if untar || unzip {
+				if untar && unzip {
+					return errors.Errorf("--untar and --unzip cannot both be used")
+				}
+				if recursive || split != "" || fileMetadata != nil {
+					return errors.Errorf("--untar and --unzip cannot be used with --recursive, --split or --metadata")
+				}
+				for _, source := range sources {
+					if err := putFileUnpack(pfc, file.Commit.Repo.Name, file.Commit.ID, file.Path, source, unzip, overwrite); err != nil {
+						return err
+					}
+				}
+				return nil
+			}
+
 			// Arguments parsed; create putFileHelper and begin copying data
 			var eg errgroup.Group
 			filesPut := &gosync.Map{}
@@ -860,19 +1360,19 @@ $ {{alias}} repo@branch -i http://host/path`,
 						return errors.Errorf("must specify filename when reading data from stdin")
 					}
 					eg.Go(func() error {
-						return putFileHelper(c, pfc, file.Commit.Repo.Name, file.Commit.ID, joinPaths("", source), source, recursive, overwrite, limiter, split, targetFileDatums, targetFileBytes, headerRecords, filesPut)
+						return putFileHelper(c, pfc, file.Commit.Repo.Name, file.Commit.ID, joinPaths("", source), source, recursive, overwrite, limiter, split, splitRegex, targetFileDatums, targetFileBytes, headerRecords, fileMetadata, filesPut)
 					})
 				} else if len(sources) == 1 {
 					// We have a single source and the user has specified a path,
 					// we use the path and ignore source (in terms of naming the file).
 					eg.Go(func() error {
-						return putFileHelper(c, pfc, file.Commit.Repo.Name, file.Commit.ID, file.Path, source, recursive, overwrite, limiter, split, targetFileDatums, targetFileBytes, headerRecords, filesPut)
+						return putFileHelper(c, pfc, file.Commit.Repo.Name, file.Commit.ID, file.Path, source, recursive, overwrite, limiter, split, splitRegex, targetFileDatums, targetFileBytes, headerRecords, fileMetadata, filesPut)
 					})
 				} else {
 					// We have multiple sources and the user has specified a path,
 					// we use that path as a prefix for the filepaths.
 					eg.Go(func() error {
-						return putFileHelper(c, pfc, file.Commit.Repo.Name, file.Commit.ID, joinPaths(file.Path, source), source, recursive, overwrite, limiter, split, targetFileDatums, targetFileBytes, headerRecords, filesPut)
+						return putFileHelper(c, pfc, file.Commit.Repo.Name, file.Commit.ID, joinPaths(file.Path, source), source, recursive, overwrite, limiter, split, splitRegex, targetFileDatums, targetFileBytes, headerRecords, fileMetadata, filesPut)
 					})
 				}
 			}
@@ -881,15 +1381,20 @@ $ {{alias}} repo@branch -i http://host/path`,
 	}
 	putFile.Flags().StringSliceVarP(&filePaths, "file", "f", []string{"-"}, "The file to be put, it can be a local file or a URL.")
 	putFile.Flags().StringVarP(&inputFile, "input-file", "i", "", "Read filepaths or URLs from a file.  If - is used, paths are read from the standard input.")
-	putFile.Flags().BoolVarP(&recursive, "recursive", "r", false, "Recursively put the files in a directory.")
+	putFile.Flags().BoolVarP(&recursive, "recursive", "r", false, "Recursively put the files in a directory. Symlinks are stored as symlinks rather than followed, unless they point outside of the directory via an absolute path.")
 	putFile.Flags().BoolVarP(&compress, "compress", "", false, "Compress data during upload. This parameter might help you upload your uncompressed data, such as CSV files, to Pachyderm faster. Use 'compress' with caution, because if your data is already compressed, this parameter might slow down the upload speed instead of increasing.")
 	putFile.Flags().IntVarP(&parallelism, "parallelism", "p", DefaultParallelism, "The maximum number of files that can be uploaded in parallel.")
-	putFile.Flags().StringVar(&split, "split", "", "Split the input file into smaller files, subject to the constraints of --target-file-datums and --target-file-bytes. Permissible values are `line`, `json`, `sql` and `csv`.")
+	putFile.Flags().StringVar(&split, "split", "", "Split the input file into smaller files, subject to the constraints of --target-file-datums and --target-file-bytes. Permissible values are `line`, `json`, `sql`, `csv`, `tsv`, `parquet` (split on row groups) and `avro` (split on data blocks).")
+	putFile.Flags().StringVar(&splitRegex, "split-regex", "", "Split the input file into records that each begin with a line matching this regular expression, subject to the constraints of --target-file-datums and --target-file-bytes. Useful for logs with multi-line records.")
 	putFile.Flags().UintVar(&targetFileDatums, "target-file-datums", 0, "The upper bound of the number of datums that each file contains, the last file will contain fewer if the datums don't divide evenly; needs to be used with --split.")
 	putFile.Flags().UintVar(&targetFileBytes, "target-file-bytes", 0, "The target upper bound of the number of bytes that each file contains; needs to be used with --split.")
-	putFile.Flags().UintVar(&headerRecords, "header-records", 0, "the number of records that will be converted to a PFS 'header', and prepended to future retrievals of any subset of data from PFS; needs to be used with --split=(json|line|csv)")
+	putFile.Flags().UintVar(&headerRecords, "header-records", 0, "the number of records that will be converted to a PFS 'header', and prepended to future retrievals of any subset of data from PFS; needs to be used with --split=(json|line|csv|tsv) or --split-regex")
 	putFile.Flags().BoolVarP(&putFileCommit, "commit", "c", false, "DEPRECATED: Put file(s) in a new commit.")
 	putFile.Flags().BoolVarP(&overwrite, "overwrite", "o", false, "Overwrite the existing content of the file, either from previous commits or previous calls to 'put file' within this commit.")
+	putFile.Flags().Var(&metadata, "metadata", "User-defined metadata to attach to the file(s), of the form key=value. May be specified multiple times.")
+	putFile.Flags().BoolVar(&untar, "untar", false, "Unpack the tar (or gzipped tar) archive being put, putting each file in it under the path.")
+	putFile.Flags().BoolVar(&unzip, "unzip", false, "Unpack the zip archive being put, putting each file in it under the path. pachd spools zip archives to disk before unpacking them, so they can be at most 10GiB; use --untar for larger archives.")
+	putFile.Flags().BoolVar(&resume, "resume", false, "Upload a single local file in chunks, saving its progress so that running the same command again after an interruption resumes the upload instead of restarting it.")
 	shell.RegisterCompletionFunc(putFile,
 		func(flag, text string, maxCompletions int64) ([]prompt.Suggest, shell.CacheFunc) {
 			if flag == "-f" || flag == "--file" || flag == "-i" || flag == "input-file" {
@@ -933,7 +1438,41 @@ $ {{alias}} repo@branch -i http://host/path`,
 	shell.RegisterCompletionFunc(copyFile, shell.FileCompletion)
 	commands = append(commands, cmdutil.CreateAlias(copyFile, "copy file"))
 
+	moveFile := &cobra.Command{
+		Use:   "{{alias}} <repo>@<branch-or-commit>:<src-path> <repo>@<branch-or-commit>:<dst-path>",
+		Short: "Move a file or directory to a new path in the same commit.",
+		Long:  "Move a file or directory to a new path in the same commit. The old path is removed and the new path is created atomically, and the file's content isn't copied. The new path must not already exist.",
+		Example: `
+# Rename directory "images" to "data" on branch "master" in repo "foo"
+$ {{alias}} foo@master:/images foo@master:/data`,
+		Run: cmdutil.RunFixedArgs(2, func(args []string) error {
+			srcFile, err := cmdutil.ParseFile(args[0])
+			if err != nil {
+				return err
+			}
+			destFile, err := cmdutil.ParseFile(args[1])
+			if err != nil {
+				return err
+			}
+			if srcFile.Commit.Repo.Name != destFile.Commit.Repo.Name || srcFile.Commit.ID != destFile.Commit.ID {
+				return errors.New("the source and destination of a move must be in the same commit")
+			}
+			c, err := client.NewOnUserMachine("user")
+			if err != nil {
+				return err
+			}
+			defer c.Close()
+
+			return txncmds.WithActiveTransaction(c, func(c *client.APIClient) error {
+				return c.MoveFile(srcFile.Commit.Repo.Name, srcFile.Commit.ID, srcFile.Path, destFile.Path)
+			})
+		}),
+	}
+	shell.RegisterCompletionFunc(moveFile, shell.FileCompletion)
+	commands = append(commands, cmdutil.CreateAlias(moveFile, "move file"))
+
 	var outputPath string
+	var archive string
 	getFile := &cobra.Command{
 		Use:   "{{alias}} <repo>@<branch-or-commit>:<path/in/pfs>",
 		Short: "Return the contents of a file.",
@@ -948,7 +1487,19 @@ $ {{alias}} foo@master^:XXX
 
 # get file "XXX" in the grandparent of the current head of branch "master"
 # in repo "foo"
-$ {{alias}} foo@master^2:XXX`,
+$ {{alias}} foo@master^2:XXX
+
+# get file "XXX" as of noon on 2020-01-01 (UTC) on branch "master" in repo "foo"
+$ {{alias}} 'foo@master@{2020-01-01T12:00:00Z}:XXX'
+
+# get file "XXX" as of two hours ago on branch "master" in repo "foo"
+$ {{alias}} 'foo@master@{2h ago}:XXX'
+
+# get the directory "dir" on branch "master" in repo "foo" as a zip archive
+$ {{alias}} foo@master:dir --archive zip -o dir.zip
+
+# get the csv files on branch "master" in repo "foo" as a tar.gz archive
+$ {{alias}} 'foo@master:/**.csv' --archive tar.gz > csvs.tar.gz`,
 		Run: cmdutil.RunFixedArgs(1, func(args []string) error {
 			file, err := cmdutil.ParseFile(args[0])
 			if err != nil {
@@ -959,6 +1510,9 @@ $ {{alias}} foo@master^2:XXX`,
 				return err
 			}
 			defer c.Close()
+			if archive != "" && recursive {
+				return errors.Errorf("--archive cannot be used with --recursive")
+			}
 			if recursive {
 				if outputPath == "" {
 					return errors.Errorf("an output path needs to be specified when using the --recursive flag")
@@ -978,12 +1532,20 @@ $ {{alias}} foo@master^2:XXX`,
 				defer f.Close()
 				w = f
 			}
+			if archive != "" {
+				format, err := pfsclient.ParseArchiveFormat(archive)
+				if err != nil {
+					return err
+				}
+				return c.GetFileArchive(file.Commit.Repo.Name, file.Commit.ID, file.Path, format, w)
+			}
 			return c.GetFile(file.Commit.Repo.Name, file.Commit.ID, file.Path, 0, 0, w)
 		}),
 	}
 	getFile.Flags().BoolVarP(&recursive, "recursive", "r", false, "Recursively download a directory.")
 	getFile.Flags().StringVarP(&outputPath, "output", "o", "", "The path where data will be downloaded.")
 	getFile.Flags().IntVarP(&parallelism, "parallelism", "p", DefaultParallelism, "The maximum number of files that can be downloaded in parallel")
+	getFile.Flags().StringVar(&archive, "archive", "", "Download the files under (or matching) the path as an archive. Permissible values are `tar`, `tar.gz` and `zip`.")
 	shell.RegisterCompletionFunc(getFile, shell.FileCompletion)
 	commands = append(commands, cmdutil.CreateAlias(getFile, "get file"))
 
@@ -1128,9 +1690,46 @@ $ {{alias}} "foo@master:data/*"`,
 	shell.RegisterCompletionFunc(globFile, shell.FileCompletion)
 	commands = append(commands, cmdutil.CreateAlias(globFile, "glob file"))
 
+	grepFile := &cobra.Command{
+		Use:   "{{alias}} <repo>@<branch-or-commit>:<pattern> <regexp>",
+		Short: "Search the content of files in a commit for a regular expression.",
+		Long:  "Search the content of the files that match a glob pattern in a commit for lines matching a regular expression, and print each matching line prefixed by the file's path and the line number. The search runs inside Pachyderm, so the files aren't downloaded. Regular expressions use the syntax documented [here](https://golang.org/pkg/regexp/syntax/); prefix the expression with (?i) to ignore case.",
+		Example: `
+# Print the lines containing "error" in the files in repo "foo" on branch
+# "master" under directory "logs".
+$ {{alias}} "foo@master:/logs/*" error
+
+# Print the lines in any file in repo "foo" on branch "master" that start with
+# a date, ignoring case.
+$ {{alias}} "foo@master:/**" "(?i)^[a-z]{3} [0-9]{1,2}"`,
+		Run: cmdutil.RunFixedArgs(2, func(args []string) error {
+			file, err := cmdutil.ParseFile(args[0])
+			if err != nil {
+				return err
+			}
+			c, err := client.NewOnUserMachine("user")
+			if err != nil {
+				return err
+			}
+			defer c.Close()
+			return c.GrepFileF(file.Commit.Repo.Name, file.Commit.ID, file.Path, args[1], func(resp *pfsclient.GrepFileResponse) error {
+				if raw {
+					return marshaller.Marshal(os.Stdout, resp)
+				}
+				fmt.Printf("%s:%d:%s\n", resp.File.Path, resp.LineNumber, resp.Line)
+				return nil
+			})
+		}),
+	}
+	grepFile.Flags().AddFlagSet(rawFlags)
+	shell.RegisterCompletionFunc(grepFile, shell.FileCompletion)
+	commands = append(commands, cmdutil.CreateAlias(grepFile, "grep file"))
+
 	var shallow bool
 	var nameOnly bool
 	var diffCmdArg string
+	var content bool
+	var contentSizeLimit string
 	diffFile := &cobra.Command{
 		Use:   "{{alias}} <new-repo>@<new-branch-or-commit>:<new-path> [<old-repo>@<old-branch-or-commit>:<old-path>]",
 		Short: "Return a diff of two file trees.",
@@ -1142,7 +1741,12 @@ $ {{alias}} foo@master:path
 
 # Return the diff between the master branches of repos foo and bar at paths
 # path1 and path2, respectively.
-$ {{alias}} foo@master:path1 bar@master:path2`,
+$ {{alias}} foo@master:path1 bar@master:path2
+
+# Return the diff of the file "path" of the repo "foo" between the head of the
+# "master" branch and its parent, computed by Pachyderm rather than by
+# downloading both versions of the file.
+$ {{alias}} foo@master:path --content`,
 		Run: cmdutil.RunBoundedArgs(1, 2, func(args []string) error {
 			newFile, err := cmdutil.ParseFile(args[0])
 			if err != nil {
@@ -1161,6 +1765,30 @@ $ {{alias}} foo@master:path1 bar@master:path2`,
 			}
 			defer c.Close()
 
+			if content {
+				if nameOnly || diffCmdArg != "" {
+					return errors.New("--content cannot be used with --name-only or --diff-command")
+				}
+				sizeLimit, err := units.RAMInBytes(contentSizeLimit)
+				if err != nil {
+					return err
+				}
+				contentDiffs, err := c.DiffFileContent(
+					newFile.Commit.Repo.Name, newFile.Commit.ID, newFile.Path,
+					oldFile.Commit.Repo.Name, oldFile.Commit.ID, oldFile.Path,
+					shallow, sizeLimit,
+				)
+				if err != nil {
+					return err
+				}
+				return pager.Page(noPager, os.Stdout, func(w io.Writer) error {
+					for _, contentDiff := range contentDiffs {
+						pretty.PrintFileContentDiff(w, contentDiff)
+					}
+					return nil
+				})
+			}
+
 			return pager.Page(noPager, os.Stdout, func(w io.Writer) (retErr error) {
 				var writer *tabwriter.Writer
 				if nameOnly {
@@ -1227,11 +1855,67 @@ $ {{alias}} foo@master:path1 bar@master:path2`,
 	diffFile.Flags().BoolVarP(&shallow, "shallow", "s", false, "Don't descend into sub directories.")
 	diffFile.Flags().BoolVar(&nameOnly, "name-only", false, "Show only the names of changed files.")
 	diffFile.Flags().StringVar(&diffCmdArg, "diff-command", "", "Use a program other than git to diff files.")
+	diffFile.Flags().BoolVar(&content, "content", false, "Diff the content of files inside Pachyderm, rather than downloading them and diffing them locally.")
+	diffFile.Flags().StringVar(&contentSizeLimit, "content-size-limit", "1MB", "With --content, don't diff the content of files larger than this.")
 	diffFile.Flags().AddFlagSet(fullTimestampsFlags)
 	diffFile.Flags().AddFlagSet(noPagerFlags)
 	shell.RegisterCompletionFunc(diffFile, shell.FileCompletion)
 	commands = append(commands, cmdutil.CreateAlias(diffFile, "diff file"))
 
+	subscribeFile := &cobra.Command{
+		Use:   "{{alias}} <repo>@<branch>:<pattern>",
+		Short: "Print changes to files as they are committed.",
+		Long:  "Print the files that are added, modified or deleted by each commit finished on a branch, if they match a glob pattern (or are in a directory that matches it). Each commit's changes are found by diffing it against the previous commit on the branch. By default, the changes made by all existing commits on the branch are returned first.",
+		Example: `
+# subscribe to changes to files under the directory "logs" in repo "test" on
+# branch "master"
+$ {{alias}} test@master:/logs
+
+# subscribe to changes to csv files in repo "test" on branch "master", but only
+# in new commits created from now on.
+$ {{alias}} "test@master:/**.csv" --new`,
+		Run: cmdutil.RunFixedArgs(1, func(args []string) error {
+			file, err := cmdutil.ParseFile(args[0])
+			if err != nil {
+				return err
+			}
+			c, err := client.NewOnUserMachine("user")
+			if err != nil {
+				return err
+			}
+			defer c.Close()
+
+			if newCommits && from != "" {
+				return errors.Errorf("--new and --from cannot be used together")
+			}
+			if newCommits {
+				from = file.Commit.ID
+			}
+			if file.Path == "" {
+				file.Path = "/"
+			}
+
+			if raw {
+				return c.SubscribeFile(file.Commit.Repo.Name, file.Commit.ID, file.Path, from, func(change *pfsclient.FileChange) error {
+					return marshaller.Marshal(os.Stdout, change)
+				})
+			}
+			writer := tabwriter.NewWriter(os.Stdout, pretty.FileChangeHeader)
+			return c.SubscribeFile(file.Commit.Repo.Name, file.Commit.ID, file.Path, from, func(change *pfsclient.FileChange) error {
+				pretty.PrintFileChange(writer, change, fullTimestamps)
+				// flush each change, so that it's printed as soon as it comes in
+				return writer.Flush()
+			})
+		}),
+	}
+	subscribeFile.Flags().StringVar(&from, "from", "", "subscribe to the changes made by all commits since this commit")
+	subscribeFile.MarkFlagCustom("from", "__pachctl_get_commit $(__parse_repo ${nouns[0]})")
+	subscribeFile.Flags().BoolVar(&newCommits, "new", false, "subscribe to only the changes made by new commits created from now on")
+	subscribeFile.Flags().AddFlagSet(rawFlags)
+	subscribeFile.Flags().AddFlagSet(fullTimestampsFlags)
+	shell.RegisterCompletionFunc(subscribeFile, shell.FileCompletion)
+	commands = append(commands, cmdutil.CreateAlias(subscribeFile, "subscribe file"))
+
 	deleteFile := &cobra.Command{
 		Use:   "{{alias}} <repo>@<branch-or-commit>:<path/in/pfs>",
 		Short: "Delete a file.",
@@ -1253,6 +1937,57 @@ $ {{alias}} foo@master:path1 bar@master:path2`,
 	shell.RegisterCompletionFunc(deleteFile, shell.FileCompletion)
 	commands = append(commands, cmdutil.CreateAlias(deleteFile, "delete file"))
 
+	var deleteOutputs bool
+	purgeFile := &cobra.Command{
+		Use:   "{{alias}} <repo> <path/in/pfs>",
+		Short: "Remove files from every commit in a repo's history.",
+		Long: `Remove files from every commit in a repo's history.
+
+Unlike "delete file", which only removes files from new commits, this rewrites
+every commit in the repo so that none of them contain files matching the glob
+pattern <path/in/pfs>. Output commits derived from the rewritten commits may
+still contain the purged data; they're listed, and deleted (so that pipelines
+reprocess the purged data) if --delete-outputs is set. The purged data is
+removed from object storage by the next "pachctl garbage-collect". Only the
+repo's owners can purge files.`,
+		Example: `
+# Remove foo.csv from every commit in repo "test"
+$ {{alias}} test /foo.csv
+
+# Remove every file under /users/alice in repo "test", and delete the output
+# commits derived from them
+$ {{alias}} test "/users/alice/*" --delete-outputs`,
+		Run: cmdutil.RunFixedArgs(2, func(args []string) error {
+			c, err := client.NewOnUserMachine("user")
+			if err != nil {
+				return err
+			}
+			defer c.Close()
+			resp, err := c.PurgeFile(args[0], args[1], deleteOutputs)
+			if err != nil {
+				return err
+			}
+			for _, commit := range resp.Rewritten {
+				fmt.Printf("rewrote %s\n", pretty.CompactPrintCommit(commit))
+			}
+			for _, commit := range resp.Outputs {
+				if deleteOutputs {
+					fmt.Printf("deleted output %s\n", pretty.CompactPrintCommit(commit))
+				} else {
+					fmt.Printf("output %s may still contain purged data\n", pretty.CompactPrintCommit(commit))
+				}
+			}
+			for _, fork := range resp.Forks {
+				fmt.Printf("fork %s may still contain purged data\n", fork.Name)
+			}
+			fmt.Printf("%d objects will be deleted by the next garbage collection if they're no longer referenced\n", len(resp.Objects))
+			return nil
+		}),
+	}
+	purgeFile.Flags().BoolVar(&deleteOutputs, "delete-outputs", false, "Delete the output commits derived from the rewritten commits, so that pipelines reprocess the purged data.")
+	shell.RegisterCompletionFunc(purgeFile, shell.RepoCompletion)
+	commands = append(commands, cmdutil.CreateAlias(purgeFile, "purge file"))
+
 	objectDocs := &cobra.Command{
 		Short: "Docs for objects.",
 		Long: `Objects are content-addressed blobs of data that are directly stored in the backend object store.
@@ -1278,9 +2013,13 @@ Objects are a low-level resource and should not be accessed directly by most use
 
 	tagDocs := &cobra.Command{
 		Short: "Docs for tags.",
-		Long: `Tags are aliases for objects. Many tags can refer to the same object.
+		Long: `Commit tags are immutable names for commits. Unlike a branch, a tag always
+refers to the same commit. Any pachctl command that can take a Commit ID can
+take a tag name instead.
 
-Tags are a low-level resource and should not be accessed directly by most users.`,
+Object tags ('get tag') are aliases for objects. Many tags can refer to the
+same object. Object tags are a low-level resource and should not be accessed
+directly by most users.`,
 	}
 	commands = append(commands, cmdutil.CreateDocsAlias(tagDocs, "tag", " tag$"))
 
@@ -1300,18 +2039,40 @@ Tags are a low-level resource and should not be accessed directly by most users.
 	commands = append(commands, cmdutil.CreateAlias(getTag, "get tag"))
 
 	var fix bool
+	var deep bool
+	var deepParallelism int64
+	var deepRate string
 	fsck := &cobra.Command{
 		Use:   "{{alias}}",
 		Short: "Run a file system consistency check on pfs.",
-		Long:  "Run a file system consistency check on the pachyderm file system, ensuring the correct provenance relationships are satisfied.",
+		Long: `Run a file system consistency check on the pachyderm file system, ensuring the correct provenance relationships are satisfied.
+
+With --deep, fsck also reads every object and block referenced by every
+commit from object storage, verifying that it exists and has the expected
+content. Missing or corrupted objects are reported along with the repos,
+commits and files that reference them. This reads all of the data in pfs, so
+--deep-rate can be used to limit its impact on the cluster.`,
+		Example: `
+# Check provenance relationships
+$ {{alias}}
+
+# Also check the content of every object, reading at most 50MB per second
+$ {{alias}} --deep --deep-rate 50MB`,
 		Run: cmdutil.RunFixedArgs(0, func(args []string) error {
+			var bytesPerSecond int64
+			if deepRate != "" {
+				var err error
+				if bytesPerSecond, err = units.RAMInBytes(deepRate); err != nil {
+					return err
+				}
+			}
 			c, err := client.NewOnUserMachine("user")
 			if err != nil {
 				return err
 			}
 			defer c.Close()
 			errors := false
-			if err = c.Fsck(fix, func(resp *pfsclient.FsckResponse) error {
+			cb := func(resp *pfsclient.FsckResponse) error {
 				if resp.Error != "" {
 					errors = true
 					fmt.Printf("Error: %s\n", resp.Error)
@@ -1319,7 +2080,13 @@ Tags are a low-level resource and should not be accessed directly by most users.
 					fmt.Printf("Fix applied: %v", resp.Fix)
 				}
 				return nil
-			}); err != nil {
+			}
+			if deep {
+				err = c.FsckDeep(fix, deepParallelism, bytesPerSecond, cb)
+			} else {
+				err = c.Fsck(fix, cb)
+			}
+			if err != nil {
 				return err
 			}
 			if !errors {
@@ -1329,6 +2096,9 @@ Tags are a low-level resource and should not be accessed directly by most users.
 		}),
 	}
 	fsck.Flags().BoolVarP(&fix, "fix", "f", false, "Attempt to fix as many issues as possible.")
+	fsck.Flags().BoolVar(&deep, "deep", false, "Also verify that the content of every object in pfs is in object storage.")
+	fsck.Flags().Int64Var(&deepParallelism, "deep-parallelism", 0, "With --deep, the number of objects to check at once (0 means the server's default).")
+	fsck.Flags().StringVar(&deepRate, "deep-rate", "", "With --deep, the maximum amount of data to read from object storage per second, e.g. 50MB (unlimited if unset).")
 	commands = append(commands, cmdutil.CreateAlias(fsck, "fsck"))
 
 	// Add the mount commands (which aren't available on Windows, so they're in
@@ -1341,7 +2111,8 @@ Tags are a low-level resource and should not be accessed directly by most users.
 func putFileHelper(c *client.APIClient, pfc client.PutFileClient,
 	repo, commit, path, source string, recursive, overwrite bool, // destination
 	limiter limit.ConcurrencyLimiter,
-	split string, targetFileDatums, targetFileBytes, headerRecords uint, // split
+	split, splitRegex string, targetFileDatums, targetFileBytes, headerRecords uint, // split
+	metadata map[string]string,
 	filesPut *gosync.Map) (retErr error) {
 	// Resolve the path, then trim any prefixed '../' to avoid sending bad paths
 	// to the server
@@ -1356,6 +2127,10 @@ func putFileHelper(c *client.APIClient, pfc client.PutFileClient,
 			"'delete file' or 'delete commit'", path)
 	}
 	putFile := func(reader io.ReadSeeker) error {
+		if split == "" && metadata != nil {
+			_, err := pfc.PutFileMetadata(repo, commit, path, reader, overwrite, metadata)
+			return err
+		}
 		if split == "" {
 			pipe, err := isPipe(reader)
 			if err != nil {
@@ -1382,9 +2157,21 @@ func putFileHelper(c *client.APIClient, pfc client.PutFileClient,
 			delimiter = pfsclient.Delimiter_SQL
 		case "csv":
 			delimiter = pfsclient.Delimiter_CSV
+		case "parquet":
+			delimiter = pfsclient.Delimiter_PARQUET
+		case "avro":
+			delimiter = pfsclient.Delimiter_AVRO
+		case "tsv":
+			delimiter = pfsclient.Delimiter_TSV
+		case "regex":
+			delimiter = pfsclient.Delimiter_REGEX
 		default:
 			return errors.Errorf("unrecognized delimiter '%s'; only accepts one of "+
-				"{json,line,sql,csv}", split)
+				"{json,line,sql,csv,tsv,parquet,avro}", split)
+		}
+		if metadata != nil || delimiter == pfsclient.Delimiter_REGEX {
+			_, err := pfc.PutFileSplitMetadata(repo, commit, path, delimiter, splitRegex, int64(targetFileDatums), int64(targetFileBytes), int64(headerRecords), overwrite, metadata, reader)
+			return err
 		}
 		_, err := pfc.PutFileSplit(repo, commit, path, delimiter, int64(targetFileDatums), int64(targetFileBytes), int64(headerRecords), overwrite, reader)
 		return err
@@ -1402,6 +2189,9 @@ func putFileHelper(c *client.APIClient, pfc client.PutFileClient,
 	}
 	// try parsing the filename as a url, if it is one do a PutFileURL
 	if url, err := url.Parse(source); err == nil && url.Scheme != "" {
+		if metadata != nil {
+			return errors.Errorf("--metadata cannot be used when putting a URL")
+		}
 		limiter.Acquire()
 		defer limiter.Release()
 		return pfc.PutFileURL(repo, commit, path, url.String(), recursive, overwrite)
@@ -1417,13 +2207,27 @@ func putFileHelper(c *client.APIClient, pfc client.PutFileClient,
 				return nil
 			}
 			childDest := filepath.Join(path, strings.TrimPrefix(filePath, source))
+			if info.Mode()&os.ModeSymlink != 0 {
+				target, ok, err := pfsSymlinkTarget(source, path, filePath)
+				if err != nil {
+					return err
+				}
+				if ok {
+					eg.Go(func() error {
+						limiter.Acquire()
+						defer limiter.Release()
+						return pfc.PutSymlink(repo, commit, childDest, target)
+					})
+					return nil
+				}
+			}
 			eg.Go(func() error {
 				// don't do a second recursive 'put file', just put the one file at
 				// filePath into childDest, and then this walk loop will go on to the
 				// next one
 				return putFileHelper(c, pfc, repo, commit, childDest, filePath, false,
-					overwrite, limiter, split, targetFileDatums, targetFileBytes,
-					headerRecords, filesPut)
+					overwrite, limiter, split, splitRegex, targetFileDatums, targetFileBytes,
+					headerRecords, metadata, filesPut)
 			})
 			return nil
 		}); err != nil {
@@ -1445,6 +2249,148 @@ func putFileHelper(c *client.APIClient, pfc client.PutFileClient,
 	return putFile(f)
 }
 
+// putFileUnpack puts the files in the archive 'source' (a local file or "-"
+// for stdin) under 'path'. The archive is a zip archive if 'zip' is set, and
+// otherwise a tar archive, which may be gzipped.
+func putFileUnpack(pfc client.PutFileClient, repo, commit, path, source string, zip, overwrite bool) (retErr error) {
+	var f *progress.File
+	if source == "-" {
+		f = progress.Stdin()
+		defer f.Finish()
+	} else {
+		if url, err := url.Parse(source); err == nil && url.Scheme != "" {
+			return errors.Errorf("--untar and --unzip cannot be used with a URL")
+		}
+		var err error
+		f, err = progress.Open(source)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if err := f.Close(); err != nil && retErr == nil {
+				retErr = err
+			}
+		}()
+	}
+	r := bufio.NewReader(f)
+	format := pfsclient.ArchiveFormat_ZIP
+	if !zip {
+		format = pfsclient.ArchiveFormat_TAR
+		if magic, err := r.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+			format = pfsclient.ArchiveFormat_TAR_GZ
+		}
+	}
+	_, err := pfc.PutFileUnpack(repo, commit, path, format, overwrite, r)
+	return err
+}
+
+// putFileResumable puts the local file 'source' at 'path' using a resumable
+// upload. Its progress is saved in a state file in config.UploadStateDir(),
+// named after the destination and the source's absolute path, so that the
+// same command resumes it.
+func putFileResumable(c *client.APIClient, repo, commit, path, source string, overwrite bool) (retErr error) {
+	if source == "-" {
+		return errors.Errorf("--resume cannot be used when reading data from stdin")
+	}
+	if url, err := url.Parse(source); err == nil && url.Scheme != "" {
+		return errors.Errorf("--resume cannot be used with a URL")
+	}
+	absSource, err := filepath.Abs(source)
+	if err != nil {
+		return err
+	}
+	hash := sha256.Sum256([]byte(strings.Join([]string{repo, commit, path, absSource}, "\x00")))
+	statePath := filepath.Join(config.UploadStateDir(), hex.EncodeToString(hash[:])+".json")
+	f, err := progress.Open(source)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := f.Close(); err != nil && retErr == nil {
+			retErr = err
+		}
+	}()
+	return c.PutFileResumable(repo, commit, path, f, overwrite, statePath)
+}
+
+// pfsSymlinkTarget returns the PFS target for the local symlink at
+// 'linkPath', which is being put recursively from the local directory 'source'
+// to 'dest' in PFS. Relative targets are preserved, and absolute targets
+// under 'source' are converted to the corresponding absolute PFS paths. It
+// returns false if the link points elsewhere, in which case it should be
+// followed and its content put instead.
+func pfsSymlinkTarget(source, dest, linkPath string) (string, bool, error) {
+	target, err := os.Readlink(linkPath)
+	if err != nil {
+		return "", false, err
+	}
+	if !filepath.IsAbs(target) {
+		return filepath.ToSlash(target), true, nil
+	}
+	absSource, err := filepath.Abs(source)
+	if err != nil {
+		return "", false, err
+	}
+	rel, err := filepath.Rel(absSource, target)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return "", false, nil
+	}
+	return filepath.ToSlash(filepath.Join("/", dest, rel)), true, nil
+}
+
+// retentionPolicy returns the retention policy set by the --keep-commits and
+// --keep-duration flags, or nil if neither was set.
+func retentionPolicy(keepCommits int64, keepDuration string) (*pfsclient.RetentionPolicy, error) {
+	if keepCommits == 0 && keepDuration == "" {
+		return nil, nil
+	}
+	retention := &pfsclient.RetentionPolicy{KeepCommits: keepCommits}
+	if keepDuration != "" {
+		d, err := time.ParseDuration(keepDuration)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid --keep-duration")
+		}
+		retention.KeepDuration = types.DurationProto(d)
+	}
+	return retention, nil
+}
+
+func repoQuota(maxSize string, maxCommits int64) (*pfsclient.RepoQuota, error) {
+	if maxSize == "" && maxCommits == 0 {
+		return nil, nil
+	}
+	quota := &pfsclient.RepoQuota{Commits: maxCommits}
+	if maxSize != "" {
+		sizeBytes, err := units.RAMInBytes(maxSize)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid --max-size")
+		}
+		if sizeBytes < 0 {
+			return nil, errors.Errorf("invalid --max-size %q, must not be negative", maxSize)
+		}
+		quota.SizeBytes = uint64(sizeBytes)
+	}
+	return quota, nil
+}
+
+func immutabilityPolicy(appendOnly bool, legalHoldUntil string) (*pfsclient.ImmutabilityPolicy, error) {
+	if !appendOnly && legalHoldUntil == "" {
+		return nil, nil
+	}
+	policy := &pfsclient.ImmutabilityPolicy{AppendOnly: appendOnly}
+	if legalHoldUntil != "" {
+		t, err := time.Parse(time.RFC3339, legalHoldUntil)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid --legal-hold-until")
+		}
+		policy.LegalHoldUntil, err = types.TimestampProto(t)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return policy, nil
+}
+
 func joinPaths(prefix, filePath string) string {
 	if url, err := url.Parse(filePath); err == nil && url.Scheme != "" {
 		if url.Scheme == "pfs" {
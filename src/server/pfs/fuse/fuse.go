@@ -83,6 +83,11 @@ func Mount(c *client.APIClient, target string, opts *Options) (retErr error) {
 			return err
 		}
 		if err := func() (retErr error) {
+			if target, ok, err := root.symlinkTarget(path); err != nil {
+				return err
+			} else if ok {
+				return pfc.PutSymlink(parts[0], root.branch(parts[0]), pathpkg.Join(parts[1:]...), target)
+			}
 			f, err := progress.Open(filepath.Join(root.rootPath, path))
 			if err != nil {
 				if os.IsNotExist(err) {
@@ -21,6 +21,7 @@ import (
 	"github.com/pachyderm/pachyderm/src/client/pkg/errors"
 	pfsserver "github.com/pachyderm/pachyderm/src/server/pfs"
 	"github.com/pachyderm/pachyderm/src/server/pkg/errutil"
+	pfssync "github.com/pachyderm/pachyderm/src/server/pkg/sync"
 )
 
 type fileState int32
@@ -591,6 +592,9 @@ func (n *loopbackNode) download(path string, state fileState) (retErr error) {
 			if fi.FileType == pfs.FileType_DIR {
 				return os.MkdirAll(n.filePath(fi), 0777)
 			}
+			if fi.FileType == pfs.FileType_SYMLINK {
+				return n.makeSymlink(fi)
+			}
 			p := n.filePath(fi)
 			// Make sure the directory exists
 			// I think this may be unnecessary based on the constraints the
@@ -622,6 +626,52 @@ func (n *loopbackNode) download(path string, state fileState) (retErr error) {
 	return nil
 }
 
+// makeSymlink materializes the PFS symlink described by 'fi' as a real
+// symlink in the loopback filesystem.
+func (n *loopbackNode) makeSymlink(fi *pfs.FileInfo) error {
+	p := n.filePath(fi)
+	target, err := pfssync.LocalSymlinkTarget(fi)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0777); err != nil {
+		return errors.WithStack(err)
+	}
+	if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(os.Symlink(target, p))
+}
+
+// symlinkTarget returns the PFS target of the file at 'path' (relative to
+// the root of the loopback filesystem) if it's a symlink. Links to absolute
+// paths in the same repo (which is what Symlink creates) are converted to
+// absolute PFS targets, which are relative to the root of the commit.
+func (r *loopbackRoot) symlinkTarget(path string) (string, bool, error) {
+	p := filepath.Join(r.rootPath, path)
+	fi, err := os.Lstat(p)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", false, nil
+		}
+		return "", false, errors.WithStack(err)
+	}
+	if fi.Mode()&os.ModeSymlink == 0 {
+		return "", false, nil
+	}
+	target, err := os.Readlink(p)
+	if err != nil {
+		return "", false, errors.WithStack(err)
+	}
+	if filepath.IsAbs(target) {
+		repoPath := filepath.Join(r.rootPath, strings.Split(path, "/")[0])
+		if rel, err := filepath.Rel(repoPath, target); err == nil && !strings.HasPrefix(rel, "..") {
+			return "/" + filepath.ToSlash(rel), true, nil
+		}
+	}
+	return filepath.ToSlash(target), true, nil
+}
+
 func (n *loopbackNode) trimPath(path string) string {
 	path = strings.TrimPrefix(path, n.root().rootPath)
 	return strings.TrimPrefix(path, "/")
@@ -3,6 +3,7 @@ package pfs
 import (
 	"fmt"
 	"regexp"
+	"strings"
 
 	"github.com/pachyderm/pachyderm/src/client/pfs"
 	"github.com/pachyderm/pachyderm/src/client/pkg/grpcutil"
@@ -62,6 +63,39 @@ type ErrOutputCommitNotFinished struct {
 	Commit *pfs.Commit
 }
 
+// ErrMergeConflict represents an error where a merge couldn't be completed
+// because some paths were changed on both branches. When a single commit is
+// reverted or cherry-picked onto To, Commit and Op are set instead of From.
+type ErrMergeConflict struct {
+	From   *pfs.Branch
+	To     *pfs.Branch
+	Paths  []string
+	Commit *pfs.Commit
+	Op     string
+}
+
+// ErrQuotaExceeded represents an error where a write would exceed a repo's
+// quota. Resource is the quota that would be exceeded ("size" or "commit").
+type ErrQuotaExceeded struct {
+	Repo     *pfs.Repo
+	Resource string
+	Usage    uint64
+	Limit    uint64
+}
+
+// ErrAppendOnlyRepo represents an error where an operation would overwrite or
+// delete data in an append-only repo. Op describes the operation.
+type ErrAppendOnlyRepo struct {
+	Repo *pfs.Repo
+	Op   string
+}
+
+// ErrUploadNotFound represents an upload session that doesn't exist, e.g.
+// because it was completed or deleted.
+type ErrUploadNotFound struct {
+	ID string
+}
+
 func (e ErrFileNotFound) Error() string {
 	return fmt.Sprintf("file %v not found in repo %v at commit %v", e.File.Path, e.File.Commit.Repo.Name, e.File.Commit.ID)
 }
@@ -103,6 +137,25 @@ func (e ErrOutputCommitNotFinished) Error() string {
 	return fmt.Sprintf("output commit %v not finished", e.Commit.ID)
 }
 
+func (e ErrMergeConflict) Error() string {
+	if e.Commit != nil {
+		return fmt.Sprintf("cannot %v commit %v onto branch %v in repo %v, conflicting paths: %v", e.Op, e.Commit.ID, e.To.Name, e.To.Repo.Name, strings.Join(e.Paths, ", "))
+	}
+	return fmt.Sprintf("cannot merge branch %v into %v in repo %v, conflicting paths: %v", e.From.Name, e.To.Name, e.To.Repo.Name, strings.Join(e.Paths, ", "))
+}
+
+func (e ErrQuotaExceeded) Error() string {
+	return fmt.Sprintf("repo %v would exceed its %v quota (%v > %v)", e.Repo.Name, e.Resource, e.Usage, e.Limit)
+}
+
+func (e ErrAppendOnlyRepo) Error() string {
+	return fmt.Sprintf("cannot %v in repo %v, as it's append-only", e.Op, e.Repo.Name)
+}
+
+func (e ErrUploadNotFound) Error() string {
+	return fmt.Sprintf("upload %v not found", e.ID)
+}
+
 // ByteRangeSize returns byteRange.Upper - byteRange.Lower.
 func ByteRangeSize(byteRange *pfs.ByteRange) uint64 {
 	return byteRange.Upper - byteRange.Lower
@@ -117,6 +170,10 @@ var (
 	fileNotFoundRe            = regexp.MustCompile(`file .+ not found`)
 	hasNoHeadRe               = regexp.MustCompile(`the branch .+ has no head \(create one with 'start commit'\)`)
 	outputCommitNotFinishedRe = regexp.MustCompile("output commit .+ not finished")
+	mergeConflictRe           = regexp.MustCompile("cannot (merge branch [^ ]+ into|[^ ]+ commit [^ ]+ onto branch) [^ ]+ in repo [^ ]+, conflicting paths")
+	quotaExceededRe           = regexp.MustCompile("repo [^ ]+ would exceed its [^ ]+ quota")
+	appendOnlyRepoRe          = regexp.MustCompile("cannot .+ in repo [^ ]+, as it's append-only")
+	uploadNotFoundRe          = regexp.MustCompile("upload [^ ]+ not found")
 )
 
 // IsCommitNotFoundErr returns true if 'err' has an error message that matches
@@ -190,3 +247,39 @@ func IsOutputCommitNotFinishedErr(err error) bool {
 	}
 	return outputCommitNotFinishedRe.MatchString(err.Error())
 }
+
+// IsMergeConflictErr returns true if the err is due to a merge that failed
+// because of conflicting paths
+func IsMergeConflictErr(err error) bool {
+	if err == nil {
+		return false
+	}
+	return mergeConflictRe.MatchString(grpcutil.ScrubGRPC(err).Error())
+}
+
+// IsQuotaExceededErr returns true if the err is due to a write that would
+// exceed a repo's quota
+func IsQuotaExceededErr(err error) bool {
+	if err == nil {
+		return false
+	}
+	return quotaExceededRe.MatchString(grpcutil.ScrubGRPC(err).Error())
+}
+
+// IsAppendOnlyRepoErr returns true if the err is due to an operation that
+// would overwrite or delete data in an append-only repo
+func IsAppendOnlyRepoErr(err error) bool {
+	if err == nil {
+		return false
+	}
+	return appendOnlyRepoRe.MatchString(grpcutil.ScrubGRPC(err).Error())
+}
+
+// IsUploadNotFoundErr returns true if the err is due to an upload session
+// that doesn't exist
+func IsUploadNotFoundErr(err error) bool {
+	if err == nil {
+		return false
+	}
+	return uploadNotFoundRe.MatchString(grpcutil.ScrubGRPC(err).Error())
+}
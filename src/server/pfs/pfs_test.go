@@ -20,4 +20,13 @@ func TestErrorMatching(t *testing.T) {
 	require.False(t, IsCommitFinishedErr(ErrCommitNotFound{c}))
 	require.False(t, IsCommitFinishedErr(ErrCommitDeleted{c}))
 	require.True(t, IsCommitFinishedErr(ErrCommitFinished{c}))
+
+	require.True(t, IsQuotaExceededErr(ErrQuotaExceeded{Repo: c.Repo, Resource: "size", Usage: 11, Limit: 10}))
+	require.False(t, IsQuotaExceededErr(ErrCommitFinished{c}))
+
+	require.True(t, IsAppendOnlyRepoErr(ErrAppendOnlyRepo{Repo: c.Repo, Op: "delete file"}))
+	require.False(t, IsAppendOnlyRepoErr(ErrCommitFinished{c}))
+
+	require.True(t, IsUploadNotFoundErr(ErrUploadNotFound{ID: "abc123"}))
+	require.False(t, IsUploadNotFoundErr(ErrCommitFinished{c}))
 }
@@ -5,9 +5,12 @@ import (
 	"html/template"
 	"io"
 	"os"
+	"strings"
+	"time"
 
 	units "github.com/docker/go-units"
 	"github.com/fatih/color"
+	"github.com/gogo/protobuf/types"
 	"github.com/pachyderm/pachyderm/src/client/pfs"
 	"github.com/pachyderm/pachyderm/src/server/pkg/pretty"
 )
@@ -21,12 +24,17 @@ const (
 	CommitHeader = "REPO\tBRANCH\tCOMMIT\tFINISHED\tSIZE\tPROGRESS\tDESCRIPTION\n"
 	// BranchHeader is the header for branches.
 	BranchHeader = "BRANCH\tHEAD\t\n"
+	// CommitTagHeader is the header for commit tags.
+	CommitTagHeader = "TAG\tCOMMIT\tCREATED\t\n"
 	// FileHeader is the header for files.
 	FileHeader = "NAME\tTYPE\tSIZE\t\n"
 	// FileHeaderWithCommit is the header for files that includes a commit field.
 	FileHeaderWithCommit = "COMMIT\tNAME\tTYPE\tCOMMITTED\tSIZE\t\n"
 	// DiffFileHeader is the header for files produced by diff file.
 	DiffFileHeader = "OP\t" + FileHeader
+	// FileChangeHeader is the header for file changes produced by subscribe
+	// file.
+	FileChangeHeader = "COMMIT\tOP\t" + FileHeader
 )
 
 // PrintRepoInfo pretty-prints repo info.
@@ -66,7 +74,11 @@ func PrintDetailedRepoInfo(repoInfo *PrintableRepoInfo) error {
 Description: {{.Description}}{{end}}{{if .FullTimestamps}}
 Created: {{.Created}}{{else}}
 Created: {{prettyAgo .Created}}{{end}}
-Size of HEAD on master: {{prettySize .SizeBytes}}{{if .AuthInfo}}
+Size of HEAD on master: {{prettySize .SizeBytes}}{{if .Quota}}{{if .Quota.SizeBytes}} (quota: {{prettySize .Quota.SizeBytes}}){{end}}{{end}}
+Commits: {{.Commits}}{{if .Quota}}{{if .Quota.Commits}} (quota: {{.Quota.Commits}}){{end}}{{end}}{{if .ForkOrigin}}
+Forked from: {{.ForkOrigin.Repo.Name}}@{{.ForkOrigin.ID}}{{end}}{{if .Retention}}
+Retention: {{retentionPolicy .Retention}}{{end}}{{if .Immutability}}{{if .Immutability.AppendOnly}}
+Immutability: {{immutabilityPolicy .Immutability}}{{end}}{{end}}{{if .AuthInfo}}
 Access level: {{ .AuthInfo.AccessLevel.String }}{{end}}
 `)
 	if err != nil {
@@ -107,6 +119,31 @@ Provenance: {{range .Provenance}} {{.Repo.Name}}@{{.Name}} {{end}} {{end}}
 	return nil
 }
 
+// PrintCommitTag pretty-prints a CommitTag.
+func PrintCommitTag(w io.Writer, tagInfo *pfs.CommitTagInfo, fullTimestamps bool) {
+	fmt.Fprintf(w, "%s\t", tagInfo.Tag.Name)
+	fmt.Fprintf(w, "%s\t", tagInfo.Commit.ID)
+	if fullTimestamps {
+		fmt.Fprintf(w, "%s\t", tagInfo.Created.String())
+	} else {
+		fmt.Fprintf(w, "%s\t", pretty.Ago(tagInfo.Created))
+	}
+	fmt.Fprintln(w)
+}
+
+// PrintDetailedCommitTagInfo pretty-prints detailed commit tag info.
+func PrintDetailedCommitTagInfo(tagInfo *pfs.CommitTagInfo) error {
+	template, err := template.New("CommitTagInfo").Funcs(funcMap).Parse(
+		`Name: {{.Tag.Repo.Name}}@{{.Tag.Name}}
+Commit: {{.Commit.Repo.Name}}@{{.Commit.ID}}
+Created: {{prettyAgo .Created}}
+`)
+	if err != nil {
+		return err
+	}
+	return template.Execute(os.Stdout, tagInfo)
+}
+
 // PrintCommitInfo pretty-prints commit info.
 func PrintCommitInfo(w io.Writer, commitInfo *pfs.CommitInfo, fullTimestamps bool) {
 	fmt.Fprintf(w, "%s\t", commitInfo.Commit.Repo.Name)
@@ -162,7 +199,8 @@ func PrintDetailedCommitInfo(commitInfo *PrintableCommitInfo) error {
 	template, err := template.New("CommitInfo").Funcs(funcMap).Parse(
 		`Commit: {{.Commit.Repo.Name}}@{{.Commit.ID}}{{if .Branch}}
 Original Branch: {{.Branch.Name}}{{end}}{{if .Description}}
-Description: {{.Description}}{{end}}{{if .ParentCommit}}
+Description: {{.Description}}{{end}}{{if .Annotations}}
+Annotations: {{range $k, $v := .Annotations}} {{$k}}={{$v}} {{end}}{{end}}{{if .ParentCommit}}
 Parent: {{.ParentCommit.ID}}{{end}}{{if .FullTimestamps}}
 Started: {{.Started}}{{else}}
 Started: {{prettyAgo .Started}}{{end}}{{if .Finished}}{{if .FullTimestamps}}
@@ -188,12 +226,12 @@ func PrintFileInfo(w io.Writer, fileInfo *pfs.FileInfo, fullTimestamps, withComm
 	if withCommit {
 		fmt.Fprintf(w, "%s\t", fileInfo.File.Commit.ID)
 	}
-	fmt.Fprintf(w, "%s\t", fileInfo.File.Path)
-	if fileInfo.FileType == pfs.FileType_FILE {
-		fmt.Fprint(w, "file\t")
+	if fileInfo.FileType == pfs.FileType_SYMLINK {
+		fmt.Fprintf(w, "%s -> %s\t", fileInfo.File.Path, fileInfo.SymlinkTarget)
 	} else {
-		fmt.Fprint(w, "dir\t")
+		fmt.Fprintf(w, "%s\t", fileInfo.File.Path)
 	}
+	fmt.Fprintf(w, "%s\t", fileType(fileInfo.FileType))
 	if withCommit {
 		if fileInfo.Committed == nil {
 			fmt.Fprintf(w, "-\t")
@@ -217,13 +255,66 @@ func PrintDiffFileInfo(w io.Writer, added bool, fileInfo *pfs.FileInfo, fullTime
 	PrintFileInfo(w, fileInfo, fullTimestamps, false)
 }
 
+// PrintFileChange pretty-prints a file change from subscribe file.
+func PrintFileChange(w io.Writer, change *pfs.FileChange, fullTimestamps bool) {
+	fmt.Fprintf(w, "%s\t", change.Commit.ID)
+	switch change.Type {
+	case pfs.FileChangeType_ADDED:
+		fmt.Fprint(w, color.GreenString("added\t"))
+	case pfs.FileChangeType_DELETED:
+		fmt.Fprint(w, color.RedString("deleted\t"))
+	default:
+		fmt.Fprint(w, color.YellowString("modified\t"))
+	}
+	PrintFileInfo(w, change.FileInfo, fullTimestamps, false)
+}
+
+// PrintFileContentDiff pretty-prints a diff of a file's content, coloring
+// added and removed lines.
+func PrintFileContentDiff(w io.Writer, contentDiff *pfs.FileContentDiff) {
+	oldPath, newPath := "/dev/null", "/dev/null"
+	if contentDiff.OldFile != nil {
+		oldPath = "a" + contentDiff.OldFile.Path
+	}
+	if contentDiff.NewFile != nil {
+		newPath = "b" + contentDiff.NewFile.Path
+	}
+	switch {
+	case contentDiff.Binary:
+		fmt.Fprintf(w, "Binary files %s and %s differ\n", oldPath, newPath)
+		return
+	case contentDiff.TooLarge:
+		fmt.Fprintf(w, "Files %s and %s are too large to diff\n", oldPath, newPath)
+		return
+	}
+	if contentDiff.UnifiedDiff == "" {
+		return
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(contentDiff.UnifiedDiff, "\n"), "\n") {
+		switch {
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+			fmt.Fprintln(w, color.New(color.Bold).Sprint(line))
+		case strings.HasPrefix(line, "+"):
+			fmt.Fprintln(w, color.New(color.FgGreen).Sprint(line))
+		case strings.HasPrefix(line, "-"):
+			fmt.Fprintln(w, color.New(color.FgRed).Sprint(line))
+		case strings.HasPrefix(line, "@@"):
+			fmt.Fprintln(w, color.New(color.FgCyan).Sprint(line))
+		default:
+			fmt.Fprintln(w, line)
+		}
+	}
+}
+
 // PrintDetailedFileInfo pretty-prints detailed file info.
 func PrintDetailedFileInfo(fileInfo *pfs.FileInfo) error {
 	template, err := template.New("FileInfo").Funcs(funcMap).Parse(
 		`Path: {{.File.Path}}
-Type: {{fileType .FileType}}
+Type: {{fileType .FileType}}{{if .SymlinkTarget}}
+Target: {{.SymlinkTarget}}{{end}}
 Size: {{prettySize .SizeBytes}}
-Children: {{range .Children}} {{.}} {{end}}
+Children: {{range .Children}} {{.}} {{end}}{{if .Metadata}}
+Metadata: {{range $k, $v := .Metadata}} {{$k}}={{$v}} {{end}}{{end}}
 `)
 	if err != nil {
 		return err
@@ -232,16 +323,55 @@ Children: {{range .Children}} {{.}} {{end}}
 }
 
 func fileType(fileType pfs.FileType) string {
-	if fileType == pfs.FileType_FILE {
+	switch fileType {
+	case pfs.FileType_FILE:
 		return "file"
+	case pfs.FileType_SYMLINK:
+		return "symlink"
+	default:
+		return "dir"
 	}
-	return "dir"
 }
 
 var funcMap = template.FuncMap{
-	"prettyAgo":  pretty.Ago,
-	"prettySize": pretty.Size,
-	"fileType":   fileType,
+	"prettyAgo":          pretty.Ago,
+	"prettySize":         pretty.Size,
+	"fileType":           fileType,
+	"retentionPolicy":    retentionPolicy,
+	"immutabilityPolicy": immutabilityPolicy,
+}
+
+// retentionPolicy renders 'r' as a human-readable string, e.g.
+// "last 10 commits per branch, or finished within 168h0m0s"
+func retentionPolicy(r *pfs.RetentionPolicy) string {
+	var policies []string
+	if r.KeepCommits > 0 {
+		policies = append(policies, fmt.Sprintf("last %d commits per branch", r.KeepCommits))
+	}
+	if r.KeepDuration != nil {
+		d, err := types.DurationFromProto(r.KeepDuration)
+		if err != nil {
+			return r.String()
+		}
+		policies = append(policies, fmt.Sprintf("finished within %v", d))
+	}
+	if len(policies) == 0 {
+		return "all commits"
+	}
+	return strings.Join(policies, ", or ")
+}
+
+// immutabilityPolicy renders 'p' as a human-readable string, e.g.
+// "append-only, legal hold until 2030-01-02T15:04:05Z"
+func immutabilityPolicy(p *pfs.ImmutabilityPolicy) string {
+	if p.LegalHoldUntil == nil {
+		return "append-only"
+	}
+	until, err := types.TimestampFromProto(p.LegalHoldUntil)
+	if err != nil {
+		return p.String()
+	}
+	return fmt.Sprintf("append-only, legal hold until %s", until.Format(time.RFC3339))
 }
 
 // CompactPrintBranch renders 'b' as a compact string, e.g.
@@ -130,6 +130,25 @@ func masterPutObject(t *testing.T, pachClient *client.APIClient, minioClient *mi
 	require.Equal(t, "content2", fetchedContent)
 }
 
+func masterObjectMetadata(t *testing.T, pachClient *client.APIClient, minioClient *minio.Client) {
+	repo := tu.UniqueString("testobjectmetadata")
+	require.NoError(t, pachClient.CreateRepo(repo))
+
+	r := strings.NewReader("content")
+	_, err := minioClient.PutObject(fmt.Sprintf("master.%s", repo), "file", r, int64(r.Len()), minio.PutObjectOptions{
+		UserMetadata: map[string]string{"checksum": "abc123"},
+	})
+	require.NoError(t, err)
+
+	fileInfo, err := pachClient.InspectFile(repo, "master", "file")
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"checksum": "abc123"}, fileInfo.Metadata)
+
+	info, err := minioClient.StatObject(fmt.Sprintf("master.%s", repo), "file", minio.StatObjectOptions{})
+	require.NoError(t, err)
+	require.Equal(t, "abc123", info.Metadata.Get("X-Amz-Meta-Checksum"))
+}
+
 func masterRemoveObject(t *testing.T, pachClient *client.APIClient, minioClient *minio.Client) {
 	repo := tu.UniqueString("testremoveobject")
 	require.NoError(t, pachClient.CreateRepo(repo))
@@ -448,6 +467,9 @@ func TestMasterDriver(t *testing.T) {
 		t.Run("PutObject", func(t *testing.T) {
 			masterPutObject(t, pachClient, minioClient)
 		})
+		t.Run("ObjectMetadata", func(t *testing.T) {
+			masterObjectMetadata(t, pachClient, minioClient)
+		})
 		t.Run("RemoveObject", func(t *testing.T) {
 			masterRemoveObject(t, pachClient, minioClient)
 		})
@@ -62,6 +62,8 @@ func (c *controller) GetObject(r *http.Request, bucketName, file, version string
 		return nil, err
 	}
 
+	setResponseMetadata(r, fileInfo.Metadata)
+
 	result := s2.GetObjectResult{
 		ModTime:      modTime,
 		Content:      content,
@@ -151,7 +153,7 @@ func (c *controller) PutObject(r *http.Request, bucketName, file string, reader
 		return nil, s2.NotImplementedError(r)
 	}
 
-	_, err = pc.PutFileOverwrite(bucket.Repo, bucket.Commit, file, reader, 0)
+	_, err = pc.PutFileMetadata(bucket.Repo, bucket.Commit, file, reader, true, requestMetadata(r))
 	if err != nil {
 		if errutil.IsWriteToOutputBranchError(err) {
 			return nil, writeToOutputBranchError(r)
@@ -218,3 +220,32 @@ func (c *controller) DeleteObject(r *http.Request, bucketName, file, version str
 
 	return &result, nil
 }
+
+// requestMetadata returns the user-defined object metadata set in the
+// x-amz-meta-* headers of 'r', keyed by the header names without the prefix
+func requestMetadata(r *http.Request) map[string]string {
+	var metadata map[string]string
+	for name, values := range r.Header {
+		name = strings.ToLower(name)
+		if !strings.HasPrefix(name, metadataHeaderPrefix) || len(values) == 0 {
+			continue
+		}
+		if metadata == nil {
+			metadata = make(map[string]string)
+		}
+		metadata[strings.TrimPrefix(name, metadataHeaderPrefix)] = strings.Join(values, ",")
+	}
+	return metadata
+}
+
+// setResponseMetadata sets an x-amz-meta-* response header for each key in
+// 'metadata'
+func setResponseMetadata(r *http.Request, metadata map[string]string) {
+	header, ok := r.Context().Value(responseHeaderKey{}).(http.Header)
+	if !ok {
+		return
+	}
+	for k, v := range metadata {
+		header.Set(metadataHeaderPrefix+k, v)
+	}
+}
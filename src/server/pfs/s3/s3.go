@@ -1,6 +1,7 @@
 package s3
 
 import (
+	"context"
 	"fmt"
 	stdlog "log"
 	"net/http"
@@ -29,8 +30,16 @@ const (
 
 	// The S3 location served back
 	globalLocation = "PACHYDERM"
+
+	// The prefix of headers holding user-defined object metadata
+	metadataHeaderPrefix = "x-amz-meta-"
 )
 
+// responseHeaderKey is the context key under which each request's response
+// headers are stored, so that controller methods can set headers that s2
+// doesn't know about (e.g. x-amz-meta-*)
+type responseHeaderKey struct{}
+
 // The S3 user associated with all PFS content
 var defaultUser = s2.User{ID: "00000000000000000000000000000000", DisplayName: "pachyderm"}
 
@@ -118,7 +127,8 @@ func Server(port uint16, driver Driver, clientFactory ClientFactory) (*http.Serv
 		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			// Log that a request was made
 			logger.Infof("http request: %s %s", r.Method, r.RequestURI)
-			router.ServeHTTP(w, r)
+			ctx := context.WithValue(r.Context(), responseHeaderKey{}, w.Header())
+			router.ServeHTTP(w, r.WithContext(ctx))
 		}),
 		// NOTE: this is not closed. If the standard logger gets customized, this will need to be fixed
 		ErrorLog: stdlog.New(logger.Writer(), "", 0),
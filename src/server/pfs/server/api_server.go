@@ -1,6 +1,7 @@
 package server
 
 import (
+	"bufio"
 	"fmt"
 	"io"
 	"time"
@@ -55,6 +56,7 @@ func newAPIServer(
 		txnEnv: txnEnv,
 	}
 	go func() { s.env.GetPachClient(context.Background()) }() // Begin dialing connection on startup
+	go d.reaper()
 	return s, nil
 }
 
@@ -64,7 +66,7 @@ func (a *apiServer) CreateRepoInTransaction(
 	txnCtx *txnenv.TransactionContext,
 	request *pfs.CreateRepoRequest,
 ) error {
-	return a.driver.createRepo(txnCtx, request.Repo, request.Description, request.Update)
+	return a.driver.createRepo(txnCtx, request.Repo, request.Description, request.Update, request.Retention, request.Quota, request.Immutability)
 }
 
 // CreateRepo implements the protobuf pfs.CreateRepo RPC
@@ -87,7 +89,17 @@ func (a *apiServer) InspectRepoInTransaction(
 	originalRequest *pfs.InspectRepoRequest,
 ) (*pfs.RepoInfo, error) {
 	request := proto.Clone(originalRequest).(*pfs.InspectRepoRequest)
-	return a.driver.inspectRepo(txnCtx, request.Repo, true)
+	repoInfo, err := a.driver.inspectRepo(txnCtx, request.Repo, true)
+	if err != nil {
+		return nil, err
+	}
+	// Report the repo's commit count, so that users can see their usage
+	// against its quota
+	repoInfo.Commits, err = a.driver.commits(request.Repo.Name).ReadOnly(txnCtx.ClientContext).Count()
+	if err != nil {
+		return nil, err
+	}
+	return repoInfo, nil
 }
 
 // InspectRepo implements the protobuf pfs.InspectRepo RPC
@@ -141,6 +153,32 @@ func (a *apiServer) DeleteRepo(ctx context.Context, request *pfs.DeleteRepoReque
 	return &types.Empty{}, nil
 }
 
+// RenameRepo implements the protobuf pfs.RenameRepo RPC
+func (a *apiServer) RenameRepo(ctx context.Context, request *pfs.RenameRepoRequest) (response *types.Empty, retErr error) {
+	func() { a.Log(request, nil, nil, 0) }()
+	defer func(start time.Time) { a.Log(request, response, retErr, time.Since(start)) }(time.Now())
+
+	if err := a.txnEnv.WithWriteContext(ctx, func(txnCtx *txnenv.TransactionContext) error {
+		return a.driver.renameRepo(txnCtx, request.Repo, request.NewName)
+	}); err != nil {
+		return nil, err
+	}
+	return &types.Empty{}, nil
+}
+
+// ForkRepo implements the protobuf pfs.ForkRepo RPC
+func (a *apiServer) ForkRepo(ctx context.Context, request *pfs.ForkRepoRequest) (response *types.Empty, retErr error) {
+	func() { a.Log(request, nil, nil, 0) }()
+	defer func(start time.Time) { a.Log(request, response, retErr, time.Since(start)) }(time.Now())
+
+	if err := a.txnEnv.WithWriteContext(ctx, func(txnCtx *txnenv.TransactionContext) error {
+		return a.driver.forkRepo(txnCtx, request.Repo, request.NewRepo, request.Branch, request.Description)
+	}); err != nil {
+		return nil, err
+	}
+	return &types.Empty{}, nil
+}
+
 // Fsckimplements the protobuf pfs.Fsck RPC
 func (a *apiServer) Fsck(request *pfs.FsckRequest, fsckServer pfs.API_FsckServer) (retErr error) {
 	func() { a.Log(request, nil, nil, 0) }()
@@ -148,7 +186,7 @@ func (a *apiServer) Fsck(request *pfs.FsckRequest, fsckServer pfs.API_FsckServer
 	defer func(start time.Time) {
 		a.Log(request, fmt.Sprintf("stream containing %d messages", sent), retErr, time.Since(start))
 	}(time.Now())
-	if err := a.driver.fsck(a.env.GetPachClient(fsckServer.Context()), request.Fix, func(resp *pfs.FsckResponse) error {
+	if err := a.driver.fsck(a.env.GetPachClient(fsckServer.Context()), request.Fix, request.Deep, request.DeepParallelism, request.DeepBytesPerSecond, func(resp *pfs.FsckResponse) error {
 		sent++
 		return fsckServer.Send(resp)
 	}); err != nil {
@@ -171,7 +209,7 @@ func (a *apiServer) StartCommitInTransaction(
 	if commit != nil {
 		id = commit.ID
 	}
-	return a.driver.startCommit(txnCtx, id, request.Parent, request.Branch, request.Provenance, request.Description)
+	return a.driver.startCommit(txnCtx, id, request.Parent, request.Branch, request.Provenance, request.Description, request.Annotations)
 }
 
 // StartCommit implements the protobuf pfs.StartCommit RPC
@@ -212,13 +250,61 @@ func (a *apiServer) BuildCommit(ctx context.Context, request *pfs.BuildCommitReq
 		}
 	}
 
-	commit, err := a.driver.buildCommit(ctx, request.ID, request.Parent, request.Branch, request.Origin, request.Provenance, request.Tree, request.Trees, request.Datums, started, finished, request.SizeBytes)
+	commit, err := a.driver.buildCommit(ctx, request.ID, request.Parent, request.Branch, request.Origin, request.Provenance, request.Tree, request.Trees, request.Datums, started, finished, request.SizeBytes, request.Annotations)
 	if err != nil {
 		return nil, err
 	}
 	return commit, nil
 }
 
+// RevertCommit implements the protobuf pfs.RevertCommit RPC
+func (a *apiServer) RevertCommit(ctx context.Context, request *pfs.RevertCommitRequest) (response *pfs.Commit, retErr error) {
+	func() { a.Log(request, nil, nil, 0) }()
+	defer func(start time.Time) { a.Log(request, response, retErr, time.Since(start)) }(time.Now())
+
+	var commit *pfs.Commit
+	if err := a.txnEnv.WithWriteContext(ctx, func(txnCtx *txnenv.TransactionContext) error {
+		var err error
+		commit, err = a.driver.revertCommit(txnCtx, request.Commit, request.Branch, request.Description)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return commit, nil
+}
+
+// CherryPickCommit implements the protobuf pfs.CherryPickCommit RPC
+func (a *apiServer) CherryPickCommit(ctx context.Context, request *pfs.CherryPickCommitRequest) (response *pfs.Commit, retErr error) {
+	func() { a.Log(request, nil, nil, 0) }()
+	defer func(start time.Time) { a.Log(request, response, retErr, time.Since(start)) }(time.Now())
+
+	var commit *pfs.Commit
+	if err := a.txnEnv.WithWriteContext(ctx, func(txnCtx *txnenv.TransactionContext) error {
+		var err error
+		commit, err = a.driver.cherryPickCommit(txnCtx, request.Commit, request.Branch, request.Description)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return commit, nil
+}
+
+// SquashCommits implements the protobuf pfs.SquashCommits RPC
+func (a *apiServer) SquashCommits(ctx context.Context, request *pfs.SquashCommitsRequest) (response *pfs.Commit, retErr error) {
+	func() { a.Log(request, nil, nil, 0) }()
+	defer func(start time.Time) { a.Log(request, response, retErr, time.Since(start)) }(time.Now())
+
+	var commit *pfs.Commit
+	if err := a.txnEnv.WithWriteContext(ctx, func(txnCtx *txnenv.TransactionContext) error {
+		var err error
+		commit, err = a.driver.squashCommits(txnCtx, request.From, request.To, request.Description)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return commit, nil
+}
+
 // FinishCommitInTransaction is identical to FinishCommit except that it can run
 // inside an existing etcd STM transaction.  This is not an RPC.
 func (a *apiServer) FinishCommitInTransaction(
@@ -228,7 +314,7 @@ func (a *apiServer) FinishCommitInTransaction(
 	if request.Trees != nil {
 		return a.driver.finishOutputCommit(txnCtx, request.Commit, request.Trees, request.Datums, request.SizeBytes)
 	}
-	return a.driver.finishCommit(txnCtx, request.Commit, request.Tree, request.Empty, request.Description)
+	return a.driver.finishCommit(txnCtx, request.Commit, request.Tree, request.Empty, request.Description, request.Annotations)
 }
 
 // FinishCommit implements the protobuf pfs.FinishCommit RPC
@@ -257,7 +343,7 @@ func (a *apiServer) ListCommit(ctx context.Context, request *pfs.ListCommitReque
 	func() { a.Log(request, nil, nil, 0) }()
 	defer func(start time.Time) { a.Log(request, response, retErr, time.Since(start)) }(time.Now())
 
-	commitInfos, err := a.driver.listCommit(a.env.GetPachClient(ctx), request.Repo, request.To, request.From, request.Number, request.Reverse)
+	commitInfos, err := a.driver.listCommit(a.env.GetPachClient(ctx), request.Repo, request.To, request.From, request.Number, request.Reverse, request.Annotations)
 	if err != nil {
 		return nil, err
 	}
@@ -273,7 +359,7 @@ func (a *apiServer) ListCommitStream(request *pfs.ListCommitRequest, respServer
 	defer func(start time.Time) {
 		a.Log(request, fmt.Sprintf("stream containing %d commits", sent), retErr, time.Since(start))
 	}(time.Now())
-	return a.driver.listCommitF(a.env.GetPachClient(respServer.Context()), request.Repo, request.To, request.From, request.Number, request.Reverse, func(ci *pfs.CommitInfo) error {
+	return a.driver.listCommitF(a.env.GetPachClient(respServer.Context()), request.Repo, request.To, request.From, request.Number, request.Reverse, request.Annotations, func(ci *pfs.CommitInfo) error {
 		sent++
 		return respServer.Send(ci)
 	})
@@ -351,6 +437,89 @@ func (a *apiServer) DeleteBranch(ctx context.Context, request *pfs.DeleteBranchR
 	return &types.Empty{}, nil
 }
 
+// RenameBranch implements the protobuf pfs.RenameBranch RPC
+func (a *apiServer) RenameBranch(ctx context.Context, request *pfs.RenameBranchRequest) (response *types.Empty, retErr error) {
+	func() { a.Log(request, nil, nil, 0) }()
+	defer func(start time.Time) { a.Log(request, response, retErr, time.Since(start)) }(time.Now())
+
+	if err := a.txnEnv.WithWriteContext(ctx, func(txnCtx *txnenv.TransactionContext) error {
+		return a.driver.renameBranch(txnCtx, request.Branch, request.NewName)
+	}); err != nil {
+		return nil, err
+	}
+	return &types.Empty{}, nil
+}
+
+// MergeBranch implements the protobuf pfs.MergeBranch RPC
+func (a *apiServer) MergeBranch(ctx context.Context, request *pfs.MergeBranchRequest) (response *pfs.Commit, retErr error) {
+	func() { a.Log(request, nil, nil, 0) }()
+	defer func(start time.Time) { a.Log(request, response, retErr, time.Since(start)) }(time.Now())
+
+	var commit *pfs.Commit
+	if err := a.txnEnv.WithWriteContext(ctx, func(txnCtx *txnenv.TransactionContext) error {
+		var err error
+		commit, err = a.driver.mergeBranch(txnCtx, request.From, request.To, request.Strategy, request.Description)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return commit, nil
+}
+
+// CreateCommitTag implements the protobuf pfs.CreateCommitTag RPC
+func (a *apiServer) CreateCommitTag(ctx context.Context, request *pfs.CreateCommitTagRequest) (response *types.Empty, retErr error) {
+	func() { a.Log(request, nil, nil, 0) }()
+	defer func(start time.Time) { a.Log(request, response, retErr, time.Since(start)) }(time.Now())
+
+	if err := a.txnEnv.WithWriteContext(ctx, func(txnCtx *txnenv.TransactionContext) error {
+		return a.driver.createCommitTag(txnCtx, request.Tag, request.Commit)
+	}); err != nil {
+		return nil, err
+	}
+	return &types.Empty{}, nil
+}
+
+// InspectCommitTag implements the protobuf pfs.InspectCommitTag RPC
+func (a *apiServer) InspectCommitTag(ctx context.Context, request *pfs.InspectCommitTagRequest) (response *pfs.CommitTagInfo, retErr error) {
+	func() { a.Log(request, nil, nil, 0) }()
+	defer func(start time.Time) { a.Log(request, response, retErr, time.Since(start)) }(time.Now())
+
+	tagInfo := &pfs.CommitTagInfo{}
+	if err := a.txnEnv.WithReadContext(ctx, func(txnCtx *txnenv.TransactionContext) error {
+		var err error
+		tagInfo, err = a.driver.inspectCommitTag(txnCtx, request.Tag)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return tagInfo, nil
+}
+
+// ListCommitTag implements the protobuf pfs.ListCommitTag RPC
+func (a *apiServer) ListCommitTag(ctx context.Context, request *pfs.ListCommitTagRequest) (response *pfs.CommitTagInfos, retErr error) {
+	func() { a.Log(request, nil, nil, 0) }()
+	defer func(start time.Time) { a.Log(request, response, retErr, time.Since(start)) }(time.Now())
+
+	tags, err := a.driver.listCommitTag(a.env.GetPachClient(ctx), request.Repo)
+	if err != nil {
+		return nil, err
+	}
+	return &pfs.CommitTagInfos{CommitTagInfo: tags}, nil
+}
+
+// DeleteCommitTag implements the protobuf pfs.DeleteCommitTag RPC
+func (a *apiServer) DeleteCommitTag(ctx context.Context, request *pfs.DeleteCommitTagRequest) (response *types.Empty, retErr error) {
+	func() { a.Log(request, nil, nil, 0) }()
+	defer func(start time.Time) { a.Log(request, response, retErr, time.Since(start)) }(time.Now())
+
+	if err := a.txnEnv.WithWriteContext(ctx, func(txnCtx *txnenv.TransactionContext) error {
+		return a.driver.deleteCommitTag(txnCtx, request.Tag)
+	}); err != nil {
+		return nil, err
+	}
+	return &types.Empty{}, nil
+}
+
 // DeleteCommitInTransaction is identical to DeleteCommit except that it can run
 // inside an existing etcd STM transaction.  This is not an RPC.
 func (a *apiServer) DeleteCommitInTransaction(
@@ -389,6 +558,14 @@ func (a *apiServer) SubscribeCommit(request *pfs.SubscribeCommitRequest, stream
 	return a.driver.subscribeCommit(a.env.GetPachClient(stream.Context()), request.Repo, request.Branch, request.Prov, request.From, request.State, stream.Send)
 }
 
+// SubscribeFile implements the protobuf pfs.SubscribeFile RPC
+func (a *apiServer) SubscribeFile(request *pfs.SubscribeFileRequest, stream pfs.API_SubscribeFileServer) (retErr error) {
+	func() { a.Log(request, nil, nil, 0) }()
+	defer func(start time.Time) { a.Log(request, nil, retErr, time.Since(start)) }(time.Now())
+
+	return a.driver.subscribeFile(a.env.GetPachClient(stream.Context()), request.File, request.From, stream.Send)
+}
+
 // PutFile implements the protobuf pfs.PutFile RPC
 func (a *apiServer) PutFile(putFileServer pfs.API_PutFileServer) (retErr error) {
 	s := newPutFileServer(putFileServer)
@@ -423,6 +600,28 @@ func (a *apiServer) CopyFile(ctx context.Context, request *pfs.CopyFileRequest)
 	return &types.Empty{}, nil
 }
 
+// MoveFileInTransaction is identical to MoveFile except that it can run
+// inside an existing etcd STM transaction.  This is not an RPC.
+func (a *apiServer) MoveFileInTransaction(
+	txnCtx *txnenv.TransactionContext,
+	request *pfs.MoveFileRequest,
+) error {
+	return a.driver.moveFile(txnCtx, request.Src, request.Dst)
+}
+
+// MoveFile implements the protobuf pfs.MoveFile RPC
+func (a *apiServer) MoveFile(ctx context.Context, request *pfs.MoveFileRequest) (response *types.Empty, retErr error) {
+	func() { a.Log(request, nil, nil, 0) }()
+	defer func(start time.Time) { a.Log(request, response, retErr, time.Since(start)) }(time.Now())
+
+	if err := a.txnEnv.WithTransaction(ctx, func(txn txnenv.Transaction) error {
+		return txn.MoveFile(request)
+	}); err != nil {
+		return nil, err
+	}
+	return &types.Empty{}, nil
+}
+
 // GetFile implements the protobuf pfs.GetFile RPC
 func (a *apiServer) GetFile(request *pfs.GetFileRequest, apiGetFileServer pfs.API_GetFileServer) (retErr error) {
 	func() { a.Log(request, nil, nil, 0) }()
@@ -435,7 +634,20 @@ func (a *apiServer) GetFile(request *pfs.GetFileRequest, apiGetFileServer pfs.AP
 
 		a.Log(request, nil, retErr, time.Since(start))
 	}(time.Now())
-	file, err := a.driver.getFile(a.env.GetPachClient(apiGetFileServer.Context()), request.File, request.OffsetBytes, request.SizeBytes)
+	pachClient := a.env.GetPachClient(apiGetFileServer.Context())
+	if request.Archive != pfs.ArchiveFormat_RAW {
+		if request.OffsetBytes != 0 || request.SizeBytes != 0 {
+			return errors.New("offset_bytes and size_bytes cannot be used with archive")
+		}
+		// Buffer the archive so that its small writes (e.g. headers) aren't
+		// each sent as a separate message
+		w := bufio.NewWriterSize(grpcutil.NewStreamingBytesWriter(apiGetFileServer), grpcutil.MaxMsgPayloadSize)
+		if err := a.driver.getFileArchive(pachClient, request.File, request.Archive, w); err != nil {
+			return err
+		}
+		return w.Flush()
+	}
+	file, err := a.driver.getFile(pachClient, request.File, request.OffsetBytes, request.SizeBytes)
 	if err != nil {
 		return err
 	}
@@ -545,11 +757,30 @@ func (a *apiServer) GlobFileStream(request *pfs.GlobFileRequest, respServer pfs.
 	})
 }
 
+// GrepFile implements the protobuf pfs.GrepFile RPC
+func (a *apiServer) GrepFile(request *pfs.GrepFileRequest, respServer pfs.API_GrepFileServer) (retErr error) {
+	func() { a.Log(request, nil, nil, 0) }()
+	var sent int
+	defer func(start time.Time) {
+		a.Log(request, fmt.Sprintf("response stream with %d objects", sent), retErr, time.Since(start))
+	}(time.Now())
+	return a.driver.grepFile(a.env.GetPachClient(respServer.Context()), request.File, request.Pattern, func(resp *pfs.GrepFileResponse) error {
+		sent++
+		return respServer.Send(resp)
+	})
+}
+
 // DiffFile implements the protobuf pfs.DiffFile RPC
 func (a *apiServer) DiffFile(ctx context.Context, request *pfs.DiffFileRequest) (response *pfs.DiffFileResponse, retErr error) {
 	func() { a.Log(request, nil, nil, 0) }()
 	defer func(start time.Time) {
-		if response != nil && (len(response.NewFiles) > client.MaxListItemsLog || len(response.OldFiles) > client.MaxListItemsLog) {
+		if response != nil && len(response.ContentDiffs) > 0 {
+			// Don't log file contents
+			a.Log(request, &pfs.DiffFileResponse{
+				NewFiles: truncateFiles(response.NewFiles),
+				OldFiles: truncateFiles(response.OldFiles),
+			}, retErr, time.Since(start))
+		} else if response != nil && (len(response.NewFiles) > client.MaxListItemsLog || len(response.OldFiles) > client.MaxListItemsLog) {
 			logrus.Infof("Response contains too many objects; truncating.")
 			a.Log(request, &pfs.DiffFileResponse{
 				NewFiles: truncateFiles(response.NewFiles),
@@ -559,13 +790,26 @@ func (a *apiServer) DiffFile(ctx context.Context, request *pfs.DiffFileRequest)
 			a.Log(request, response, retErr, time.Since(start))
 		}
 	}(time.Now())
-	newFileInfos, oldFileInfos, err := a.driver.diffFile(a.env.GetPachClient(ctx), request.NewFile, request.OldFile, request.Shallow)
+	pachClient := a.env.GetPachClient(ctx)
+	newFileInfos, oldFileInfos, err := a.driver.diffFile(pachClient, request.NewFile, request.OldFile, request.Shallow)
 	if err != nil {
 		return nil, err
 	}
+	var contentDiffs []*pfs.FileContentDiff
+	if request.Content {
+		oldRoot := request.NewFile.Path
+		if request.OldFile != nil {
+			oldRoot = request.OldFile.Path
+		}
+		contentDiffs, err = a.driver.diffFileContent(pachClient, request.NewFile.Path, oldRoot, newFileInfos, oldFileInfos, request.ContentSizeLimit)
+		if err != nil {
+			return nil, err
+		}
+	}
 	return &pfs.DiffFileResponse{
-		NewFiles: newFileInfos,
-		OldFiles: oldFileInfos,
+		NewFiles:     newFileInfos,
+		OldFiles:     oldFileInfos,
+		ContentDiffs: contentDiffs,
 	}, nil
 }
 
@@ -581,6 +825,85 @@ func (a *apiServer) DeleteFile(ctx context.Context, request *pfs.DeleteFileReque
 	return &types.Empty{}, nil
 }
 
+// PurgeFile implements the protobuf pfs.PurgeFile RPC
+func (a *apiServer) PurgeFile(ctx context.Context, request *pfs.PurgeFileRequest) (response *pfs.PurgeFileResponse, retErr error) {
+	func() { a.Log(request, nil, nil, 0) }()
+	defer func(start time.Time) { a.Log(request, response, retErr, time.Since(start)) }(time.Now())
+
+	return a.driver.purgeFile(a.env.GetPachClient(ctx), request.Repo, request.Path, request.DeleteOutputs)
+}
+
+// StartUpload implements the protobuf pfs.StartUpload RPC
+func (a *apiServer) StartUpload(ctx context.Context, request *pfs.StartUploadRequest) (response *pfs.UploadInfo, retErr error) {
+	func() { a.Log(request, nil, nil, 0) }()
+	defer func(start time.Time) { a.Log(request, response, retErr, time.Since(start)) }(time.Now())
+
+	return a.driver.startUpload(a.env.GetPachClient(ctx), request.File, request.Overwrite)
+}
+
+// PutChunk implements the protobuf pfs.PutChunk RPC
+func (a *apiServer) PutChunk(putChunkServer pfs.API_PutChunkServer) (retErr error) {
+	defer drainPutChunkServer(putChunkServer)
+	request, err := putChunkServer.Recv()
+	if err != nil {
+		return err
+	}
+	r := &putChunkReader{server: putChunkServer}
+	r.buffer.Write(request.Value)
+	request.Value = nil
+	var response *pfs.UploadInfo
+	func() { a.Log(request, nil, nil, 0) }()
+	defer func(start time.Time) { a.Log(request, response, retErr, time.Since(start)) }(time.Now())
+
+	response, err = a.driver.putChunk(a.env.GetPachClient(putChunkServer.Context()), request.UploadID, request.Offset, r)
+	if err != nil {
+		return err
+	}
+	return putChunkServer.SendAndClose(response)
+}
+
+// InspectUpload implements the protobuf pfs.InspectUpload RPC
+func (a *apiServer) InspectUpload(ctx context.Context, request *pfs.InspectUploadRequest) (response *pfs.UploadInfo, retErr error) {
+	func() { a.Log(request, nil, nil, 0) }()
+	defer func(start time.Time) { a.Log(request, response, retErr, time.Since(start)) }(time.Now())
+
+	return a.driver.inspectUpload(a.env.GetPachClient(ctx), request.UploadID)
+}
+
+// ListUpload implements the protobuf pfs.ListUpload RPC
+func (a *apiServer) ListUpload(ctx context.Context, request *pfs.ListUploadRequest) (response *pfs.UploadInfos, retErr error) {
+	func() { a.Log(request, nil, nil, 0) }()
+	defer func(start time.Time) { a.Log(request, response, retErr, time.Since(start)) }(time.Now())
+
+	uploadInfos, err := a.driver.listUpload(a.env.GetPachClient(ctx), request.Repo)
+	if err != nil {
+		return nil, err
+	}
+	return &pfs.UploadInfos{UploadInfo: uploadInfos}, nil
+}
+
+// CompleteUpload implements the protobuf pfs.CompleteUpload RPC
+func (a *apiServer) CompleteUpload(ctx context.Context, request *pfs.CompleteUploadRequest) (response *types.Empty, retErr error) {
+	func() { a.Log(request, nil, nil, 0) }()
+	defer func(start time.Time) { a.Log(request, response, retErr, time.Since(start)) }(time.Now())
+
+	if err := a.driver.completeUpload(a.env.GetPachClient(ctx), request.UploadID); err != nil {
+		return nil, err
+	}
+	return &types.Empty{}, nil
+}
+
+// DeleteUpload implements the protobuf pfs.DeleteUpload RPC
+func (a *apiServer) DeleteUpload(ctx context.Context, request *pfs.DeleteUploadRequest) (response *types.Empty, retErr error) {
+	func() { a.Log(request, nil, nil, 0) }()
+	defer func(start time.Time) { a.Log(request, response, retErr, time.Since(start)) }(time.Now())
+
+	if err := a.driver.deleteUpload(a.env.GetPachClient(ctx), request.UploadID); err != nil {
+		return nil, err
+	}
+	return &types.Empty{}, nil
+}
+
 // DeleteAll implements the protobuf pfs.DeleteAll RPC
 func (a *apiServer) DeleteAll(ctx context.Context, request *types.Empty) (response *types.Empty, retErr error) {
 	func() { a.Log(request, nil, nil, 0) }()
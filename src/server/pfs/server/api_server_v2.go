@@ -240,7 +240,7 @@ func (a *apiServerV2) FinishCommitInTransaction(
 	request *pfs.FinishCommitRequest,
 ) error {
 	return metrics.ReportRequest(func() error {
-		return a.driver.finishCommitV2(txnCtx, request.Commit, request.Description)
+		return a.driver.finishCommitV2(txnCtx, request.Commit, request.Description, request.Annotations)
 	})
 }
 
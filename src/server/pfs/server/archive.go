@@ -0,0 +1,413 @@
+package server
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/gogo/protobuf/types"
+	"github.com/pachyderm/pachyderm/src/client"
+	"github.com/pachyderm/pachyderm/src/client/pfs"
+	"github.com/pachyderm/pachyderm/src/client/pkg/errors"
+	pfsserver "github.com/pachyderm/pachyderm/src/server/pfs"
+	"github.com/pachyderm/pachyderm/src/server/pkg/hashtree"
+)
+
+// maxUnpackZipBytes is the largest zip archive that unpackFile will read.
+// Zip archives are spooled to a temporary file on pachd's disk before they're
+// unpacked, so this bounds the disk space that one upload can take.
+const maxUnpackZipBytes = 10 << 30 // 10GiB
+
+// checkArchivePath returns an error if 'name', a path in an archive, is
+// absolute or refers to a location outside of the archive.
+func checkArchivePath(name string) error {
+	clean := path.Clean(name)
+	if path.IsAbs(clean) || clean == "." || clean == ".." || strings.HasPrefix(clean, "../") {
+		return errors.Errorf("invalid path %q in archive, it must be a relative path inside the archive", name)
+	}
+	return nil
+}
+
+// checkArchiveSymlink returns an error if 'target', the target of the symlink
+// 'name' in an archive, is absolute or refers to a location outside of the
+// archive.
+func checkArchiveSymlink(name, target string) error {
+	resolved := path.Join(path.Dir(path.Clean(name)), target)
+	if path.IsAbs(target) || (resolved != "." && checkArchivePath(resolved) != nil) {
+		return errors.Errorf("invalid target %q for symlink %q in archive, it must be a relative path inside the archive", target, name)
+	}
+	return nil
+}
+
+// unpackFile reads the archive in 'r' (whose format is req.Unpack) and calls
+// 'f' with a request and the content of each file and symlink in it, as if
+// each had been sent in its own PutFileRequest under req.File.Path.
+// Directories aren't put explicitly, as PFS creates them implicitly. Tar
+// archives are read as they're streamed, but zip archives are spooled to a
+// temporary file, as their index is at the end, and can be at most
+// maxUnpackZipBytes. Entries and symlink targets must be relative paths that
+// stay inside the archive.
+func (d *driver) unpackFile(req *pfs.PutFileRequest, r io.Reader, f func(*pfs.PutFileRequest, io.Reader) error) (retErr error) {
+	if r == nil {
+		return errors.New("unpack cannot be used with delete")
+	}
+	if req.Delimiter != pfs.Delimiter_NONE {
+		return errors.New("unpack cannot be used with a delimiter")
+	}
+	if req.Symlink != "" {
+		return errors.New("unpack cannot be used with symlink")
+	}
+	// Read anything after the end of the archive (e.g. tar's padding), so
+	// that the request stream isn't blocked
+	defer func(src io.Reader) {
+		if retErr == nil {
+			_, retErr = io.Copy(ioutil.Discard, src)
+		}
+	}(r)
+	entryRequest := func(name string) (*pfs.PutFileRequest, error) {
+		if err := checkArchivePath(name); err != nil {
+			return nil, err
+		}
+		entryPath := path.Join("/", req.File.Path, name)
+		if err := d.checkFilePath(path.Join(req.File.Path, name)); err != nil {
+			return nil, errors.Wrapf(err, "invalid path %q in archive", name)
+		}
+		entryReq := *req // copy req so we can make changes
+		entryReq.File = client.NewFile(req.File.Commit.Repo.Name, req.File.Commit.ID, entryPath)
+		entryReq.Unpack = pfs.ArchiveFormat_RAW
+		return &entryReq, nil
+	}
+	switch req.Unpack {
+	case pfs.ArchiveFormat_TAR, pfs.ArchiveFormat_TAR_GZ:
+		if req.Unpack == pfs.ArchiveFormat_TAR_GZ {
+			gr, err := gzip.NewReader(r)
+			if err != nil {
+				return err
+			}
+			defer func() {
+				if err := gr.Close(); err != nil && retErr == nil {
+					retErr = err
+				}
+			}()
+			r = gr
+		}
+		tr := tar.NewReader(r)
+		for {
+			header, err := tr.Next()
+			if err != nil {
+				if errors.Is(err, io.EOF) {
+					return nil
+				}
+				return err
+			}
+			if header.Typeflag != tar.TypeReg && header.Typeflag != tar.TypeRegA && header.Typeflag != tar.TypeSymlink {
+				continue // directories are implicit, and other types aren't supported
+			}
+			entryReq, err := entryRequest(header.Name)
+			if err != nil {
+				return err
+			}
+			if header.Typeflag == tar.TypeSymlink {
+				if err := checkArchiveSymlink(header.Name, header.Linkname); err != nil {
+					return err
+				}
+				entryReq.Symlink = header.Linkname
+				entryReq.Metadata = nil
+				if err := f(entryReq, nil); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := f(entryReq, tr); err != nil {
+				return err
+			}
+		}
+	case pfs.ArchiveFormat_ZIP:
+		tmp, err := ioutil.TempFile("", "pachyderm-unpack-")
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if err := tmp.Close(); err != nil && retErr == nil {
+				retErr = err
+			}
+			if err := os.Remove(tmp.Name()); err != nil && retErr == nil {
+				retErr = err
+			}
+		}()
+		size, err := io.Copy(tmp, io.LimitReader(r, maxUnpackZipBytes+1))
+		if err != nil {
+			return err
+		}
+		if size > maxUnpackZipBytes {
+			return errors.Errorf("zip archive is larger than the maximum of %d bytes, use a tar archive instead", int64(maxUnpackZipBytes))
+		}
+		zr, err := zip.NewReader(tmp, size)
+		if err != nil {
+			return err
+		}
+		for _, zf := range zr.File {
+			mode := zf.Mode()
+			if mode.IsDir() || (!mode.IsRegular() && mode&os.ModeSymlink == 0) {
+				continue
+			}
+			entryReq, err := entryRequest(zf.Name)
+			if err != nil {
+				return err
+			}
+			if err := func() (retErr error) {
+				rc, err := zf.Open()
+				if err != nil {
+					return err
+				}
+				defer func() {
+					if err := rc.Close(); err != nil && retErr == nil {
+						retErr = err
+					}
+				}()
+				if mode&os.ModeSymlink != 0 {
+					target, err := ioutil.ReadAll(rc)
+					if err != nil {
+						return err
+					}
+					if err := checkArchiveSymlink(zf.Name, string(target)); err != nil {
+						return err
+					}
+					entryReq.Symlink = string(target)
+					entryReq.Metadata = nil
+					return f(entryReq, nil)
+				}
+				return f(entryReq, rc)
+			}(); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return errors.Errorf("unrecognized archive format %v", req.Unpack)
+	}
+}
+
+// archiveWriter is implemented for each ArchiveFormat that getFileArchive
+// can write.
+type archiveWriter interface {
+	// writeEntry adds the file, directory or symlink described by 'fi' to
+	// the archive as 'name'. 'r' holds the content of regular files.
+	writeEntry(name string, fi *pfs.FileInfo, modTime time.Time, r io.Reader) error
+	Close() error
+}
+
+// getFileArchive writes every file under 'file's path (or under the paths
+// matching it, if it's a glob pattern) to 'w', as an archive in 'format'.
+// Entries keep their full path in the commit, and the time at which they
+// were committed as their modification time.
+func (d *driver) getFileArchive(pachClient *client.APIClient, file *pfs.File, format pfs.ArchiveFormat, w io.Writer) (retErr error) {
+	// Validate arguments
+	if file == nil {
+		return errors.New("file cannot be nil")
+	}
+	if file.Commit == nil {
+		return errors.New("file commit cannot be nil")
+	}
+	if file.Commit.Repo == nil {
+		return errors.New("file commit repo cannot be nil")
+	}
+	// Pin the commit, so that every file is read from the same commit even
+	// if file.Commit is a branch whose head moves while the archive is written
+	commitInfo, err := d.inspectCommit(pachClient, file.Commit, pfs.CommitState_STARTED)
+	if err != nil {
+		return err
+	}
+	pinned := &pfs.File{Commit: commitInfo.Commit, Path: file.Path}
+
+	// The archive is only started once the first file is found, so that
+	// nothing is written if 'file' doesn't exist
+	var newArchiveWriter func() archiveWriter
+	switch format {
+	case pfs.ArchiveFormat_TAR:
+		newArchiveWriter = func() archiveWriter {
+			return &tarArchiveWriter{w: tar.NewWriter(w)}
+		}
+	case pfs.ArchiveFormat_TAR_GZ:
+		newArchiveWriter = func() archiveWriter {
+			gw := gzip.NewWriter(w)
+			return &tarArchiveWriter{w: tar.NewWriter(gw), gw: gw}
+		}
+	case pfs.ArchiveFormat_ZIP:
+		newArchiveWriter = func() archiveWriter {
+			return &zipArchiveWriter{w: zip.NewWriter(w)}
+		}
+	default:
+		return errors.Errorf("unrecognized archive format %v", format)
+	}
+	var aw archiveWriter
+	defer func() {
+		if aw == nil {
+			return
+		}
+		if err := aw.Close(); err != nil && retErr == nil {
+			retErr = err
+		}
+	}()
+	writeEntry := func(fi *pfs.FileInfo) error {
+		if aw == nil {
+			aw = newArchiveWriter()
+		}
+		name := strings.TrimPrefix(path.Clean("/"+fi.File.Path), "/")
+		if name == "" {
+			return nil // the root directory
+		}
+		modTime := time.Now()
+		if fi.Committed != nil {
+			var err error
+			modTime, err = types.TimestampFromProto(fi.Committed)
+			if err != nil {
+				return err
+			}
+		}
+		var r io.Reader
+		if fi.FileType == pfs.FileType_FILE {
+			var err error
+			r, err = d.getFile(pachClient, fi.File, 0, 0)
+			if err != nil {
+				return err
+			}
+		}
+		return aw.writeEntry(name, fi, modTime, r)
+	}
+	if hashtree.IsGlob(file.Path) {
+		// Matches may overlap (e.g. a directory and a file in it), but each
+		// file is only written once
+		seen := make(map[string]bool)
+		writeNewEntry := func(fi *pfs.FileInfo) error {
+			if seen[fi.File.Path] {
+				return nil
+			}
+			seen[fi.File.Path] = true
+			return writeEntry(fi)
+		}
+		if err := d.globFile(pachClient, pinned.Commit, pinned.Path, func(match *pfs.FileInfo) error {
+			if match.FileType != pfs.FileType_DIR {
+				return writeNewEntry(match)
+			}
+			return d.walkFile(pachClient, match.File, writeNewEntry)
+		}); err != nil {
+			return err
+		}
+	} else if err := d.walkFile(pachClient, pinned, writeEntry); err != nil {
+		return err
+	}
+	if aw == nil {
+		return pfsserver.ErrFileNotFound{file}
+	}
+	return nil
+}
+
+// archiveSymlinkTarget returns the target of the symlink 'name' (described by
+// 'fi') as it should appear in an archive, whose root is the root of the
+// commit. The target is made relative to the symlink, and clamped to the root
+// of the commit like hashtree.SymlinkTarget, so that extracting the archive
+// can't create a symlink that points outside of it.
+func archiveSymlinkTarget(name string, fi *pfs.FileInfo) string {
+	linkPath := path.Clean("/" + name)
+	target := hashtree.SymlinkTarget(linkPath, &hashtree.SymlinkNodeProto{Target: fi.SymlinkTarget})
+	dir := strings.TrimPrefix(path.Dir(linkPath), "/")
+	rel := ""
+	if dir != "" {
+		rel = strings.Repeat("../", strings.Count(dir, "/")+1)
+	}
+	return path.Clean(rel + strings.TrimPrefix(target, "/"))
+}
+
+type tarArchiveWriter struct {
+	w  *tar.Writer
+	gw *gzip.Writer // set for tar.gz
+}
+
+func (a *tarArchiveWriter) writeEntry(name string, fi *pfs.FileInfo, modTime time.Time, r io.Reader) error {
+	header := &tar.Header{
+		Name:    name,
+		ModTime: modTime.Truncate(time.Second), // tar rounds to the nearest second otherwise
+	}
+	switch fi.FileType {
+	case pfs.FileType_DIR:
+		header.Typeflag = tar.TypeDir
+		header.Name += "/"
+		header.Mode = 0755
+	case pfs.FileType_SYMLINK:
+		header.Typeflag = tar.TypeSymlink
+		header.Linkname = archiveSymlinkTarget(name, fi)
+		header.Mode = 0777
+	default:
+		header.Typeflag = tar.TypeReg
+		header.Size = int64(fi.SizeBytes)
+		header.Mode = 0644
+	}
+	if err := a.w.WriteHeader(header); err != nil {
+		return err
+	}
+	if r == nil {
+		return nil
+	}
+	n, err := io.Copy(a.w, r)
+	if err != nil {
+		return err
+	}
+	if n != header.Size {
+		return errors.Errorf("file %q changed size while being archived (expected %d bytes, got %d)", name, header.Size, n)
+	}
+	return nil
+}
+
+func (a *tarArchiveWriter) Close() error {
+	if err := a.w.Close(); err != nil {
+		return err
+	}
+	if a.gw != nil {
+		return a.gw.Close()
+	}
+	return nil
+}
+
+type zipArchiveWriter struct {
+	w *zip.Writer
+}
+
+func (a *zipArchiveWriter) writeEntry(name string, fi *pfs.FileInfo, modTime time.Time, r io.Reader) error {
+	header := &zip.FileHeader{
+		Name:     name,
+		Method:   zip.Deflate,
+		Modified: modTime,
+	}
+	switch fi.FileType {
+	case pfs.FileType_DIR:
+		header.Name += "/"
+		header.Method = zip.Store
+		header.SetMode(os.ModeDir | 0755)
+	case pfs.FileType_SYMLINK:
+		header.Method = zip.Store
+		header.SetMode(os.ModeSymlink | 0777)
+		r = strings.NewReader(archiveSymlinkTarget(name, fi))
+	default:
+		header.SetMode(0644)
+	}
+	ew, err := a.w.CreateHeader(header)
+	if err != nil {
+		return err
+	}
+	if r == nil {
+		return nil
+	}
+	_, err = io.Copy(ew, r)
+	return err
+}
+
+func (a *zipArchiveWriter) Close() error {
+	return a.w.Close()
+}
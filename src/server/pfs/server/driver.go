@@ -12,8 +12,10 @@ import (
 	"math"
 	"net/http"
 	"net/url"
+	"os"
 	"path"
 	"path/filepath"
+	"regexp"
 	"sort"
 	"strconv"
 	"strings"
@@ -27,14 +29,18 @@ import (
 	"github.com/pachyderm/pachyderm/src/client/pfs"
 	"github.com/pachyderm/pachyderm/src/client/pkg/errors"
 	"github.com/pachyderm/pachyderm/src/client/pkg/grpcutil"
+	"github.com/pachyderm/pachyderm/src/client/pps"
 	pfsserver "github.com/pachyderm/pachyderm/src/server/pfs"
 	"github.com/pachyderm/pachyderm/src/server/pkg/ancestry"
+	"github.com/pachyderm/pachyderm/src/server/pkg/avro"
 	col "github.com/pachyderm/pachyderm/src/server/pkg/collection"
 	"github.com/pachyderm/pachyderm/src/server/pkg/errutil"
 	"github.com/pachyderm/pachyderm/src/server/pkg/hashtree"
 	"github.com/pachyderm/pachyderm/src/server/pkg/obj"
+	"github.com/pachyderm/pachyderm/src/server/pkg/parquet"
 	"github.com/pachyderm/pachyderm/src/server/pkg/pfsdb"
 	"github.com/pachyderm/pachyderm/src/server/pkg/ppsconsts"
+	"github.com/pachyderm/pachyderm/src/server/pkg/ppsdb"
 	"github.com/pachyderm/pachyderm/src/server/pkg/serviceenv"
 	"github.com/pachyderm/pachyderm/src/server/pkg/sql"
 	"github.com/pachyderm/pachyderm/src/server/pkg/storage/fileset"
@@ -46,6 +52,7 @@ import (
 	etcd "github.com/coreos/etcd/clientv3"
 	"github.com/gogo/protobuf/proto"
 	"github.com/gogo/protobuf/types"
+	"github.com/pmezard/go-difflib/difflib"
 	"github.com/sirupsen/logrus"
 	"golang.org/x/sync/errgroup"
 	"golang.org/x/sync/semaphore"
@@ -58,6 +65,23 @@ const (
 
 	// Makes calls to ListRepo and InspectRepo more legible
 	includeAuth = true
+
+	// grepFileConcurrency is the number of files that grepFile searches at once
+	grepFileConcurrency = 10
+	// grepFileMaxLineBytes is the number of bytes of each line that grepFile
+	// matches against (and returns); the rest of a longer line is ignored
+	grepFileMaxLineBytes = 64 * 1024
+
+	// defaultDiffContentSizeLimit is the size (in bytes) of the largest file
+	// whose content diffFileContent diffs, if the caller doesn't set a limit
+	defaultDiffContentSizeLimit = 1024 * 1024
+	// binarySniffLen is the number of bytes at the start of a file that are
+	// checked for NUL bytes to decide whether the file is binary (as git does)
+	binarySniffLen = 8000
+	// maxDiffContentTotalBytes is the total size (in bytes) of the unified
+	// diffs that diffFileContent returns. Diffs that would exceed it are
+	// marked as too large instead.
+	maxDiffContentTotalBytes = 8 * 1024 * 1024
 )
 
 // IsPermissionError returns true if a given error is a permission error.
@@ -97,7 +121,9 @@ type driver struct {
 	putFileRecords col.Collection
 	commits        collectionFactory
 	branches       collectionFactory
+	commitTags     collectionFactory
 	openCommits    col.Collection
+	uploads        col.Collection
 
 	// a cache for hashtrees
 	treeCache *hashtree.Cache
@@ -147,7 +173,11 @@ func newDriver(
 		branches: func(repo string) col.Collection {
 			return pfsdb.Branches(etcdClient, etcdPrefix, repo)
 		},
+		commitTags: func(repo string) col.Collection {
+			return pfsdb.CommitTags(etcdClient, etcdPrefix, repo)
+		},
 		openCommits: pfsdb.OpenCommits(etcdClient, etcdPrefix),
+		uploads:     pfsdb.Uploads(etcdClient, etcdPrefix),
 		treeCache:   treeCache,
 		storageRoot: storageRoot,
 		// Allow up to a third of the requested memory to be used for memory intensive operations
@@ -211,11 +241,20 @@ func (d *driver) checkIsAuthorized(pachClient *client.APIClient, r *pfs.Repo, s
 	return nil
 }
 
-func (d *driver) createRepo(txnCtx *txnenv.TransactionContext, repo *pfs.Repo, description string, update bool) error {
+func (d *driver) createRepo(txnCtx *txnenv.TransactionContext, repo *pfs.Repo, description string, update bool, retention *pfs.RetentionPolicy, quota *pfs.RepoQuota, immutability *pfs.ImmutabilityPolicy) error {
 	// Validate arguments
 	if repo == nil {
 		return errors.New("repo cannot be nil")
 	}
+	if err := validateRetentionPolicy(retention); err != nil {
+		return err
+	}
+	if err := validateRepoQuota(quota); err != nil {
+		return err
+	}
+	if err := validateImmutabilityPolicy(immutability); err != nil {
+		return err
+	}
 
 	// Check that the user is logged in (user doesn't need any access level to
 	// create a repo, but they must be authenticated if auth is active)
@@ -240,6 +279,36 @@ func (d *driver) createRepo(txnCtx *txnenv.TransactionContext, repo *pfs.Repo, d
 	} else if err == nil && !update {
 		return errors.Errorf("cannot create \"%s\" as it already exists", repo.Name)
 	}
+	exists := err == nil
+	// An update without a retention policy, quota or immutability policy
+	// leaves the repo's as it is, and one with an empty one removes it
+	if update {
+		if retention == nil {
+			retention = existingRepoInfo.Retention
+		} else if proto.Equal(retention, &pfs.RetentionPolicy{}) {
+			retention = nil
+		}
+		if quota == nil {
+			quota = existingRepoInfo.Quota
+		} else if proto.Equal(quota, &pfs.RepoQuota{}) {
+			quota = nil
+		}
+		if immutability == nil {
+			immutability = existingRepoInfo.Immutability
+		} else if proto.Equal(immutability, &pfs.ImmutabilityPolicy{}) {
+			immutability = nil
+		}
+	}
+	if exists && (!proto.Equal(quota, existingRepoInfo.Quota) || !proto.Equal(immutability, existingRepoInfo.Immutability)) {
+		// Only owners can change a repo's quota or immutability policy, and no
+		// one can remove a legal hold before it expires
+		if err := checkImmutabilityUpdate(repo, existingRepoInfo.Immutability, immutability); err != nil {
+			return err
+		}
+		if err := d.checkIsAuthorizedInTransaction(txnCtx, repo, auth.Scope_OWNER); err != nil {
+			return err
+		}
+	}
 
 	// Create ACL for new repo
 	if authIsActivated {
@@ -259,12 +328,16 @@ func (d *driver) createRepo(txnCtx *txnenv.TransactionContext, repo *pfs.Repo, d
 	}
 
 	repoInfo := &pfs.RepoInfo{
-		Repo:        repo,
-		Created:     types.TimestampNow(),
-		Description: description,
+		Repo:         repo,
+		Created:      types.TimestampNow(),
+		Description:  description,
+		Retention:    retention,
+		Quota:        quota,
+		Immutability: immutability,
 	}
 	if update && existingRepoInfo.Created != nil {
 		repoInfo.Created = existingRepoInfo.Created
+		repoInfo.ForkOrigin = existingRepoInfo.ForkOrigin
 	}
 	// Only Put the new repoInfo if something has changed.  This
 	// optimization is impactful because pps will frequently update the
@@ -539,7 +612,9 @@ func (e ErrProvenanceOfSubvenance) Error() string {
 // 3. Commit provenance is transitive
 // 4. Commit provenance and commit subvenance are dual relations
 // If fix is true it will attempt to fix as many of these issues as it can.
-func (d *driver) fsck(pachClient *client.APIClient, fix bool, cb func(*pfs.FsckResponse) error) error {
+// If deep is true it also verifies that the content referenced by every
+// finished commit's hashtree is in object storage (see fsckContent).
+func (d *driver) fsck(pachClient *client.APIClient, fix, deep bool, deepParallelism, deepBytesPerSecond int64, cb func(*pfs.FsckResponse) error) error {
 	ctx := pachClient.Ctx()
 	repos := d.repos.ReadOnly(ctx)
 	key := path.Join
@@ -874,7 +949,7 @@ func (d *driver) fsck(pachClient *client.APIClient, fix bool, cb func(*pfs.FsckR
 		}
 	}
 	if fix {
-		_, err := col.NewSTM(ctx, d.etcdClient, func(stm col.STM) error {
+		if _, err := col.NewSTM(ctx, d.etcdClient, func(stm col.STM) error {
 			for _, ci := range newCommitInfos {
 				// We've observed users getting ErrExists from this create,
 				// which doesn't make a lot of sense, but we insulate against
@@ -884,8 +959,12 @@ func (d *driver) fsck(pachClient *client.APIClient, fix bool, cb func(*pfs.FsckR
 				}
 			}
 			return nil
-		})
-		return err
+		}); err != nil {
+			return err
+		}
+	}
+	if deep {
+		return d.fsckContent(pachClient, commitInfos, deepParallelism, deepBytesPerSecond, onError)
 	}
 	return nil
 }
@@ -947,6 +1026,9 @@ func (d *driver) deleteRepo(txnCtx *txnenv.TransactionContext, repo *pfs.Repo, f
 	if err := d.checkIsAuthorizedInTransaction(txnCtx, repo, auth.Scope_OWNER); err != nil {
 		return err
 	}
+	if err := checkAppendOnly(&existingRepoInfo, "delete the repo"); err != nil {
+		return err
+	}
 
 	repoInfo := new(pfs.RepoInfo)
 	if err := repos.Get(repo.Name, repoInfo); err != nil {
@@ -1070,6 +1152,8 @@ func (d *driver) deleteRepo(txnCtx *txnenv.TransactionContext, repo *pfs.Repo, f
 	// Similarly with commits
 	commitsX := d.commits(repo.Name).ReadWrite(txnCtx.Stm)
 	commitsX.DeleteAll()
+	// Tags can't outlive the commits they point to
+	d.commitTags(repo.Name).ReadWrite(txnCtx.Stm).DeleteAll()
 	if err := repos.Delete(repo.Name); err != nil && !col.IsErrNotFound(err) {
 		return errors.Wrapf(err, "repos.Delete")
 	}
@@ -1082,21 +1166,449 @@ func (d *driver) deleteRepo(txnCtx *txnenv.TransactionContext, repo *pfs.Repo, f
 	return nil
 }
 
+// forkRepo creates 'newRepo', with a single commit on 'branch' that has the
+// same tree as the head of 'branch' in 'repo'. Hashtrees are immutable, so
+// the two repos share the tree and its objects and no data is copied, and
+// later writes to either repo don't affect the other.
+func (d *driver) forkRepo(txnCtx *txnenv.TransactionContext, repo *pfs.Repo, newRepo *pfs.Repo, branch string, description string) error {
+	// Validate arguments
+	if repo == nil || newRepo == nil {
+		return errors.New("repo cannot be nil")
+	}
+	if branch == "" {
+		branch = "master"
+	}
+	if repo.Name == ppsconsts.SpecRepo {
+		return errors.Errorf("cannot fork the special PPS repo %s", ppsconsts.SpecRepo)
+	}
+	if err := d.checkIsAuthorizedInTransaction(txnCtx, repo, auth.Scope_READER); err != nil {
+		return err
+	}
+
+	commitInfo, err := d.resolveCommit(txnCtx.Stm, client.NewCommit(repo.Name, branch))
+	if err != nil {
+		return err
+	}
+	if commitInfo.Finished == nil {
+		return errors.Errorf("cannot fork %s@%s because commit %s has not been finished", repo.Name, branch, commitInfo.Commit.ID)
+	}
+	if commitInfo.Tree == nil && commitInfo.Trees != nil {
+		return errors.Errorf("cannot fork %s@%s because commit %s is an output commit", repo.Name, branch, commitInfo.Commit.ID)
+	}
+	if description == "" {
+		description = fmt.Sprintf("Fork of %s@%s.", repo.Name, commitInfo.Commit.ID)
+	}
+
+	if err := d.createRepo(txnCtx, newRepo, description, false, nil, nil, nil); err != nil {
+		return err
+	}
+	if _, err := d.makeCommit(txnCtx, "", client.NewCommit(newRepo.Name, ""), branch, nil, nil, commitInfo.Tree, nil, nil, nil, nil,
+		fmt.Sprintf("fork %s@%s", repo.Name, commitInfo.Commit.ID), nil, time.Time{}, time.Now(), commitInfo.SizeBytes); err != nil {
+		return err
+	}
+	repoInfo := &pfs.RepoInfo{}
+	return d.repos.ReadWrite(txnCtx.Stm).Update(newRepo.Name, repoInfo, func() error {
+		repoInfo.ForkOrigin = commitInfo.Commit
+		return nil
+	})
+}
+
+func (d *driver) renameRepo(txnCtx *txnenv.TransactionContext, repo *pfs.Repo, newName string) error {
+	// Validate arguments
+	if repo == nil {
+		return errors.New("repo cannot be nil")
+	}
+	if err := ancestry.ValidateName(newName); err != nil {
+		return err
+	}
+	if repo.Name == ppsconsts.SpecRepo || newName == ppsconsts.SpecRepo {
+		return errors.Errorf("cannot rename the special PPS repo %s", ppsconsts.SpecRepo)
+	}
+	if err := d.checkIsAuthorizedInTransaction(txnCtx, repo, auth.Scope_OWNER); err != nil {
+		return err
+	}
+
+	repos := d.repos.ReadWrite(txnCtx.Stm)
+	repoInfo := &pfs.RepoInfo{}
+	if err := repos.Get(repo.Name, repoInfo); err != nil {
+		return errors.Wrapf(err, "error getting repo %s", repo.Name)
+	}
+	if err := repos.Get(newName, &pfs.RepoInfo{}); err == nil {
+		return errors.Errorf("cannot rename %q to %q as %q already exists", repo.Name, newName, newName)
+	} else if !col.IsErrNotFound(err) {
+		return errors.Wrapf(err, "error checking whether \"%s\" exists", newName)
+	}
+
+	// Read every branch and commit in 'repo', and collect the (other) repos
+	// whose branches and commits refer to them through provenance
+	related := make(map[string]bool)
+	var branchInfos []*pfs.BranchInfo
+	branchInfo := &pfs.BranchInfo{}
+	if err := d.branches(repo.Name).ReadWrite(txnCtx.Stm).List(branchInfo, func(string) error {
+		for _, b := range branchInfo.Provenance {
+			if b.Repo.Name == ppsconsts.SpecRepo {
+				return errors.Errorf("cannot rename repo %s as it's the output of pipeline %s", repo.Name, b.Name)
+			}
+			related[b.Repo.Name] = true
+		}
+		for _, b := range branchInfo.Subvenance {
+			related[b.Repo.Name] = true
+		}
+		branchInfos = append(branchInfos, proto.Clone(branchInfo).(*pfs.BranchInfo))
+		return nil
+	}); err != nil {
+		return err
+	}
+	var commitInfos []*pfs.CommitInfo
+	commitInfo := &pfs.CommitInfo{}
+	if err := d.commits(repo.Name).ReadWrite(txnCtx.Stm).List(commitInfo, func(string) error {
+		for _, prov := range commitInfo.Provenance {
+			related[prov.Commit.Repo.Name] = true
+		}
+		for _, subv := range commitInfo.Subvenance {
+			related[subv.Lower.Repo.Name] = true
+			related[subv.Upper.Repo.Name] = true
+		}
+		commitInfos = append(commitInfos, proto.Clone(commitInfo).(*pfs.CommitInfo))
+		return nil
+	}); err != nil {
+		return err
+	}
+	var tagInfos []*pfs.CommitTagInfo
+	tagInfo := &pfs.CommitTagInfo{}
+	if err := d.commitTags(repo.Name).ReadWrite(txnCtx.Stm).List(tagInfo, func(string) error {
+		tagInfos = append(tagInfos, proto.Clone(tagInfo).(*pfs.CommitTagInfo))
+		return nil
+	}); err != nil {
+		return err
+	}
+	delete(related, repo.Name)
+
+	// Move the repo's ACL. The new ACL must be set first, as the caller is only
+	// authorized to modify the old one while it still exists.
+	aclResp, err := txnCtx.Auth().GetACLInTransaction(txnCtx, &auth.GetACLRequest{Repo: repo.Name})
+	if err != nil && !auth.IsErrNotActivated(err) {
+		return grpcutil.ScrubGRPC(err)
+	}
+	if err == nil {
+		if _, err := txnCtx.Auth().SetACLInTransaction(txnCtx, &auth.SetACLRequest{
+			Repo:    newName,
+			Entries: aclResp.Entries,
+		}); err != nil {
+			return errors.Wrapf(grpcutil.ScrubGRPC(err), "could not create ACL for \"%s\"", newName)
+		}
+		if _, err := txnCtx.Auth().SetACLInTransaction(txnCtx, &auth.SetACLRequest{
+			Repo: repo.Name, // NewACL is unset, so this will clear the acl for 'repo'
+		}); err != nil {
+			return grpcutil.ScrubGRPC(err)
+		}
+	}
+
+	// Move everything stored under the old name to the new name
+	newCommits := d.commits(newName).ReadWrite(txnCtx.Stm)
+	openCommits := d.openCommits.ReadWrite(txnCtx.Stm)
+	for _, ci := range commitInfos {
+		oldCommit := proto.Clone(ci.Commit).(*pfs.Commit)
+		renameRepoRefs(commitInfoRepos(ci), repo.Name, newName)
+		if err := newCommits.Put(ci.Commit.ID, ci); err != nil {
+			return err
+		}
+		if ci.Finished == nil {
+			if err := openCommits.Put(ci.Commit.ID, ci.Commit); err != nil {
+				return err
+			}
+			if err := d.moveOpenCommitRecords(txnCtx, oldCommit, ci.Commit); err != nil {
+				return err
+			}
+		}
+	}
+	d.commits(repo.Name).ReadWrite(txnCtx.Stm).DeleteAll()
+	newBranches := d.branches(newName).ReadWrite(txnCtx.Stm)
+	for _, bi := range branchInfos {
+		renameRepoRefs(branchInfoRepos(bi), repo.Name, newName)
+		if err := newBranches.Put(bi.Branch.Name, bi); err != nil {
+			return err
+		}
+	}
+	d.branches(repo.Name).ReadWrite(txnCtx.Stm).DeleteAll()
+	newTags := d.commitTags(newName).ReadWrite(txnCtx.Stm)
+	for _, ti := range tagInfos {
+		renameRepoRefs([]*pfs.Repo{ti.Tag.Repo, ti.Commit.Repo}, repo.Name, newName)
+		if err := newTags.Put(ti.Tag.Name, ti); err != nil {
+			return err
+		}
+	}
+	d.commitTags(repo.Name).ReadWrite(txnCtx.Stm).DeleteAll()
+	repoInfo.Repo.Name = newName
+	for _, b := range repoInfo.Branches {
+		b.Repo.Name = newName
+	}
+	if err := repos.Create(newName, repoInfo); err != nil {
+		return errors.Wrapf(err, "repos.Create")
+	}
+	if err := repos.Delete(repo.Name); err != nil {
+		return errors.Wrapf(err, "repos.Delete")
+	}
+
+	// Fix the provenance and subvenance of branches and commits in other repos
+	for relatedRepo := range related {
+		if err := d.updateRepoRefs(txnCtx, relatedRepo, func(repos []*pfs.Repo) bool {
+			return renameRepoRefs(repos, repo.Name, newName)
+		}); err != nil {
+			return err
+		}
+	}
+
+	// Finally, point any pipelines that read from 'repo' at 'newName'
+	return d.updatePipelineInputs(txnCtx, func(input *pps.Input) bool {
+		switch {
+		case input.Pfs != nil && input.Pfs.Repo == repo.Name:
+			input.Pfs.Repo = newName
+		case input.Cron != nil && input.Cron.Repo == repo.Name:
+			input.Cron.Repo = newName
+		default:
+			return false
+		}
+		return true
+	})
+}
+
+// moveOpenCommitRecords moves the put file records of the open commit 'from'
+// to 'to', so that they're found under 'to's scratch space.
+func (d *driver) moveOpenCommitRecords(txnCtx *txnenv.TransactionContext, from, to *pfs.Commit) error {
+	var keys []string
+	var records []*pfs.PutFileRecords
+	putFileRecords := &pfs.PutFileRecords{}
+	if err := d.putFileRecords.ReadWrite(txnCtx.Stm).ListPrefix(d.scratchCommitPrefix(from), putFileRecords, func(key string) error {
+		keys = append(keys, key)
+		records = append(records, proto.Clone(putFileRecords).(*pfs.PutFileRecords))
+		return nil
+	}); err != nil {
+		return err
+	}
+	recordsCol := d.putFileRecords.ReadWrite(txnCtx.Stm)
+	for i, key := range keys {
+		if err := recordsCol.Put(path.Join(d.scratchCommitPrefix(to), key), records[i]); err != nil {
+			return err
+		}
+		if err := recordsCol.Delete(path.Join(d.scratchCommitPrefix(from), key)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// updateRepoRefs calls 'f' with the repos referenced by each branch and commit
+// in 'repo', and writes back the branches and commits for which 'f' returns
+// true.
+func (d *driver) updateRepoRefs(txnCtx *txnenv.TransactionContext, repo string, f func([]*pfs.Repo) bool) error {
+	var branchNames []string
+	branchInfo := &pfs.BranchInfo{}
+	if err := d.branches(repo).ReadWrite(txnCtx.Stm).List(branchInfo, func(name string) error {
+		if f(branchInfoRepos(branchInfo)) {
+			branchNames = append(branchNames, name)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	for _, name := range branchNames {
+		if err := d.branches(repo).ReadWrite(txnCtx.Stm).Update(name, branchInfo, func() error {
+			f(branchInfoRepos(branchInfo))
+			return nil
+		}); err != nil {
+			return err
+		}
+	}
+	var commitIDs []string
+	commitInfo := &pfs.CommitInfo{}
+	if err := d.commits(repo).ReadWrite(txnCtx.Stm).List(commitInfo, func(id string) error {
+		if f(commitInfoRepos(commitInfo)) {
+			commitIDs = append(commitIDs, id)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	for _, id := range commitIDs {
+		if err := d.commits(repo).ReadWrite(txnCtx.Stm).Update(id, commitInfo, func() error {
+			f(commitInfoRepos(commitInfo))
+			return nil
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// branchInfoRepos returns every repo referenced by 'branchInfo'
+func branchInfoRepos(branchInfo *pfs.BranchInfo) []*pfs.Repo {
+	repos := []*pfs.Repo{branchInfo.Branch.Repo}
+	if branchInfo.Head != nil {
+		repos = append(repos, branchInfo.Head.Repo)
+	}
+	for _, branches := range [][]*pfs.Branch{branchInfo.Provenance, branchInfo.Subvenance, branchInfo.DirectProvenance} {
+		for _, b := range branches {
+			repos = append(repos, b.Repo)
+		}
+	}
+	return repos
+}
+
+// commitInfoRepos returns every repo referenced by 'commitInfo'
+func commitInfoRepos(commitInfo *pfs.CommitInfo) []*pfs.Repo {
+	repos := []*pfs.Repo{commitInfo.Commit.Repo}
+	if commitInfo.Branch != nil {
+		repos = append(repos, commitInfo.Branch.Repo)
+	}
+	for _, c := range append([]*pfs.Commit{commitInfo.ParentCommit, commitInfo.MergeParent}, commitInfo.ChildCommits...) {
+		if c != nil {
+			repos = append(repos, c.Repo)
+		}
+	}
+	for _, prov := range commitInfo.Provenance {
+		repos = append(repos, prov.Commit.Repo)
+		if prov.Branch != nil {
+			repos = append(repos, prov.Branch.Repo)
+		}
+	}
+	for _, subv := range commitInfo.Subvenance {
+		repos = append(repos, subv.Lower.Repo, subv.Upper.Repo)
+	}
+	return repos
+}
+
+// renameRepoRefs renames each repo in 'repos' named 'from' to 'to', and
+// returns true if any of them were renamed
+func renameRepoRefs(repos []*pfs.Repo, from, to string) bool {
+	var renamed bool
+	for _, r := range repos {
+		if r.Name == from {
+			r.Name = to
+			renamed = true
+		}
+	}
+	return renamed
+}
+
+// updatePipelineInputs calls 'f' on each input of every pipeline, and commits
+// a new spec for each pipeline where 'f' modified (and returned true for) at
+// least one input. It's used to keep pipelines reading from their inputs when
+// those are renamed.
+func (d *driver) updatePipelineInputs(txnCtx *txnenv.TransactionContext, f func(*pps.Input) bool) error {
+	pipelines := ppsdb.Pipelines(d.etcdClient, path.Join(d.env.EtcdPrefix, d.env.PPSEtcdPrefix))
+	var pipelineNames []string
+	pipelinePtr := &pps.EtcdPipelineInfo{}
+	if err := pipelines.ReadWrite(txnCtx.Stm).List(pipelinePtr, func(name string) error {
+		pipelineNames = append(pipelineNames, name)
+		return nil
+	}); err != nil {
+		return err
+	}
+	for _, name := range pipelineNames {
+		if err := pipelines.ReadWrite(txnCtx.Stm).Get(name, pipelinePtr); err != nil {
+			return err
+		}
+		if pipelinePtr.SpecCommit == nil {
+			continue
+		}
+		pipelineInfo, err := d.readPipelineSpec(txnCtx, pipelinePtr.SpecCommit)
+		if err != nil {
+			return errors.Wrapf(err, "could not read spec for pipeline %s", name)
+		}
+		var modified bool
+		pps.VisitInput(pipelineInfo.Input, func(input *pps.Input) {
+			if f(input) {
+				modified = true
+			}
+		})
+		if !modified {
+			continue
+		}
+		data, err := pipelineInfo.Marshal()
+		if err != nil {
+			return err
+		}
+		object, size, err := txnCtx.Client.PutObject(bytes.NewReader(data))
+		if err != nil {
+			return err
+		}
+		specCommit, err := d.makeCommit(txnCtx, "", client.NewCommit(ppsconsts.SpecRepo, ""), name, nil, nil, nil, nil, nil,
+			[]string{ppsconsts.SpecFile}, []*pfs.PutFileRecords{{
+				Tombstone: true,
+				Records:   []*pfs.PutFileRecord{{SizeBytes: size, ObjectHash: object.Hash}},
+			}}, "", nil, time.Time{}, time.Time{}, 0)
+		if err != nil {
+			return errors.Wrapf(err, "could not update spec for pipeline %s", name)
+		}
+		pipelinePtr.SpecCommit = specCommit
+		if err := pipelines.ReadWrite(txnCtx.Stm).Put(name, pipelinePtr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readPipelineSpec reads the PipelineInfo stored in 'specCommit'. It reads
+// the spec's hashtree directly, as callers don't generally have access to the
+// spec repo.
+func (d *driver) readPipelineSpec(txnCtx *txnenv.TransactionContext, specCommit *pfs.Commit) (*pps.PipelineInfo, error) {
+	tree, err := d.getTreeForCommit(txnCtx, specCommit)
+	if err != nil {
+		return nil, err
+	}
+	node, err := tree.Get(ppsconsts.SpecFile)
+	if err != nil {
+		return nil, err
+	}
+	if node.FileNode == nil {
+		return nil, errors.Errorf("%s in commit %s is not a file", ppsconsts.SpecFile, specCommit.ID)
+	}
+	var buf bytes.Buffer
+	if len(node.FileNode.BlockRefs) > 0 {
+		getBlocksClient, err := txnCtx.Client.ObjectAPIClient.GetBlocks(
+			txnCtx.ClientContext,
+			&pfs.GetBlocksRequest{
+				BlockRefs: node.FileNode.BlockRefs,
+				TotalSize: uint64(node.SubtreeSize),
+			})
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.Copy(&buf, grpcutil.NewStreamingBytesReader(getBlocksClient, nil)); err != nil {
+			return nil, err
+		}
+	} else {
+		var hashes []string
+		for _, object := range node.FileNode.Objects {
+			hashes = append(hashes, object.Hash)
+		}
+		if err := txnCtx.Client.GetObjects(hashes, 0, 0, uint64(node.SubtreeSize), &buf); err != nil {
+			return nil, err
+		}
+	}
+	pipelineInfo := &pps.PipelineInfo{}
+	if err := pipelineInfo.Unmarshal(buf.Bytes()); err != nil {
+		return nil, err
+	}
+	return pipelineInfo, nil
+}
+
 // ID can be passed in for transactions, which need to ensure the ID doesn't
 // change after the commit ID has been reported to a client.
-func (d *driver) startCommit(txnCtx *txnenv.TransactionContext, ID string, parent *pfs.Commit, branch string, provenance []*pfs.CommitProvenance, description string) (*pfs.Commit, error) {
-	return d.makeCommit(txnCtx, ID, parent, branch, nil, provenance, nil, nil, nil, nil, nil, description, time.Time{}, time.Time{}, 0)
+func (d *driver) startCommit(txnCtx *txnenv.TransactionContext, ID string, parent *pfs.Commit, branch string, provenance []*pfs.CommitProvenance, description string, annotations map[string]string) (*pfs.Commit, error) {
+	return d.makeCommit(txnCtx, ID, parent, branch, nil, provenance, nil, nil, nil, nil, nil, description, annotations, time.Time{}, time.Time{}, 0)
 }
 
 func (d *driver) buildCommit(ctx context.Context, ID string, parent *pfs.Commit,
 	branch string, origin *pfs.CommitOrigin, provenance []*pfs.CommitProvenance,
 	tree *pfs.Object, trees []*pfs.Object, datums *pfs.Object,
-	started, finished time.Time, sizeBytes uint64) (*pfs.Commit, error) {
+	started, finished time.Time, sizeBytes uint64, annotations map[string]string) (*pfs.Commit, error) {
 	commit := &pfs.Commit{}
 	err := d.txnEnv.WithWriteContext(ctx, func(txnCtx *txnenv.TransactionContext) error {
 		var err error
 		commit, err = d.makeCommit(txnCtx, ID, parent, branch, origin, provenance, tree, trees,
-			datums, nil, nil, "", started, finished, sizeBytes)
+			datums, nil, nil, "", annotations, started, finished, sizeBytes)
 		return err
 	})
 	return commit, err
@@ -1127,6 +1639,7 @@ func (d *driver) makeCommit(
 	recordFiles []string,
 	records []*pfs.PutFileRecords,
 	description string,
+	annotations map[string]string,
 	started time.Time,
 	finished time.Time,
 	sizeBytes uint64,
@@ -1156,11 +1669,15 @@ func (d *driver) makeCommit(
 		Commit:      newCommit,
 		Origin:      origin,
 		Description: description,
+		Annotations: annotations,
 	}
 	if branch != "" {
 		if err := ancestry.ValidateName(branch); err != nil {
 			return nil, err
 		}
+		if err := d.checkNotCommitTag(txnCtx.Stm, parent.Repo, branch); err != nil {
+			return nil, err
+		}
 	}
 
 	// Set newCommitInfo.Started and possibly newCommitInfo.Finished. Enforce:
@@ -1209,6 +1726,9 @@ func (d *driver) makeCommit(
 	if err := repos.Get(parent.Repo.Name, repoInfo); err != nil {
 		return nil, err
 	}
+	if err := d.checkCommitQuota(txnCtx, repoInfo); err != nil {
+		return nil, err
+	}
 
 	// create/update 'branch' (if it was set) and set parent.ID (if, in
 	// addition, 'parent.ID' was not set)
@@ -1299,6 +1819,9 @@ func (d *driver) makeCommit(
 				return nil, err
 			}
 			sizeBytes = uint64(tree.FSSize())
+			if err := checkSizeQuota(repoInfo, sizeBytes); err != nil {
+				return nil, err
+			}
 		}
 
 		// at this point, either 'treeRef' is set OR 'newCommit' is tree-less
@@ -1421,7 +1944,7 @@ func (d *driver) makeCommit(
 	return newCommit, nil
 }
 
-func (d *driver) finishCommit(txnCtx *txnenv.TransactionContext, commit *pfs.Commit, tree *pfs.Object, empty bool, description string) (retErr error) {
+func (d *driver) finishCommit(txnCtx *txnenv.TransactionContext, commit *pfs.Commit, tree *pfs.Object, empty bool, description string, annotations map[string]string) (retErr error) {
 	// Validate arguments
 	if commit == nil {
 		return errors.New("commit cannot be nil")
@@ -1443,6 +1966,7 @@ func (d *driver) finishCommit(txnCtx *txnenv.TransactionContext, commit *pfs.Com
 	if description != "" {
 		commitInfo.Description = description
 	}
+	addAnnotations(commitInfo, annotations)
 
 	var parentTree, finishedTree hashtree.HashTree
 	if !empty {
@@ -1494,6 +2018,13 @@ func (d *driver) finishCommit(txnCtx *txnenv.TransactionContext, commit *pfs.Com
 		}
 
 		commitInfo.SizeBytes = uint64(finishedTree.FSSize())
+		repoInfo := &pfs.RepoInfo{}
+		if err := d.repos.ReadWrite(txnCtx.Stm).Get(commit.Repo.Name, repoInfo); err != nil {
+			return err
+		}
+		if err := checkSizeQuota(repoInfo, commitInfo.SizeBytes); err != nil {
+			return err
+		}
 	}
 	commitInfo.Finished = types.TimestampNow()
 	if err := d.updateProvenanceProgress(txnCtx, !empty, commitInfo); err != nil {
@@ -1513,6 +2044,13 @@ func (d *driver) finishOutputCommit(txnCtx *txnenv.TransactionContext, commit *p
 	if commitInfo.Finished != nil {
 		return errors.Errorf("commit %s has already been finished", commit.FullID())
 	}
+	repoInfo := &pfs.RepoInfo{}
+	if err := d.repos.ReadWrite(txnCtx.Stm).Get(commit.Repo.Name, repoInfo); err != nil {
+		return err
+	}
+	if err := checkSizeQuota(repoInfo, size); err != nil {
+		return err
+	}
 	commitInfo.Trees = trees
 	commitInfo.Datums = datums
 	commitInfo.SizeBytes = size
@@ -1855,8 +2393,8 @@ func (d *driver) inspectCommit(pachClient *client.APIClient, commit *pfs.Commit,
 }
 
 // resolveCommit contains the essential implementation of inspectCommit: it converts 'commit' (which may
-// be a commit ID or branch reference, plus '~' and/or '^') to a repo + commit
-// ID. It accepts an STM so that it can be used in a transaction and avoids an
+// be a commit ID or branch reference, plus '~' and/or '^', or '@{time}') to a
+// repo + commit ID. It accepts an STM so that it can be used in a transaction and avoids an
 // inconsistent call to d.inspectCommit()
 func (d *driver) resolveCommit(stm col.STM, userCommit *pfs.Commit) (*pfs.CommitInfo, error) {
 	if userCommit == nil {
@@ -1868,14 +2406,33 @@ func (d *driver) resolveCommit(stm col.STM, userCommit *pfs.Commit) (*pfs.Commit
 	if userCommit.ID == "" {
 		return nil, errors.Errorf("cannot resolve commit with no ID or branch")
 	}
+	// Check if 'commit.ID' refers to a point in time (e.g. master@{2h ago})
+	name, at, err := ancestry.ParseTime(userCommit.ID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if at != nil {
+		return d.resolveCommitAt(stm, userCommit, name, *at)
+	}
 	commit := proto.Clone(userCommit).(*pfs.Commit) // back up user commit, for error reporting
-	// Extract any ancestor tokens from 'commit.ID' (i.e. ~, ^ and .)
+	// Check if 'commit.ID' refers to a commit tag. Tag names may contain '.', so
+	// tags are parsed separately (with only ~ and ^ as ancestry tokens) and take
+	// precedence over branches.
 	var ancestryLength int
-	var err error
-	commit.ID, ancestryLength, err = ancestry.Parse(commit.ID)
+	tagInfo, tagAncestryLength, err := d.resolveCommitTag(stm, commit)
 	if err != nil {
 		return nil, err
 	}
+	if tagInfo != nil {
+		commit.ID = tagInfo.Commit.ID
+		ancestryLength = tagAncestryLength
+	} else {
+		// Extract any ancestor tokens from 'commit.ID' (i.e. ~, ^ and .)
+		commit.ID, ancestryLength, err = ancestry.Parse(commit.ID)
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	// Keep track of the commit branch, in case it isn't set in the commitInfo already
 	var commitBranch *pfs.Branch
@@ -1941,10 +2498,80 @@ func (d *driver) resolveCommit(stm col.STM, userCommit *pfs.Commit) (*pfs.Commit
 	return commitInfo, nil
 }
 
-func (d *driver) listCommit(pachClient *client.APIClient, repo *pfs.Repo,
-	to *pfs.Commit, from *pfs.Commit, number uint64, reverse bool) ([]*pfs.CommitInfo, error) {
+// resolveCommitAt resolves a time reference (e.g. master@{2h ago}) by
+// resolving 'name' and then walking back through its ancestors until it
+// reaches the latest commit that was finished at or before 'at'.
+func (d *driver) resolveCommitAt(stm col.STM, userCommit *pfs.Commit, name string, at time.Time) (*pfs.CommitInfo, error) {
+	commitInfo, err := d.resolveCommit(stm, client.NewCommit(userCommit.Repo.Name, name))
+	if err != nil {
+		return nil, err
+	}
+	commitBranch := commitInfo.Branch
+	commits := d.commits(userCommit.Repo.Name).ReadWrite(stm)
+	for {
+		if commitInfo.Finished != nil {
+			finished, err := types.TimestampFromProto(commitInfo.Finished)
+			if err != nil {
+				return nil, err
+			}
+			if !finished.After(at) {
+				break
+			}
+		}
+		if commitInfo.ParentCommit == nil {
+			return nil, pfsserver.ErrCommitNotFound{userCommit}
+		}
+		parent := commitInfo.ParentCommit
+		commitInfo = &pfs.CommitInfo{}
+		if err := commits.Get(parent.ID, commitInfo); err != nil {
+			if col.IsErrNotFound(err) {
+				return nil, pfsserver.ErrParentCommitNotFound{parent}
+			}
+			return nil, err
+		}
+	}
+	if commitInfo.Branch == nil {
+		commitInfo.Branch = commitBranch
+	}
+	userCommit.ID = commitInfo.Commit.ID
+	return commitInfo, nil
+}
+
+// checkNotCommitTag returns an error if 'repo' has a commit tag named
+// 'branch'. Branches and tags share a namespace, so that commit references
+// aren't ambiguous.
+func (d *driver) checkNotCommitTag(stm col.STM, repo *pfs.Repo, branch string) error {
+	if err := d.commitTags(repo.Name).ReadWrite(stm).Get(branch, &pfs.CommitTagInfo{}); err == nil {
+		return errors.Errorf("cannot create branch %q, a tag with that name already exists in repo %s", branch, repo.Name)
+	} else if !col.IsErrNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// resolveCommitTag returns the tag that 'commit.ID' refers to, along with the
+// number of ancestors to traverse from the tagged commit. If 'commit.ID' isn't
+// a tag, it returns a nil CommitTagInfo.
+func (d *driver) resolveCommitTag(stm col.STM, commit *pfs.Commit) (*pfs.CommitTagInfo, int, error) {
+	name, ancestryLength, err := ancestry.ParseTag(commit.ID)
+	if err != nil || name == "" || uuid.IsUUIDWithoutDashes(name) {
+		// Not a valid tag name, leave it to the caller to parse
+		return nil, 0, nil
+	}
+	tagInfo := &pfs.CommitTagInfo{}
+	if err := d.commitTags(commit.Repo.Name).ReadWrite(stm).Get(name, tagInfo); err != nil {
+		if col.IsErrNotFound(err) {
+			return nil, 0, nil
+		}
+		return nil, 0, err
+	}
+	return tagInfo, ancestryLength, nil
+}
+
+func (d *driver) listCommit(pachClient *client.APIClient, repo *pfs.Repo,
+	to *pfs.Commit, from *pfs.Commit, number uint64, reverse bool, annotations map[string]string) ([]*pfs.CommitInfo, error) {
 	var result []*pfs.CommitInfo
-	if err := d.listCommitF(pachClient, repo, to, from, number, reverse, func(ci *pfs.CommitInfo) error {
+	if err := d.listCommitF(pachClient, repo, to, from, number, reverse, annotations, func(ci *pfs.CommitInfo) error {
 		result = append(result, ci)
 		return nil
 	}); err != nil {
@@ -1954,7 +2581,7 @@ func (d *driver) listCommit(pachClient *client.APIClient, repo *pfs.Repo,
 }
 
 func (d *driver) listCommitF(pachClient *client.APIClient, repo *pfs.Repo,
-	to *pfs.Commit, from *pfs.Commit, number uint64, reverse bool, f func(*pfs.CommitInfo) error) error {
+	to *pfs.Commit, from *pfs.Commit, number uint64, reverse bool, annotations map[string]string, f func(*pfs.CommitInfo) error) error {
 	// Validate arguments
 	if repo == nil {
 		return errors.New("repo cannot be nil")
@@ -2042,7 +2669,9 @@ func (d *driver) listCommitF(pachClient *client.APIClient, repo *pfs.Repo,
 				}
 				lastRev = createRev
 			}
-			cis = append(cis, proto.Clone(ci).(*pfs.CommitInfo))
+			if hasAnnotations(ci, annotations) {
+				cis = append(cis, proto.Clone(ci).(*pfs.CommitInfo))
+			}
 			return nil
 		}); err != nil {
 			return err
@@ -2061,19 +2690,47 @@ func (d *driver) listCommitF(pachClient *client.APIClient, repo *pfs.Repo,
 			if err := commits.Get(cursor.ID, &commitInfo); err != nil {
 				return err
 			}
+			cursor = commitInfo.ParentCommit
+			if !hasAnnotations(&commitInfo, annotations) {
+				continue
+			}
 			if err := f(&commitInfo); err != nil {
 				if errors.Is(err, errutil.ErrBreak) {
 					return nil
 				}
 				return err
 			}
-			cursor = commitInfo.ParentCommit
 			number--
 		}
 	}
 	return nil
 }
 
+// addAnnotations adds 'annotations' to 'commitInfo', overwriting any existing
+// values for the same keys
+func addAnnotations(commitInfo *pfs.CommitInfo, annotations map[string]string) {
+	if len(annotations) == 0 {
+		return
+	}
+	if commitInfo.Annotations == nil {
+		commitInfo.Annotations = make(map[string]string)
+	}
+	for k, v := range annotations {
+		commitInfo.Annotations[k] = v
+	}
+}
+
+// hasAnnotations returns true if 'commitInfo' has all of the key/value pairs
+// in 'annotations'
+func hasAnnotations(commitInfo *pfs.CommitInfo, annotations map[string]string) bool {
+	for k, v := range annotations {
+		if actual, ok := commitInfo.Annotations[k]; !ok || actual != v {
+			return false
+		}
+	}
+	return true
+}
+
 func (d *driver) subscribeCommit(pachClient *client.APIClient, repo *pfs.Repo, branch string, prov *pfs.CommitProvenance,
 	from *pfs.Commit, state pfs.CommitState, f func(*pfs.CommitInfo) error) error {
 	// Validate arguments
@@ -2153,6 +2810,77 @@ func (d *driver) subscribeCommit(pachClient *client.APIClient, repo *pfs.Repo, b
 	}
 }
 
+// subscribeFile calls 'f' with the changes to the files matching the glob
+// pattern file.Path in each finished commit on the branch file.Commit.ID.
+// Each commit is diffed against the previous commit seen on the branch (or its
+// parent, for the first commit), so changes made by commits that were skipped
+// by resetting the branch are still reported.
+func (d *driver) subscribeFile(pachClient *client.APIClient, file *pfs.File, from *pfs.Commit, f func(*pfs.FileChange) error) error {
+	if err := validateFile(file); err != nil {
+		return err
+	}
+	if err := d.checkIsAuthorized(pachClient, file.Commit.Repo, auth.Scope_READER); err != nil {
+		return err
+	}
+	g, err := globlib.Compile(path.Clean("/"+file.Path), '/')
+	if err != nil {
+		return err
+	}
+	// match reports whether 'p' or one of its ancestors matches the pattern
+	match := func(p string) bool {
+		for ; p != "/"; p = path.Dir(p) {
+			if g.Match(p) {
+				return true
+			}
+		}
+		return g.Match(p)
+	}
+	var prev *pfs.Commit
+	return d.subscribeCommit(pachClient, file.Commit.Repo, file.Commit.ID, nil, from, pfs.CommitState_FINISHED, func(commitInfo *pfs.CommitInfo) error {
+		var oldFile *pfs.File // diffFile uses the commit's parent if this is nil
+		if prev != nil {
+			oldFile = client.NewFile(prev.Repo.Name, prev.ID, "/")
+		}
+		prev = commitInfo.Commit
+		newFileInfos, oldFileInfos, err := d.diffFile(pachClient, client.NewFile(file.Commit.Repo.Name, commitInfo.Commit.ID, "/"), oldFile, false)
+		if err != nil {
+			return err
+		}
+		// Both lists are sorted by path, so a file that appears in both was
+		// modified
+		nI, oI := 0, 0
+		for nI < len(newFileInfos) || oI < len(oldFileInfos) {
+			change := &pfs.FileChange{Commit: commitInfo.Commit}
+			switch {
+			case oI == len(oldFileInfos) || (nI < len(newFileInfos) && newFileInfos[nI].File.Path < oldFileInfos[oI].File.Path):
+				change.Type, change.FileInfo = pfs.FileChangeType_ADDED, newFileInfos[nI]
+				nI++
+			case nI == len(newFileInfos) || oldFileInfos[oI].File.Path < newFileInfos[nI].File.Path:
+				change.Type, change.FileInfo = pfs.FileChangeType_DELETED, oldFileInfos[oI]
+				oI++
+			default:
+				change.Type, change.FileInfo = pfs.FileChangeType_MODIFIED, newFileInfos[nI]
+				// A file that replaced a directory was added, and a file that was
+				// replaced by a directory was deleted
+				if oldFileInfos[oI].FileType == pfs.FileType_DIR {
+					change.Type = pfs.FileChangeType_ADDED
+				} else if newFileInfos[nI].FileType == pfs.FileType_DIR {
+					change.Type, change.FileInfo = pfs.FileChangeType_DELETED, oldFileInfos[oI]
+				}
+				nI++
+				oI++
+			}
+			if change.FileInfo.FileType == pfs.FileType_DIR || !match(change.FileInfo.File.Path) {
+				continue
+			}
+			if err := f(change); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
 func (d *driver) flushCommit(pachClient *client.APIClient, fromCommits []*pfs.Commit, toRepos []*pfs.Repo, f func(*pfs.CommitInfo) error) error {
 	if len(fromCommits) == 0 {
 		return errors.Errorf("fromCommits cannot be empty")
@@ -2236,11 +2964,6 @@ func (d *driver) deleteCommit(txnCtx *txnenv.TransactionContext, userCommit *pfs
 	if err := d.checkIsAuthorizedInTransaction(txnCtx, userCommit.Repo, auth.Scope_WRITER); err != nil {
 		return err
 	}
-	// Main txn: Delete all downstream commits, and update subvenance of upstream commits
-	// TODO update branches inside this txn, by storing a repo's branches in its
-	// RepoInfo or its HEAD commit
-	deleted := make(map[string]*pfs.CommitInfo) // deleted commits
-	affectedRepos := make(map[string]struct{})  // repos containing deleted commits
 
 	// 1) re-read CommitInfo inside txn
 	userCommitInfo, err := d.resolveCommit(txnCtx.Stm, userCommit)
@@ -2248,7 +2971,46 @@ func (d *driver) deleteCommit(txnCtx *txnenv.TransactionContext, userCommit *pfs
 		return errors.Wrapf(err, "resolveCommit")
 	}
 
-	// 2) Define helper for deleting commits. 'lower' corresponds to
+	// 2) Validate the commit (check that it has no provenance, and that
+	// neither its repo nor the repos of its subvenant commits are append-only)
+	if provenantOnInput(userCommitInfo.Provenance) {
+		return errors.Errorf("cannot delete the commit \"%s/%s\" because it has non-empty provenance", userCommit.Repo.Name, userCommit.ID)
+	}
+	checkedRepos := map[string]bool{}
+	repoNames := []string{userCommit.Repo.Name}
+	for _, subv := range userCommitInfo.Subvenance {
+		repoNames = append(repoNames, subv.Upper.Repo.Name)
+	}
+	for _, repo := range repoNames {
+		if checkedRepos[repo] {
+			continue
+		}
+		checkedRepos[repo] = true
+		repoInfo := &pfs.RepoInfo{}
+		if err := d.repos.ReadWrite(txnCtx.Stm).Get(repo, repoInfo); err != nil {
+			return err
+		}
+		if err := checkAppendOnly(repoInfo, "delete commits"); err != nil {
+			return err
+		}
+	}
+
+	// 3) Delete the commit and all of its downstream commits
+	ranges := []*pfs.CommitRange{{Lower: userCommitInfo.Commit, Upper: userCommitInfo.Commit}}
+	return d.deleteCommitRanges(txnCtx, append(ranges, userCommitInfo.Subvenance...))
+}
+
+// deleteCommitRanges deletes the commits in 'ranges' (each of which runs from
+// Upper back through its ancestors to Lower, inclusive), and repairs the
+// subvenance, parents, children, branches and tags that refer to them.
+func (d *driver) deleteCommitRanges(txnCtx *txnenv.TransactionContext, ranges []*pfs.CommitRange) error {
+	// Main txn: Delete all downstream commits, and update subvenance of upstream commits
+	// TODO update branches inside this txn, by storing a repo's branches in its
+	// RepoInfo or its HEAD commit
+	deleted := make(map[string]*pfs.CommitInfo) // deleted commits
+	affectedRepos := make(map[string]struct{})  // repos containing deleted commits
+
+	// 1) Define helper for deleting commits. 'lower' corresponds to
 	// pfs.CommitRange.Lower, and is an ancestor of 'upper'
 	deleteCommit := func(lower, upper *pfs.Commit) error {
 		// Validate arguments
@@ -2284,18 +3046,12 @@ func (d *driver) deleteCommit(txnCtx *txnenv.TransactionContext, userCommit *pfs
 		return nil
 	}
 
-	// 3) Validate the commit (check that it has no provenance) and delete it
-	if provenantOnInput(userCommitInfo.Provenance) {
-		return errors.Errorf("cannot delete the commit \"%s/%s\" because it has non-empty provenance", userCommit.Repo.Name, userCommit.ID)
-	}
-	deleteCommit(userCommitInfo.Commit, userCommitInfo.Commit)
-
-	// 4) Delete all of the downstream commits of 'commit'
-	for _, subv := range userCommitInfo.Subvenance {
-		deleteCommit(subv.Lower, subv.Upper)
+	// 2) Delete the commits in each range
+	for _, r := range ranges {
+		deleteCommit(r.Lower, r.Upper)
 	}
 
-	// 5) Remove the commits in 'deleted' from all remaining upstream commits'
+	// 3) Remove the commits in 'deleted' from all remaining upstream commits'
 	// subvenance.
 	// While 'commit' is required to be an input commit (no provenance),
 	// downstream commits from 'commit' may have multiple inputs, and those
@@ -2369,7 +3125,7 @@ func (d *driver) deleteCommit(txnCtx *txnenv.TransactionContext, userCommit *pfs
 		}
 	}
 
-	// 6) Rewrite ParentCommit of deleted commits' children, and
+	// 4) Rewrite ParentCommit of deleted commits' children, and
 	// ChildCommits of deleted commits' parents
 	visited = make(map[string]bool) // visited child/parent commits
 	for deletedID, deletedInfo := range deleted {
@@ -2445,7 +3201,7 @@ func (d *driver) deleteCommit(txnCtx *txnenv.TransactionContext, userCommit *pfs
 		}
 	}
 
-	// 7) Traverse affected repos and rewrite all branches so that no branch
+	// 5) Traverse affected repos and rewrite all branches so that no branch
 	// points to a deleted commit
 	var affectedBranches []*pfs.BranchInfo
 	repos := d.repos.ReadWrite(txnCtx.Stm)
@@ -2473,7 +3229,7 @@ func (d *driver) deleteCommit(txnCtx *txnenv.TransactionContext, userCommit *pfs
 				if prevHead != nil && prevHead.ID != branchInfo.Head.ID {
 					affectedBranches = append(affectedBranches, &branchInfo)
 				}
-				return err
+				return nil
 			}); err != nil && !col.IsErrNotFound(err) {
 				// If err is NotFound, branch is in downstream provenance but
 				// doesn't exist yet--nothing to update
@@ -2500,7 +3256,26 @@ func (d *driver) deleteCommit(txnCtx *txnenv.TransactionContext, userCommit *pfs
 		}
 	}
 
-	// 8) propagate the changes to 'branch' and its subvenance. This may start
+	// 6) Delete any tags that point to deleted commits
+	for repo := range affectedRepos {
+		var brokenTags []string
+		tagInfo := &pfs.CommitTagInfo{}
+		if err := d.commitTags(repo).ReadWrite(txnCtx.Stm).List(tagInfo, func(name string) error {
+			if _, ok := deleted[tagInfo.Commit.ID]; ok {
+				brokenTags = append(brokenTags, name)
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+		for _, name := range brokenTags {
+			if err := d.commitTags(repo).ReadWrite(txnCtx.Stm).Delete(name); err != nil && !col.IsErrNotFound(err) {
+				return errors.Wrapf(err, "error deleting tag %v/%v", repo, name)
+			}
+		}
+	}
+
+	// 7) propagate the changes to 'branch' and its subvenance. This may start
 	// new HEAD commits downstream, if the new branch heads haven't been
 	// processed yet
 	for _, afBranch := range affectedBranches {
@@ -2512,6 +3287,208 @@ func (d *driver) deleteCommit(txnCtx *txnenv.TransactionContext, userCommit *pfs
 	return nil
 }
 
+// revertCommit creates a new commit on 'branch' that undoes the changes made
+// in 'commit' (relative to its parent). If 'branch' is nil, the new commit is
+// created on the branch of 'commit'.
+func (d *driver) revertCommit(txnCtx *txnenv.TransactionContext, commit *pfs.Commit, branch *pfs.Branch, description string) (*pfs.Commit, error) {
+	return d.applyCommit(txnCtx, commit, branch, true, description)
+}
+
+// cherryPickCommit creates a new commit on 'branch' that re-applies the
+// changes made in 'commit' (relative to its parent).
+func (d *driver) cherryPickCommit(txnCtx *txnenv.TransactionContext, commit *pfs.Commit, branch *pfs.Branch, description string) (*pfs.Commit, error) {
+	if branch == nil {
+		return nil, errors.New("branch cannot be nil")
+	}
+	return d.applyCommit(txnCtx, commit, branch, false, description)
+}
+
+// applyCommit implements revertCommit and cherryPickCommit. The diff between
+// 'commit' and its parent is three-way merged into the head of 'branch', and
+// the result is written to a new commit on 'branch'.
+func (d *driver) applyCommit(txnCtx *txnenv.TransactionContext, commit *pfs.Commit, branch *pfs.Branch, revert bool, description string) (*pfs.Commit, error) {
+	// Validate arguments
+	if commit == nil {
+		return nil, errors.New("commit cannot be nil")
+	}
+	if commit.Repo == nil {
+		return nil, errors.New("commit repo cannot be nil")
+	}
+
+	if err := d.checkIsAuthorizedInTransaction(txnCtx, commit.Repo, auth.Scope_WRITER); err != nil {
+		return nil, err
+	}
+
+	commitInfo, err := d.resolveCommit(txnCtx.Stm, commit)
+	if err != nil {
+		return nil, err
+	}
+	if commitInfo.Finished == nil {
+		return nil, errors.Errorf("commit %s@%s has not been finished", commit.Repo.Name, commit.ID)
+	}
+	if branch == nil {
+		if commitInfo.Branch == nil {
+			return nil, errors.Errorf("commit %s@%s is not on a branch, a target branch must be specified", commit.Repo.Name, commit.ID)
+		}
+		branch = commitInfo.Branch
+	}
+	if branch.Repo == nil {
+		return nil, errors.New("branch repo cannot be nil")
+	}
+	if branch.Repo.Name != commit.Repo.Name {
+		return nil, errors.Errorf("cannot apply commit %s@%s to branch in a different repo (%s@%s)", commit.Repo.Name, commit.ID, branch.Repo.Name, branch.Name)
+	}
+
+	branchInfo := &pfs.BranchInfo{}
+	if err := d.branches(branch.Repo.Name).ReadWrite(txnCtx.Stm).Get(branch.Name, branchInfo); err != nil && !col.IsErrNotFound(err) {
+		return nil, err
+	}
+	if len(branchInfo.Provenance) > 0 {
+		return nil, errors.Errorf("cannot apply commit %s@%s to output branch %s", commit.Repo.Name, commit.ID, branch.Name)
+	}
+
+	// Reverting a commit applies the diff from the commit to its parent, while
+	// cherry-picking it applies the diff from its parent to the commit
+	theirs, base := commitInfo.Commit, commitInfo.ParentCommit
+	verb := "cherry-pick"
+	if revert {
+		repoInfo := &pfs.RepoInfo{}
+		if err := d.repos.ReadWrite(txnCtx.Stm).Get(branch.Repo.Name, repoInfo); err != nil {
+			return nil, err
+		}
+		if err := checkAppendOnly(repoInfo, "revert commits"); err != nil {
+			return nil, err
+		}
+		theirs, base = base, theirs
+		verb = "revert"
+	}
+	if description == "" {
+		description = fmt.Sprintf("%s commit %s", verb, commitInfo.Commit.ID)
+	}
+	newCommit, conflicts, err := d.commitThreeWayMerge(txnCtx, branch, branchInfo.Head, theirs, base, pfs.MergeStrategy_FAIL, description)
+	if err != nil {
+		return nil, err
+	}
+	if len(conflicts) > 0 {
+		return nil, pfsserver.ErrMergeConflict{To: branch, Paths: conflicts, Commit: commitInfo.Commit, Op: verb}
+	}
+	return newCommit, nil
+}
+
+// squashCommits collapses the linear range of finished commits from 'from' to
+// 'to' (inclusive) into a single commit. Since each commit's tree is a full
+// snapshot, 'to' already has the final tree, so the squash deletes the other
+// commits in the range, which points 'to' at the parent of 'from'. 'to' takes
+// the start time of 'from', and tags on the deleted commits are moved to 'to'.
+func (d *driver) squashCommits(txnCtx *txnenv.TransactionContext, from, to *pfs.Commit, description string) (*pfs.Commit, error) {
+	// Validate arguments
+	if from == nil || to == nil {
+		return nil, errors.New("from and to cannot be nil")
+	}
+	if from.Repo == nil || to.Repo == nil {
+		return nil, errors.New("commit repo cannot be nil")
+	}
+	if from.Repo.Name != to.Repo.Name {
+		return nil, errors.Errorf("cannot squash commits in different repos (%s and %s)", from.Repo.Name, to.Repo.Name)
+	}
+	repo := to.Repo.Name
+
+	if err := d.checkIsAuthorizedInTransaction(txnCtx, to.Repo, auth.Scope_WRITER); err != nil {
+		return nil, err
+	}
+
+	fromInfo, err := d.resolveCommit(txnCtx.Stm, from)
+	if err != nil {
+		return nil, err
+	}
+	toInfo, err := d.resolveCommit(txnCtx.Stm, to)
+	if err != nil {
+		return nil, err
+	}
+	heads, err := d.branchHeads(txnCtx, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	// Collect the commits to delete (every commit in the range but 'to'),
+	// checking that the range is linear and finished
+	var squashed []*pfs.CommitInfo
+	commitInfo := toInfo
+	for {
+		if commitInfo.Finished == nil {
+			return nil, errors.Errorf("cannot squash commit %s@%s because it has not been finished", repo, commitInfo.Commit.ID)
+		}
+		if provenantOnInput(commitInfo.Provenance) {
+			return nil, errors.Errorf("cannot squash commit %s@%s because it has non-empty provenance", repo, commitInfo.Commit.ID)
+		}
+		if commitInfo != toInfo {
+			if commitInfo.MergeParent != nil {
+				return nil, errors.Errorf("cannot squash merge commit %s@%s", repo, commitInfo.Commit.ID)
+			}
+			if len(commitInfo.ChildCommits) != 1 {
+				return nil, errors.Errorf("cannot squash commit %s@%s because it has %d children", repo, commitInfo.Commit.ID, len(commitInfo.ChildCommits))
+			}
+			if heads[commitInfo.Commit.ID] {
+				return nil, errors.Errorf("cannot squash commit %s@%s because it is the head of a branch", repo, commitInfo.Commit.ID)
+			}
+			squashed = append(squashed, commitInfo)
+		}
+		if commitInfo.Commit.ID == fromInfo.Commit.ID {
+			break
+		}
+		if commitInfo.ParentCommit == nil {
+			return nil, errors.Errorf("commit %s@%s is not an ancestor of %s", repo, fromInfo.Commit.ID, toInfo.Commit.ID)
+		}
+		parent := commitInfo.ParentCommit
+		commitInfo = &pfs.CommitInfo{}
+		if err := d.commits(repo).ReadWrite(txnCtx.Stm).Get(parent.ID, commitInfo); err != nil {
+			return nil, err
+		}
+	}
+	if len(squashed) == 0 {
+		return toInfo.Commit, nil // nothing to squash
+	}
+
+	// Find the tags that point to squashed commits before they're deleted
+	isSquashed := make(map[string]bool)
+	for _, squashedInfo := range squashed {
+		isSquashed[squashedInfo.Commit.ID] = true
+	}
+	var tagInfos []*pfs.CommitTagInfo
+	tagInfo := &pfs.CommitTagInfo{}
+	if err := d.commitTags(repo).ReadWrite(txnCtx.Stm).List(tagInfo, func(string) error {
+		if isSquashed[tagInfo.Commit.ID] {
+			tagInfos = append(tagInfos, proto.Clone(tagInfo).(*pfs.CommitTagInfo))
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	// Delete the squashed commits, oldest first
+	for i := len(squashed) - 1; i >= 0; i-- {
+		if err := d.deleteCommit(txnCtx, squashed[i].Commit); err != nil {
+			return nil, err
+		}
+	}
+	for _, tagInfo := range tagInfos {
+		tagInfo.Commit = toInfo.Commit
+		if err := d.commitTags(repo).ReadWrite(txnCtx.Stm).Put(tagInfo.Tag.Name, tagInfo); err != nil {
+			return nil, err
+		}
+	}
+	if err := d.commits(repo).ReadWrite(txnCtx.Stm).Update(toInfo.Commit.ID, toInfo, func() error {
+		toInfo.Started = fromInfo.Started
+		if description != "" {
+			toInfo.Description = description
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return toInfo.Commit, nil
+}
+
 // resolveCommitProvenance resolves a user 'commit' (which may be a commit ID or
 // branch reference) to a commit + branch pair interpreted as commit provenance.
 // If a complete commit provenance is passed in it just uses that.
@@ -2563,6 +3540,9 @@ func (d *driver) createBranch(txnCtx *txnenv.TransactionContext, branch *pfs.Bra
 	if err := ancestry.ValidateName(branch.Name); err != nil {
 		return err
 	}
+	if err := d.checkNotCommitTag(txnCtx.Stm, branch.Repo, branch.Name); err != nil {
+		return err
+	}
 	// The request must do exactly one of:
 	// 1) updating 'branch's provenance (commit is nil OR commit == branch)
 	// 2) re-pointing 'branch' at a new commit
@@ -2829,22 +3809,448 @@ func (d *driver) deleteBranch(txnCtx *txnenv.TransactionContext, branch *pfs.Bra
 	return nil
 }
 
-// scratchCommitPrefix returns an etcd prefix that's used to temporarily
-// store the state of a file in an open commit.  Once the commit is finished,
-// the scratch space is removed.
-func (d *driver) scratchCommitPrefix(commit *pfs.Commit) string {
-	return path.Join(commit.Repo.Name, commit.ID)
-}
+func (d *driver) renameBranch(txnCtx *txnenv.TransactionContext, branch *pfs.Branch, newName string) error {
+	// Validate arguments
+	if branch == nil {
+		return errors.New("branch cannot be nil")
+	}
+	if branch.Repo == nil {
+		return errors.New("branch repo cannot be nil")
+	}
+	if branch.Repo.Name == ppsconsts.SpecRepo {
+		return errors.Errorf("cannot rename branches in the special PPS repo %s", ppsconsts.SpecRepo)
+	}
 
-func (d *driver) checkFilePath(path string) error {
-	path = filepath.Clean(path)
-	if strings.HasPrefix(path, "../") {
-		return errors.Errorf("path (%s) invalid: traverses above root", path)
+	if err := d.checkIsAuthorizedInTransaction(txnCtx, branch.Repo, auth.Scope_WRITER); err != nil {
+		return err
+	}
+	// Validate request
+	if err := ancestry.ValidateName(newName); err != nil {
+		return err
+	}
+	if err := d.checkNotCommitTag(txnCtx.Stm, branch.Repo, newName); err != nil {
+		return err
 	}
-	return nil
-}
 
-// scratchFilePrefix returns an etcd prefix that's used to temporarily
+	branches := d.branches(branch.Repo.Name).ReadWrite(txnCtx.Stm)
+	branchInfo := &pfs.BranchInfo{}
+	if err := branches.Get(branch.Name, branchInfo); err != nil {
+		return errors.Wrapf(err, "error getting branch %s", branch.Name)
+	}
+	if err := branches.Get(newName, &pfs.BranchInfo{}); err == nil {
+		return errors.Errorf("cannot rename branch %q to %q as %q already exists", branch.Name, newName, newName)
+	} else if !col.IsErrNotFound(err) {
+		return errors.Wrapf(err, "error checking whether \"%s\" exists", newName)
+	}
+	for _, b := range branchInfo.Provenance {
+		if b.Repo.Name == ppsconsts.SpecRepo {
+			return errors.Errorf("cannot rename branch %s as it's the output of pipeline %s", branch.Name, b.Name)
+		}
+	}
+	newBranch := client.NewBranch(branch.Repo.Name, newName)
+	rename := func(branches []*pfs.Branch) bool {
+		var renamed bool
+		for _, b := range branches {
+			if b.Repo.Name == branch.Repo.Name && b.Name == branch.Name {
+				b.Name = newName
+				renamed = true
+			}
+		}
+		return renamed
+	}
+
+	// Move the branch itself
+	if err := branches.Delete(branch.Name); err != nil {
+		return errors.Wrapf(err, "branches.Delete")
+	}
+	branchInfo.Branch = newBranch
+	if err := branches.Create(newName, branchInfo); err != nil {
+		return errors.Wrapf(err, "branches.Create")
+	}
+	repoInfo := &pfs.RepoInfo{}
+	if err := d.repos.ReadWrite(txnCtx.Stm).Update(branch.Repo.Name, repoInfo, func() error {
+		rename(repoInfo.Branches)
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	// Fix the branches on either side of it, whose provenance or subvenance
+	// refers to it
+	for _, provBranch := range branchInfo.Provenance {
+		provBranchInfo := &pfs.BranchInfo{}
+		if err := d.branches(provBranch.Repo.Name).ReadWrite(txnCtx.Stm).Update(provBranch.Name, provBranchInfo, func() error {
+			rename(provBranchInfo.Subvenance)
+			return nil
+		}); err != nil && !isNotFoundErr(err) {
+			return errors.Wrapf(err, "error updating subvenance")
+		}
+	}
+	for _, subvBranch := range branchInfo.Subvenance {
+		subvBranchInfo := &pfs.BranchInfo{}
+		if err := d.branches(subvBranch.Repo.Name).ReadWrite(txnCtx.Stm).Update(subvBranch.Name, subvBranchInfo, func() error {
+			rename(subvBranchInfo.Provenance)
+			rename(subvBranchInfo.DirectProvenance)
+			return nil
+		}); err != nil && !isNotFoundErr(err) {
+			return errors.Wrapf(err, "error updating provenance")
+		}
+	}
+
+	// Fix the commits that were made on the branch, and the downstream commits
+	// that record it as the branch of their provenance
+	repos := map[string]bool{branch.Repo.Name: true}
+	for _, subvBranch := range branchInfo.Subvenance {
+		repos[subvBranch.Repo.Name] = true
+	}
+	for repo := range repos {
+		var commitIDs []string
+		commitInfo := &pfs.CommitInfo{}
+		if err := d.commits(repo).ReadWrite(txnCtx.Stm).List(commitInfo, func(id string) error {
+			if rename(commitInfoBranches(commitInfo)) {
+				commitIDs = append(commitIDs, id)
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+		for _, id := range commitIDs {
+			if err := d.commits(repo).ReadWrite(txnCtx.Stm).Update(id, commitInfo, func() error {
+				rename(commitInfoBranches(commitInfo))
+				return nil
+			}); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Finally, point any pipelines that read from 'branch' at 'newName'
+	return d.updatePipelineInputs(txnCtx, func(input *pps.Input) bool {
+		if input.Pfs == nil || input.Pfs.Repo != branch.Repo.Name || input.Pfs.Branch != branch.Name {
+			return false
+		}
+		input.Pfs.Branch = newName
+		return true
+	})
+}
+
+// commitInfoBranches returns every branch referenced by 'commitInfo'
+func commitInfoBranches(commitInfo *pfs.CommitInfo) []*pfs.Branch {
+	var branches []*pfs.Branch
+	if commitInfo.Branch != nil {
+		branches = append(branches, commitInfo.Branch)
+	}
+	for _, prov := range commitInfo.Provenance {
+		if prov.Branch != nil {
+			branches = append(branches, prov.Branch)
+		}
+	}
+	return branches
+}
+
+// mergeBranch merges the head of 'from' into 'to'. The changes made on 'from'
+// since the two branches diverged are applied to the head of 'to', and the
+// result is written to a new commit on 'to' whose parents are the heads of
+// both branches.
+func (d *driver) mergeBranch(txnCtx *txnenv.TransactionContext, from *pfs.Branch, to *pfs.Branch, strategy pfs.MergeStrategy, description string) (*pfs.Commit, error) {
+	// Validate arguments
+	if from == nil || to == nil {
+		return nil, errors.New("branch cannot be nil")
+	}
+	if from.Repo == nil || to.Repo == nil {
+		return nil, errors.New("branch repo cannot be nil")
+	}
+	if from.Repo.Name != to.Repo.Name {
+		return nil, errors.Errorf("cannot merge branch %s@%s into branch in a different repo (%s@%s)", from.Repo.Name, from.Name, to.Repo.Name, to.Name)
+	}
+	if from.Name == to.Name {
+		return nil, errors.Errorf("cannot merge branch %s into itself", from.Name)
+	}
+
+	if err := d.checkIsAuthorizedInTransaction(txnCtx, to.Repo, auth.Scope_WRITER); err != nil {
+		return nil, err
+	}
+
+	fromInfo, err := d.inspectBranch(txnCtx, from)
+	if err != nil {
+		return nil, err
+	}
+	if fromInfo.Head == nil {
+		return nil, pfsserver.ErrNoHead{from}
+	}
+	toInfo := &pfs.BranchInfo{}
+	if err := d.branches(to.Repo.Name).ReadWrite(txnCtx.Stm).Get(to.Name, toInfo); err != nil && !col.IsErrNotFound(err) {
+		return nil, err
+	}
+	if len(toInfo.Provenance) > 0 {
+		return nil, errors.Errorf("cannot merge into output branch %s", to.Name)
+	}
+
+	var base *pfs.Commit
+	if toInfo.Head != nil {
+		base, err = d.mergeBase(txnCtx.Stm, toInfo.Head, fromInfo.Head)
+		if err != nil {
+			return nil, err
+		}
+		if base != nil && base.ID == fromInfo.Head.ID {
+			// 'from' has already been merged into 'to', nothing to do
+			return toInfo.Head, nil
+		}
+	}
+
+	commit, conflicts, err := d.commitThreeWayMerge(txnCtx, to, toInfo.Head, fromInfo.Head, base, strategy, description)
+	if err != nil {
+		return nil, err
+	}
+	if len(conflicts) > 0 {
+		return nil, pfsserver.ErrMergeConflict{From: from, To: to, Paths: conflicts}
+	}
+	commitInfo := &pfs.CommitInfo{}
+	if err := d.commits(to.Repo.Name).ReadWrite(txnCtx.Stm).Update(commit.ID, commitInfo, func() error {
+		commitInfo.MergeParent = fromInfo.Head
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return commit, nil
+}
+
+// commitThreeWayMerge applies the changes between 'base' and 'theirs' to
+// 'head' (which may be nil) and writes the result to a new commit on 'branch'.
+// If any paths conflict, no commit is created and the conflicting paths are
+// returned instead.
+func (d *driver) commitThreeWayMerge(txnCtx *txnenv.TransactionContext, branch *pfs.Branch, head *pfs.Commit, theirs *pfs.Commit, base *pfs.Commit, strategy pfs.MergeStrategy, description string) (*pfs.Commit, []string, error) {
+	ourTree, err := d.getTreeForCommit(txnCtx, head)
+	if err != nil {
+		return nil, nil, err
+	}
+	tree, err := ourTree.Copy()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer destroyHashtree(tree)
+	theirTree, err := d.getTreeForCommit(txnCtx, theirs)
+	if err != nil {
+		return nil, nil, err
+	}
+	baseTree, err := d.getTreeForCommit(txnCtx, base)
+	if err != nil {
+		return nil, nil, err
+	}
+	conflicts, err := hashtree.ThreeWayMerge(tree, theirTree, baseTree, strategy)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(conflicts) > 0 {
+		return nil, conflicts, nil
+	}
+	treeRef, err := hashtree.PutHashTree(txnCtx.Client, tree)
+	if err != nil {
+		return nil, nil, err
+	}
+	commit, err := d.makeCommit(txnCtx, "", client.NewCommit(branch.Repo.Name, ""), branch.Name, nil, nil, treeRef, nil, nil, nil, nil, description, nil, time.Time{}, time.Time{}, uint64(tree.FSSize()))
+	if err != nil {
+		return nil, nil, err
+	}
+	return commit, nil, nil
+}
+
+// mergeBase returns the nearest common ancestor of 'ours' and 'theirs',
+// following both parents of merge commits, or nil if the two commits share no
+// history.
+func (d *driver) mergeBase(stm col.STM, ours *pfs.Commit, theirs *pfs.Commit) (*pfs.Commit, error) {
+	commits := d.commits(ours.Repo.Name).ReadWrite(stm)
+	// walk calls f on 'commit' and all of its ancestors in breadth-first order,
+	// stopping early if f returns false
+	walk := func(commit *pfs.Commit, f func(*pfs.Commit) bool) error {
+		visited := make(map[string]bool)
+		queue := []*pfs.Commit{commit}
+		for len(queue) > 0 {
+			commit, queue = queue[0], queue[1:]
+			if visited[commit.ID] {
+				continue
+			}
+			visited[commit.ID] = true
+			if !f(commit) {
+				return nil
+			}
+			commitInfo := &pfs.CommitInfo{}
+			if err := commits.Get(commit.ID, commitInfo); err != nil {
+				if col.IsErrNotFound(err) {
+					// the commit was deleted after it was merged
+					continue
+				}
+				return err
+			}
+			if commitInfo.ParentCommit != nil {
+				queue = append(queue, commitInfo.ParentCommit)
+			}
+			if commitInfo.MergeParent != nil {
+				queue = append(queue, commitInfo.MergeParent)
+			}
+		}
+		return nil
+	}
+	ourAncestors := make(map[string]bool)
+	if err := walk(ours, func(commit *pfs.Commit) bool {
+		ourAncestors[commit.ID] = true
+		return true
+	}); err != nil {
+		return nil, err
+	}
+	var base *pfs.Commit
+	if err := walk(theirs, func(commit *pfs.Commit) bool {
+		if ourAncestors[commit.ID] {
+			base = commit
+			return false
+		}
+		return true
+	}); err != nil {
+		return nil, err
+	}
+	return base, nil
+}
+
+// createCommitTag creates the tag 'tag', pointing at 'commit'. Tags are
+// immutable, so this fails if 'tag' already exists.
+func (d *driver) createCommitTag(txnCtx *txnenv.TransactionContext, tag *pfs.CommitTag, commit *pfs.Commit) error {
+	// Validate arguments
+	if tag == nil {
+		return errors.New("tag cannot be nil")
+	}
+	if tag.Repo == nil {
+		return errors.New("tag repo cannot be nil")
+	}
+	if commit == nil {
+		return errors.New("commit cannot be nil")
+	}
+	if commit.Repo == nil {
+		commit.Repo = tag.Repo
+	}
+	if commit.Repo.Name != tag.Repo.Name {
+		return errors.Errorf("cannot tag commit %s@%s in a different repo (%s)", commit.Repo.Name, commit.ID, tag.Repo.Name)
+	}
+
+	if err := d.checkIsAuthorizedInTransaction(txnCtx, tag.Repo, auth.Scope_WRITER); err != nil {
+		return err
+	}
+	if err := ancestry.ValidateTagName(tag.Name); err != nil {
+		return err
+	}
+	// Branches and tags share a namespace, so that commit references aren't
+	// ambiguous
+	if err := d.branches(tag.Repo.Name).ReadWrite(txnCtx.Stm).Get(tag.Name, &pfs.BranchInfo{}); err == nil {
+		return errors.Errorf("cannot create tag %q, a branch with that name already exists in repo %s", tag.Name, tag.Repo.Name)
+	} else if !col.IsErrNotFound(err) {
+		return err
+	}
+	commitInfo, err := d.resolveCommit(txnCtx.Stm, commit)
+	if err != nil {
+		return err
+	}
+	return d.commitTags(tag.Repo.Name).ReadWrite(txnCtx.Stm).Create(tag.Name, &pfs.CommitTagInfo{
+		Tag:     tag,
+		Commit:  commitInfo.Commit,
+		Created: types.TimestampNow(),
+	})
+}
+
+func (d *driver) inspectCommitTag(txnCtx *txnenv.TransactionContext, tag *pfs.CommitTag) (*pfs.CommitTagInfo, error) {
+	// Validate arguments
+	if tag == nil {
+		return nil, errors.New("tag cannot be nil")
+	}
+	if tag.Repo == nil {
+		return nil, errors.New("tag repo cannot be nil")
+	}
+
+	result := &pfs.CommitTagInfo{}
+	if err := d.commitTags(tag.Repo.Name).ReadWrite(txnCtx.Stm).Get(tag.Name, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (d *driver) listCommitTag(pachClient *client.APIClient, repo *pfs.Repo) ([]*pfs.CommitTagInfo, error) {
+	// Validate arguments
+	if repo == nil {
+		return nil, errors.New("repo cannot be nil")
+	}
+
+	if err := d.checkIsAuthorized(pachClient, repo, auth.Scope_READER); err != nil {
+		return nil, err
+	}
+
+	// Make sure that the repo exists
+	if repo.Name != "" {
+		err := d.txnEnv.WithReadContext(pachClient.Ctx(), func(txnCtx *txnenv.TransactionContext) error {
+			_, err := d.inspectRepo(txnCtx, repo, !includeAuth)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var result []*pfs.CommitTagInfo
+	tagInfo := &pfs.CommitTagInfo{}
+	if err := d.commitTags(repo.Name).ReadOnly(pachClient.Ctx()).List(tagInfo, col.DefaultOptions, func(string) error {
+		result = append(result, proto.Clone(tagInfo).(*pfs.CommitTagInfo))
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (d *driver) deleteCommitTag(txnCtx *txnenv.TransactionContext, tag *pfs.CommitTag) error {
+	// Validate arguments
+	if tag == nil {
+		return errors.New("tag cannot be nil")
+	}
+	if tag.Repo == nil {
+		return errors.New("tag repo cannot be nil")
+	}
+
+	if err := d.checkIsAuthorizedInTransaction(txnCtx, tag.Repo, auth.Scope_WRITER); err != nil {
+		return err
+	}
+	return d.commitTags(tag.Repo.Name).ReadWrite(txnCtx.Stm).Delete(tag.Name)
+}
+
+// scratchCommitPrefix returns an etcd prefix that's used to temporarily
+// store the state of a file in an open commit.  Once the commit is finished,
+// the scratch space is removed.
+func (d *driver) scratchCommitPrefix(commit *pfs.Commit) string {
+	return path.Join(commit.Repo.Name, commit.ID)
+}
+
+func (d *driver) checkFilePath(path string) error {
+	path = filepath.Clean(path)
+	if strings.HasPrefix(path, "../") {
+		return errors.Errorf("path (%s) invalid: traverses above root", path)
+	}
+	return nil
+}
+
+// checkSymlinkTarget returns an error if 'target' isn't a valid target for a
+// symlink at 'linkPath'. Relative targets may not climb above the root of the
+// commit, as the links would point outside of it when they're downloaded.
+func checkSymlinkTarget(linkPath, target string) error {
+	if err := hashtree.ValidatePath(target); err != nil {
+		return err
+	}
+	if path.IsAbs(target) {
+		return nil
+	}
+	linkDir := strings.TrimPrefix(path.Dir(path.Clean("/"+linkPath)), "/")
+	if resolved := path.Join(linkDir, target); resolved == ".." || strings.HasPrefix(resolved, "../") {
+		return errors.Errorf("symlink target (%s) invalid: traverses above root", target)
+	}
+	return nil
+}
+
+// scratchFilePrefix returns an etcd prefix that's used to temporarily
 // store the state of a file in an open commit.  Once the commit is finished,
 // the scratch space is removed.
 func (d *driver) scratchFilePrefix(file *pfs.File) (string, error) {
@@ -2860,9 +4266,9 @@ func (d *driver) putFiles(pachClient *client.APIClient, s *putFileServer) error
 	var putFilePaths []string
 	var putFileRecords []*pfs.PutFileRecords
 	var mu sync.Mutex
-	oneOff, repo, branch, err := d.forEachPutFile(pachClient, s, func(req *pfs.PutFileRequest, r io.Reader) error {
-		records, err := d.putFile(pachClient, req.File, req.Delimiter, req.TargetFileDatums,
-			req.TargetFileBytes, req.HeaderRecords, req.OverwriteIndex, req.Delete, r)
+	put := func(req *pfs.PutFileRequest, r io.Reader) error {
+		records, err := d.putFile(pachClient, req.File, req.Delimiter, req.DelimiterRegex, req.TargetFileDatums,
+			req.TargetFileBytes, req.HeaderRecords, req.OverwriteIndex, req.Delete, req.Metadata, req.Symlink, r)
 		if err != nil {
 			return err
 		}
@@ -2872,6 +4278,12 @@ func (d *driver) putFiles(pachClient *client.APIClient, s *putFileServer) error
 		putFilePaths = append(putFilePaths, req.File.Path)
 		putFileRecords = append(putFileRecords, records)
 		return nil
+	}
+	oneOff, repo, branch, err := d.forEachPutFile(pachClient, s, func(req *pfs.PutFileRequest, r io.Reader) error {
+		if req.Unpack != pfs.ArchiveFormat_RAW {
+			return d.unpackFile(req, r, put)
+		}
+		return put(req, r)
 	})
 	if err != nil {
 		return err
@@ -2883,7 +4295,7 @@ func (d *driver) putFiles(pachClient *client.APIClient, s *putFileServer) error
 		// a commit with no ID, that ID will be filled in with the head of
 		// branch (if it exists).
 		return d.txnEnv.WithWriteContext(ctx, func(txnCtx *txnenv.TransactionContext) error {
-			_, err := d.makeCommit(txnCtx, "", client.NewCommit(repo, ""), branch, nil, nil, nil, nil, nil, putFilePaths, putFileRecords, "", time.Time{}, time.Time{}, 0)
+			_, err := d.makeCommit(txnCtx, "", client.NewCommit(repo, ""), branch, nil, nil, nil, nil, nil, putFilePaths, putFileRecords, "", nil, time.Time{}, time.Time{}, 0)
 			return err
 		})
 	}
@@ -2895,17 +4307,48 @@ func (d *driver) putFiles(pachClient *client.APIClient, s *putFileServer) error
 	return nil
 }
 
-func (d *driver) putFile(pachClient *client.APIClient, file *pfs.File, delimiter pfs.Delimiter,
+func (d *driver) putFile(pachClient *client.APIClient, file *pfs.File, delimiter pfs.Delimiter, delimiterRegex string,
 	targetFileDatums, targetFileBytes, headerRecords int64, overwriteIndex *pfs.OverwriteIndex,
-	del bool, reader io.Reader) (*pfs.PutFileRecords, error) {
+	del bool, metadata map[string]string, symlink string, reader io.Reader) (_ *pfs.PutFileRecords, retErr error) {
 	if err := d.checkIsAuthorized(pachClient, file.Commit.Repo, auth.Scope_WRITER); err != nil {
 		return nil, err
 	}
+	// The repo's size quota is checked against the size of the data's commit
+	// (or its parent) plus the new data as it streams in, so a write that would
+	// clearly exceed the quota fails before it's stored. finishCommit checks
+	// the final size.
+	repoInfo := &pfs.RepoInfo{}
+	if err := d.repos.ReadOnly(pachClient.Ctx()).Get(file.Commit.Repo.Name, repoInfo); err != nil {
+		return nil, err
+	}
+	var baseSize uint64
+	if repoInfo.Quota != nil && repoInfo.Quota.SizeBytes != 0 && !del {
+		var err error
+		if baseSize, err = d.quotaBaseSize(pachClient, file.Commit); err != nil {
+			return nil, err
+		}
+		if err := checkSizeQuota(repoInfo, baseSize); err != nil {
+			return nil, err
+		}
+		reader = &quotaReader{r: reader, repoInfo: repoInfo, size: baseSize}
+	}
+	if del {
+		if err := checkAppendOnly(repoInfo, "delete files"); err != nil {
+			return nil, err
+		}
+	} else if overwriteIndex != nil {
+		if err := checkAppendOnly(repoInfo, "overwrite files"); err != nil {
+			return nil, err
+		}
+	}
 	//  validation -- make sure the various putFileSplit options are coherent
 	hasPutFileOptions := targetFileBytes != 0 || targetFileDatums != 0 || headerRecords != 0
 	if hasPutFileOptions && delimiter == pfs.Delimiter_NONE {
 		return nil, errors.Errorf("cannot set split options--targetFileBytes, targetFileDatums, or headerRecords--with delimiter == NONE, split disabled")
 	}
+	if (delimiter == pfs.Delimiter_REGEX) != (delimiterRegex != "") {
+		return nil, errors.Errorf("a delimiter regex must be set if and only if the delimiter is REGEX")
+	}
 	records := &pfs.PutFileRecords{}
 	if del {
 		records.Tombstone = true
@@ -2914,12 +4357,23 @@ func (d *driver) putFile(pachClient *client.APIClient, file *pfs.File, delimiter
 	if overwriteIndex != nil && overwriteIndex.Index == 0 {
 		records.Tombstone = true
 	}
+	records.Metadata = metadata
 	if err := d.checkFilePath(file.Path); err != nil {
 		return nil, err
 	}
 	if err := hashtree.ValidatePath(file.Path); err != nil {
 		return nil, err
 	}
+	if symlink != "" {
+		if delimiter != pfs.Delimiter_NONE || metadata != nil {
+			return nil, errors.Errorf("cannot set a delimiter or metadata when putting a symlink")
+		}
+		if err := checkSymlinkTarget(file.Path, symlink); err != nil {
+			return nil, err
+		}
+		records.SymlinkTarget = symlink
+		return records, nil
+	}
 
 	if delimiter == pfs.Delimiter_NONE {
 		d.putObjectLimiter.Acquire()
@@ -2928,27 +4382,10 @@ func (d *driver) putFile(pachClient *client.APIClient, file *pfs.File, delimiter
 		if err != nil {
 			return nil, err
 		}
-
-		// Here we use the invariant that every one but the last object
-		// should have a size of ChunkSize.
-		for i, object := range objects {
-			record := &pfs.PutFileRecord{
-				ObjectHash: object.Hash,
-			}
-
-			if size > pfs.ChunkSize {
-				record.SizeBytes = pfs.ChunkSize
-			} else {
-				record.SizeBytes = size
-			}
-			size -= pfs.ChunkSize
-
-			// The first record takes care of the overwriting
-			if i == 0 && overwriteIndex != nil && overwriteIndex.Index != 0 {
-				record.OverwriteIndex = overwriteIndex
-			}
-
-			records.Records = append(records.Records, record)
+		records.Records = objectRecords(objects, size)
+		// The first record takes care of the overwriting
+		if len(records.Records) > 0 && overwriteIndex != nil && overwriteIndex.Index != 0 {
+			records.Records[0].OverwriteIndex = overwriteIndex
 		}
 	} else {
 		var (
@@ -2959,16 +4396,17 @@ func (d *driver) putFile(pachClient *client.APIClient, file *pfs.File, delimiter
 			// Note: this code generally distinguishes between nil header/footer (no
 			// header) and empty header/footer. To create a header-enabled directory
 			// with an empty header, allocate an empty slice & store it here
-			header    []byte
-			footer    []byte
-			EOF       = false
-			eg        errgroup.Group
-			bufioR    = bufio.NewReader(reader)
-			decoder   = json.NewDecoder(bufioR)
-			sqlReader = sql.NewPGDumpReader(bufioR)
-			csvReader = csv.NewReader(bufioR)
-			csvBuffer bytes.Buffer
-			csvWriter = csv.NewWriter(&csvBuffer)
+			header     []byte
+			footer     []byte
+			EOF        = false
+			eg         errgroup.Group
+			bufioR     = bufio.NewReader(reader)
+			decoder    = json.NewDecoder(bufioR)
+			sqlReader  = sql.NewPGDumpReader(bufioR)
+			csvReader  = csv.NewReader(bufioR)
+			csvBuffer  bytes.Buffer
+			csvWriter  = csv.NewWriter(&csvBuffer)
+			avroReader = avro.NewReader(bufioR)
 			// indexToRecord serves as a de-facto slice of PutFileRecords. We can't
 			// use a real slice of PutFileRecords b/c indexToRecord has data appended
 			// to it by concurrent processes, and you can't append() to a slice
@@ -2979,10 +4417,50 @@ func (d *driver) putFile(pachClient *client.APIClient, file *pfs.File, delimiter
 		)
 		csvReader.FieldsPerRecord = -1 // ignore unexpected # of fields, for now
 		csvReader.ReuseRecord = true   // returned rows are written to buffer immediately
+		if (delimiter == pfs.Delimiter_PARQUET || delimiter == pfs.Delimiter_AVRO) && headerRecords != 0 {
+			return nil, errors.Errorf("cannot set headerRecords with delimiter %s, its header is found automatically", delimiter)
+		}
+		var (
+			recordRegex *regexp.Regexp
+			nextRecord  []byte // the first line of the next record, if delimiter == REGEX
+			tsvFields   int    // the number of fields in each record, if delimiter == TSV
+		)
+		if delimiter == pfs.Delimiter_REGEX {
+			var err error
+			if recordRegex, err = regexp.Compile(delimiterRegex); err != nil {
+				return nil, errors.Wrapf(err, "invalid delimiter regex")
+			}
+		}
+		var parquetSplitter *parquet.Splitter
+		if delimiter == pfs.Delimiter_PARQUET {
+			// A parquet file's metadata is at its end, so the file is spooled to
+			// disk before it's split
+			tmp, err := ioutil.TempFile("", "pachyderm-parquet-")
+			if err != nil {
+				return nil, err
+			}
+			defer func() {
+				if err := tmp.Close(); err != nil && retErr == nil {
+					retErr = err
+				}
+				if err := os.Remove(tmp.Name()); err != nil && retErr == nil {
+					retErr = err
+				}
+			}()
+			size, err := io.Copy(tmp, bufioR)
+			if err != nil {
+				return nil, err
+			}
+			parquetSplitter, err = parquet.NewSplitter(tmp, size, targetFileDatums, targetFileBytes)
+			if err != nil {
+				return nil, err
+			}
+		}
 		for !EOF {
 			var err error
 			var value []byte
 			var csvRow []string // only used if delimiter == CSV
+			datums := int64(1)  // the number of datums in 'value'
 			switch delimiter {
 			case pfs.Delimiter_JSON:
 				var jsonValue json.RawMessage
@@ -3013,6 +4491,41 @@ func (d *driver) putFile(pachClient *client.APIClient, file *pfs.File, delimiter
 					}
 					value = csvBuffer.Bytes()
 				}
+			case pfs.Delimiter_TSV:
+				value, err = bufioR.ReadBytes('\n')
+				if len(value) > 0 {
+					fields := bytes.Count(value, []byte{'\t'}) + 1
+					if tsvFields == 0 {
+						tsvFields = fields
+					} else if fields != tsvFields {
+						return nil, errors.Errorf("invalid tsv record with %d fields; the first record has %d", fields, tsvFields)
+					}
+				}
+			case pfs.Delimiter_REGEX:
+				// Read lines until the next one that matches recordRegex, which
+				// is the first line of the next record
+				value, nextRecord = nextRecord, nil
+				for {
+					var line []byte
+					line, err = bufioR.ReadBytes('\n')
+					if len(value) > 0 && len(line) > 0 && recordRegex.Match(bytes.TrimRight(line, "\r\n")) {
+						// If this is the last line, the record it starts is still to
+						// be written, so EOF is only returned after it
+						nextRecord, err = line, nil
+						break
+					}
+					value = append(value, line...)
+					if err != nil {
+						break
+					}
+				}
+			case pfs.Delimiter_AVRO:
+				value, datums, err = avroReader.ReadBlock()
+				if header == nil {
+					header = avroReader.Header
+				}
+			case pfs.Delimiter_PARQUET:
+				value, datums, err = parquetSplitter.Next()
 			default:
 				return nil, errors.Errorf("unrecognized delimiter %s", delimiter.String())
 			}
@@ -3025,16 +4538,18 @@ func (d *driver) putFile(pachClient *client.APIClient, file *pfs.File, delimiter
 			}
 			buffer.Write(value)
 			bytesWritten += int64(len(value))
-			datumsWritten++
+			datumsWritten += datums
 			var (
 				headerDone         = headerRecords == 0 || header != nil
 				headerReady        = !headerDone && datumsWritten >= headerRecords
 				hitFileBytesLimit  = headerDone && targetFileBytes != 0 && bytesWritten >= targetFileBytes
 				hitFileDatumsLimit = headerDone && targetFileDatums != 0 && datumsWritten >= targetFileDatums
 				noLimitsSet        = headerDone && targetFileBytes == 0 && targetFileDatums == 0
+				// each value read from a parquet file is a whole split file
+				isWholeFile = delimiter == pfs.Delimiter_PARQUET
 			)
 			if buffer.Len() != 0 &&
-				(headerReady || hitFileBytesLimit || hitFileDatumsLimit || noLimitsSet || EOF) {
+				(headerReady || hitFileBytesLimit || hitFileDatumsLimit || noLimitsSet || isWholeFile || EOF) {
 				_buffer := buffer
 				if !headerDone /* implies headerReady || EOF */ {
 					header = _buffer.Bytes() // record header
@@ -3104,6 +4619,9 @@ func (d *driver) putFile(pachClient *client.APIClient, file *pfs.File, delimiter
 			return nil, err
 		}
 	}
+	if err := checkSizeQuota(repoInfo, baseSize+putFileRecordsSize(records)); err != nil {
+		return nil, err
+	}
 	return records, nil
 }
 
@@ -3130,6 +4648,22 @@ func appendRecords(pfr *pfs.PutFileRecords, node *hashtree.NodeProto) {
 			SizeBytes: int64(blockRef.Range.Upper - blockRef.Range.Lower),
 		})
 	}
+	pfr.Metadata = mergeMetadata(pfr.Metadata, node.FileNode.Metadata)
+}
+
+// mergeMetadata merges the file metadata in 'from' into 'into', overwriting
+// existing keys, and returns the result. 'into' may be nil.
+func mergeMetadata(into, from map[string]string) map[string]string {
+	if len(from) == 0 {
+		return into
+	}
+	if into == nil {
+		into = make(map[string]string)
+	}
+	for k, v := range from {
+		into[k] = v
+	}
+	return into
 }
 
 // headerDirToPutFileRecords is a helper for copyFile that handles copying
@@ -3207,6 +4741,11 @@ func (d *driver) copyFile(pachClient *client.APIClient, src *pfs.File, dst *pfs.
 	if err := d.checkIsAuthorized(pachClient, dst.Commit.Repo, auth.Scope_WRITER); err != nil {
 		return err
 	}
+	if overwrite {
+		if err := d.checkRepoAppendOnly(pachClient.Ctx(), dst.Commit.Repo, "overwrite files"); err != nil {
+			return err
+		}
+	}
 	if err := d.checkFilePath(dst.Path); err != nil {
 		return err
 	}
@@ -3256,6 +4795,8 @@ func (d *driver) copyFile(pachClient *client.APIClient, src *pfs.File, dst *pfs.
 			if err != nil {
 				return err
 			}
+		} else if node.SymlinkNode != nil {
+			record.SymlinkTarget = node.SymlinkNode.Target
 		} else if node.FileNode == nil {
 			return nil
 		} else if node.FileNode.HasHeaderFooter {
@@ -3316,46 +4857,193 @@ func (d *driver) copyFile(pachClient *client.APIClient, src *pfs.File, dst *pfs.
 	// dst is finished => all PutFileRecords are in 'records'--put in a new commit
 	if !dstIsOpenCommit {
 		return d.txnEnv.WithWriteContext(pachClient.Ctx(), func(txnCtx *txnenv.TransactionContext) error {
-			_, err = d.makeCommit(txnCtx, "", client.NewCommit(dst.Commit.Repo.Name, ""), branch, nil, nil, nil, nil, nil, paths, records, "", time.Time{}, time.Time{}, 0)
+			_, err = d.makeCommit(txnCtx, "", client.NewCommit(dst.Commit.Repo.Name, ""), branch, nil, nil, nil, nil, nil, paths, records, "", nil, time.Time{}, time.Time{}, 0)
 			return err
 		})
 	}
 	return nil
 }
 
-func (d *driver) getTreeForCommit(txnCtx *txnenv.TransactionContext, commit *pfs.Commit) (hashtree.HashTree, error) {
-	if commit == nil || commit.ID == "" {
-		return d.treeCache.GetOrAdd("nil", func() (hashtree.HashTree, error) {
-			return hashtree.NewDBHashTree(d.storageRoot)
-		})
+// moveFile moves the file or directory at 'src' to 'dst', which must be in the
+// same commit. Like copyFile, the moved files' existing objects are reused
+// rather than rewritten. Unlike copyFile, the new path is added and the old
+// path is removed in a single etcd transaction, so no reader ever sees both.
+func (d *driver) moveFile(txnCtx *txnenv.TransactionContext, src *pfs.File, dst *pfs.File) error {
+	// Validate arguments
+	if src == nil {
+		return errors.New("src cannot be nil")
+	}
+	if src.Commit == nil {
+		return errors.New("src commit cannot be nil")
+	}
+	if src.Commit.Repo == nil {
+		return errors.New("src commit repo cannot be nil")
+	}
+	if dst == nil {
+		return errors.New("dst cannot be nil")
+	}
+	if dst.Commit == nil {
+		return errors.New("dst commit cannot be nil")
+	}
+	if dst.Commit.Repo == nil {
+		return errors.New("dst commit repo cannot be nil")
+	}
+	if src.Commit.Repo.Name != dst.Commit.Repo.Name || src.Commit.ID != dst.Commit.ID {
+		return errors.Errorf("cannot move files between commits (%s@%s and %s@%s)",
+			src.Commit.Repo.Name, src.Commit.ID, dst.Commit.Repo.Name, dst.Commit.ID)
 	}
 
-	return d.treeCache.GetOrAdd(commit.ID, func() (hashtree.HashTree, error) {
-		if _, err := d.resolveCommit(txnCtx.Stm, commit); err != nil {
-			return nil, err
+	if err := d.checkIsAuthorizedInTransaction(txnCtx, src.Commit.Repo, auth.Scope_WRITER); err != nil {
+		return err
+	}
+	repoInfo := &pfs.RepoInfo{}
+	if err := d.repos.ReadWrite(txnCtx.Stm).Get(src.Commit.Repo.Name, repoInfo); err != nil {
+		return err
+	}
+	if err := checkAppendOnly(repoInfo, "move files"); err != nil {
+		return err
+	}
+	if err := d.checkFilePath(dst.Path); err != nil {
+		return err
+	}
+	if err := hashtree.ValidatePath(dst.Path); err != nil {
+		return err
+	}
+	srcPath, dstPath := path.Clean("/"+src.Path), path.Clean("/"+dst.Path)
+	if srcPath == "/" {
+		return errors.New("cannot move the root directory")
+	}
+	if dstPath == srcPath || strings.HasPrefix(dstPath, srcPath+"/") {
+		return errors.Errorf("cannot move %q to %q, which is inside of it", srcPath, dstPath)
+	}
+	branch := ""
+	if !uuid.IsUUIDWithoutDashes(src.Commit.ID) {
+		branch = src.Commit.ID
+	}
+	commit := proto.Clone(src.Commit).(*pfs.Commit)
+	commitInfo, err := d.resolveCommit(txnCtx.Stm, commit)
+	if err != nil {
+		return err
+	}
+	if commitInfo.Finished != nil && branch == "" {
+		return pfsserver.ErrCommitFinished{commit}
+	}
+	if provenantOnInput(commitInfo.Provenance) && commitInfo.Tree == nil {
+		return errors.Errorf("cannot move files in output commit %s@%s", commit.Repo.Name, commit.ID)
+	}
+
+	// Get the tree of the commit, including any writes to it so far if it's
+	// still open
+	var tree hashtree.HashTree
+	if commitInfo.Finished != nil {
+		tree, err = d.getTreeForCommit(txnCtx, commit)
+		if err != nil {
+			return err
+		}
+	} else {
+		parentTree, err := d.getTreeForCommit(txnCtx, commitInfo.ParentCommit)
+		if err != nil {
+			return err
+		}
+		tree, err = d.getTreeForOpenCommit(txnCtx.Client, client.NewFile(commit.Repo.Name, commit.ID, ""), parentTree)
+		if err != nil {
+			return err
 		}
+		defer destroyHashtree(tree)
+	}
+	if _, err := tree.Get(srcPath); err != nil {
+		if hashtree.Code(err) == hashtree.PathNotFound {
+			return pfsserver.ErrFileNotFound{src}
+		}
+		return err
+	}
+	if _, err := tree.Get(dstPath); err == nil {
+		return errors.Errorf("cannot move %q to %q, which already exists", srcPath, dstPath)
+	} else if hashtree.Code(err) != hashtree.PathNotFound {
+		return err
+	}
 
-		commits := d.commits(commit.Repo.Name).ReadWrite(txnCtx.Stm)
-		commitInfo := &pfs.CommitInfo{}
-		if err := commits.Get(commit.ID, commitInfo); err != nil {
-			return nil, err
+	// Delete 'src', and recreate each of the files under it under 'dst'
+	paths := []string{srcPath}
+	records := []*pfs.PutFileRecords{{Tombstone: true}}
+	if err := tree.Walk(srcPath, func(walkPath string, node *hashtree.NodeProto) error {
+		record := &pfs.PutFileRecords{}
+		if node.DirNode != nil && node.DirNode.Shared != nil {
+			var err error
+			record, err = headerDirToPutFileRecords(tree, walkPath, node)
+			if err != nil {
+				return err
+			}
+		} else if node.SymlinkNode != nil {
+			record.SymlinkTarget = node.SymlinkNode.Target
+		} else if node.FileNode == nil {
+			return nil
+		} else if node.FileNode.HasHeaderFooter {
+			return nil // parent dir will be moved as a PutFileRecord w/ Split==true
+		} else {
+			appendRecords(record, node)
 		}
-		if commitInfo.Finished == nil {
-			return nil, errors.Errorf("cannot read from an open commit")
+		paths = append(paths, path.Join(dstPath, strings.TrimPrefix(walkPath, srcPath)))
+		records = append(records, record)
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	if commitInfo.Finished != nil {
+		_, err := d.makeCommit(txnCtx, "", client.NewCommit(commit.Repo.Name, ""), branch, nil, nil, nil, nil, nil, paths, records, "", nil, time.Time{}, time.Time{}, 0)
+		return err
+	}
+	// 'src' and 'dst' are disjoint and 'dst' doesn't exist, so the order in which
+	// these records are applied doesn't matter
+	recordsCol := d.putFileRecords.ReadWrite(txnCtx.Stm)
+	for i, p := range paths {
+		prefix, err := d.scratchFilePrefix(client.NewFile(commit.Repo.Name, commit.ID, p))
+		if err != nil {
+			return err
 		}
-		treeRef := commitInfo.Tree
+		var existingRecords pfs.PutFileRecords
+		if err := recordsCol.Upsert(prefix, &existingRecords, func() error {
+			mergePutFileRecords(&existingRecords, records[i])
+			return nil
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-		if treeRef == nil {
+func (d *driver) getTreeForCommit(txnCtx *txnenv.TransactionContext, commit *pfs.Commit) (hashtree.HashTree, error) {
+	if commit == nil || commit.ID == "" {
+		return d.treeCache.GetOrAdd("nil", func() (hashtree.HashTree, error) {
+			return hashtree.NewDBHashTree(d.storageRoot)
+		})
+	}
+
+	if _, err := d.resolveCommit(txnCtx.Stm, commit); err != nil {
+		return nil, err
+	}
+	commits := d.commits(commit.Repo.Name).ReadWrite(txnCtx.Stm)
+	commitInfo := &pfs.CommitInfo{}
+	if err := commits.Get(commit.ID, commitInfo); err != nil {
+		return nil, err
+	}
+	if commitInfo.Finished == nil {
+		return nil, errors.Errorf("cannot read from an open commit")
+	}
+	treeRef := commitInfo.Tree
+	if treeRef == nil {
+		return d.treeCache.GetOrAdd("nil", func() (hashtree.HashTree, error) {
 			return hashtree.NewDBHashTree(d.storageRoot)
-		}
+		})
+	}
 
+	// Trees are cached by their object's hash rather than by commit ID, so
+	// a commit whose tree is rewritten (e.g. by purgeFile) misses the cache on
+	// every pachd, not just the one that rewrote it
+	return d.treeCache.GetOrAdd(treeRef.Hash, func() (hashtree.HashTree, error) {
 		// read the tree from the block store
-		h, err := hashtree.GetHashTreeObject(txnCtx.Client, d.storageRoot, treeRef)
-		if err != nil {
-			return nil, err
-		}
-
-		return h, nil
+		return hashtree.GetHashTreeObject(txnCtx.Client, d.storageRoot, treeRef)
 	})
 }
 
@@ -3534,7 +5222,29 @@ func (d *driver) getFile(pachClient *client.APIClient, file *pfs.File, offset in
 			footer     *pfs.Object
 			prevDir    string
 		)
-		if err := tree.Glob(file.Path, func(p string, node *hashtree.NodeProto) error {
+		pattern := file.Path
+		if !hashtree.IsGlob(pattern) {
+			// Follow any symlinks in the path of the file being read
+			resolved, _, err := hashtree.Resolve(tree, pattern)
+			if err != nil && hashtree.Code(err) != hashtree.PathNotFound {
+				return nil, err
+			} else if err == nil {
+				pattern = resolved
+			}
+		}
+		if err := tree.Glob(pattern, func(p string, node *hashtree.NodeProto) error {
+			if node.SymlinkNode != nil {
+				// Read the file that the matching symlink points to (if any).
+				// Dangling symlinks aren't counted as found.
+				var err error
+				p, node, err = hashtree.Resolve(tree, p)
+				if err != nil {
+					if hashtree.Code(err) == hashtree.PathNotFound {
+						return nil
+					}
+					return err
+				}
+			}
 			pathsFound++
 			if node.FileNode == nil {
 				return nil
@@ -3686,6 +5396,7 @@ func nodeToFileInfo(ci *pfs.CommitInfo, path string, node *hashtree.NodeProto, f
 	}
 	if node.FileNode != nil {
 		fileInfo.FileType = pfs.FileType_FILE
+		fileInfo.Metadata = node.FileNode.Metadata
 		if full {
 			fileInfo.Objects = node.FileNode.Objects
 			fileInfo.BlockRefs = node.FileNode.BlockRefs
@@ -3695,6 +5406,9 @@ func nodeToFileInfo(ci *pfs.CommitInfo, path string, node *hashtree.NodeProto, f
 		if full {
 			fileInfo.Children = node.DirNode.Children
 		}
+	} else if node.SymlinkNode != nil {
+		fileInfo.FileType = pfs.FileType_SYMLINK
+		fileInfo.SymlinkTarget = node.SymlinkNode.Target
 	}
 	return fileInfo
 }
@@ -3955,11 +5669,25 @@ func (d *driver) walkFile(pachClient *client.APIClient, file *pfs.File, f func(*
 			return err
 		}
 		defer destroyHashtree(tree)
-		return tree.Walk(file.Path, func(path string, node *hashtree.NodeProto) error {
-			fi, err := nodeToFileInfoHeaderFooter(commitInfo, path, node, tree, false)
+		// If 'file' is a symlink (or is under one), walk the path that it
+		// points to, but report the files found as being under 'file'
+		filePath := path.Clean("/" + file.Path)
+		walkPath := filePath
+		if resolved, _, err := hashtree.Resolve(tree, filePath); err == nil {
+			walkPath = resolved
+		}
+		return tree.Walk(walkPath, func(p string, node *hashtree.NodeProto) error {
+			fi, err := nodeToFileInfoHeaderFooter(commitInfo, p, node, tree, false)
 			if err != nil {
 				return err
 			}
+			if walkPath != filePath {
+				relPath, err := filepath.Rel(walkPath, p)
+				if err != nil {
+					return err
+				}
+				fi.File.Path = path.Join(filePath, relPath)
+			}
 			return f(fi)
 		})
 	}
@@ -4010,10 +5738,22 @@ func (d *driver) globFile(pachClient *client.APIClient, commit *pfs.Commit, patt
 		}
 		defer destroyHashtree(tree)
 		globErr := tree.Glob(pattern, func(path string, node *hashtree.NodeProto) error {
-			fi, err := nodeToFileInfoHeaderFooter(commitInfo, path, node, tree, false)
+			// Matching symlinks are reported as the file that they point to (if
+			// it exists), so that e.g. each one can be processed as a datum
+			nodePath := path
+			if node.SymlinkNode != nil {
+				resolved, resolvedNode, err := hashtree.Resolve(tree, path)
+				if err != nil && hashtree.Code(err) != hashtree.PathNotFound {
+					return err
+				} else if err == nil {
+					nodePath, node = resolved, resolvedNode
+				}
+			}
+			fi, err := nodeToFileInfoHeaderFooter(commitInfo, nodePath, node, tree, false)
 			if err != nil {
 				return err
 			}
+			fi.File.Path = path
 			return f(fi)
 		})
 		if hashtree.Code(globErr) == hashtree.PathNotFound {
@@ -4051,6 +5791,107 @@ func (d *driver) globFile(pachClient *client.APIClient, commit *pfs.Commit, patt
 	})
 }
 
+// grepFile searches the content of the files matching 'file' (whose path may
+// be a glob pattern) for lines matching the regular expression 'pattern', and
+// calls 'f' on each matching line. Files are searched concurrently, so while
+// the lines from each file are passed to 'f' together and in order, files may
+// be passed to 'f' in any order. 'f' is never called concurrently.
+func (d *driver) grepFile(pachClient *client.APIClient, file *pfs.File, pattern string, f func(*pfs.GrepFileResponse) error) error {
+	// Validate arguments
+	if file == nil {
+		return errors.New("file cannot be nil")
+	}
+	if file.Commit == nil {
+		return errors.New("file commit cannot be nil")
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return errors.Wrapf(err, "invalid pattern %q", pattern)
+	}
+	// Resolve the commit once, so that every file is found and read in the
+	// same commit even if 'file.Commit' is a branch that moves while the search
+	// is running. inspectCommit checks that the caller is authorized to read
+	// the repo.
+	commitInfo, err := d.inspectCommit(pachClient, file.Commit, pfs.CommitState_STARTED)
+	if err != nil {
+		return err
+	}
+	commit := commitInfo.Commit
+	var paths []string
+	if err := d.globFile(pachClient, commit, file.Path, func(fi *pfs.FileInfo) error {
+		if fi.FileType == pfs.FileType_FILE {
+			paths = append(paths, fi.File.Path)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	limiter := limit.New(grepFileConcurrency)
+	eg, ctx := errgroup.WithContext(pachClient.Ctx())
+	pachClient = pachClient.WithCtx(ctx)
+	var mu sync.Mutex
+	for _, p := range paths {
+		p := p
+		limiter.Acquire()
+		eg.Go(func() error {
+			defer limiter.Release()
+			r, err := d.getFile(pachClient, client.NewFile(commit.Repo.Name, commit.ID, p), 0, 0)
+			if err != nil {
+				return err
+			}
+			br := bufio.NewReader(r)
+			for lineNumber := int64(1); ; lineNumber++ {
+				line, err := readLine(br, grepFileMaxLineBytes)
+				if err != nil && !errors.Is(err, io.EOF) {
+					return err
+				}
+				if len(line) == 0 && err != nil {
+					return nil
+				}
+				if re.Match(line) {
+					mu.Lock()
+					fErr := f(&pfs.GrepFileResponse{
+						File:       client.NewFile(commit.Repo.Name, commit.ID, p),
+						LineNumber: lineNumber,
+						Line:       string(line),
+					})
+					mu.Unlock()
+					if fErr != nil {
+						return fErr
+					}
+				}
+				if err != nil {
+					return nil
+				}
+			}
+		})
+	}
+	return eg.Wait()
+}
+
+// readLine reads the next line from 'r', without its trailing newline. Lines
+// longer than 'maxBytes' are truncated to 'maxBytes' (the rest of the line is
+// discarded), so that a large file with no newlines isn't read into memory.
+// It returns io.EOF along with the last line if 'r' doesn't end in a newline.
+func readLine(r *bufio.Reader, maxBytes int) ([]byte, error) {
+	var line []byte
+	for {
+		chunk, isPrefix, err := r.ReadLine()
+		if err != nil {
+			return line, err
+		}
+		if len(line) < maxBytes {
+			if len(line)+len(chunk) > maxBytes {
+				chunk = chunk[:maxBytes-len(line)]
+			}
+			line = append(line, chunk...)
+		}
+		if !isPrefix {
+			return line, nil
+		}
+	}
+}
+
 func (d *driver) diffFile(pachClient *client.APIClient, newFile *pfs.File, oldFile *pfs.File, shallow bool) ([]*pfs.FileInfo, []*pfs.FileInfo, error) {
 	// Validate arguments
 	if newFile == nil {
@@ -4134,6 +5975,137 @@ func (d *driver) diffFile(pachClient *client.APIClient, newFile *pfs.File, oldFi
 	return newFileInfos, oldFileInfos, nil
 }
 
+// diffFileContent computes line-level diffs of the content of the files
+// returned by diffFile. Files in 'newFileInfos' and 'oldFileInfos' with the
+// same path are diffed against each other, and the remaining files are diffed
+// against an empty file. Directories are skipped.
+func (d *driver) diffFileContent(pachClient *client.APIClient, newRoot, oldRoot string, newFileInfos, oldFileInfos []*pfs.FileInfo, sizeLimit int64) ([]*pfs.FileContentDiff, error) {
+	if sizeLimit == 0 {
+		sizeLimit = defaultDiffContentSizeLimit
+	}
+	// Files are paired by their paths relative to the roots of the diff, so
+	// that diffing two different directories compares the files in them
+	newRoot, oldRoot = path.Clean("/"+newRoot), path.Clean("/"+oldRoot)
+	relPath := func(root string, fileInfo *pfs.FileInfo) string {
+		return strings.TrimPrefix(path.Clean("/"+fileInfo.File.Path), root)
+	}
+	var result []*pfs.FileContentDiff
+	var totalBytes int
+	nI, oI := 0, 0
+	for nI < len(newFileInfos) || oI < len(oldFileInfos) {
+		var newFileInfo, oldFileInfo *pfs.FileInfo
+		switch {
+		case oI == len(oldFileInfos) || (nI < len(newFileInfos) && relPath(newRoot, newFileInfos[nI]) < relPath(oldRoot, oldFileInfos[oI])):
+			newFileInfo = newFileInfos[nI]
+			nI++
+		case nI == len(newFileInfos) || relPath(oldRoot, oldFileInfos[oI]) < relPath(newRoot, newFileInfos[nI]):
+			oldFileInfo = oldFileInfos[oI]
+			oI++
+		default:
+			newFileInfo, oldFileInfo = newFileInfos[nI], oldFileInfos[oI]
+			nI++
+			oI++
+		}
+		if newFileInfo != nil && newFileInfo.FileType != pfs.FileType_FILE {
+			newFileInfo = nil
+		}
+		if oldFileInfo != nil && oldFileInfo.FileType != pfs.FileType_FILE {
+			oldFileInfo = nil
+		}
+		if newFileInfo == nil && oldFileInfo == nil {
+			continue
+		}
+		contentDiff, err := d.diffContent(pachClient, newFileInfo, oldFileInfo, sizeLimit)
+		if err != nil {
+			return nil, err
+		}
+		// Keep the response under the gRPC message size limit
+		if totalBytes+len(contentDiff.UnifiedDiff) > maxDiffContentTotalBytes {
+			contentDiff.UnifiedDiff = ""
+			contentDiff.TooLarge = true
+		}
+		totalBytes += len(contentDiff.UnifiedDiff)
+		result = append(result, contentDiff)
+	}
+	return result, nil
+}
+
+// diffContent computes a unified diff from 'oldFileInfo' to 'newFileInfo',
+// either of which may be nil.
+func (d *driver) diffContent(pachClient *client.APIClient, newFileInfo, oldFileInfo *pfs.FileInfo, sizeLimit int64) (*pfs.FileContentDiff, error) {
+	result := &pfs.FileContentDiff{}
+	diff := difflib.UnifiedDiff{
+		FromFile: "/dev/null",
+		ToFile:   "/dev/null",
+		Context:  3,
+	}
+	if oldFileInfo != nil {
+		result.OldFile = oldFileInfo.File
+		result.TooLarge = int64(oldFileInfo.SizeBytes) > sizeLimit
+		diff.FromFile = path.Join("a", path.Clean("/"+oldFileInfo.File.Path))
+	}
+	if newFileInfo != nil {
+		result.NewFile = newFileInfo.File
+		result.TooLarge = result.TooLarge || int64(newFileInfo.SizeBytes) > sizeLimit
+		diff.ToFile = path.Join("b", path.Clean("/"+newFileInfo.File.Path))
+	}
+	if result.TooLarge {
+		return result, nil
+	}
+	var err error
+	var oldBinary, newBinary bool
+	if diff.A, oldBinary, err = d.diffLines(pachClient, oldFileInfo); err != nil {
+		return nil, err
+	}
+	if diff.B, newBinary, err = d.diffLines(pachClient, newFileInfo); err != nil {
+		return nil, err
+	}
+	if oldBinary || newBinary {
+		result.Binary = true
+		return result, nil
+	}
+	result.UnifiedDiff, err = difflib.GetUnifiedDiffString(diff)
+	if err != nil {
+		return nil, errors.EnsureStack(err)
+	}
+	return result, nil
+}
+
+// diffLines returns the lines of the file described by 'fileInfo' (or no lines
+// if 'fileInfo' is nil), or true if the file appears to be binary.
+func (d *driver) diffLines(pachClient *client.APIClient, fileInfo *pfs.FileInfo) ([]string, bool, error) {
+	if fileInfo == nil {
+		return nil, false, nil
+	}
+	r, err := d.getFile(pachClient, fileInfo.File, 0, 0)
+	if err != nil {
+		return nil, false, err
+	}
+	content, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, false, err
+	}
+	if isBinary(content) {
+		return nil, true, nil
+	}
+	lines := strings.SplitAfter(string(content), "\n")
+	if last := len(lines) - 1; lines[last] == "" {
+		lines = lines[:last]
+	} else {
+		// difflib expects every line to end in a newline
+		lines[last] += "\n"
+	}
+	return lines, false, nil
+}
+
+// isBinary returns true if 'content' appears to be binary rather than text.
+func isBinary(content []byte) bool {
+	if len(content) > binarySniffLen {
+		content = content[:binarySniffLen]
+	}
+	return bytes.IndexByte(content, 0) >= 0
+}
+
 func (d *driver) deleteFile(pachClient *client.APIClient, file *pfs.File) error {
 	// Validate arguments
 	if file == nil {
@@ -4149,6 +6121,9 @@ func (d *driver) deleteFile(pachClient *client.APIClient, file *pfs.File) error
 	if err := d.checkIsAuthorized(pachClient, file.Commit.Repo, auth.Scope_WRITER); err != nil {
 		return err
 	}
+	if err := d.checkRepoAppendOnly(pachClient.Ctx(), file.Commit.Repo, "delete files"); err != nil {
+		return err
+	}
 	if err := d.checkFilePath(file.Path); err != nil {
 		return err
 	}
@@ -4165,7 +6140,7 @@ func (d *driver) deleteFile(pachClient *client.APIClient, file *pfs.File) error
 			return pfsserver.ErrCommitFinished{file.Commit}
 		}
 		return d.txnEnv.WithWriteContext(pachClient.Ctx(), func(txnCtx *txnenv.TransactionContext) error {
-			_, err := d.makeCommit(txnCtx, "", client.NewCommit(file.Commit.Repo.Name, ""), branch, nil, nil, nil, nil, nil, []string{file.Path}, []*pfs.PutFileRecords{&pfs.PutFileRecords{Tombstone: true}}, "", time.Time{}, time.Time{}, 0)
+			_, err := d.makeCommit(txnCtx, "", client.NewCommit(file.Commit.Repo.Name, ""), branch, nil, nil, nil, nil, nil, []string{file.Path}, []*pfs.PutFileRecords{&pfs.PutFileRecords{Tombstone: true}}, "", nil, time.Time{}, time.Time{}, 0)
 			return err
 		})
 	}
@@ -4173,6 +6148,246 @@ func (d *driver) deleteFile(pachClient *client.APIClient, file *pfs.File) error
 	return d.upsertPutFileRecords(pachClient, file, &pfs.PutFileRecords{Tombstone: true})
 }
 
+// purgeFile removes the files matching 'pattern' from every commit in 'repo',
+// by rewriting each commit's tree in place. Output commits derived from the
+// rewritten commits are returned, and deleted if 'deleteOutputs' is set (in
+// which case the repo's branches are re-propagated so pipelines reprocess the
+// purged data). The objects that held the purged data are returned too; they
+// aren't deleted until the next garbage collection, which only deletes those
+// that nothing else still references. Forks of 'repo', which still contain
+// the purged files, are returned as well.
+func (d *driver) purgeFile(pachClient *client.APIClient, repo *pfs.Repo, pattern string, deleteOutputs bool) (*pfs.PurgeFileResponse, error) {
+	// Validate arguments
+	if repo == nil {
+		return nil, errors.New("repo cannot be nil")
+	}
+	if err := d.checkFilePath(pattern); err != nil {
+		return nil, err
+	}
+	if strings.Trim(pattern, "/") == "" {
+		return nil, errors.New("cannot purge the root directory")
+	}
+
+	var response *pfs.PurgeFileResponse
+	if err := d.txnEnv.WithWriteContext(pachClient.Ctx(), func(txnCtx *txnenv.TransactionContext) error {
+		var err error
+		response, err = d.purgeFileInTransaction(txnCtx, repo, pattern, deleteOutputs)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (d *driver) purgeFileInTransaction(txnCtx *txnenv.TransactionContext, repo *pfs.Repo, pattern string, deleteOutputs bool) (*pfs.PurgeFileResponse, error) {
+	// Purging rewrites history, so it requires the same access as deleting
+	// the repo
+	if err := d.checkIsAuthorizedInTransaction(txnCtx, repo, auth.Scope_OWNER); err != nil {
+		return nil, err
+	}
+	repoInfo := &pfs.RepoInfo{}
+	if err := d.repos.ReadWrite(txnCtx.Stm).Get(repo.Name, repoInfo); err != nil {
+		if col.IsErrNotFound(err) {
+			return nil, pfsserver.ErrRepoNotFound{repo}
+		}
+		return nil, err
+	}
+	if err := checkAppendOnly(repoInfo, "purge files"); err != nil {
+		return nil, err
+	}
+
+	var commitIDs []string
+	commitInfo := &pfs.CommitInfo{}
+	if err := d.commits(repo.Name).ReadWrite(txnCtx.Stm).List(commitInfo, func(commitID string) error {
+		commitIDs = append(commitIDs, commitID)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	response := &pfs.PurgeFileResponse{}
+	objects := make(map[string]bool)
+	addObject := func(object *pfs.Object) {
+		if !objects[object.Hash] {
+			objects[object.Hash] = true
+			response.Objects = append(response.Objects, object)
+		}
+	}
+	var outputs []*pfs.CommitRange
+	commits := d.commits(repo.Name).ReadWrite(txnCtx.Stm)
+	for _, commitID := range commitIDs {
+		commitInfo := &pfs.CommitInfo{}
+		if err := commits.Get(commitID, commitInfo); err != nil {
+			return nil, err
+		}
+		if commitInfo.Finished == nil {
+			return nil, errors.Errorf("cannot purge files while commit %s@%s is open", repo.Name, commitID)
+		}
+		if provenantOnInput(commitInfo.Provenance) {
+			return nil, errors.Errorf("cannot purge files from output commit %s@%s, purge them from its input repos instead", repo.Name, commitID)
+		}
+		if commitInfo.Tree == nil {
+			continue // empty commit
+		}
+		tree, err := hashtree.GetHashTreeObject(txnCtx.Client, d.storageRoot, commitInfo.Tree)
+		if err != nil {
+			return nil, err
+		}
+		purged, err := purgeTree(tree, pattern, addObject)
+		if err != nil {
+			destroyHashtree(tree)
+			return nil, err
+		}
+		if !purged {
+			destroyHashtree(tree)
+			continue
+		}
+		treeRef, err := hashtree.PutHashTree(txnCtx.Client, tree)
+		size := uint64(tree.FSSize())
+		destroyHashtree(tree)
+		if err != nil {
+			return nil, err
+		}
+		addObject(commitInfo.Tree)
+		commitInfo.Tree = treeRef
+		commitInfo.SizeBytes = size
+		if err := commits.Put(commitID, commitInfo); err != nil {
+			return nil, err
+		}
+		response.Rewritten = append(response.Rewritten, commitInfo.Commit)
+		for _, subv := range commitInfo.Subvenance {
+			outputs = append(outputs, subv)
+		}
+	}
+
+	// List (and maybe delete) the output commits derived from the rewritten
+	// commits
+	seen := make(map[string]bool)
+	for _, subv := range outputs {
+		outputCommits := d.commits(subv.Upper.Repo.Name).ReadWrite(txnCtx.Stm)
+		for cur := subv.Upper; cur != nil; {
+			if !seen[cur.ID] {
+				seen[cur.ID] = true
+				response.Outputs = append(response.Outputs, cur)
+			}
+			if cur.ID == subv.Lower.ID {
+				break
+			}
+			curInfo := &pfs.CommitInfo{}
+			if err := outputCommits.Get(cur.ID, curInfo); err != nil {
+				return nil, errors.Wrapf(err, "error reading commitInfo for subvenant \"%s/%s\"", subv.Upper.Repo.Name, cur.ID)
+			}
+			cur = curInfo.ParentCommit
+		}
+	}
+	if deleteOutputs && len(outputs) > 0 {
+		for _, output := range response.Outputs {
+			outputRepoInfo := &pfs.RepoInfo{}
+			if err := d.repos.ReadWrite(txnCtx.Stm).Get(output.Repo.Name, outputRepoInfo); err != nil {
+				return nil, err
+			}
+			if err := checkAppendOnly(outputRepoInfo, "delete commits"); err != nil {
+				return nil, err
+			}
+		}
+		if err := d.deleteCommitRanges(txnCtx, outputs); err != nil {
+			return nil, err
+		}
+		for _, branch := range repoInfo.Branches {
+			if err := txnCtx.PropagateCommit(branch, false); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// Update the repo's size, in case the head of master was rewritten
+	masterInfo := &pfs.BranchInfo{}
+	if err := d.branches(repo.Name).ReadWrite(txnCtx.Stm).Get("master", masterInfo); err != nil && !col.IsErrNotFound(err) {
+		return nil, err
+	}
+	if masterInfo.Head != nil {
+		headInfo := &pfs.CommitInfo{}
+		if err := commits.Get(masterInfo.Head.ID, headInfo); err != nil {
+			return nil, err
+		}
+		if err := d.repos.ReadWrite(txnCtx.Stm).Update(repo.Name, repoInfo, func() error {
+			repoInfo.SizeBytes = headInfo.SizeBytes
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	// Forks of the repo (and their forks) share its commits' data, which
+	// isn't purged from them
+	forks, err := d.listForks(txnCtx, repo)
+	if err != nil {
+		return nil, err
+	}
+	response.Forks = forks
+	return response, nil
+}
+
+// listForks returns the repos forked from 'repo', directly or from one of its
+// forks.
+func (d *driver) listForks(txnCtx *txnenv.TransactionContext, repo *pfs.Repo) ([]*pfs.Repo, error) {
+	forksOf := make(map[string][]*pfs.Repo)
+	repoInfo := &pfs.RepoInfo{}
+	if err := d.repos.ReadWrite(txnCtx.Stm).List(repoInfo, func(string) error {
+		if repoInfo.ForkOrigin != nil {
+			origin := repoInfo.ForkOrigin.Repo.Name
+			forksOf[origin] = append(forksOf[origin], repoInfo.Repo)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	var forks []*pfs.Repo
+	seen := map[string]bool{repo.Name: true}
+	for queue := []string{repo.Name}; len(queue) > 0; queue = queue[1:] {
+		for _, fork := range forksOf[queue[0]] {
+			if !seen[fork.Name] {
+				seen[fork.Name] = true
+				forks = append(forks, fork)
+				queue = append(queue, fork.Name)
+			}
+		}
+	}
+	return forks, nil
+}
+
+// purgeTree deletes the files matching 'pattern' from 'tree' and rehashes it,
+// calling 'f' on the objects of each deleted file. It returns false if
+// nothing matched.
+func purgeTree(tree hashtree.HashTree, pattern string, f func(*pfs.Object)) (bool, error) {
+	var paths []string
+	if err := tree.Glob(pattern, func(path string, _ *hashtree.NodeProto) error {
+		paths = append(paths, path)
+		return nil
+	}); err != nil && hashtree.Code(err) != hashtree.PathNotFound {
+		return false, err
+	}
+	if len(paths) == 0 {
+		return false, nil
+	}
+	for _, path := range paths {
+		if err := tree.Walk(path, func(_ string, node *hashtree.NodeProto) error {
+			if node.FileNode != nil {
+				for _, object := range node.FileNode.Objects {
+					f(object)
+				}
+			}
+			return nil
+		}); err != nil {
+			return false, err
+		}
+	}
+	if err := tree.DeleteFile(pattern); err != nil {
+		return false, err
+	}
+	return true, tree.Hash()
+}
+
 func (d *driver) deleteAll(txnCtx *txnenv.TransactionContext) error {
 	// Note: d.listRepo() doesn't return the 'spec' repo, so it doesn't get
 	// deleted here. Instead, PPS is responsible for deleting and re-creating it
@@ -4181,7 +6396,7 @@ func (d *driver) deleteAll(txnCtx *txnenv.TransactionContext) error {
 		return err
 	}
 	for _, repoInfo := range repoInfos.RepoInfo {
-		if err := d.deleteRepo(txnCtx, repoInfo.Repo, true); err != nil && !auth.IsErrNotAuthorized(err) {
+		if err := d.deleteRepo(txnCtx, repoInfo.Repo, true); err != nil && !auth.IsErrNotAuthorized(err) && !errors.As(err, &pfsserver.ErrAppendOnlyRepo{}) {
 			return err
 		}
 	}
@@ -4197,39 +6412,60 @@ func (d *driver) upsertPutFileRecords(
 	file *pfs.File,
 	newRecords *pfs.PutFileRecords,
 ) error {
+	_, err := col.NewSTM(pachClient.Ctx(), d.etcdClient, func(stm col.STM) error {
+		return d.upsertPutFileRecordsInSTM(stm, file, newRecords)
+	})
+	return err
+}
+
+// upsertPutFileRecordsInSTM is identical to upsertPutFileRecords except that
+// it runs in 'stm', so it can be combined with other writes.
+func (d *driver) upsertPutFileRecordsInSTM(stm col.STM, file *pfs.File, newRecords *pfs.PutFileRecords) error {
 	prefix, err := d.scratchFilePrefix(file)
 	if err != nil {
 		return err
 	}
-
-	ctx := pachClient.Ctx()
-	_, err = col.NewSTM(ctx, d.etcdClient, func(stm col.STM) error {
-		commitsCol := d.openCommits.ReadWrite(stm)
-		var commit pfs.Commit
-		err := commitsCol.Get(file.Commit.ID, &commit)
-		if err != nil {
-			return err
-		}
-		// Dumb check to make sure the unmarshalled value exists (and matches the current ID)
-		// to denote that the current commit is indeed open
-		if commit.ID != file.Commit.ID {
-			return errors.Errorf("commit %v is not open", file.Commit.ID)
-		}
-		recordsCol := d.putFileRecords.ReadWrite(stm)
-		var existingRecords pfs.PutFileRecords
-		return recordsCol.Upsert(prefix, &existingRecords, func() error {
-			if newRecords.Tombstone {
-				existingRecords.Tombstone = true
-				existingRecords.Records = nil
-			}
-			existingRecords.Split = newRecords.Split
-			existingRecords.Records = append(existingRecords.Records, newRecords.Records...)
-			existingRecords.Header = newRecords.Header
-			existingRecords.Footer = newRecords.Footer
-			return nil
-		})
+	commitsCol := d.openCommits.ReadWrite(stm)
+	var commit pfs.Commit
+	if err := commitsCol.Get(file.Commit.ID, &commit); err != nil {
+		return err
+	}
+	// Dumb check to make sure the unmarshalled value exists (and matches the current ID)
+	// to denote that the current commit is indeed open
+	if commit.ID != file.Commit.ID {
+		return errors.Errorf("commit %v is not open", file.Commit.ID)
+	}
+	recordsCol := d.putFileRecords.ReadWrite(stm)
+	var existingRecords pfs.PutFileRecords
+	return recordsCol.Upsert(prefix, &existingRecords, func() error {
+		mergePutFileRecords(&existingRecords, newRecords)
+		return nil
 	})
-	return err
+}
+
+// mergePutFileRecords merges 'newRecords' into the 'existingRecords' that were
+// written to the same path in an open commit earlier.
+func mergePutFileRecords(existingRecords, newRecords *pfs.PutFileRecords) {
+	if newRecords.Tombstone {
+		existingRecords.Tombstone = true
+		existingRecords.Records = nil
+		existingRecords.Metadata = nil
+		existingRecords.SymlinkTarget = ""
+	}
+	if newRecords.SymlinkTarget != "" {
+		// A symlink replaces anything written to this path earlier
+		existingRecords.Records = nil
+		existingRecords.Metadata = nil
+	} else if existingRecords.SymlinkTarget != "" && len(newRecords.Records) > 0 {
+		// Writing to a symlink that was put earlier replaces it
+		existingRecords.Tombstone = true
+	}
+	existingRecords.SymlinkTarget = newRecords.SymlinkTarget
+	existingRecords.Metadata = mergeMetadata(existingRecords.Metadata, newRecords.Metadata)
+	existingRecords.Split = newRecords.Split
+	existingRecords.Records = append(existingRecords.Records, newRecords.Records...)
+	existingRecords.Header = newRecords.Header
+	existingRecords.Footer = newRecords.Footer
 }
 
 func (d *driver) applyWrite(key string, records *pfs.PutFileRecords, tree hashtree.HashTree) error {
@@ -4241,6 +6477,9 @@ func (d *driver) applyWrite(key string, records *pfs.PutFileRecords, tree hashtr
 			return err
 		}
 	}
+	if records.SymlinkTarget != "" {
+		return tree.PutSymlink(key, records.SymlinkTarget)
+	}
 	if !records.Split {
 		if len(records.Records) == 0 {
 			return nil
@@ -4279,6 +6518,11 @@ func (d *driver) applyWrite(key string, records *pfs.PutFileRecords, tree hashtr
 				}
 			}
 		}
+		if len(records.Metadata) > 0 {
+			if err := tree.PutFileMetadata(key, records.Metadata); err != nil {
+				return err
+			}
+		}
 	} else {
 		nodes, err := tree.ListAll(key)
 		if err != nil && hashtree.Code(err) != hashtree.PathNotFound {
@@ -4333,6 +6577,11 @@ func (d *driver) applyWrite(key string, records *pfs.PutFileRecords, tree hashtr
 					}
 				}
 			}
+			if len(records.Metadata) > 0 {
+				if err := tree.PutFileMetadata(path.Join(key, fmt.Sprintf(splitSuffixFmt, i+int(indexOffset))), records.Metadata); err != nil {
+					return err
+				}
+			}
 		}
 	}
 	return nil
@@ -4640,7 +6889,8 @@ func (d *driver) forEachPutFile(pachClient *client.APIClient, server pfs.API_Put
 			if pw != nil {
 				pw.Close() // can't error
 			}
-			if req.Delete {
+			// Deletes and symlinks have no content, so they don't get a pipe
+			if req.Delete || req.Symlink != "" {
 				d.putFileLimiter.Acquire()
 				eg.Go(func() error {
 					defer d.putFileLimiter.Release()
@@ -78,7 +78,7 @@ func newDriverV2(
 	return d2, nil
 }
 
-func (d *driverV2) finishCommitV2(txnCtx *txnenv.TransactionContext, commit *pfs.Commit, description string) error {
+func (d *driverV2) finishCommitV2(txnCtx *txnenv.TransactionContext, commit *pfs.Commit, description string, annotations map[string]string) error {
 	if err := d.checkIsAuthorizedInTransaction(txnCtx, commit.Repo, auth.Scope_WRITER); err != nil {
 		return err
 	}
@@ -92,6 +92,7 @@ func (d *driverV2) finishCommitV2(txnCtx *txnenv.TransactionContext, commit *pfs
 	if description != "" {
 		commitInfo.Description = description
 	}
+	addAnnotations(commitInfo, annotations)
 	commitPath := path.Join(commit.Repo.Name, commit.ID)
 	// Clean up temporary filesets leftover from failed operations.
 	if err := d.storage.Delete(txnCtx.Client.Ctx(), path.Join(tmpPrefix, commitPath)); err != nil {
@@ -0,0 +1,405 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pachyderm/pachyderm/src/client"
+	"github.com/pachyderm/pachyderm/src/client/limit"
+	"github.com/pachyderm/pachyderm/src/client/pfs"
+	"github.com/pachyderm/pachyderm/src/client/pkg/grpcutil"
+	"github.com/pachyderm/pachyderm/src/server/pkg/hashtree"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// defaultFsckParallelism is the number of objects that a deep fsck checks
+// concurrently if the request doesn't say
+const defaultFsckParallelism = 10
+
+// ErrContentMissing An object referenced by one or more hashtrees is missing
+// from object storage.
+// This struct contains all the information that was used to demonstrate that this invariant is not being satisfied.
+type ErrContentMissing struct {
+	Ref   string
+	Files []*pfs.File
+}
+
+func (e ErrContentMissing) Error() string {
+	var msg strings.Builder
+	msg.WriteString("consistency error: " + e.Ref + " is missing from object storage\n")
+	writeFsckFiles(&msg, e.Files)
+	return msg.String()
+}
+
+// ErrContentCorrupted An object or block range referenced by one or more
+// hashtrees can't be read from object storage, or doesn't have the expected
+// content.
+// This struct contains all the information that was used to demonstrate that this invariant is not being satisfied.
+type ErrContentCorrupted struct {
+	Ref    string
+	Reason string
+	Files  []*pfs.File
+}
+
+func (e ErrContentCorrupted) Error() string {
+	var msg strings.Builder
+	msg.WriteString("consistency error: " + e.Ref + " is corrupted: " + e.Reason + "\n")
+	writeFsckFiles(&msg, e.Files)
+	return msg.String()
+}
+
+// ErrHashtreeUnreadable A commit's hashtree can't be read, so the content it
+// references can't be checked.
+// This struct contains all the information that was used to demonstrate that this invariant is not being satisfied.
+type ErrHashtreeUnreadable struct {
+	Commit *pfs.Commit
+	Err    error
+}
+
+func (e ErrHashtreeUnreadable) Error() string {
+	return fmt.Sprintf("consistency error: the hashtree of commit %v in repo %v could not be read: %v",
+		e.Commit.ID, e.Commit.Repo.Name, e.Err)
+}
+
+func writeFsckFiles(msg *strings.Builder, files []*pfs.File) {
+	msg.WriteString("it is referenced by:\n")
+	for _, file := range files {
+		if file.Path == "" {
+			msg.WriteString("the hashtree of commit " + file.Commit.ID + " in repo " + file.Commit.Repo.Name + "\n")
+			continue
+		}
+		msg.WriteString(file.Path + " in commit " + file.Commit.ID + " in repo " + file.Commit.Repo.Name + "\n")
+	}
+}
+
+// contentRef is a reference, from a commit's hashtree, to content in object
+// storage. Exactly one of object or blockRef is set.
+type contentRef struct {
+	object   *pfs.Object
+	blockRef *pfs.BlockRef
+}
+
+func (r contentRef) String() string {
+	if r.object != nil {
+		return "object " + r.object.Hash
+	}
+	if r.blockRef.Block == nil || r.blockRef.Range == nil {
+		return "block ref " + r.blockRef.String()
+	}
+	return fmt.Sprintf("block %s [%d, %d)", r.blockRef.Block.Hash, r.blockRef.Range.Lower, r.blockRef.Range.Upper)
+}
+
+// fsckContent verifies that every object and block range referenced by the
+// hashtrees of the finished commits in 'commitInfos' exists in object storage
+// and holds the expected content. Up to 'parallelism' references are checked
+// at once, and reads from object storage are limited to 'bytesPerSecond' (if
+// it's nonzero). Each missing or corrupted reference is passed to 'onError'
+// along with the files that reference it.
+func (d *driver) fsckContent(pachClient *client.APIClient, commitInfos map[string]*pfs.CommitInfo, parallelism, bytesPerSecond int64, onError func(error) error) error {
+	ctx := pachClient.Ctx()
+	var keys []string
+	for key, ci := range commitInfos {
+		if ci.Finished != nil {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	// collect every distinct reference
+	refs := make(map[string]contentRef)
+	treeErrs := make(map[string]error)
+	for _, key := range keys {
+		if err := d.walkContentRefs(pachClient, commitInfos[key], func(_ *pfs.File, ref contentRef) error {
+			refs[ref.String()] = ref
+			return nil
+		}); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			treeErrs[key] = err
+		}
+	}
+
+	// check each reference
+	if parallelism <= 0 {
+		parallelism = defaultFsckParallelism
+	}
+	limiter := limit.New(int(parallelism))
+	throttle := &byteThrottle{bytesPerSecond: bytesPerSecond}
+	var eg errgroup.Group
+	var mu sync.Mutex
+	problems := make(map[string]*contentProblem)
+	for refKey, ref := range refs {
+		refKey, ref := refKey, ref
+		limiter.Acquire()
+		eg.Go(func() error {
+			defer limiter.Release()
+			problem, err := checkContentRef(pachClient, ref, throttle)
+			if err != nil || problem == nil {
+				return err
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			problems[refKey] = problem
+			return nil
+		})
+	}
+	if err := eg.Wait(); err != nil {
+		return err
+	}
+
+	// find the files that reference the problematic content. Hashtrees are
+	// walked again (rather than remembering every file above) so that only
+	// the files that need to be reported are held in memory.
+	files := make(map[string][]*pfs.File)
+	if len(problems) > 0 {
+		for _, key := range keys {
+			if _, ok := treeErrs[key]; ok {
+				continue
+			}
+			if err := d.walkContentRefs(pachClient, commitInfos[key], func(file *pfs.File, ref contentRef) error {
+				if _, ok := problems[ref.String()]; ok {
+					files[ref.String()] = append(files[ref.String()], file)
+				}
+				return nil
+			}); err != nil {
+				return err
+			}
+		}
+	}
+	var problemKeys []string
+	for refKey := range problems {
+		problemKeys = append(problemKeys, refKey)
+	}
+	sort.Strings(problemKeys)
+	for _, refKey := range problemKeys {
+		if err := onError(problems[refKey].toError(refs[refKey], files[refKey])); err != nil {
+			return err
+		}
+	}
+
+	// report unreadable hashtrees, unless that's explained by their objects
+	// being missing or corrupted
+	for _, key := range keys {
+		treeErr, ok := treeErrs[key]
+		if !ok {
+			continue
+		}
+		ci := commitInfos[key]
+		explained := false
+		for _, tree := range append([]*pfs.Object{ci.Tree}, ci.Trees...) {
+			if tree == nil {
+				continue
+			}
+			if _, ok := problems[contentRef{object: tree}.String()]; ok {
+				explained = true
+			}
+		}
+		if !explained {
+			if err := onError(ErrHashtreeUnreadable{Commit: ci.Commit, Err: treeErr}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// walkContentRefs calls 'f' with each reference to content in object storage
+// made by 'commitInfo': the objects holding its hashtree (which are reported
+// with an empty path) and the objects and block ranges holding its files.
+func (d *driver) walkContentRefs(pachClient *client.APIClient, commitInfo *pfs.CommitInfo, f func(*pfs.File, contentRef) error) (retErr error) {
+	commit := commitInfo.Commit
+	treeFile := client.NewFile(commit.Repo.Name, commit.ID, "")
+	for _, tree := range append([]*pfs.Object{commitInfo.Tree}, commitInfo.Trees...) {
+		if tree == nil {
+			continue
+		}
+		if err := f(treeFile, contentRef{object: tree}); err != nil {
+			return err
+		}
+	}
+	walkNode := func(path string, node *hashtree.NodeProto) error {
+		file := client.NewFile(commit.Repo.Name, commit.ID, path)
+		var refs []contentRef
+		if node.FileNode != nil {
+			for _, object := range node.FileNode.Objects {
+				if object != nil {
+					refs = append(refs, contentRef{object: object})
+				}
+			}
+			for _, blockRef := range node.FileNode.BlockRefs {
+				if blockRef != nil {
+					refs = append(refs, contentRef{blockRef: blockRef})
+				}
+			}
+		}
+		if node.DirNode != nil && node.DirNode.Shared != nil {
+			if node.DirNode.Shared.Header != nil {
+				refs = append(refs, contentRef{object: node.DirNode.Shared.Header})
+			}
+			if node.DirNode.Shared.Footer != nil {
+				refs = append(refs, contentRef{object: node.DirNode.Shared.Footer})
+			}
+		}
+		for _, ref := range refs {
+			if err := f(file, ref); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	// Handle commits that use the old hashtree format.
+	if !provenantOnInput(commitInfo.Provenance) || commitInfo.Tree != nil {
+		if commitInfo.Tree == nil {
+			return nil
+		}
+		tree, err := d.getTreeForFile(pachClient, treeFile)
+		if err != nil {
+			return err
+		}
+		defer destroyHashtree(tree)
+		return tree.Walk("/", walkNode)
+	}
+	// Handle commits that use the newer hashtree format.
+	if commitInfo.Trees == nil {
+		return nil
+	}
+	rs, err := d.getTrees(pachClient, commitInfo, "/")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		for _, r := range rs {
+			if err := r.Close(); err != nil && retErr == nil {
+				retErr = err
+			}
+		}
+	}()
+	return hashtree.Walk(rs, "/", walkNode)
+}
+
+// contentProblem describes missing or corrupted content in object storage
+type contentProblem struct {
+	missing bool
+	reason  string // why the content is corrupted, if it's not missing
+}
+
+func (p *contentProblem) toError(ref contentRef, files []*pfs.File) error {
+	if p.missing {
+		return ErrContentMissing{Ref: ref.String(), Files: files}
+	}
+	return ErrContentCorrupted{Ref: ref.String(), Reason: p.reason, Files: files}
+}
+
+// checkContentRef reads the content referenced by 'ref' from object storage,
+// and returns a description of the problem if it's missing or corrupted.
+// Errors that prevent the check from finishing are returned as errors.
+func checkContentRef(pachClient *client.APIClient, ref contentRef, throttle *byteThrottle) (*contentProblem, error) {
+	ctx := pachClient.Ctx()
+	corrupted := func(reason string) (*contentProblem, error) {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return &contentProblem{reason: reason}, nil
+	}
+	blockRef := ref.blockRef
+	if ref.object != nil {
+		resp, err := pachClient.ObjectAPIClient.CheckObject(ctx, &pfs.CheckObjectRequest{Object: ref.object})
+		if err != nil {
+			return nil, grpcutil.ScrubGRPC(err)
+		}
+		if !resp.Exists {
+			return &contentProblem{missing: true}, nil
+		}
+		objectInfo, err := pachClient.InspectObject(ref.object.Hash)
+		if err != nil {
+			return corrupted(err.Error())
+		}
+		if objectInfo.BlockRef == nil || objectInfo.BlockRef.Range == nil {
+			return corrupted("the object has no block reference")
+		}
+		blockRef = objectInfo.BlockRef
+	}
+	if blockRef.Block == nil || blockRef.Range == nil || blockRef.Range.Upper < blockRef.Range.Lower {
+		return corrupted("invalid block reference")
+	}
+	getBlocksClient, err := pachClient.ObjectAPIClient.GetBlocks(ctx, &pfs.GetBlocksRequest{
+		BlockRefs: []*pfs.BlockRef{blockRef},
+		// A large total size makes the server read from object storage directly,
+		// rather than from its cache
+		TotalSize: math.MaxUint64,
+	})
+	if err != nil {
+		return nil, grpcutil.ScrubGRPC(err)
+	}
+	hash := pfs.NewHash()
+	r := &throttledReader{r: grpcutil.NewStreamingBytesReader(getBlocksClient, nil), ctx: ctx, throttle: throttle}
+	buf := grpcutil.GetBuffer()
+	defer grpcutil.PutBuffer(buf)
+	size, err := io.CopyBuffer(hash, r, buf)
+	if err != nil {
+		return corrupted(grpcutil.ScrubGRPC(err).Error())
+	}
+	if expected := int64(blockRef.Range.Upper - blockRef.Range.Lower); size != expected {
+		return corrupted(fmt.Sprintf("expected %d bytes but read %d", expected, size))
+	}
+	if ref.object != nil {
+		if actual := pfs.EncodeHash(hash.Sum(nil)); actual != ref.object.Hash {
+			return corrupted("the content's hash is " + actual)
+		}
+	}
+	return nil, nil
+}
+
+// byteThrottle limits the rate at which bytes are read by several goroutines
+// at once. A zero bytesPerSecond means that reads aren't limited.
+type byteThrottle struct {
+	bytesPerSecond int64
+
+	mu   sync.Mutex
+	next time.Time // the earliest time at which the next read may proceed
+}
+
+// wait blocks until 'n' more bytes can be read
+func (t *byteThrottle) wait(ctx context.Context, n int) error {
+	if t.bytesPerSecond <= 0 || n <= 0 {
+		return nil
+	}
+	t.mu.Lock()
+	now := time.Now()
+	if t.next.Before(now) {
+		t.next = now
+	}
+	at := t.next
+	t.next = t.next.Add(time.Duration(int64(n) * int64(time.Second) / t.bytesPerSecond))
+	t.mu.Unlock()
+	timer := time.NewTimer(time.Until(at))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+type throttledReader struct {
+	r        io.Reader
+	ctx      context.Context
+	throttle *byteThrottle
+}
+
+func (r *throttledReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if err := r.throttle.wait(r.ctx, n); err != nil {
+		return n, err
+	}
+	return n, err
+}
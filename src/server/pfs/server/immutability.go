@@ -0,0 +1,73 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"github.com/gogo/protobuf/types"
+	"github.com/pachyderm/pachyderm/src/client/pfs"
+	"github.com/pachyderm/pachyderm/src/client/pkg/errors"
+	pfsserver "github.com/pachyderm/pachyderm/src/server/pfs"
+	col "github.com/pachyderm/pachyderm/src/server/pkg/collection"
+)
+
+func validateImmutabilityPolicy(policy *pfs.ImmutabilityPolicy) error {
+	if policy == nil || policy.LegalHoldUntil == nil {
+		return nil
+	}
+	if _, err := types.TimestampFromProto(policy.LegalHoldUntil); err != nil {
+		return err
+	}
+	if !policy.AppendOnly {
+		return errors.New("a legal hold can only be placed on an append-only repo")
+	}
+	return nil
+}
+
+// legalHoldUntil returns the time until which 'policy' is under a legal hold,
+// or the zero time if it's not.
+func legalHoldUntil(policy *pfs.ImmutabilityPolicy) time.Time {
+	if policy == nil || policy.LegalHoldUntil == nil {
+		return time.Time{}
+	}
+	until, err := types.TimestampFromProto(policy.LegalHoldUntil)
+	if err != nil {
+		return time.Time{}
+	}
+	return until
+}
+
+// checkImmutabilityUpdate returns an error if 'repo's immutability policy
+// can't be changed from 'existing' to 'policy', because the change would
+// remove or shorten a legal hold that's still in effect.
+func checkImmutabilityUpdate(repo *pfs.Repo, existing, policy *pfs.ImmutabilityPolicy) error {
+	until := legalHoldUntil(existing)
+	if !time.Now().Before(until) {
+		return nil
+	}
+	if policy == nil || !policy.AppendOnly || legalHoldUntil(policy).Before(until) {
+		return errors.Errorf("repo %s is under a legal hold until %v, its immutability policy cannot be removed or shortened", repo.Name, until.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// checkAppendOnly returns ErrAppendOnlyRepo if 'repoInfo's repo is
+// append-only. 'op' describes the operation being checked.
+func checkAppendOnly(repoInfo *pfs.RepoInfo, op string) error {
+	if repoInfo.Immutability != nil && repoInfo.Immutability.AppendOnly {
+		return pfsserver.ErrAppendOnlyRepo{Repo: repoInfo.Repo, Op: op}
+	}
+	return nil
+}
+
+// checkRepoAppendOnly is like checkAppendOnly, but reads 'repo's RepoInfo.
+func (d *driver) checkRepoAppendOnly(ctx context.Context, repo *pfs.Repo, op string) error {
+	repoInfo := &pfs.RepoInfo{}
+	if err := d.repos.ReadOnly(ctx).Get(repo.Name, repoInfo); err != nil {
+		if col.IsErrNotFound(err) {
+			return nil // let the caller report the missing repo
+		}
+		return err
+	}
+	return checkAppendOnly(repoInfo, op)
+}
@@ -0,0 +1,122 @@
+package server
+
+import (
+	"io"
+
+	"github.com/pachyderm/pachyderm/src/client"
+	"github.com/pachyderm/pachyderm/src/client/pfs"
+	"github.com/pachyderm/pachyderm/src/client/pkg/errors"
+	pfsserver "github.com/pachyderm/pachyderm/src/server/pfs"
+	txnenv "github.com/pachyderm/pachyderm/src/server/pkg/transactionenv"
+)
+
+func validateRepoQuota(quota *pfs.RepoQuota) error {
+	if quota == nil {
+		return nil
+	}
+	if quota.Commits < 0 {
+		return errors.Errorf("repo quota cannot allow a negative number of commits (%d)", quota.Commits)
+	}
+	return nil
+}
+
+// checkSizeQuota returns ErrQuotaExceeded if 'sizeBytes' is larger than the
+// size quota of 'repoInfo's repo.
+func checkSizeQuota(repoInfo *pfs.RepoInfo, sizeBytes uint64) error {
+	quota := repoInfo.Quota
+	if quota == nil || quota.SizeBytes == 0 || sizeBytes <= quota.SizeBytes {
+		return nil
+	}
+	return pfsserver.ErrQuotaExceeded{
+		Repo:     repoInfo.Repo,
+		Resource: "size",
+		Usage:    sizeBytes,
+		Limit:    quota.SizeBytes,
+	}
+}
+
+// checkCommitQuota returns ErrQuotaExceeded if adding a commit to
+// 'repoInfo's repo would exceed its commit quota.
+func (d *driver) checkCommitQuota(txnCtx *txnenv.TransactionContext, repoInfo *pfs.RepoInfo) error {
+	quota := repoInfo.Quota
+	if quota == nil || quota.Commits == 0 {
+		return nil
+	}
+	// Count the commits through the STM, so that commits made concurrently
+	// conflict with this one rather than both fitting under the quota
+	var count int64
+	if err := d.commits(repoInfo.Repo.Name).ReadWrite(txnCtx.Stm).List(&pfs.CommitInfo{}, func(string) error {
+		count++
+		return nil
+	}); err != nil {
+		return err
+	}
+	if count < quota.Commits {
+		return nil
+	}
+	return pfsserver.ErrQuotaExceeded{
+		Repo:     repoInfo.Repo,
+		Resource: "commit",
+		Usage:    uint64(count + 1),
+		Limit:    uint64(quota.Commits),
+	}
+}
+
+// quotaBaseSize returns the size that data written to 'commit' is added to
+// when checking its repo's size quota: the size of 'commit' if it's finished
+// (the data goes into a new commit on top of it), or of its parent if it's
+// open.
+func (d *driver) quotaBaseSize(pachClient *client.APIClient, commit *pfs.Commit) (uint64, error) {
+	commitInfo, err := d.inspectCommit(pachClient, commit, pfs.CommitState_STARTED)
+	if err != nil {
+		if isNotFoundErr(err) || isNoHeadErr(err) {
+			return 0, nil // the data goes into the branch's first commit
+		}
+		return 0, err
+	}
+	if commitInfo.Finished != nil || commitInfo.ParentCommit == nil {
+		return commitInfo.SizeBytes, nil
+	}
+	parentInfo, err := d.inspectCommit(pachClient, commitInfo.ParentCommit, pfs.CommitState_STARTED)
+	if err != nil {
+		return 0, err
+	}
+	return parentInfo.SizeBytes, nil
+}
+
+// quotaReader reads data being written to a repo, and fails with
+// ErrQuotaExceeded as soon as the data would exceed the repo's size quota.
+// 'size' starts as the size the data is added to.
+type quotaReader struct {
+	r        io.Reader
+	repoInfo *pfs.RepoInfo
+	size     uint64
+}
+
+func (r *quotaReader) Read(p []byte) (int, error) {
+	// Read at most one byte past the quota, so that the data isn't consumed
+	// past the point where the write fails
+	if limit := r.repoInfo.Quota.SizeBytes; r.size <= limit && uint64(len(p)) > limit-r.size+1 {
+		p = p[:limit-r.size+1]
+	}
+	n, err := r.r.Read(p)
+	r.size += uint64(n)
+	if quotaErr := checkSizeQuota(r.repoInfo, r.size); quotaErr != nil {
+		return n, quotaErr
+	}
+	return n, err
+}
+
+// putFileRecordsSize returns the number of bytes written by 'records'.
+func putFileRecordsSize(records *pfs.PutFileRecords) uint64 {
+	var size int64
+	for _, record := range records.Records {
+		size += record.SizeBytes
+	}
+	for _, record := range []*pfs.PutFileRecord{records.Header, records.Footer} {
+		if record != nil {
+			size += record.SizeBytes
+		}
+	}
+	return uint64(size)
+}
@@ -0,0 +1,333 @@
+package server
+
+import (
+	"context"
+	"path"
+	"sort"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/gogo/protobuf/types"
+	"github.com/pachyderm/pachyderm/src/client"
+	"github.com/pachyderm/pachyderm/src/client/pfs"
+	"github.com/pachyderm/pachyderm/src/client/pkg/errors"
+	"github.com/pachyderm/pachyderm/src/server/pkg/backoff"
+	col "github.com/pachyderm/pachyderm/src/server/pkg/collection"
+	"github.com/pachyderm/pachyderm/src/server/pkg/dlock"
+	"github.com/pachyderm/pachyderm/src/server/pkg/ppsconsts"
+	txnenv "github.com/pachyderm/pachyderm/src/server/pkg/transactionenv"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	reaperLockPath = "pfs-reaper-lock"
+	// defaultReaperInterval is how often the reaper applies repos' retention
+	// policies, if PFS_REAPER_INTERVAL isn't set
+	defaultReaperInterval = time.Minute
+	// defaultUploadTTL is how long an upload session can go without a chunk
+	// being uploaded before the reaper deletes it, if PFS_UPLOAD_TTL isn't set
+	defaultUploadTTL = 7 * 24 * time.Hour
+)
+
+func validateRetentionPolicy(retention *pfs.RetentionPolicy) error {
+	if retention == nil {
+		return nil
+	}
+	if retention.KeepCommits < 0 {
+		return errors.Errorf("retention policy cannot keep a negative number of commits (%d)", retention.KeepCommits)
+	}
+	if retention.KeepDuration != nil {
+		keepDuration, err := types.DurationFromProto(retention.KeepDuration)
+		if err != nil {
+			return err
+		}
+		if keepDuration < 0 {
+			return errors.Errorf("retention policy cannot keep commits for a negative duration (%v)", keepDuration)
+		}
+	}
+	return nil
+}
+
+// reaper periodically deletes the commits that have expired under their
+// repo's retention policy, and the upload sessions that have been abandoned.
+// Only one pachd runs the reaper at a time.
+func (d *driver) reaper() {
+	interval := defaultReaperInterval
+	if d.env.PFSReaperInterval != "" {
+		var err error
+		interval, err = time.ParseDuration(d.env.PFSReaperInterval)
+		if err != nil {
+			logrus.Errorf("invalid PFS_REAPER_INTERVAL %q, using %v: %v", d.env.PFSReaperInterval, defaultReaperInterval, err)
+			interval = defaultReaperInterval
+		}
+	}
+	uploadTTL := defaultUploadTTL
+	if d.env.PFSUploadTTL != "" {
+		var err error
+		uploadTTL, err = time.ParseDuration(d.env.PFSUploadTTL)
+		if err != nil {
+			logrus.Errorf("invalid PFS_UPLOAD_TTL %q, using %v: %v", d.env.PFSUploadTTL, defaultUploadTTL, err)
+			uploadTTL = defaultUploadTTL
+		}
+	}
+	reaperLock := dlock.NewDLock(d.etcdClient, path.Join(d.prefix, reaperLockPath))
+	backoff.RetryNotify(func() error {
+		ctx, err := reaperLock.Lock(context.Background())
+		if err != nil {
+			return err
+		}
+		defer reaperLock.Unlock(ctx)
+		for {
+			if err := d.reapCommits(ctx); err != nil {
+				return err
+			}
+			if err := d.reapUploads(ctx, uploadTTL); err != nil {
+				return err
+			}
+			select {
+			case <-time.After(interval):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}, backoff.NewInfiniteBackOff(), func(err error, retryIn time.Duration) error {
+		logrus.Errorf("error in PFS reaper: %v; retrying in %v", err, retryIn)
+		return nil
+	})
+}
+
+// reapCommits deletes the expired commits in every repo that has a retention
+// policy and isn't append-only. Commits are deleted one at a time, oldest
+// first, and commits that can't be deleted are logged and skipped.
+func (d *driver) reapCommits(ctx context.Context) error {
+	pachClient, err := d.reaperClient(ctx)
+	if err != nil {
+		return err
+	}
+	var repoInfos []*pfs.RepoInfo
+	repoInfo := &pfs.RepoInfo{}
+	if err := d.repos.ReadOnly(ctx).List(repoInfo, col.DefaultOptions, func(string) error {
+		if checkAppendOnly(repoInfo, "") != nil {
+			return nil
+		}
+		if repoInfo.Retention != nil && (repoInfo.Retention.KeepCommits > 0 || repoInfo.Retention.KeepDuration != nil) {
+			repoInfos = append(repoInfos, proto.Clone(repoInfo).(*pfs.RepoInfo))
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	for _, repoInfo := range repoInfos {
+		expired, err := d.expiredCommits(pachClient, repoInfo)
+		if err != nil {
+			logrus.Errorf("could not apply retention policy of repo %s: %v", repoInfo.Repo.Name, err)
+			continue
+		}
+		for _, commit := range expired {
+			if err := d.txnEnv.WithWriteContext(pachClient.Ctx(), func(txnCtx *txnenv.TransactionContext) error {
+				return d.reapCommit(txnCtx, commit)
+			}); err != nil {
+				logrus.Errorf("could not delete expired commit %s@%s: %v", commit.Repo.Name, commit.ID, err)
+			}
+		}
+	}
+	return nil
+}
+
+// reaperClient returns a client that the reaper can use to delete commits in
+// any repo. If auth is active, it's authenticated as PPS's superuser.
+func (d *driver) reaperClient(ctx context.Context) (*client.APIClient, error) {
+	pachClient := d.env.GetPachClient(ctx)
+	pachClient = pachClient.WithCtx(ctx)
+	var token types.StringValue
+	if err := col.NewCollection(d.etcdClient, ppsconsts.PPSTokenKey, nil, &types.StringValue{}, nil, nil).ReadOnly(ctx).Get("", &token); err != nil {
+		if col.IsErrNotFound(err) {
+			return pachClient, nil // auth has never been activated
+		}
+		return nil, err
+	}
+	pachClient.SetAuthToken(token.Value)
+	return pachClient, nil
+}
+
+// expiredCommits returns the finished input commits in 'repoInfo's repo that
+// aren't kept by its retention policy on any branch, oldest first.
+func (d *driver) expiredCommits(pachClient *client.APIClient, repoInfo *pfs.RepoInfo) ([]*pfs.Commit, error) {
+	ctx := pachClient.Ctx()
+	retention := repoInfo.Retention
+	var cutoff time.Time
+	if retention.KeepDuration != nil {
+		keepDuration, err := types.DurationFromProto(retention.KeepDuration)
+		if err != nil {
+			return nil, err
+		}
+		cutoff = time.Now().Add(-keepDuration)
+	}
+	kept := make(map[string]bool)
+	candidates := make(map[string]time.Time) // commit ID -> finish time
+	commits := d.commits(repoInfo.Repo.Name).ReadOnly(ctx)
+	for _, branch := range repoInfo.Branches {
+		branchInfo := &pfs.BranchInfo{}
+		if err := d.branches(repoInfo.Repo.Name).ReadOnly(ctx).Get(branch.Name, branchInfo); err != nil {
+			if col.IsErrNotFound(err) {
+				continue
+			}
+			return nil, err
+		}
+		commit := branchInfo.Head
+		for i := int64(0); commit != nil; i++ {
+			commitInfo := &pfs.CommitInfo{}
+			if err := commits.Get(commit.ID, commitInfo); err != nil {
+				return nil, err
+			}
+			switch {
+			case i == 0, commitInfo.Finished == nil, provenantOnInput(commitInfo.Provenance):
+				kept[commit.ID] = true
+			case i < retention.KeepCommits:
+				kept[commit.ID] = true
+			case retention.KeepDuration != nil:
+				finished, err := types.TimestampFromProto(commitInfo.Finished)
+				if err != nil {
+					return nil, err
+				}
+				if finished.After(cutoff) {
+					kept[commit.ID] = true
+				} else {
+					candidates[commit.ID] = finished
+				}
+			default:
+				finished, err := types.TimestampFromProto(commitInfo.Finished)
+				if err != nil {
+					return nil, err
+				}
+				candidates[commit.ID] = finished
+			}
+			commit = commitInfo.ParentCommit
+		}
+	}
+	var expired []*pfs.Commit
+	for id := range candidates {
+		if !kept[id] {
+			expired = append(expired, client.NewCommit(repoInfo.Repo.Name, id))
+		}
+	}
+	sort.Slice(expired, func(i, j int) bool {
+		return candidates[expired[i].ID].Before(candidates[expired[j].ID])
+	})
+	return expired, nil
+}
+
+// reapCommit deletes the expired commit 'commit', along with its subvenant
+// output commits, unless one of those output commits is still live (it's
+// unfinished or the head of a branch).
+func (d *driver) reapCommit(txnCtx *txnenv.TransactionContext, commit *pfs.Commit) error {
+	commitInfo := &pfs.CommitInfo{}
+	if err := d.commits(commit.Repo.Name).ReadWrite(txnCtx.Stm).Get(commit.ID, commitInfo); err != nil {
+		if col.IsErrNotFound(err) {
+			return nil // already deleted, e.g. by a user
+		}
+		return err
+	}
+	heads := make(map[string]map[string]bool) // repo -> IDs of branch heads
+	for _, subv := range commitInfo.Subvenance {
+		repo := subv.Upper.Repo.Name
+		if heads[repo] == nil {
+			repoHeads, err := d.branchHeads(txnCtx, repo)
+			if err != nil {
+				return err
+			}
+			heads[repo] = repoHeads
+		}
+		for cur := subv.Upper; cur != nil; {
+			if heads[repo][cur.ID] {
+				return nil
+			}
+			curInfo := &pfs.CommitInfo{}
+			if err := d.commits(repo).ReadWrite(txnCtx.Stm).Get(cur.ID, curInfo); err != nil {
+				return errors.Wrapf(err, "error reading commitInfo for subvenant \"%s/%s\"", repo, cur.ID)
+			}
+			if curInfo.Finished == nil {
+				return nil
+			}
+			if cur.ID == subv.Lower.ID {
+				break
+			}
+			cur = curInfo.ParentCommit
+		}
+	}
+	return d.deleteCommit(txnCtx, commit)
+}
+
+// branchHeads returns the IDs of the heads of all branches in 'repo'
+func (d *driver) branchHeads(txnCtx *txnenv.TransactionContext, repo string) (map[string]bool, error) {
+	repoInfo := &pfs.RepoInfo{}
+	if err := d.repos.ReadWrite(txnCtx.Stm).Get(repo, repoInfo); err != nil {
+		return nil, err
+	}
+	heads := make(map[string]bool)
+	for _, branch := range repoInfo.Branches {
+		branchInfo := &pfs.BranchInfo{}
+		if err := d.branches(repo).ReadWrite(txnCtx.Stm).Get(branch.Name, branchInfo); err != nil {
+			if col.IsErrNotFound(err) {
+				continue
+			}
+			return nil, err
+		}
+		if branchInfo.Head != nil {
+			heads[branchInfo.Head.ID] = true
+		}
+	}
+	return heads, nil
+}
+
+// reapUploads deletes the upload sessions that no chunk has been uploaded to
+// for 'ttl'. Their data is deleted by the next garbage collection.
+func (d *driver) reapUploads(ctx context.Context, ttl time.Duration) error {
+	var expired []string
+	uploadInfo := &pfs.UploadInfo{}
+	if err := d.uploads.ReadOnly(ctx).List(uploadInfo, col.DefaultOptions, func(id string) error {
+		ok, err := uploadExpired(uploadInfo, ttl)
+		if err != nil {
+			return err
+		}
+		if ok {
+			expired = append(expired, id)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	for _, id := range expired {
+		if _, err := col.NewSTM(ctx, d.etcdClient, func(stm col.STM) error {
+			// Skip sessions that were updated or completed in the meantime
+			uploadInfo := &pfs.UploadInfo{}
+			if err := d.uploads.ReadWrite(stm).Get(id, uploadInfo); err != nil {
+				if col.IsErrNotFound(err) {
+					return nil
+				}
+				return err
+			}
+			if ok, err := uploadExpired(uploadInfo, ttl); err != nil || !ok {
+				return err
+			}
+			return d.uploads.ReadWrite(stm).Delete(id)
+		}); err != nil {
+			logrus.Errorf("could not delete expired upload %s: %v", id, err)
+		}
+	}
+	return nil
+}
+
+// uploadExpired returns true if no chunk has been uploaded to 'uploadInfo's
+// session for 'ttl'.
+func uploadExpired(uploadInfo *pfs.UploadInfo, ttl time.Duration) (bool, error) {
+	updated := uploadInfo.Updated
+	if updated == nil {
+		updated = uploadInfo.Started
+	}
+	updatedTime, err := types.TimestampFromProto(updated)
+	if err != nil {
+		return false, err
+	}
+	return time.Since(updatedTime) > ttl, nil
+}
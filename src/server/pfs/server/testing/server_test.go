@@ -1,8 +1,11 @@
 package testing
 
 import (
+	"archive/tar"
+	"archive/zip"
 	"bufio"
 	"bytes"
+	"compress/gzip"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -23,8 +26,11 @@ import (
 	"github.com/pachyderm/pachyderm/src/client/pfs"
 	"github.com/pachyderm/pachyderm/src/client/pkg/errors"
 	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+	pfsserver "github.com/pachyderm/pachyderm/src/server/pfs"
 	"github.com/pachyderm/pachyderm/src/server/pkg/ancestry"
+	"github.com/pachyderm/pachyderm/src/server/pkg/avro"
 	"github.com/pachyderm/pachyderm/src/server/pkg/backoff"
+	"github.com/pachyderm/pachyderm/src/server/pkg/errutil"
 	"github.com/pachyderm/pachyderm/src/server/pkg/hashtree"
 	"github.com/pachyderm/pachyderm/src/server/pkg/obj"
 	"github.com/pachyderm/pachyderm/src/server/pkg/serviceenv"
@@ -213,6 +219,1510 @@ func TestBranch(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestMergeBranch(t *testing.T) {
+	t.Parallel()
+	err := testpachd.WithRealEnv(func(env *testpachd.RealEnv) error {
+		repo := "repo"
+		require.NoError(t, env.PachClient.CreateRepo(repo))
+		_, err := env.PachClient.PutFile(repo, "master", "base", strings.NewReader("base\n"))
+		require.NoError(t, err)
+		require.NoError(t, env.PachClient.CreateBranch(repo, "feature", "master", nil))
+
+		_, err = env.PachClient.PutFile(repo, "master", "ours", strings.NewReader("ours\n"))
+		require.NoError(t, err)
+		_, err = env.PachClient.PutFile(repo, "feature", "theirs", strings.NewReader("theirs\n"))
+		require.NoError(t, err)
+		require.NoError(t, env.PachClient.DeleteFile(repo, "feature", "base"))
+		oursHead, err := env.PachClient.InspectCommit(repo, "master")
+		require.NoError(t, err)
+		theirsHead, err := env.PachClient.InspectCommit(repo, "feature")
+		require.NoError(t, err)
+
+		commit, err := env.PachClient.MergeBranch(repo, "feature", "master", pfs.MergeStrategy_FAIL, "")
+		require.NoError(t, err)
+		commitInfo, err := env.PachClient.InspectCommit(repo, "master")
+		require.NoError(t, err)
+		require.Equal(t, commit.ID, commitInfo.Commit.ID)
+		require.Equal(t, oursHead.Commit.ID, commitInfo.ParentCommit.ID)
+		require.Equal(t, theirsHead.Commit.ID, commitInfo.MergeParent.ID)
+
+		fileInfos, err := env.PachClient.ListFile(repo, "master", "")
+		require.NoError(t, err)
+		require.Equal(t, 2, len(fileInfos))
+		var buffer bytes.Buffer
+		require.NoError(t, env.PachClient.GetFile(repo, "master", "theirs", 0, 0, &buffer))
+		require.Equal(t, "theirs\n", buffer.String())
+		_, err = env.PachClient.InspectFile(repo, "master", "base")
+		require.YesError(t, err)
+
+		// Merging again is a no-op
+		commit, err = env.PachClient.MergeBranch(repo, "feature", "master", pfs.MergeStrategy_FAIL, "")
+		require.NoError(t, err)
+		require.Equal(t, commitInfo.Commit.ID, commit.ID)
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func TestMergeBranchConflict(t *testing.T) {
+	t.Parallel()
+	err := testpachd.WithRealEnv(func(env *testpachd.RealEnv) error {
+		repo := "repo"
+		require.NoError(t, env.PachClient.CreateRepo(repo))
+		_, err := env.PachClient.PutFile(repo, "master", "file", strings.NewReader("base\n"))
+		require.NoError(t, err)
+		require.NoError(t, env.PachClient.CreateBranch(repo, "feature", "master", nil))
+		_, err = env.PachClient.PutFileOverwrite(repo, "master", "file", strings.NewReader("ours\n"), 0)
+		require.NoError(t, err)
+		_, err = env.PachClient.PutFileOverwrite(repo, "feature", "file", strings.NewReader("theirs\n"), 0)
+		require.NoError(t, err)
+
+		_, err = env.PachClient.MergeBranch(repo, "feature", "master", pfs.MergeStrategy_FAIL, "")
+		require.YesError(t, err)
+		require.Matches(t, "conflicting paths: /file", err.Error())
+
+		_, err = env.PachClient.MergeBranch(repo, "feature", "master", pfs.MergeStrategy_THEIRS, "")
+		require.NoError(t, err)
+		var buffer bytes.Buffer
+		require.NoError(t, env.PachClient.GetFile(repo, "master", "file", 0, 0, &buffer))
+		require.Equal(t, "theirs\n", buffer.String())
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func TestRevertCommit(t *testing.T) {
+	t.Parallel()
+	err := testpachd.WithRealEnv(func(env *testpachd.RealEnv) error {
+		repo := "repo"
+		require.NoError(t, env.PachClient.CreateRepo(repo))
+		_, err := env.PachClient.PutFile(repo, "master", "foo", strings.NewReader("foo\n"))
+		require.NoError(t, err)
+		badCommit, err := env.PachClient.StartCommit(repo, "master")
+		require.NoError(t, err)
+		_, err = env.PachClient.PutFile(repo, badCommit.ID, "bar", strings.NewReader("bar\n"))
+		require.NoError(t, err)
+		require.NoError(t, env.PachClient.DeleteFile(repo, badCommit.ID, "foo"))
+		require.NoError(t, env.PachClient.FinishCommit(repo, badCommit.ID))
+		_, err = env.PachClient.PutFile(repo, "master", "buzz", strings.NewReader("buzz\n"))
+		require.NoError(t, err)
+
+		commit, err := env.PachClient.RevertCommit(repo, badCommit.ID, "", "")
+		require.NoError(t, err)
+		commitInfo, err := env.PachClient.InspectCommit(repo, "master")
+		require.NoError(t, err)
+		require.Equal(t, commit.ID, commitInfo.Commit.ID)
+
+		fileInfos, err := env.PachClient.ListFile(repo, "master", "")
+		require.NoError(t, err)
+		require.Equal(t, 2, len(fileInfos))
+		var buffer bytes.Buffer
+		require.NoError(t, env.PachClient.GetFile(repo, "master", "foo", 0, 0, &buffer))
+		require.Equal(t, "foo\n", buffer.String())
+		_, err = env.PachClient.InspectFile(repo, "master", "buzz")
+		require.NoError(t, err)
+		_, err = env.PachClient.InspectFile(repo, "master", "bar")
+		require.YesError(t, err)
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func TestCherryPickCommit(t *testing.T) {
+	t.Parallel()
+	err := testpachd.WithRealEnv(func(env *testpachd.RealEnv) error {
+		repo := "repo"
+		require.NoError(t, env.PachClient.CreateRepo(repo))
+		_, err := env.PachClient.PutFile(repo, "master", "foo", strings.NewReader("foo\n"))
+		require.NoError(t, err)
+		require.NoError(t, env.PachClient.CreateBranch(repo, "release", "master", nil))
+		_, err = env.PachClient.PutFile(repo, "master", "feature", strings.NewReader("feature\n"))
+		require.NoError(t, err)
+		fixCommit, err := env.PachClient.StartCommit(repo, "master")
+		require.NoError(t, err)
+		_, err = env.PachClient.PutFileOverwrite(repo, fixCommit.ID, "foo", strings.NewReader("fixed\n"), 0)
+		require.NoError(t, err)
+		require.NoError(t, env.PachClient.FinishCommit(repo, fixCommit.ID))
+
+		_, err = env.PachClient.CherryPickCommit(repo, fixCommit.ID, "release", "")
+		require.NoError(t, err)
+		var buffer bytes.Buffer
+		require.NoError(t, env.PachClient.GetFile(repo, "release", "foo", 0, 0, &buffer))
+		require.Equal(t, "fixed\n", buffer.String())
+		_, err = env.PachClient.InspectFile(repo, "release", "feature")
+		require.YesError(t, err)
+
+		// Cherry-picking onto a conflicting change fails
+		_, err = env.PachClient.PutFileOverwrite(repo, "release", "foo", strings.NewReader("release\n"), 0)
+		require.NoError(t, err)
+		_, err = env.PachClient.CherryPickCommit(repo, "master", "release", "")
+		require.YesError(t, err)
+		require.True(t, pfsserver.IsMergeConflictErr(err))
+		require.Matches(t, "conflicting paths: /foo", err.Error())
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func TestSquashCommits(t *testing.T) {
+	t.Parallel()
+	err := testpachd.WithRealEnv(func(env *testpachd.RealEnv) error {
+		repo := "repo"
+		require.NoError(t, env.PachClient.CreateRepo(repo))
+		var commits []*pfs.Commit
+		for i := 0; i < 5; i++ {
+			commit, err := env.PachClient.StartCommit(repo, "master")
+			require.NoError(t, err)
+			_, err = env.PachClient.PutFile(repo, commit.ID, fmt.Sprintf("file%d", i), strings.NewReader(fmt.Sprintf("%d\n", i)))
+			require.NoError(t, err)
+			require.NoError(t, env.PachClient.FinishCommit(repo, commit.ID))
+			commits = append(commits, commit)
+		}
+		require.NoError(t, env.PachClient.CreateCommitTag(repo, "v1", commits[2].ID))
+
+		// Squash commits 1-3 into commit 3
+		squashed, err := env.PachClient.SquashCommits(repo, commits[1].ID, commits[3].ID, "squashed")
+		require.NoError(t, err)
+		require.Equal(t, commits[3].ID, squashed.ID)
+
+		commitInfos, err := env.PachClient.ListCommitByRepo(repo)
+		require.NoError(t, err)
+		require.Equal(t, 3, len(commitInfos))
+		commitInfo, err := env.PachClient.InspectCommit(repo, squashed.ID)
+		require.NoError(t, err)
+		require.Equal(t, commits[0].ID, commitInfo.ParentCommit.ID)
+		require.Equal(t, "squashed", commitInfo.Description)
+		fileInfos, err := env.PachClient.ListFile(repo, squashed.ID, "")
+		require.NoError(t, err)
+		require.Equal(t, 4, len(fileInfos))
+		headInfo, err := env.PachClient.InspectCommit(repo, "master")
+		require.NoError(t, err)
+		require.Equal(t, commits[4].ID, headInfo.Commit.ID)
+		require.Equal(t, squashed.ID, headInfo.ParentCommit.ID)
+		tagInfo, err := env.PachClient.InspectCommitTag(repo, "v1")
+		require.NoError(t, err)
+		require.Equal(t, squashed.ID, tagInfo.Commit.ID)
+
+		// Squashing past a branch head fails
+		require.NoError(t, env.PachClient.CreateBranch(repo, "release", squashed.ID, nil))
+		_, err = env.PachClient.SquashCommits(repo, commits[0].ID, "master", "")
+		require.YesError(t, err)
+		require.Matches(t, "head of a branch", err.Error())
+
+		// 'from' must be an ancestor of 'to'
+		_, err = env.PachClient.SquashCommits(repo, commits[4].ID, commits[0].ID, "")
+		require.YesError(t, err)
+		require.Matches(t, "not an ancestor", err.Error())
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func TestResolveCommitAtTime(t *testing.T) {
+	t.Parallel()
+	err := testpachd.WithRealEnv(func(env *testpachd.RealEnv) error {
+		repo := "repo"
+		require.NoError(t, env.PachClient.CreateRepo(repo))
+		var commitInfos []*pfs.CommitInfo
+		for i := 0; i < 3; i++ {
+			commit, err := env.PachClient.StartCommit(repo, "master")
+			require.NoError(t, err)
+			require.NoError(t, env.PachClient.FinishCommit(repo, commit.ID))
+			commitInfo, err := env.PachClient.InspectCommit(repo, commit.ID)
+			require.NoError(t, err)
+			commitInfos = append(commitInfos, commitInfo)
+			time.Sleep(10 * time.Millisecond)
+		}
+		at := func(commitInfo *pfs.CommitInfo, offset time.Duration) string {
+			finished, err := types.TimestampFromProto(commitInfo.Finished)
+			require.NoError(t, err)
+			return fmt.Sprintf("master@{%s}", finished.Add(offset).Format(time.RFC3339Nano))
+		}
+
+		// A commit finished exactly at the given time is included
+		commitInfo, err := env.PachClient.InspectCommit(repo, at(commitInfos[1], 0))
+		require.NoError(t, err)
+		require.Equal(t, commitInfos[1].Commit.ID, commitInfo.Commit.ID)
+		commitInfo, err = env.PachClient.InspectCommit(repo, at(commitInfos[2], -time.Nanosecond))
+		require.NoError(t, err)
+		require.Equal(t, commitInfos[1].Commit.ID, commitInfo.Commit.ID)
+		commitInfo, err = env.PachClient.InspectCommit(repo, "master@{0s ago}")
+		require.NoError(t, err)
+		require.Equal(t, commitInfos[2].Commit.ID, commitInfo.Commit.ID)
+		_, err = env.PachClient.InspectCommit(repo, at(commitInfos[0], -time.Nanosecond))
+		require.YesError(t, err)
+		_, err = env.PachClient.InspectCommit(repo, "master@{1w ago}")
+		require.YesError(t, err)
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func TestCommitTag(t *testing.T) {
+	t.Parallel()
+	err := testpachd.WithRealEnv(func(env *testpachd.RealEnv) error {
+		repo := "repo"
+		require.NoError(t, env.PachClient.CreateRepo(repo))
+		_, err := env.PachClient.PutFile(repo, "master", "file", strings.NewReader("1\n"))
+		require.NoError(t, err)
+		first, err := env.PachClient.InspectCommit(repo, "master")
+		require.NoError(t, err)
+		_, err = env.PachClient.PutFile(repo, "master", "file", strings.NewReader("2\n"))
+		require.NoError(t, err)
+
+		require.NoError(t, env.PachClient.CreateCommitTag(repo, "v1.2", "master"))
+		// Tags are immutable
+		require.YesError(t, env.PachClient.CreateCommitTag(repo, "v1.2", first.Commit.ID))
+		// Tags and branches can't share names
+		require.YesError(t, env.PachClient.CreateCommitTag(repo, "master", "master"))
+		require.NoError(t, env.PachClient.CreateCommitTag(repo, "first", first.Commit.ID))
+		require.YesError(t, env.PachClient.CreateBranch(repo, "first", "master", nil))
+
+		// Tags can be used anywhere a commit ID is accepted
+		_, err = env.PachClient.PutFile(repo, "master", "file", strings.NewReader("3\n"))
+		require.NoError(t, err)
+		var buffer bytes.Buffer
+		require.NoError(t, env.PachClient.GetFile(repo, "v1.2", "file", 0, 0, &buffer))
+		require.Equal(t, "1\n2\n", buffer.String())
+		commitInfo, err := env.PachClient.InspectCommit(repo, "v1.2^")
+		require.NoError(t, err)
+		require.Equal(t, first.Commit.ID, commitInfo.Commit.ID)
+
+		tagInfo, err := env.PachClient.InspectCommitTag(repo, "first")
+		require.NoError(t, err)
+		require.Equal(t, first.Commit.ID, tagInfo.Commit.ID)
+		tagInfos, err := env.PachClient.ListCommitTag(repo)
+		require.NoError(t, err)
+		require.Equal(t, 2, len(tagInfos))
+
+		require.NoError(t, env.PachClient.DeleteCommitTag(repo, "first"))
+		_, err = env.PachClient.InspectCommit(repo, "first")
+		require.YesError(t, err)
+		// Deleting a commit deletes its tags
+		commitInfo, err = env.PachClient.InspectCommit(repo, "master")
+		require.NoError(t, err)
+		require.NoError(t, env.PachClient.CreateCommitTag(repo, "head", "master"))
+		require.NoError(t, env.PachClient.DeleteCommit(repo, commitInfo.Commit.ID))
+		_, err = env.PachClient.InspectCommitTag(repo, "head")
+		require.YesError(t, err)
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func TestCommitAnnotations(t *testing.T) {
+	t.Parallel()
+	err := testpachd.WithRealEnv(func(env *testpachd.RealEnv) error {
+		repo := "repo"
+		require.NoError(t, env.PachClient.CreateRepo(repo))
+
+		commit1, err := env.PachClient.PfsAPIClient.StartCommit(env.PachClient.Ctx(), &pfs.StartCommitRequest{
+			Parent:      pclient.NewCommit(repo, ""),
+			Branch:      "master",
+			Annotations: map[string]string{"batch": "1", "source": "s3"},
+		})
+		require.NoError(t, err)
+		_, err = env.PachClient.PfsAPIClient.FinishCommit(env.PachClient.Ctx(), &pfs.FinishCommitRequest{
+			Commit:      commit1,
+			Annotations: map[string]string{"batch": "2", "status": "ok"},
+		})
+		require.NoError(t, err)
+		commitInfo, err := env.PachClient.InspectCommit(repo, commit1.ID)
+		require.NoError(t, err)
+		// Annotations passed to FinishCommit are merged into the existing ones
+		require.Equal(t, map[string]string{"batch": "2", "source": "s3", "status": "ok"}, commitInfo.Annotations)
+
+		commit2, err := env.PachClient.StartCommit(repo, "master")
+		require.NoError(t, err)
+		require.NoError(t, env.PachClient.FinishCommit(repo, commit2.ID))
+
+		var commitInfos []*pfs.CommitInfo
+		require.NoError(t, env.PachClient.ListCommitFilterF(repo, "master", "", 0, false, map[string]string{"source": "s3"}, func(ci *pfs.CommitInfo) error {
+			commitInfos = append(commitInfos, ci)
+			return nil
+		}))
+		require.Equal(t, 1, len(commitInfos))
+		require.Equal(t, commit1.ID, commitInfos[0].Commit.ID)
+
+		commitInfos = nil
+		require.NoError(t, env.PachClient.ListCommitFilterF(repo, "master", "", 0, false, map[string]string{"source": "s3", "batch": "1"}, func(ci *pfs.CommitInfo) error {
+			commitInfos = append(commitInfos, ci)
+			return nil
+		}))
+		require.Equal(t, 0, len(commitInfos))
+
+		commitInfos, err = env.PachClient.ListCommit(repo, "master", "", 0)
+		require.NoError(t, err)
+		require.Equal(t, 2, len(commitInfos))
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func TestPutFileMetadata(t *testing.T) {
+	t.Parallel()
+	err := testpachd.WithRealEnv(func(env *testpachd.RealEnv) error {
+		repo := "repo"
+		require.NoError(t, env.PachClient.CreateRepo(repo))
+
+		_, err := env.PachClient.PutFileMetadata(repo, "master", "file", strings.NewReader("foo\n"), false,
+			map[string]string{"content-type": "text/plain", "source": "a"})
+		require.NoError(t, err)
+		fileInfo, err := env.PachClient.InspectFile(repo, "master", "file")
+		require.NoError(t, err)
+		require.Equal(t, map[string]string{"content-type": "text/plain", "source": "a"}, fileInfo.Metadata)
+
+		// Appending merges the new metadata into the existing metadata
+		_, err = env.PachClient.PutFileMetadata(repo, "master", "file", strings.NewReader("bar\n"), false,
+			map[string]string{"source": "b"})
+		require.NoError(t, err)
+		fileInfos, err := env.PachClient.ListFile(repo, "master", "/")
+		require.NoError(t, err)
+		require.Equal(t, 1, len(fileInfos))
+		require.Equal(t, map[string]string{"content-type": "text/plain", "source": "b"}, fileInfos[0].Metadata)
+
+		// Metadata travels with the file when it's copied
+		require.NoError(t, env.PachClient.CopyFile(repo, "master", "file", repo, "master", "copy", false))
+		fileInfo, err = env.PachClient.InspectFile(repo, "master", "copy")
+		require.NoError(t, err)
+		require.Equal(t, map[string]string{"content-type": "text/plain", "source": "b"}, fileInfo.Metadata)
+
+		// Overwriting the file replaces its metadata
+		_, err = env.PachClient.PutFileOverwrite(repo, "master", "file", strings.NewReader("baz\n"), 0)
+		require.NoError(t, err)
+		fileInfo, err = env.PachClient.InspectFile(repo, "master", "file")
+		require.NoError(t, err)
+		require.Equal(t, 0, len(fileInfo.Metadata))
+
+		// Metadata is attached to each of the files a split creates
+		_, err = env.PachClient.PutFileSplitMetadata(repo, "master", "split", pfs.Delimiter_LINE, "", 0, 0, 0, false,
+			map[string]string{"source": "c"}, strings.NewReader("a\nb\n"))
+		require.NoError(t, err)
+		fileInfos, err = env.PachClient.ListFile(repo, "master", "split")
+		require.NoError(t, err)
+		require.Equal(t, 2, len(fileInfos))
+		for _, fileInfo := range fileInfos {
+			require.Equal(t, map[string]string{"source": "c"}, fileInfo.Metadata)
+		}
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func TestSymlinks(t *testing.T) {
+	t.Parallel()
+	err := testpachd.WithRealEnv(func(env *testpachd.RealEnv) error {
+		repo := "repo"
+		require.NoError(t, env.PachClient.CreateRepo(repo))
+
+		_, err := env.PachClient.PutFile(repo, "master", "dir/file", strings.NewReader("foo\n"))
+		require.NoError(t, err)
+		require.NoError(t, env.PachClient.PutSymlink(repo, "master", "rel", "dir/file"))
+		require.NoError(t, env.PachClient.PutSymlink(repo, "master", "abs", "/dir"))
+		require.NoError(t, env.PachClient.PutSymlink(repo, "master", "dangling", "missing"))
+		require.NoError(t, env.PachClient.PutSymlink(repo, "master", "loop1", "loop2"))
+		require.NoError(t, env.PachClient.PutSymlink(repo, "master", "loop2", "loop1"))
+		// Relative targets can't climb above the root of the commit
+		require.YesError(t, env.PachClient.PutSymlink(repo, "master", "dir/escape", "../../etc/shadow"))
+		require.YesError(t, env.PachClient.PutSymlink(repo, "master", "escape", ".."))
+
+		// InspectFile describes the link itself
+		fileInfo, err := env.PachClient.InspectFile(repo, "master", "rel")
+		require.NoError(t, err)
+		require.Equal(t, pfs.FileType_SYMLINK, fileInfo.FileType)
+		require.Equal(t, "dir/file", fileInfo.SymlinkTarget)
+
+		// GetFile follows links, including in directory components
+		var buf bytes.Buffer
+		require.NoError(t, env.PachClient.GetFile(repo, "master", "rel", 0, 0, &buf))
+		require.Equal(t, "foo\n", buf.String())
+		buf.Reset()
+		require.NoError(t, env.PachClient.GetFile(repo, "master", "abs/file", 0, 0, &buf))
+		require.Equal(t, "foo\n", buf.String())
+		require.YesError(t, env.PachClient.GetFile(repo, "master", "dangling", 0, 0, &buf))
+		err = env.PachClient.GetFile(repo, "master", "loop1", 0, 0, &buf)
+		require.YesError(t, err)
+		require.Matches(t, "too many levels of symlinks", err.Error())
+
+		// GlobFile reports matched links as the files that they point to
+		fileInfos, err := env.PachClient.GlobFile(repo, "master", "rel")
+		require.NoError(t, err)
+		require.Equal(t, 1, len(fileInfos))
+		require.Equal(t, "/rel", fileInfos[0].File.Path)
+		require.Equal(t, pfs.FileType_FILE, fileInfos[0].FileType)
+		require.Equal(t, uint64(4), fileInfos[0].SizeBytes)
+
+		// Copying a link copies the link, not its target
+		require.NoError(t, env.PachClient.CopyFile(repo, "master", "rel", repo, "master", "dir/copy", false))
+		fileInfo, err = env.PachClient.InspectFile(repo, "master", "dir/copy")
+		require.NoError(t, err)
+		require.Equal(t, pfs.FileType_SYMLINK, fileInfo.FileType)
+		require.Equal(t, "dir/file", fileInfo.SymlinkTarget)
+
+		// Putting a file over a link replaces the link
+		_, err = env.PachClient.PutFileOverwrite(repo, "master", "rel", strings.NewReader("bar\n"), 0)
+		require.NoError(t, err)
+		fileInfo, err = env.PachClient.InspectFile(repo, "master", "rel")
+		require.NoError(t, err)
+		require.Equal(t, pfs.FileType_FILE, fileInfo.FileType)
+
+		// ...including when appending to a link from an earlier commit
+		_, err = env.PachClient.PutFile(repo, "master", "dangling", strings.NewReader("baz\n"))
+		require.NoError(t, err)
+		fileInfo, err = env.PachClient.InspectFile(repo, "master", "dangling")
+		require.NoError(t, err)
+		require.Equal(t, pfs.FileType_FILE, fileInfo.FileType)
+		buf.Reset()
+		require.NoError(t, env.PachClient.GetFile(repo, "master", "dangling", 0, 0, &buf))
+		require.Equal(t, "baz\n", buf.String())
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func TestGrepFile(t *testing.T) {
+	t.Parallel()
+	err := testpachd.WithRealEnv(func(env *testpachd.RealEnv) error {
+		repo := "repo"
+		require.NoError(t, env.PachClient.CreateRepo(repo))
+
+		_, err := env.PachClient.PutFile(repo, "master", "logs/a", strings.NewReader("ok\nerror: foo\nok\nERROR: bar"))
+		require.NoError(t, err)
+		_, err = env.PachClient.PutFile(repo, "master", "logs/b", strings.NewReader("ok\n"))
+		require.NoError(t, err)
+		_, err = env.PachClient.PutFile(repo, "master", "other", strings.NewReader("error: baz\n"))
+		require.NoError(t, err)
+
+		resps, err := env.PachClient.GrepFile(repo, "master", "/logs/*", "(?i)^error")
+		require.NoError(t, err)
+		require.Equal(t, 2, len(resps))
+		require.Equal(t, "/logs/a", resps[0].File.Path)
+		require.Equal(t, int64(2), resps[0].LineNumber)
+		require.Equal(t, "error: foo", resps[0].Line)
+		require.Equal(t, int64(4), resps[1].LineNumber)
+		require.Equal(t, "ERROR: bar", resps[1].Line)
+
+		// Results refer to the commit that was searched, not the branch
+		ci, err := env.PachClient.InspectCommit(repo, "master")
+		require.NoError(t, err)
+		require.Equal(t, ci.Commit.ID, resps[0].File.Commit.ID)
+
+		resps, err = env.PachClient.GrepFile(repo, "master", "/logs/*", "missing")
+		require.NoError(t, err)
+		require.Equal(t, 0, len(resps))
+
+		_, err = env.PachClient.GrepFile(repo, "master", "/logs/*", "(")
+		require.YesError(t, err)
+
+		// Long lines are truncated rather than read into memory whole
+		_, err = env.PachClient.PutFile(repo, "master", "long", strings.NewReader("error"+strings.Repeat("x", 1024*1024)))
+		require.NoError(t, err)
+		resps, err = env.PachClient.GrepFile(repo, "master", "/long", "^error")
+		require.NoError(t, err)
+		require.Equal(t, 1, len(resps))
+		require.Equal(t, 64*1024, len(resps[0].Line))
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func TestDiffFileContent(t *testing.T) {
+	t.Parallel()
+	err := testpachd.WithRealEnv(func(env *testpachd.RealEnv) error {
+		repo := "repo"
+		require.NoError(t, env.PachClient.CreateRepo(repo))
+
+		_, err := env.PachClient.PutFile(repo, "master", "modified", strings.NewReader("1\n2\n3\n"))
+		require.NoError(t, err)
+		_, err = env.PachClient.PutFile(repo, "master", "deleted", strings.NewReader("foo\n"))
+		require.NoError(t, err)
+		_, err = env.PachClient.PutFile(repo, "master", "binary", strings.NewReader("foo\x00"))
+		require.NoError(t, err)
+
+		commit, err := env.PachClient.StartCommit(repo, "master")
+		require.NoError(t, err)
+		require.NoError(t, env.PachClient.DeleteFile(repo, commit.ID, "modified"))
+		_, err = env.PachClient.PutFile(repo, commit.ID, "modified", strings.NewReader("1\ntwo\n3\n"))
+		require.NoError(t, err)
+		require.NoError(t, env.PachClient.DeleteFile(repo, commit.ID, "deleted"))
+		_, err = env.PachClient.PutFile(repo, commit.ID, "binary", strings.NewReader("bar\x00"))
+		require.NoError(t, err)
+		_, err = env.PachClient.PutFile(repo, commit.ID, "large", strings.NewReader(strings.Repeat("a", 100)))
+		require.NoError(t, err)
+		require.NoError(t, env.PachClient.FinishCommit(repo, commit.ID))
+
+		contentDiffs, err := env.PachClient.DiffFileContent(repo, commit.ID, "", "", "", "", false, 50)
+		require.NoError(t, err)
+		require.Equal(t, 4, len(contentDiffs))
+
+		// binary
+		require.Equal(t, "binary", contentDiffs[0].NewFile.Path)
+		require.True(t, contentDiffs[0].Binary)
+		require.Equal(t, "", contentDiffs[0].UnifiedDiff)
+
+		// deleted
+		require.Nil(t, contentDiffs[1].NewFile)
+		require.Equal(t, "deleted", contentDiffs[1].OldFile.Path)
+		require.Equal(t, "--- a/deleted\n+++ /dev/null\n@@ -1 +0,0 @@\n-foo\n", contentDiffs[1].UnifiedDiff)
+
+		// large
+		require.Equal(t, "large", contentDiffs[2].NewFile.Path)
+		require.Nil(t, contentDiffs[2].OldFile)
+		require.True(t, contentDiffs[2].TooLarge)
+
+		// modified
+		require.Equal(t, "modified", contentDiffs[3].NewFile.Path)
+		require.Equal(t, "modified", contentDiffs[3].OldFile.Path)
+		require.Equal(t, "--- a/modified\n+++ b/modified\n@@ -1,3 +1,3 @@\n 1\n-2\n+two\n 3\n", contentDiffs[3].UnifiedDiff)
+
+		// Files in different directories are paired by their relative paths
+		_, err = env.PachClient.PutFile(repo, "master", "dirs/a/file", strings.NewReader("1\n2\n"))
+		require.NoError(t, err)
+		_, err = env.PachClient.PutFile(repo, "master", "dirs/b/file", strings.NewReader("1\n3\n"))
+		require.NoError(t, err)
+		contentDiffs, err = env.PachClient.DiffFileContent(repo, "master", "dirs/b", repo, "master", "dirs/a", false, 0)
+		require.NoError(t, err)
+		require.Equal(t, 1, len(contentDiffs))
+		require.Equal(t, "dirs/b/file", contentDiffs[0].NewFile.Path)
+		require.Equal(t, "dirs/a/file", contentDiffs[0].OldFile.Path)
+		require.Equal(t, "--- a/dirs/a/file\n+++ b/dirs/b/file\n@@ -1,2 +1,2 @@\n 1\n-2\n+3\n", contentDiffs[0].UnifiedDiff)
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func TestMoveFile(t *testing.T) {
+	t.Parallel()
+	err := testpachd.WithRealEnv(func(env *testpachd.RealEnv) error {
+		repo := "repo"
+		require.NoError(t, env.PachClient.CreateRepo(repo))
+
+		_, err := env.PachClient.PutFile(repo, "master", "dir/a", strings.NewReader("a\n"))
+		require.NoError(t, err)
+		_, err = env.PachClient.PutFile(repo, "master", "dir/sub/b", strings.NewReader("b\n"))
+		require.NoError(t, err)
+		_, err = env.PachClient.PutFile(repo, "master", "other", strings.NewReader("other\n"))
+		require.NoError(t, err)
+
+		// Moving on a branch whose head is finished creates a new commit
+		require.NoError(t, env.PachClient.MoveFile(repo, "master", "dir", "moved"))
+		fileInfos, err := env.PachClient.ListFile(repo, "master", "/")
+		require.NoError(t, err)
+		require.Equal(t, 2, len(fileInfos))
+		require.Equal(t, "/moved", fileInfos[0].File.Path)
+		require.Equal(t, "/other", fileInfos[1].File.Path)
+		var buf bytes.Buffer
+		require.NoError(t, env.PachClient.GetFile(repo, "master", "moved/sub/b", 0, 0, &buf))
+		require.Equal(t, "b\n", buf.String())
+
+		// Moving in an open commit includes writes made earlier in the commit
+		commit, err := env.PachClient.StartCommit(repo, "master")
+		require.NoError(t, err)
+		_, err = env.PachClient.PutFile(repo, commit.ID, "other", strings.NewReader("more\n"))
+		require.NoError(t, err)
+		require.NoError(t, env.PachClient.MoveFile(repo, commit.ID, "other", "moved/other"))
+		require.NoError(t, env.PachClient.FinishCommit(repo, commit.ID))
+		_, err = env.PachClient.InspectFile(repo, commit.ID, "other")
+		require.YesError(t, err)
+		buf.Reset()
+		require.NoError(t, env.PachClient.GetFile(repo, commit.ID, "moved/other", 0, 0, &buf))
+		require.Equal(t, "other\nmore\n", buf.String())
+
+		// Invalid moves
+		require.YesError(t, env.PachClient.MoveFile(repo, "master", "moved/a", "moved/other"))
+		require.YesError(t, env.PachClient.MoveFile(repo, "master", "moved", "moved/sub/moved"))
+		require.YesError(t, env.PachClient.MoveFile(repo, "master", "missing", "present"))
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func TestRenameRepo(t *testing.T) {
+	t.Parallel()
+	err := testpachd.WithRealEnv(func(env *testpachd.RealEnv) error {
+		require.NoError(t, env.PachClient.CreateRepo("in"))
+		require.NoError(t, env.PachClient.CreateRepo("out"))
+		require.NoError(t, env.PachClient.CreateBranch("out", "master", "", []*pfs.Branch{pclient.NewBranch("in", "master")}))
+		_, err := env.PachClient.PutFile("in", "master", "file", strings.NewReader("foo\n"))
+		require.NoError(t, err)
+		// Leave a commit open, so that its writes have to be moved too
+		commit, err := env.PachClient.StartCommit("in", "master")
+		require.NoError(t, err)
+		_, err = env.PachClient.PutFile("in", commit.ID, "open", strings.NewReader("bar\n"))
+		require.NoError(t, err)
+
+		require.NoError(t, env.PachClient.RenameRepo("in", "renamed"))
+		_, err = env.PachClient.InspectRepo("in")
+		require.YesError(t, err)
+		repoInfo, err := env.PachClient.InspectRepo("renamed")
+		require.NoError(t, err)
+		require.Equal(t, "renamed", repoInfo.Repo.Name)
+		require.Equal(t, "renamed", repoInfo.Branches[0].Repo.Name)
+
+		require.NoError(t, env.PachClient.FinishCommit("renamed", commit.ID))
+		var buf bytes.Buffer
+		require.NoError(t, env.PachClient.GetFile("renamed", "master", "file", 0, 0, &buf))
+		require.Equal(t, "foo\n", buf.String())
+		buf.Reset()
+		require.NoError(t, env.PachClient.GetFile("renamed", "master", "open", 0, 0, &buf))
+		require.Equal(t, "bar\n", buf.String())
+
+		// Downstream branches and commits refer to the new name
+		branchInfo, err := env.PachClient.InspectBranch("out", "master")
+		require.NoError(t, err)
+		require.Equal(t, 1, len(branchInfo.Provenance))
+		require.Equal(t, "renamed", branchInfo.Provenance[0].Repo.Name)
+		commitInfo, err := env.PachClient.InspectCommit("out", "master")
+		require.NoError(t, err)
+		require.Equal(t, "renamed", commitInfo.Provenance[0].Commit.Repo.Name)
+		require.Equal(t, "renamed", commitInfo.Provenance[0].Branch.Repo.Name)
+
+		// Invalid renames
+		require.YesError(t, env.PachClient.RenameRepo("renamed", "out"))
+		require.YesError(t, env.PachClient.RenameRepo("missing", "other"))
+		require.YesError(t, env.PachClient.RenameRepo("renamed", "bad name"))
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func TestRenameBranch(t *testing.T) {
+	t.Parallel()
+	err := testpachd.WithRealEnv(func(env *testpachd.RealEnv) error {
+		require.NoError(t, env.PachClient.CreateRepo("in"))
+		require.NoError(t, env.PachClient.CreateRepo("out"))
+		require.NoError(t, env.PachClient.CreateBranch("out", "master", "", []*pfs.Branch{pclient.NewBranch("in", "master")}))
+		_, err := env.PachClient.PutFile("in", "master", "file", strings.NewReader("foo\n"))
+		require.NoError(t, err)
+
+		require.NoError(t, env.PachClient.RenameBranch("in", "master", "main"))
+		_, err = env.PachClient.InspectBranch("in", "master")
+		require.YesError(t, err)
+		branchInfo, err := env.PachClient.InspectBranch("in", "main")
+		require.NoError(t, err)
+		require.Equal(t, "main", branchInfo.Branch.Name)
+		require.Equal(t, 1, len(branchInfo.Subvenance))
+		repoInfo, err := env.PachClient.InspectRepo("in")
+		require.NoError(t, err)
+		require.Equal(t, 1, len(repoInfo.Branches))
+		require.Equal(t, "main", repoInfo.Branches[0].Name)
+
+		commitInfo, err := env.PachClient.InspectCommit("in", "main")
+		require.NoError(t, err)
+		require.Equal(t, "main", commitInfo.Branch.Name)
+		branchInfo, err = env.PachClient.InspectBranch("out", "master")
+		require.NoError(t, err)
+		require.Equal(t, "main", branchInfo.Provenance[0].Name)
+		commitInfo, err = env.PachClient.InspectCommit("out", "master")
+		require.NoError(t, err)
+		require.Equal(t, "main", commitInfo.Provenance[0].Branch.Name)
+
+		// New commits on the renamed branch still propagate downstream
+		_, err = env.PachClient.PutFile("in", "main", "file2", strings.NewReader("bar\n"))
+		require.NoError(t, err)
+		commitInfos, err := env.PachClient.ListCommit("out", "master", "", 0)
+		require.NoError(t, err)
+		require.Equal(t, 2, len(commitInfos))
+
+		// Invalid renames
+		require.NoError(t, env.PachClient.CreateBranch("in", "other", "main", nil))
+		require.YesError(t, env.PachClient.RenameBranch("in", "main", "other"))
+		require.YesError(t, env.PachClient.RenameBranch("in", "missing", "new"))
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func TestRetentionPolicy(t *testing.T) {
+	t.Parallel()
+
+	pachdConfig := &serviceenv.PachdFullConfiguration{}
+	pachdConfig.PFSReaperInterval = "100ms"
+
+	err := testpachd.WithRealEnv(func(env *testpachd.RealEnv) error {
+		_, err := env.PachClient.PfsAPIClient.CreateRepo(env.Context, &pfs.CreateRepoRequest{
+			Repo:      pclient.NewRepo("in"),
+			Retention: &pfs.RetentionPolicy{KeepCommits: 2},
+		})
+		require.NoError(t, err)
+		repoInfo, err := env.PachClient.InspectRepo("in")
+		require.NoError(t, err)
+		require.Equal(t, int64(2), repoInfo.Retention.KeepCommits)
+
+		// Updating the repo without a policy leaves the policy as it is
+		_, err = env.PachClient.PfsAPIClient.CreateRepo(env.Context, &pfs.CreateRepoRequest{
+			Repo:        pclient.NewRepo("in"),
+			Description: "input",
+			Update:      true,
+		})
+		require.NoError(t, err)
+		repoInfo, err = env.PachClient.InspectRepo("in")
+		require.NoError(t, err)
+		require.Equal(t, "input", repoInfo.Description)
+		require.Equal(t, int64(2), repoInfo.Retention.KeepCommits)
+
+		// Commits in 'out' that are the head of a branch keep their
+		// provenance in 'in' alive
+		require.NoError(t, env.PachClient.CreateRepo("out"))
+		require.NoError(t, env.PachClient.CreateBranch("out", "master", "", []*pfs.Branch{pclient.NewBranch("in", "master")}))
+		_, err = env.PachClient.PutFile("in", "master", "file", strings.NewReader("0\n"))
+		require.NoError(t, err)
+		require.NoError(t, env.PachClient.FinishCommit("out", "master"))
+		live, err := env.PachClient.InspectCommit("in", "master")
+		require.NoError(t, err)
+		require.NoError(t, env.PachClient.CreateBranch("out", "live", "master", nil))
+		for i := 1; i < 5; i++ {
+			_, err = env.PachClient.PutFile("in", "master", "file", strings.NewReader(fmt.Sprintf("%d\n", i)))
+			require.NoError(t, err)
+			require.NoError(t, env.PachClient.FinishCommit("out", "master"))
+		}
+
+		require.NoError(t, backoff.Retry(func() error {
+			commitInfos, err := env.PachClient.ListCommit("in", "master", "", 0)
+			if err != nil {
+				return err
+			}
+			if len(commitInfos) != 3 {
+				return errors.Errorf("expected 3 commits, but got %d", len(commitInfos))
+			}
+			return nil
+		}, backoff.NewTestingBackOff()))
+		commitInfos, err := env.PachClient.ListCommit("in", "master", "", 0)
+		require.NoError(t, err)
+		require.Equal(t, live.Commit.ID, commitInfos[2].Commit.ID)
+
+		// The content of the kept commits is unchanged
+		var buf bytes.Buffer
+		require.NoError(t, env.PachClient.GetFile("in", "master", "file", 0, 0, &buf))
+		require.Equal(t, "0\n1\n2\n3\n4\n", buf.String())
+
+		// Invalid policies
+		_, err = env.PachClient.PfsAPIClient.CreateRepo(env.Context, &pfs.CreateRepoRequest{
+			Repo:      pclient.NewRepo("invalid"),
+			Retention: &pfs.RetentionPolicy{KeepCommits: -1},
+		})
+		require.YesError(t, err)
+		_, err = env.PachClient.PfsAPIClient.CreateRepo(env.Context, &pfs.CreateRepoRequest{
+			Repo:      pclient.NewRepo("invalid"),
+			Retention: &pfs.RetentionPolicy{KeepDuration: types.DurationProto(-time.Hour)},
+		})
+		require.YesError(t, err)
+
+		// Updating the repo with an empty policy removes it
+		_, err = env.PachClient.PfsAPIClient.CreateRepo(env.Context, &pfs.CreateRepoRequest{
+			Repo:      pclient.NewRepo("in"),
+			Update:    true,
+			Retention: &pfs.RetentionPolicy{},
+		})
+		require.NoError(t, err)
+		repoInfo, err = env.PachClient.InspectRepo("in")
+		require.NoError(t, err)
+		require.Nil(t, repoInfo.Retention)
+		return nil
+	}, pachdConfig)
+	require.NoError(t, err)
+}
+
+func TestRepoQuota(t *testing.T) {
+	t.Parallel()
+	err := testpachd.WithRealEnv(func(env *testpachd.RealEnv) error {
+		repo := "repo"
+		_, err := env.PachClient.PfsAPIClient.CreateRepo(env.Context, &pfs.CreateRepoRequest{
+			Repo:  pclient.NewRepo(repo),
+			Quota: &pfs.RepoQuota{SizeBytes: 10, Commits: 3},
+		})
+		require.NoError(t, err)
+
+		// Writes that fit within the size quota succeed
+		_, err = env.PachClient.PutFile(repo, "master", "foo", strings.NewReader("foo\n"))
+		require.NoError(t, err)
+		repoInfo, err := env.PachClient.InspectRepo(repo)
+		require.NoError(t, err)
+		require.Equal(t, uint64(4), repoInfo.SizeBytes)
+		require.Equal(t, int64(1), repoInfo.Commits)
+		require.Equal(t, uint64(10), repoInfo.Quota.SizeBytes)
+
+		// Writes that would exceed it fail, in PutFile or FinishCommit
+		_, err = env.PachClient.PutFile(repo, "master", "big", strings.NewReader("0123456789\n"))
+		require.YesError(t, err)
+		require.Matches(t, "would exceed its size quota", err.Error())
+		commit, err := env.PachClient.StartCommit(repo, "master")
+		require.NoError(t, err)
+		_, err = env.PachClient.PutFile(repo, commit.ID, "bar", strings.NewReader("bar\n"))
+		require.NoError(t, err)
+		_, err = env.PachClient.PutFile(repo, commit.ID, "buzz", strings.NewReader("buzz\n"))
+		require.NoError(t, err)
+		err = env.PachClient.FinishCommit(repo, commit.ID)
+		require.YesError(t, err)
+		require.Matches(t, "would exceed its size quota", err.Error())
+		require.NoError(t, env.PachClient.DeleteFile(repo, commit.ID, "buzz"))
+		require.NoError(t, env.PachClient.FinishCommit(repo, commit.ID))
+
+		// New commits that would exceed the commit quota fail
+		require.NoError(t, env.PachClient.DeleteFile(repo, "master", "bar"))
+		_, err = env.PachClient.StartCommit(repo, "master")
+		require.YesError(t, err)
+		require.Matches(t, "would exceed its commit quota", err.Error())
+
+		// Raising the quota allows new commits
+		_, err = env.PachClient.PfsAPIClient.CreateRepo(env.Context, &pfs.CreateRepoRequest{
+			Repo:   pclient.NewRepo(repo),
+			Update: true,
+			Quota:  &pfs.RepoQuota{SizeBytes: 10, Commits: 4},
+		})
+		require.NoError(t, err)
+		_, err = env.PachClient.StartCommit(repo, "master")
+		require.NoError(t, err)
+
+		// Updating the repo without a quota leaves the quota as it is, and an
+		// empty quota removes it
+		_, err = env.PachClient.PfsAPIClient.CreateRepo(env.Context, &pfs.CreateRepoRequest{
+			Repo:        pclient.NewRepo(repo),
+			Description: "quota",
+			Update:      true,
+		})
+		require.NoError(t, err)
+		repoInfo, err = env.PachClient.InspectRepo(repo)
+		require.NoError(t, err)
+		require.Equal(t, int64(4), repoInfo.Quota.Commits)
+		_, err = env.PachClient.PfsAPIClient.CreateRepo(env.Context, &pfs.CreateRepoRequest{
+			Repo:   pclient.NewRepo(repo),
+			Update: true,
+			Quota:  &pfs.RepoQuota{},
+		})
+		require.NoError(t, err)
+		repoInfo, err = env.PachClient.InspectRepo(repo)
+		require.NoError(t, err)
+		require.Nil(t, repoInfo.Quota)
+
+		_, err = env.PachClient.PfsAPIClient.CreateRepo(env.Context, &pfs.CreateRepoRequest{
+			Repo:  pclient.NewRepo("negative"),
+			Quota: &pfs.RepoQuota{Commits: -1},
+		})
+		require.YesError(t, err)
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func TestForkRepo(t *testing.T) {
+	t.Parallel()
+	err := testpachd.WithRealEnv(func(env *testpachd.RealEnv) error {
+		require.NoError(t, env.PachClient.CreateRepo("src"))
+		_, err := env.PachClient.PutFile("src", "master", "foo", strings.NewReader("foo\n"))
+		require.NoError(t, err)
+		_, err = env.PachClient.PutFile("src", "master", "bar", strings.NewReader("bar\n"))
+		require.NoError(t, err)
+		srcHead, err := env.PachClient.InspectCommit("src", "master")
+		require.NoError(t, err)
+
+		require.NoError(t, env.PachClient.ForkRepo("src", "dst", ""))
+		repoInfo, err := env.PachClient.InspectRepo("dst")
+		require.NoError(t, err)
+		require.Equal(t, srcHead.Commit.ID, repoInfo.ForkOrigin.ID)
+		require.Equal(t, "src", repoInfo.ForkOrigin.Repo.Name)
+		require.Equal(t, srcHead.SizeBytes, repoInfo.SizeBytes)
+		dstHead, err := env.PachClient.InspectCommit("dst", "master")
+		require.NoError(t, err)
+		require.Equal(t, srcHead.Tree.Hash, dstHead.Tree.Hash)
+		require.Nil(t, dstHead.ParentCommit)
+
+		// Changes to the fork don't affect the source, and vice versa
+		require.NoError(t, env.PachClient.DeleteFile("dst", "master", "foo"))
+		_, err = env.PachClient.PutFile("dst", "master", "buzz", strings.NewReader("buzz\n"))
+		require.NoError(t, err)
+		_, err = env.PachClient.PutFile("src", "master", "fizz", strings.NewReader("fizz\n"))
+		require.NoError(t, err)
+		var buffer bytes.Buffer
+		require.NoError(t, env.PachClient.GetFile("src", "master", "foo", 0, 0, &buffer))
+		require.Equal(t, "foo\n", buffer.String())
+		fileInfos, err := env.PachClient.ListFile("src", "master", "")
+		require.NoError(t, err)
+		require.Equal(t, 3, len(fileInfos))
+		fileInfos, err = env.PachClient.ListFile("dst", "master", "")
+		require.NoError(t, err)
+		require.Equal(t, 2, len(fileInfos))
+		buffer.Reset()
+		require.NoError(t, env.PachClient.GetFile("dst", "master", "bar", 0, 0, &buffer))
+		require.Equal(t, "bar\n", buffer.String())
+
+		// Forking into an existing repo fails
+		require.YesError(t, env.PachClient.ForkRepo("src", "dst", ""))
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func TestAppendOnlyRepo(t *testing.T) {
+	t.Parallel()
+	err := testpachd.WithRealEnv(func(env *testpachd.RealEnv) error {
+		repo := "repo"
+		_, err := env.PachClient.PfsAPIClient.CreateRepo(env.Context, &pfs.CreateRepoRequest{
+			Repo:         pclient.NewRepo(repo),
+			Immutability: &pfs.ImmutabilityPolicy{AppendOnly: true},
+		})
+		require.NoError(t, err)
+
+		// Appending to files and adding new files succeeds
+		_, err = env.PachClient.PutFile(repo, "master", "foo", strings.NewReader("foo\n"))
+		require.NoError(t, err)
+		_, err = env.PachClient.PutFile(repo, "master", "foo", strings.NewReader("bar\n"))
+		require.NoError(t, err)
+		var buf bytes.Buffer
+		require.NoError(t, env.PachClient.GetFile(repo, "master", "foo", 0, 0, &buf))
+		require.Equal(t, "foo\nbar\n", buf.String())
+		commitInfo, err := env.PachClient.InspectCommit(repo, "master")
+		require.NoError(t, err)
+
+		// Removing data fails
+		_, err = env.PachClient.PutFileOverwrite(repo, "master", "foo", strings.NewReader("buzz\n"), 0)
+		require.YesError(t, err)
+		require.Matches(t, "append-only", err.Error())
+		err = env.PachClient.DeleteFile(repo, "master", "foo")
+		require.YesError(t, err)
+		require.Matches(t, "append-only", err.Error())
+		err = env.PachClient.DeleteCommit(repo, commitInfo.Commit.ID)
+		require.YesError(t, err)
+		require.Matches(t, "append-only", err.Error())
+		err = env.PachClient.DeleteRepo(repo, true)
+		require.YesError(t, err)
+		require.Matches(t, "append-only", err.Error())
+
+		// A legal hold requires append-only, and can't be removed or shortened
+		// while it's in effect
+		until, err := types.TimestampProto(time.Now().Add(time.Hour))
+		require.NoError(t, err)
+		_, err = env.PachClient.PfsAPIClient.CreateRepo(env.Context, &pfs.CreateRepoRequest{
+			Repo:         pclient.NewRepo(repo),
+			Update:       true,
+			Immutability: &pfs.ImmutabilityPolicy{LegalHoldUntil: until},
+		})
+		require.YesError(t, err)
+		_, err = env.PachClient.PfsAPIClient.CreateRepo(env.Context, &pfs.CreateRepoRequest{
+			Repo:         pclient.NewRepo(repo),
+			Update:       true,
+			Immutability: &pfs.ImmutabilityPolicy{AppendOnly: true, LegalHoldUntil: until},
+		})
+		require.NoError(t, err)
+		_, err = env.PachClient.PfsAPIClient.CreateRepo(env.Context, &pfs.CreateRepoRequest{
+			Repo:         pclient.NewRepo(repo),
+			Update:       true,
+			Immutability: &pfs.ImmutabilityPolicy{},
+		})
+		require.YesError(t, err)
+		require.Matches(t, "legal hold", err.Error())
+
+		// Updates that don't set an immutability policy leave it as it is, even
+		// under a legal hold
+		_, err = env.PachClient.PfsAPIClient.CreateRepo(env.Context, &pfs.CreateRepoRequest{
+			Repo:        pclient.NewRepo(repo),
+			Description: "held",
+			Update:      true,
+		})
+		require.NoError(t, err)
+		repoInfo, err := env.PachClient.InspectRepo(repo)
+		require.NoError(t, err)
+		require.True(t, repoInfo.Immutability.AppendOnly)
+		require.NotNil(t, repoInfo.Immutability.LegalHoldUntil)
+
+		// Without a legal hold, the repo can be made writable again
+		other := "other"
+		_, err = env.PachClient.PfsAPIClient.CreateRepo(env.Context, &pfs.CreateRepoRequest{
+			Repo:         pclient.NewRepo(other),
+			Immutability: &pfs.ImmutabilityPolicy{AppendOnly: true},
+		})
+		require.NoError(t, err)
+		_, err = env.PachClient.PfsAPIClient.CreateRepo(env.Context, &pfs.CreateRepoRequest{
+			Repo:   pclient.NewRepo(other),
+			Update: true,
+		})
+		require.NoError(t, err)
+		err = env.PachClient.DeleteRepo(other, false)
+		require.YesError(t, err)
+		require.Matches(t, "append-only", err.Error())
+		_, err = env.PachClient.PfsAPIClient.CreateRepo(env.Context, &pfs.CreateRepoRequest{
+			Repo:         pclient.NewRepo(other),
+			Update:       true,
+			Immutability: &pfs.ImmutabilityPolicy{},
+		})
+		require.NoError(t, err)
+		require.NoError(t, env.PachClient.DeleteRepo(other, false))
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func TestPurgeFile(t *testing.T) {
+	t.Parallel()
+	err := testpachd.WithRealEnv(func(env *testpachd.RealEnv) error {
+		require.NoError(t, env.PachClient.CreateRepo("in"))
+		require.NoError(t, env.PachClient.CreateRepo("out"))
+		require.NoError(t, env.PachClient.CreateBranch("out", "master", "", []*pfs.Branch{pclient.NewBranch("in", "master")}))
+
+		commit1, err := env.PachClient.StartCommit("in", "master")
+		require.NoError(t, err)
+		_, err = env.PachClient.PutFile("in", commit1.ID, "foo", strings.NewReader("foo\n"))
+		require.NoError(t, err)
+		_, err = env.PachClient.PutFile("in", commit1.ID, "secret", strings.NewReader("secret\n"))
+		require.NoError(t, err)
+		require.NoError(t, env.PachClient.FinishCommit("in", commit1.ID))
+		require.NoError(t, env.PachClient.FinishCommit("out", "master"))
+		commit2, err := env.PachClient.StartCommit("in", "master")
+		require.NoError(t, err)
+		_, err = env.PachClient.PutFile("in", commit2.ID, "bar", strings.NewReader("bar\n"))
+		require.NoError(t, err)
+		require.NoError(t, env.PachClient.FinishCommit("in", commit2.ID))
+		require.NoError(t, env.PachClient.FinishCommit("out", "master"))
+		outCommits, err := env.PachClient.ListCommit("out", "master", "", 0)
+		require.NoError(t, err)
+		require.Equal(t, 2, len(outCommits))
+
+		// Read the file first, so that the old trees are cached
+		var buf bytes.Buffer
+		require.NoError(t, env.PachClient.GetFile("in", commit1.ID, "secret", 0, 0, &buf))
+
+		// Purging rewrites every commit that contains the file, and lists the
+		// output commits derived from them
+		resp, err := env.PachClient.PurgeFile("in", "/secret", false)
+		require.NoError(t, err)
+		require.Equal(t, 2, len(resp.Rewritten))
+		require.Equal(t, 2, len(resp.Outputs))
+		require.True(t, len(resp.Objects) > 0)
+		for _, commit := range []*pfs.Commit{commit1, commit2} {
+			var buf bytes.Buffer
+			require.YesError(t, env.PachClient.GetFile("in", commit.ID, "secret", 0, 0, &buf))
+			require.NoError(t, env.PachClient.GetFile("in", commit.ID, "foo", 0, 0, &buf))
+		}
+		repoInfo, err := env.PachClient.InspectRepo("in")
+		require.NoError(t, err)
+		require.Equal(t, uint64(8), repoInfo.SizeBytes)
+		_, err = env.PachClient.InspectCommit("out", outCommits[0].Commit.ID)
+		require.NoError(t, err)
+
+		// Purging a path that doesn't exist rewrites nothing
+		resp, err = env.PachClient.PurgeFile("in", "/secret", false)
+		require.NoError(t, err)
+		require.Equal(t, 0, len(resp.Rewritten))
+
+		// Deleting the outputs removes the derived commits and starts a new
+		// output commit for the purged input
+		resp, err = env.PachClient.PurgeFile("in", "/bar", true)
+		require.NoError(t, err)
+		require.Equal(t, 1, len(resp.Rewritten))
+		require.Equal(t, 1, len(resp.Outputs))
+		_, err = env.PachClient.InspectCommit("out", resp.Outputs[0].ID)
+		require.YesError(t, err)
+		outHead, err := env.PachClient.InspectCommit("out", "master")
+		require.NoError(t, err)
+		require.Nil(t, outHead.Finished)
+		var provIDs []string
+		for _, prov := range outHead.Provenance {
+			provIDs = append(provIDs, prov.Commit.ID)
+		}
+		require.OneOfEquals(t, commit2.ID, provIDs)
+
+		// Forks, and forks of forks, still contain the purged data
+		require.NoError(t, env.PachClient.ForkRepo("in", "fork", "master"))
+		require.NoError(t, env.PachClient.ForkRepo("fork", "fork2", "master"))
+		resp, err = env.PachClient.PurgeFile("in", "/foo", false)
+		require.NoError(t, err)
+		var forks []string
+		for _, fork := range resp.Forks {
+			forks = append(forks, fork.Name)
+		}
+		require.ElementsEqual(t, []string{"fork", "fork2"}, forks)
+		buf.Reset()
+		require.NoError(t, env.PachClient.GetFile("fork2", "master", "foo", 0, 0, &buf))
+		require.Equal(t, "foo\n", buf.String())
+
+		// Purging the root isn't allowed
+		_, err = env.PachClient.PurgeFile("in", "/", false)
+		require.YesError(t, err)
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func TestResumableUpload(t *testing.T) {
+	t.Parallel()
+	err := testpachd.WithRealEnv(func(env *testpachd.RealEnv) error {
+		repo := "test"
+		require.NoError(t, env.PachClient.CreateRepo(repo))
+		uploadInfo, err := env.PachClient.StartUpload(repo, "master", "file", false)
+		require.NoError(t, err)
+
+		// Chunks can be put out of order, and retried at the same offset
+		_, err = env.PachClient.PutChunk(uploadInfo.ID, 4, strings.NewReader("bar\n"))
+		require.NoError(t, err)
+		_, err = env.PachClient.PutChunk(uploadInfo.ID, 8, strings.NewReader("bzz\n"))
+		require.NoError(t, err)
+		uploadInfo, err = env.PachClient.PutChunk(uploadInfo.ID, 8, strings.NewReader("baz\n"))
+		require.NoError(t, err)
+		require.Equal(t, 2, len(uploadInfo.Chunks))
+		require.Equal(t, int64(0), uploadInfo.UploadedBytes())
+		// Overlapping chunks are rejected
+		_, err = env.PachClient.PutChunk(uploadInfo.ID, 6, strings.NewReader("xxxx"))
+		require.YesError(t, err)
+		// The upload can't be completed until every byte has been uploaded
+		require.YesError(t, env.PachClient.CompleteUpload(uploadInfo.ID))
+		uploadInfo, err = env.PachClient.PutChunk(uploadInfo.ID, 0, strings.NewReader("foo\n"))
+		require.NoError(t, err)
+		require.Equal(t, int64(12), uploadInfo.UploadedBytes())
+		uploadInfos, err := env.PachClient.ListUpload(repo)
+		require.NoError(t, err)
+		require.Equal(t, 1, len(uploadInfos))
+
+		require.NoError(t, env.PachClient.CompleteUpload(uploadInfo.ID))
+		var buf bytes.Buffer
+		require.NoError(t, env.PachClient.GetFile(repo, "master", "file", 0, 0, &buf))
+		require.Equal(t, "foo\nbar\nbaz\n", buf.String())
+		err = env.PachClient.CompleteUpload(uploadInfo.ID)
+		require.YesError(t, err)
+		require.True(t, pfsserver.IsUploadNotFoundErr(err))
+		uploadInfos, err = env.PachClient.ListUpload(repo)
+		require.NoError(t, err)
+		require.Equal(t, 0, len(uploadInfos))
+
+		// Abandoned uploads can be deleted
+		uploadInfo, err = env.PachClient.StartUpload(repo, "master", "file", true)
+		require.NoError(t, err)
+		require.NoError(t, env.PachClient.DeleteUpload(uploadInfo.ID))
+		_, err = env.PachClient.InspectUpload(uploadInfo.ID)
+		require.True(t, pfsserver.IsUploadNotFoundErr(err))
+
+		// PutFileResumable picks up an upload where its state file left it
+		dir, err := ioutil.TempDir("", "pachyderm-upload-")
+		require.NoError(t, err)
+		defer os.RemoveAll(dir)
+		statePath := filepath.Join(dir, "state.json")
+		uploadInfo, err = env.PachClient.StartUpload(repo, "master", "resumed", true)
+		require.NoError(t, err)
+		_, err = env.PachClient.PutChunk(uploadInfo.ID, 0, strings.NewReader("foo\n"))
+		require.NoError(t, err)
+		require.NoError(t, ioutil.WriteFile(statePath, []byte(fmt.Sprintf(
+			`{"upload_id":%q,"repo":%q,"commit":"master","path":"resumed","offset":4,"source_size":8}`, uploadInfo.ID, repo)), 0644))
+		// The data can't be resumed if it's changed since the upload started
+		err = env.PachClient.PutFileResumable(repo, "master", "resumed", strings.NewReader("xxx\nbar\nbaz\n"), true, statePath)
+		require.YesError(t, err)
+		require.Matches(t, "has changed", err.Error())
+		// The first chunk is skipped, so "xxx\n" is never uploaded
+		require.NoError(t, env.PachClient.PutFileResumable(repo, "master", "resumed", strings.NewReader("xxx\nbar\n"), true, statePath))
+		buf.Reset()
+		require.NoError(t, env.PachClient.GetFile(repo, "master", "resumed", 0, 0, &buf))
+		require.Equal(t, "foo\nbar\n", buf.String())
+		_, err = os.Stat(statePath)
+		require.True(t, os.IsNotExist(err))
+
+		// An upload that was completed before its state file was removed isn't
+		// uploaded again
+		require.NoError(t, ioutil.WriteFile(statePath, []byte(fmt.Sprintf(
+			`{"upload_id":%q,"repo":%q,"commit":"master","path":"resumed","offset":8,"source_size":4,"completing":true}`, uploadInfo.ID, repo)), 0644))
+		require.NoError(t, env.PachClient.PutFileResumable(repo, "master", "resumed", strings.NewReader("baz\n"), false, statePath))
+		buf.Reset()
+		require.NoError(t, env.PachClient.GetFile(repo, "master", "resumed", 0, 0, &buf))
+		require.Equal(t, "foo\nbar\n", buf.String())
+		_, err = os.Stat(statePath)
+		require.True(t, os.IsNotExist(err))
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func TestUploadTTL(t *testing.T) {
+	t.Parallel()
+	pachdConfig := &serviceenv.PachdFullConfiguration{}
+	pachdConfig.PFSReaperInterval = "100ms"
+	pachdConfig.PFSUploadTTL = "1s"
+
+	err := testpachd.WithRealEnv(func(env *testpachd.RealEnv) error {
+		repo := "test"
+		require.NoError(t, env.PachClient.CreateRepo(repo))
+		uploadInfo, err := env.PachClient.StartUpload(repo, "master", "file", false)
+		require.NoError(t, err)
+		_, err = env.PachClient.PutChunk(uploadInfo.ID, 0, strings.NewReader("foo\n"))
+		require.NoError(t, err)
+		uploadInfo, err = env.PachClient.InspectUpload(uploadInfo.ID)
+		require.NoError(t, err)
+		require.NotNil(t, uploadInfo.Updated)
+
+		// Sessions that aren't updated within the TTL are deleted
+		require.NoErrorWithinTRetry(t, 30*time.Second, func() error {
+			_, err := env.PachClient.InspectUpload(uploadInfo.ID)
+			if !pfsserver.IsUploadNotFoundErr(err) {
+				return errors.Errorf("upload %s hasn't been deleted (err: %v)", uploadInfo.ID, err)
+			}
+			return nil
+		})
+		return nil
+	}, pachdConfig)
+	require.NoError(t, err)
+}
+
+func TestGetFileArchive(t *testing.T) {
+	t.Parallel()
+	err := testpachd.WithRealEnv(func(env *testpachd.RealEnv) error {
+		repo := "test"
+		require.NoError(t, env.PachClient.CreateRepo(repo))
+		_, err := env.PachClient.PutFile(repo, "master", "dir/a.csv", strings.NewReader("a\n"))
+		require.NoError(t, err)
+		_, err = env.PachClient.PutFile(repo, "master", "dir/sub/b.txt", strings.NewReader("bb\n"))
+		require.NoError(t, err)
+		_, err = env.PachClient.PutFile(repo, "master", "c.csv", strings.NewReader("ccc\n"))
+		require.NoError(t, err)
+		fileInfo, err := env.PachClient.InspectFile(repo, "master", "dir/a.csv")
+		require.NoError(t, err)
+		committed, err := types.TimestampFromProto(fileInfo.Committed)
+		require.NoError(t, err)
+
+		readTar := func(r io.Reader) map[string]string {
+			files := make(map[string]string)
+			tr := tar.NewReader(r)
+			for {
+				header, err := tr.Next()
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				require.NoError(t, err)
+				if header.Typeflag == tar.TypeDir {
+					files[header.Name] = ""
+					continue
+				}
+				require.Equal(t, committed.Unix(), header.ModTime.Unix())
+				content, err := ioutil.ReadAll(tr)
+				require.NoError(t, err)
+				files[header.Name] = string(content)
+			}
+			return files
+		}
+		expected := map[string]string{
+			"dir/":          "",
+			"dir/a.csv":     "a\n",
+			"dir/sub/":      "",
+			"dir/sub/b.txt": "bb\n",
+		}
+
+		var buf bytes.Buffer
+		require.NoError(t, env.PachClient.GetFileArchive(repo, "master", "dir", pfs.ArchiveFormat_TAR, &buf))
+		require.Equal(t, expected, readTar(&buf))
+
+		buf.Reset()
+		require.NoError(t, env.PachClient.GetFileArchive(repo, "master", "dir", pfs.ArchiveFormat_TAR_GZ, &buf))
+		gr, err := gzip.NewReader(&buf)
+		require.NoError(t, err)
+		require.Equal(t, expected, readTar(gr))
+
+		buf.Reset()
+		require.NoError(t, env.PachClient.GetFileArchive(repo, "master", "dir", pfs.ArchiveFormat_ZIP, &buf))
+		zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+		require.NoError(t, err)
+		files := make(map[string]string)
+		for _, f := range zr.File {
+			rc, err := f.Open()
+			require.NoError(t, err)
+			content, err := ioutil.ReadAll(rc)
+			require.NoError(t, err)
+			require.NoError(t, rc.Close())
+			files[f.Name] = string(content)
+		}
+		require.Equal(t, expected, files)
+
+		// Globs select the files to archive
+		buf.Reset()
+		require.NoError(t, env.PachClient.GetFileArchive(repo, "master", "/**.csv", pfs.ArchiveFormat_TAR, &buf))
+		require.Equal(t, map[string]string{"c.csv": "ccc\n", "dir/a.csv": "a\n"}, readTar(&buf))
+
+		// Symlink targets are made relative to the symlink
+		require.NoError(t, env.PachClient.PutSymlink(repo, "master", "links/sub/abs", "/c.csv"))
+		require.NoError(t, env.PachClient.PutSymlink(repo, "master", "links/rel", "../dir/a.csv"))
+		buf.Reset()
+		require.NoError(t, env.PachClient.GetFileArchive(repo, "master", "links", pfs.ArchiveFormat_TAR, &buf))
+		links := make(map[string]string)
+		tr := tar.NewReader(&buf)
+		for {
+			header, err := tr.Next()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			require.NoError(t, err)
+			if header.Typeflag == tar.TypeSymlink {
+				links[header.Name] = header.Linkname
+			}
+		}
+		require.Equal(t, map[string]string{"links/sub/abs": "../../c.csv", "links/rel": "../dir/a.csv"}, links)
+
+		require.YesError(t, env.PachClient.GetFileArchive(repo, "master", "missing", pfs.ArchiveFormat_TAR, &buf))
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func TestPutFileUnpack(t *testing.T) {
+	t.Parallel()
+	err := testpachd.WithRealEnv(func(env *testpachd.RealEnv) error {
+		repo := "test"
+		require.NoError(t, env.PachClient.CreateRepo(repo))
+		files := map[string]string{
+			"a":     "a\n",
+			"dir/b": "bb\n",
+		}
+		writeTar := func(w io.Writer, names ...string) {
+			tw := tar.NewWriter(w)
+			require.NoError(t, tw.WriteHeader(&tar.Header{Name: "dir/", Typeflag: tar.TypeDir, Mode: 0755}))
+			for _, name := range names {
+				content := files[name]
+				require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Typeflag: tar.TypeReg, Mode: 0644, Size: int64(len(content))}))
+				_, err := tw.Write([]byte(content))
+				require.NoError(t, err)
+			}
+			require.NoError(t, tw.WriteHeader(&tar.Header{Name: "link", Typeflag: tar.TypeSymlink, Linkname: "dir/b"}))
+			require.NoError(t, tw.Close())
+		}
+		checkFiles := func(commit string, prefix string) {
+			for name, content := range files {
+				var buf bytes.Buffer
+				require.NoError(t, env.PachClient.GetFile(repo, commit, path.Join(prefix, name), 0, 0, &buf))
+				require.Equal(t, content, buf.String())
+			}
+		}
+
+		var buf bytes.Buffer
+		writeTar(&buf, "a", "dir/b")
+		_, err := env.PachClient.PutFileUnpack(repo, "master", "tar", pfs.ArchiveFormat_TAR, false, &buf)
+		require.NoError(t, err)
+		checkFiles("master", "tar")
+		var linkBuf bytes.Buffer
+		require.NoError(t, env.PachClient.GetFile(repo, "master", "tar/link", 0, 0, &linkBuf))
+		require.Equal(t, "bb\n", linkBuf.String())
+
+		buf.Reset()
+		gw := gzip.NewWriter(&buf)
+		writeTar(gw, "a", "dir/b")
+		require.NoError(t, gw.Close())
+		_, err = env.PachClient.PutFileUnpack(repo, "master", "tgz", pfs.ArchiveFormat_TAR_GZ, false, &buf)
+		require.NoError(t, err)
+		checkFiles("master", "tgz")
+
+		buf.Reset()
+		zw := zip.NewWriter(&buf)
+		for name, content := range files {
+			w, err := zw.Create(name)
+			require.NoError(t, err)
+			_, err = w.Write([]byte(content))
+			require.NoError(t, err)
+		}
+		require.NoError(t, zw.Close())
+		_, err = env.PachClient.PutFileUnpack(repo, "master", "", pfs.ArchiveFormat_ZIP, false, &buf)
+		require.NoError(t, err)
+		checkFiles("master", "")
+
+		// Unpacking into an open commit works the same way, and overwrite
+		// applies to each file
+		commit, err := env.PachClient.StartCommit(repo, "master")
+		require.NoError(t, err)
+		buf.Reset()
+		writeTar(&buf, "a", "dir/b")
+		_, err = env.PachClient.PutFileUnpack(repo, commit.ID, "tar", pfs.ArchiveFormat_TAR, true, &buf)
+		require.NoError(t, err)
+		require.NoError(t, env.PachClient.FinishCommit(repo, commit.ID))
+		checkFiles(commit.ID, "tar")
+
+		// Paths in the archive can't escape the target directory
+		buf.Reset()
+		tw := tar.NewWriter(&buf)
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: "../../escape", Typeflag: tar.TypeReg, Mode: 0644, Size: 1}))
+		_, err = tw.Write([]byte("x"))
+		require.NoError(t, err)
+		require.NoError(t, tw.Close())
+		_, err = env.PachClient.PutFileUnpack(repo, "master", "tar", pfs.ArchiveFormat_TAR, false, &buf)
+		require.YesError(t, err)
+
+		// Neither can absolute paths, or symlink targets
+		for _, header := range []*tar.Header{
+			{Name: "/etc/escape", Typeflag: tar.TypeReg, Mode: 0644, Size: 1},
+			{Name: "dir/link", Typeflag: tar.TypeSymlink, Linkname: "../../etc/passwd"},
+			{Name: "link", Typeflag: tar.TypeSymlink, Linkname: "/etc/passwd"},
+		} {
+			buf.Reset()
+			tw := tar.NewWriter(&buf)
+			require.NoError(t, tw.WriteHeader(header))
+			if header.Size > 0 {
+				_, err = tw.Write([]byte("x"))
+				require.NoError(t, err)
+			}
+			require.NoError(t, tw.Close())
+			_, err = env.PachClient.PutFileUnpack(repo, "master", "tar", pfs.ArchiveFormat_TAR, false, &buf)
+			require.YesError(t, err)
+			require.Matches(t, "in archive", err.Error())
+		}
+		buf.Reset()
+		zw = zip.NewWriter(&buf)
+		zipHeader := &zip.FileHeader{Name: "link", Method: zip.Store}
+		zipHeader.SetMode(os.ModeSymlink | 0777)
+		w, err := zw.CreateHeader(zipHeader)
+		require.NoError(t, err)
+		_, err = w.Write([]byte("../escape"))
+		require.NoError(t, err)
+		require.NoError(t, zw.Close())
+		_, err = env.PachClient.PutFileUnpack(repo, "master", "zip", pfs.ArchiveFormat_ZIP, false, &buf)
+		require.YesError(t, err)
+		require.Matches(t, "in archive", err.Error())
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func TestSubscribeFile(t *testing.T) {
+	t.Parallel()
+	err := testpachd.WithRealEnv(func(env *testpachd.RealEnv) error {
+		repo := tu.UniqueString("TestSubscribeFile")
+		require.NoError(t, env.PachClient.CreateRepo(repo))
+
+		commit1, err := env.PachClient.StartCommit(repo, "master")
+		require.NoError(t, err)
+		_, err = env.PachClient.PutFile(repo, commit1.ID, "/a/x", strings.NewReader("x"))
+		require.NoError(t, err)
+		_, err = env.PachClient.PutFile(repo, commit1.ID, "/b/y", strings.NewReader("y"))
+		require.NoError(t, err)
+		require.NoError(t, env.PachClient.FinishCommit(repo, commit1.ID))
+
+		commit2, err := env.PachClient.StartCommit(repo, "master")
+		require.NoError(t, err)
+		_, err = env.PachClient.PutFileOverwrite(repo, commit2.ID, "/a/x", strings.NewReader("x2"), 0)
+		require.NoError(t, err)
+		_, err = env.PachClient.PutFile(repo, commit2.ID, "/a/z", strings.NewReader("z"))
+		require.NoError(t, err)
+		require.NoError(t, env.PachClient.DeleteFile(repo, commit2.ID, "/b/y"))
+		require.NoError(t, env.PachClient.FinishCommit(repo, commit2.ID))
+
+		// subscribe collects 'n' changes to the files matching 'pattern'
+		subscribe := func(pattern, from string, n int) []string {
+			var changes []string
+			require.NoError(t, env.PachClient.SubscribeFile(repo, "master", pattern, from, func(change *pfs.FileChange) error {
+				changes = append(changes, fmt.Sprintf("%s %s %s", change.Commit.ID, change.Type, change.FileInfo.File.Path))
+				if len(changes) == n {
+					return errutil.ErrBreak
+				}
+				return nil
+			}))
+			return changes
+		}
+		require.Equal(t, []string{
+			commit1.ID + " ADDED /a/x",
+			commit2.ID + " MODIFIED /a/x",
+			commit2.ID + " ADDED /a/z",
+		}, subscribe("/a", "", 3))
+		require.Equal(t, []string{
+			commit1.ID + " ADDED /b/y",
+			commit2.ID + " DELETED /b/y",
+		}, subscribe("/b/*", "", 2))
+		require.Equal(t, []string{
+			commit2.ID + " ADDED /a/z",
+		}, subscribe("/**z", commit1.ID, 1))
+		return nil
+	})
+	require.NoError(t, err)
+}
+
 func TestToggleBranchProvenance(t *testing.T) {
 	t.Parallel()
 	err := testpachd.WithRealEnv(func(env *testpachd.RealEnv) error {
@@ -3275,6 +4785,67 @@ func TestPutFileSplitCSV(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestPutFileSplitTSV(t *testing.T) {
+	t.Parallel()
+	err := testpachd.WithRealEnv(func(env *testpachd.RealEnv) error {
+		repo := tu.UniqueString("TestPutFileSplitTSV")
+		require.NoError(t, env.PachClient.CreateRepo(repo))
+		// Quotes aren't special in TSV, so records are stored as they're read
+		_, err := env.PachClient.PutFileSplit(repo, "master", "data", pfs.Delimiter_TSV, 0, 0, 1, false,
+			strings.NewReader("make\tmodel\tnote\n"+
+				"Tesla\tRoadster\t\"literally\" a rocket\n"+
+				"Honda\tCivic\t5\" spoiler\n"))
+		require.NoError(t, err)
+		fileInfos, err := env.PachClient.ListFile(repo, "master", "/data")
+		require.NoError(t, err)
+		require.Equal(t, 2, len(fileInfos))
+		var contents bytes.Buffer
+		require.NoError(t, env.PachClient.GetFile(repo, "master", "/data/0000000000000001", 0, 0, &contents))
+		require.Equal(t, "make\tmodel\tnote\nHonda\tCivic\t5\" spoiler\n", contents.String())
+
+		// Every record must have as many fields as the first
+		_, err = env.PachClient.PutFileSplit(repo, "master", "bad", pfs.Delimiter_TSV, 0, 0, 0, false,
+			strings.NewReader("a\tb\nc\n"))
+		require.YesError(t, err)
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func TestPutFileSplitRegex(t *testing.T) {
+	t.Parallel()
+	err := testpachd.WithRealEnv(func(env *testpachd.RealEnv) error {
+		repo := tu.UniqueString("TestPutFileSplitRegex")
+		require.NoError(t, env.PachClient.CreateRepo(repo))
+		entries := []string{
+			"2020-04-01 12:00:00 INFO starting\n",
+			"2020-04-01 12:00:01 ERROR panic: oh no\n" +
+				"goroutine 1 [running]:\n" +
+				"main.main()\n",
+			"2020-04-01 12:00:02 INFO restarting",
+		}
+		_, err := env.PachClient.PutFileSplitRegex(repo, "master", "logs", `^\d{4}-\d{2}-\d{2} `, 0, 0, 0, false,
+			strings.NewReader(strings.Join(entries, "")))
+		require.NoError(t, err)
+		fileInfos, err := env.PachClient.ListFile(repo, "master", "/logs")
+		require.NoError(t, err)
+		require.Equal(t, len(entries), len(fileInfos))
+		for i, entry := range entries {
+			var contents bytes.Buffer
+			require.NoError(t, env.PachClient.GetFile(repo, "master", fmt.Sprintf("/logs/%016x", i), 0, 0, &contents))
+			require.Equal(t, entry, contents.String())
+		}
+
+		// Invalid and missing regexes are rejected
+		_, err = env.PachClient.PutFileSplitRegex(repo, "master", "logs2", "(", 0, 0, 0, false, strings.NewReader("a\n"))
+		require.YesError(t, err)
+		_, err = env.PachClient.PutFileSplit(repo, "master", "logs2", pfs.Delimiter_REGEX, 0, 0, 0, false, strings.NewReader("a\n"))
+		require.YesError(t, err)
+		return nil
+	})
+	require.NoError(t, err)
+}
+
 func TestPutFileSplitSQL(t *testing.T) {
 	t.Parallel()
 	err := testpachd.WithRealEnv(func(env *testpachd.RealEnv) error {
@@ -3342,6 +4913,107 @@ func TestPutFileSplitSQL(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestPutFileSplitMySQL(t *testing.T) {
+	t.Parallel()
+	err := testpachd.WithRealEnv(func(env *testpachd.RealEnv) error {
+		repo := tu.UniqueString("TestPutFileSplitMySQL")
+		require.NoError(t, env.PachClient.CreateRepo(repo))
+
+		// Each row of the dump's extended INSERTs gets its own file
+		_, err := env.PachClient.PutFileSplit(repo, "master", "/sql", pfs.Delimiter_SQL, 0, 0, 0,
+			false, strings.NewReader(tu.TestMySQLDump))
+		require.NoError(t, err)
+		fileInfos, err := env.PachClient.ListFile(repo, "master", "/sql")
+		require.NoError(t, err)
+		require.Equal(t, 5, len(fileInfos))
+
+		// Each file creates both tables, and inserts a single row
+		var contents bytes.Buffer
+		require.NoError(t, env.PachClient.GetFile(repo, "master", "/sql/0000000000000003", 0, 0, &contents))
+		require.Matches(t, "CREATE TABLE `cars`", contents.String())
+		require.Matches(t, "CREATE TABLE `owners`", contents.String())
+		require.Equal(t, 1, strings.Count(contents.String(), "INSERT INTO"))
+		require.Matches(t, "INSERT INTO `owners` VALUES \\('Alice','Tesla'\\);", contents.String())
+		require.True(t, strings.HasSuffix(contents.String(), "-- Dump completed on 2020-04-01 12:00:00\n"))
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func TestPutFileSplitAvro(t *testing.T) {
+	t.Parallel()
+	err := testpachd.WithRealEnv(func(env *testpachd.RealEnv) error {
+		repo := tu.UniqueString("TestPutFileSplitAvro")
+		require.NoError(t, env.PachClient.CreateRepo(repo))
+
+		// Build an avro container file of longs, with blocks of 2, 2 and 1 objects
+		writeLong := func(buf *bytes.Buffer, n int64) {
+			u := uint64((n << 1) ^ (n >> 63))
+			for u >= 0x80 {
+				buf.WriteByte(byte(u) | 0x80)
+				u >>= 7
+			}
+			buf.WriteByte(byte(u))
+		}
+		syncMarker := []byte("0123456789abcdef")
+		var header bytes.Buffer
+		header.WriteString("Obj\x01")
+		writeLong(&header, 1)
+		writeLong(&header, int64(len("avro.schema")))
+		header.WriteString("avro.schema")
+		writeLong(&header, int64(len(`"long"`)))
+		header.WriteString(`"long"`)
+		writeLong(&header, 0)
+		header.Write(syncMarker)
+		file := bytes.NewBuffer(append([]byte{}, header.Bytes()...))
+		for _, values := range [][]int64{{1, 2}, {3, 4}, {5}} {
+			var data bytes.Buffer
+			for _, v := range values {
+				writeLong(&data, v)
+			}
+			writeLong(file, int64(len(values)))
+			writeLong(file, int64(data.Len()))
+			file.Write(data.Bytes())
+			file.Write(syncMarker)
+		}
+
+		// Blocks aren't split, so the first file gets 4 objects
+		_, err := env.PachClient.PutFileSplit(repo, "master", "data", pfs.Delimiter_AVRO, 3, 0, 0, false, file)
+		require.NoError(t, err)
+		fileInfos, err := env.PachClient.ListFile(repo, "master", "/data")
+		require.NoError(t, err)
+		require.Equal(t, 2, len(fileInfos))
+		// Each split file is a valid container file, with the original header
+		for i, expected := range [][]int64{{2, 2}, {1}} {
+			var contents bytes.Buffer
+			require.NoError(t, env.PachClient.GetFile(repo, "master", fmt.Sprintf("/data/%016x", i), 0, 0, &contents))
+			r := avro.NewReader(bufio.NewReader(&contents))
+			var counts []int64
+			for {
+				_, count, err := r.ReadBlock()
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				require.NoError(t, err)
+				counts = append(counts, count)
+			}
+			require.Equal(t, expected, counts)
+			require.Equal(t, header.Bytes(), r.Header)
+		}
+
+		// Avro and parquet headers can't be set explicitly
+		_, err = env.PachClient.PutFileSplit(repo, "master", "data2", pfs.Delimiter_AVRO, 0, 0, 1, false, bytes.NewReader(header.Bytes()))
+		require.YesError(t, err)
+		// Invalid files are rejected
+		_, err = env.PachClient.PutFileSplit(repo, "master", "data2", pfs.Delimiter_AVRO, 0, 0, 0, false, strings.NewReader("not avro"))
+		require.YesError(t, err)
+		_, err = env.PachClient.PutFileSplit(repo, "master", "data2", pfs.Delimiter_PARQUET, 0, 0, 0, false, strings.NewReader("not parquet"))
+		require.YesError(t, err)
+		return nil
+	})
+	require.NoError(t, err)
+}
+
 func TestPutFileHeaderRecordsBasic(t *testing.T) {
 	t.Parallel()
 	err := testpachd.WithRealEnv(func(env *testpachd.RealEnv) error {
@@ -6057,6 +7729,74 @@ func TestFsckFix(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestFsckDeep(t *testing.T) {
+	t.Parallel()
+	err := testpachd.WithRealEnv(func(env *testpachd.RealEnv) error {
+		c := env.PachClient
+		repo := "test"
+		require.NoError(t, c.CreateRepo(repo))
+		_, err := c.PutFile(repo, "master", "a", strings.NewReader("foo\n"))
+		require.NoError(t, err)
+		_, err = c.PutFile(repo, "master", "b", strings.NewReader("bar\n"))
+		require.NoError(t, err)
+		// 'c' has the same content as 'a', so it references the same object
+		_, err = c.PutFile(repo, "master", "c", strings.NewReader("foo\n"))
+		require.NoError(t, err)
+		commitInfo, err := c.InspectCommit(repo, "master")
+		require.NoError(t, err)
+
+		checkErrors := func() []string {
+			var errs []string
+			require.NoError(t, c.FsckDeep(false, 2, 0, func(resp *pfs.FsckResponse) error {
+				if resp.Error != "" {
+					errs = append(errs, resp.Error)
+				}
+				return nil
+			}))
+			return errs
+		}
+		require.Equal(t, 0, len(checkErrors()))
+
+		// findStorage returns the path of the file in object storage called 'name'
+		findStorage := func(name string) string {
+			var result string
+			require.NoError(t, filepath.Walk(env.LocalStorageDirectory, func(p string, info os.FileInfo, err error) error {
+				if err == nil && !info.IsDir() && info.Name() == name {
+					result = p
+				}
+				return err
+			}))
+			require.NotEqual(t, "", result)
+			return result
+		}
+		objectHash := func(content string) string {
+			hash := pfs.NewHash()
+			hash.Write([]byte(content))
+			return pfs.EncodeHash(hash.Sum(nil))
+		}
+
+		// corrupt the block holding 'a' and 'c', and remove the object holding 'b'
+		objectInfo, err := c.InspectObject(objectHash("foo\n"))
+		require.NoError(t, err)
+		require.NoError(t, ioutil.WriteFile(findStorage(objectInfo.BlockRef.Block.Hash), []byte("baz\n"), 0644))
+		require.NoError(t, os.Remove(findStorage(objectHash("bar\n"))))
+
+		errs := checkErrors()
+		require.Equal(t, 2, len(errs))
+		missing, corrupted := errs[0], errs[1]
+		if strings.Contains(missing, "is corrupted") {
+			missing, corrupted = corrupted, missing
+		}
+		require.True(t, strings.Contains(missing, "object "+objectHash("bar\n")+" is missing"), missing)
+		require.True(t, strings.Contains(missing, "/b in commit "+commitInfo.Commit.ID), missing)
+		require.True(t, strings.Contains(corrupted, "object "+objectHash("foo\n")+" is corrupted"), corrupted)
+		require.True(t, strings.Contains(corrupted, "/a in commit "+commitInfo.Commit.ID), corrupted)
+		require.True(t, strings.Contains(corrupted, "/c in commit "+commitInfo.Commit.ID), corrupted)
+		return nil
+	})
+	require.NoError(t, err)
+}
+
 func TestPutFileAtomic(t *testing.T) {
 	t.Parallel()
 	err := testpachd.WithRealEnv(func(env *testpachd.RealEnv) error {
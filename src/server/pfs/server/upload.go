@@ -0,0 +1,344 @@
+package server
+
+import (
+	"bytes"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/gogo/protobuf/types"
+	"github.com/pachyderm/pachyderm/src/client"
+	"github.com/pachyderm/pachyderm/src/client/auth"
+	"github.com/pachyderm/pachyderm/src/client/pfs"
+	"github.com/pachyderm/pachyderm/src/client/pkg/errors"
+	pfsserver "github.com/pachyderm/pachyderm/src/server/pfs"
+	col "github.com/pachyderm/pachyderm/src/server/pkg/collection"
+	"github.com/pachyderm/pachyderm/src/server/pkg/hashtree"
+	txnenv "github.com/pachyderm/pachyderm/src/server/pkg/transactionenv"
+	"github.com/pachyderm/pachyderm/src/server/pkg/uuid"
+)
+
+// startUpload starts an upload session for 'file'. The file's commit doesn't
+// need to be open until the upload is completed.
+func (d *driver) startUpload(pachClient *client.APIClient, file *pfs.File, overwrite bool) (*pfs.UploadInfo, error) {
+	// Validate arguments
+	if file == nil {
+		return nil, errors.New("file cannot be nil")
+	}
+	if file.Commit == nil {
+		return nil, errors.New("file commit cannot be nil")
+	}
+	if file.Commit.Repo == nil {
+		return nil, errors.New("file commit repo cannot be nil")
+	}
+	if err := d.checkFilePath(file.Path); err != nil {
+		return nil, err
+	}
+	if err := hashtree.ValidatePath(file.Path); err != nil {
+		return nil, err
+	}
+
+	if err := d.checkIsAuthorized(pachClient, file.Commit.Repo, auth.Scope_WRITER); err != nil {
+		return nil, err
+	}
+	repoInfo := &pfs.RepoInfo{}
+	if err := d.repos.ReadOnly(pachClient.Ctx()).Get(file.Commit.Repo.Name, repoInfo); err != nil {
+		if col.IsErrNotFound(err) {
+			return nil, pfsserver.ErrRepoNotFound{file.Commit.Repo}
+		}
+		return nil, err
+	}
+	if overwrite {
+		if err := checkAppendOnly(repoInfo, "overwrite files"); err != nil {
+			return nil, err
+		}
+	}
+	uploadInfo := &pfs.UploadInfo{
+		ID:        uuid.NewWithoutDashes(),
+		File:      file,
+		Overwrite: overwrite,
+		Started:   types.TimestampNow(),
+	}
+	uploadInfo.Updated = uploadInfo.Started
+	if _, err := col.NewSTM(pachClient.Ctx(), d.etcdClient, func(stm col.STM) error {
+		return d.uploads.ReadWrite(stm).Create(uploadInfo.ID, uploadInfo)
+	}); err != nil {
+		return nil, err
+	}
+	return uploadInfo, nil
+}
+
+// inspectUpload returns the upload session 'id'. Only writers of the
+// session's repo can see it.
+func (d *driver) inspectUpload(pachClient *client.APIClient, id string) (*pfs.UploadInfo, error) {
+	uploadInfo := &pfs.UploadInfo{}
+	if err := d.uploads.ReadOnly(pachClient.Ctx()).Get(id, uploadInfo); err != nil {
+		if col.IsErrNotFound(err) {
+			return nil, pfsserver.ErrUploadNotFound{id}
+		}
+		return nil, err
+	}
+	if err := d.checkIsAuthorized(pachClient, uploadInfo.File.Commit.Repo, auth.Scope_WRITER); err != nil {
+		return nil, err
+	}
+	return uploadInfo, nil
+}
+
+// listUpload returns the upload sessions to files in 'repo' (or in every repo
+// that the caller can write to, if 'repo' is nil).
+func (d *driver) listUpload(pachClient *client.APIClient, repo *pfs.Repo) ([]*pfs.UploadInfo, error) {
+	if repo != nil && repo.Name != "" {
+		if err := d.checkIsAuthorized(pachClient, repo, auth.Scope_WRITER); err != nil {
+			return nil, err
+		}
+	}
+	var result []*pfs.UploadInfo
+	authorized := make(map[string]bool)
+	uploadInfo := &pfs.UploadInfo{}
+	if err := d.uploads.ReadOnly(pachClient.Ctx()).List(uploadInfo, col.DefaultOptions, func(string) error {
+		uploadRepo := uploadInfo.File.Commit.Repo
+		if repo != nil && repo.Name != "" {
+			if uploadRepo.Name != repo.Name {
+				return nil
+			}
+		} else {
+			ok, seen := authorized[uploadRepo.Name]
+			if !seen {
+				err := d.checkIsAuthorized(pachClient, uploadRepo, auth.Scope_WRITER)
+				if err != nil && !auth.IsErrNotAuthorized(err) {
+					return err
+				}
+				ok = err == nil
+				authorized[uploadRepo.Name] = ok
+			}
+			if !ok {
+				return nil
+			}
+		}
+		result = append(result, proto.Clone(uploadInfo).(*pfs.UploadInfo))
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// putChunk uploads the data in 'r' to the upload session 'id', as the chunk
+// starting at 'offset'. A chunk at the same offset as an earlier chunk
+// replaces it, so that chunks can be retried.
+func (d *driver) putChunk(pachClient *client.APIClient, id string, offset int64, r io.Reader) (*pfs.UploadInfo, error) {
+	if offset < 0 {
+		return nil, errors.Errorf("chunk offset cannot be negative (%d)", offset)
+	}
+	if _, err := d.inspectUpload(pachClient, id); err != nil {
+		return nil, err
+	}
+	d.putObjectLimiter.Acquire()
+	objects, size, err := pachClient.PutObjectSplit(r)
+	d.putObjectLimiter.Release()
+	if err != nil {
+		return nil, err
+	}
+	chunk := &pfs.UploadChunk{
+		Offset:    offset,
+		SizeBytes: size,
+		Records:   objectRecords(objects, size),
+	}
+	uploadInfo := &pfs.UploadInfo{}
+	if _, err := col.NewSTM(pachClient.Ctx(), d.etcdClient, func(stm col.STM) error {
+		return d.uploads.ReadWrite(stm).Update(id, uploadInfo, func() error {
+			uploadInfo.Updated = types.TimestampNow()
+			return addUploadChunk(uploadInfo, chunk)
+		})
+	}); err != nil {
+		if col.IsErrNotFound(err) {
+			return nil, pfsserver.ErrUploadNotFound{id}
+		}
+		return nil, err
+	}
+	return uploadInfo, nil
+}
+
+// addUploadChunk adds 'chunk' to 'uploadInfo', keeping its chunks sorted by
+// offset. It returns an error if 'chunk' overlaps a chunk at another offset.
+func addUploadChunk(uploadInfo *pfs.UploadInfo, chunk *pfs.UploadChunk) error {
+	if chunk.SizeBytes == 0 {
+		return nil
+	}
+	var chunks []*pfs.UploadChunk
+	for _, c := range uploadInfo.Chunks {
+		if c.Offset == chunk.Offset {
+			continue // replaced by 'chunk'
+		}
+		if c.Offset < chunk.Offset+chunk.SizeBytes && chunk.Offset < c.Offset+c.SizeBytes {
+			return errors.Errorf("chunk at [%d, %d) overlaps the chunk at [%d, %d) in upload %s",
+				chunk.Offset, chunk.Offset+chunk.SizeBytes, c.Offset, c.Offset+c.SizeBytes, uploadInfo.ID)
+		}
+		chunks = append(chunks, c)
+	}
+	chunks = append(chunks, chunk)
+	sort.Slice(chunks, func(i, j int) bool {
+		return chunks[i].Offset < chunks[j].Offset
+	})
+	uploadInfo.Chunks = chunks
+	return nil
+}
+
+// completeUpload writes the data in the upload session 'id' to its file and
+// deletes the session, atomically. Every byte of the file must have been
+// uploaded. Like putFile, the file's commit must be open, or its branch's
+// head is used as the parent of a new commit.
+func (d *driver) completeUpload(pachClient *client.APIClient, id string) error {
+	uploadInfo, err := d.inspectUpload(pachClient, id)
+	if err != nil {
+		return err
+	}
+	file := uploadInfo.File
+	repoInfo := &pfs.RepoInfo{}
+	if err := d.repos.ReadOnly(pachClient.Ctx()).Get(file.Commit.Repo.Name, repoInfo); err != nil {
+		return err
+	}
+	if uploadInfo.Overwrite {
+		if err := checkAppendOnly(repoInfo, "overwrite files"); err != nil {
+			return err
+		}
+	}
+	var baseSize uint64
+	if repoInfo.Quota != nil && repoInfo.Quota.SizeBytes != 0 {
+		if baseSize, err = d.quotaBaseSize(pachClient, file.Commit); err != nil {
+			return err
+		}
+	}
+	branch := ""
+	if !uuid.IsUUIDWithoutDashes(file.Commit.ID) {
+		branch = file.Commit.ID
+	}
+	// As in forEachPutFile, a branch with a finished head (or no head) gets a
+	// new commit containing just this file
+	commitInfo, err := d.inspectCommit(pachClient, file.Commit, pfs.CommitState_STARTED)
+	if err != nil && ((!isNotFoundErr(err) && !isNoHeadErr(err)) || branch == "") {
+		return err
+	}
+	if commitInfo == nil || commitInfo.Finished != nil {
+		if branch == "" {
+			return pfsserver.ErrCommitFinished{file.Commit}
+		}
+		return d.txnEnv.WithWriteContext(pachClient.Ctx(), func(txnCtx *txnenv.TransactionContext) error {
+			records, err := d.takeUploadInSTM(txnCtx.Stm, id, repoInfo, baseSize)
+			if err != nil {
+				return err
+			}
+			_, err = d.makeCommit(txnCtx, "", client.NewCommit(file.Commit.Repo.Name, ""), branch, nil, nil, nil, nil, nil, []string{file.Path}, []*pfs.PutFileRecords{records}, "", nil, time.Time{}, time.Time{}, 0)
+			return err
+		})
+	}
+	file = client.NewFile(file.Commit.Repo.Name, commitInfo.Commit.ID, file.Path)
+	_, err = col.NewSTM(pachClient.Ctx(), d.etcdClient, func(stm col.STM) error {
+		records, err := d.takeUploadInSTM(stm, id, repoInfo, baseSize)
+		if err != nil {
+			return err
+		}
+		return d.upsertPutFileRecordsInSTM(stm, file, records)
+	})
+	return err
+}
+
+// takeUploadInSTM deletes the upload session 'id' and returns the records of
+// its data, reading the session in 'stm' so that chunks uploaded concurrently
+// are either included or conflict. The data's size is checked against
+// 'repoInfo's size quota, on top of 'baseSize'.
+func (d *driver) takeUploadInSTM(stm col.STM, id string, repoInfo *pfs.RepoInfo, baseSize uint64) (*pfs.PutFileRecords, error) {
+	uploadInfo := &pfs.UploadInfo{}
+	if err := d.uploads.ReadWrite(stm).Get(id, uploadInfo); err != nil {
+		if col.IsErrNotFound(err) {
+			return nil, pfsserver.ErrUploadNotFound{id}
+		}
+		return nil, err
+	}
+	records := &pfs.PutFileRecords{Tombstone: uploadInfo.Overwrite}
+	var size int64
+	for _, chunk := range uploadInfo.Chunks {
+		if chunk.Offset != size {
+			return nil, errors.Errorf("upload %s is missing the data at [%d, %d)", id, size, chunk.Offset)
+		}
+		records.Records = append(records.Records, chunk.Records...)
+		size += chunk.SizeBytes
+	}
+	if err := checkSizeQuota(repoInfo, baseSize+uint64(size)); err != nil {
+		return nil, err
+	}
+	if err := d.deleteUploadInSTM(stm, id); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// deleteUpload abandons the upload session 'id'. Its data is deleted by the
+// next garbage collection.
+func (d *driver) deleteUpload(pachClient *client.APIClient, id string) error {
+	if _, err := d.inspectUpload(pachClient, id); err != nil {
+		return err
+	}
+	_, err := col.NewSTM(pachClient.Ctx(), d.etcdClient, func(stm col.STM) error {
+		return d.deleteUploadInSTM(stm, id)
+	})
+	return err
+}
+
+func (d *driver) deleteUploadInSTM(stm col.STM, id string) error {
+	if err := d.uploads.ReadWrite(stm).Delete(id); err != nil {
+		if col.IsErrNotFound(err) {
+			return pfsserver.ErrUploadNotFound{id}
+		}
+		return err
+	}
+	return nil
+}
+
+// objectRecords returns the PutFileRecords for 'objects', which hold 'size'
+// bytes written by PutObjectSplit.
+func objectRecords(objects []*pfs.Object, size int64) []*pfs.PutFileRecord {
+	// Here we use the invariant that every one but the last object
+	// should have a size of ChunkSize.
+	var records []*pfs.PutFileRecord
+	for _, object := range objects {
+		record := &pfs.PutFileRecord{
+			ObjectHash: object.Hash,
+		}
+		if size > pfs.ChunkSize {
+			record.SizeBytes = pfs.ChunkSize
+		} else {
+			record.SizeBytes = size
+		}
+		size -= pfs.ChunkSize
+		records = append(records, record)
+	}
+	return records
+}
+
+type putChunkReader struct {
+	server pfs.API_PutChunkServer
+	buffer bytes.Buffer
+}
+
+func (r *putChunkReader) Read(p []byte) (int, error) {
+	if r.buffer.Len() == 0 {
+		request, err := r.server.Recv()
+		if err != nil {
+			return 0, err
+		}
+		r.buffer.Reset()
+		// buffer.Write cannot error
+		r.buffer.Write(request.Value)
+	}
+	return r.buffer.Read(p)
+}
+
+func drainPutChunkServer(server pfs.API_PutChunkServer) {
+	for {
+		if _, err := server.Recv(); err != nil {
+			break
+		}
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/pachyderm/pachyderm/src/client/pkg/errors"
 )
@@ -24,7 +25,18 @@ import (
 // foo.3 -> foo, -3
 // (all examples apply with ~ in place of ^ as well
 func Parse(s string) (string, int, error) {
-	sepIndex := strings.IndexAny(s, "^~.")
+	return parse(s, "^~.")
+}
+
+// ParseTag is like Parse, but for commit tags, which may contain .
+// characters (e.g. "v1.2"). Only ^ and ~ are treated as ancestry separators,
+// so ParseTag("v1.2^") returns v1.2, 1.
+func ParseTag(s string) (string, int, error) {
+	return parse(s, "^~")
+}
+
+func parse(s string, seps string) (string, int, error) {
+	sepIndex := strings.IndexAny(s, seps)
 	if sepIndex == -1 {
 		return s, 0, nil
 	}
@@ -72,9 +84,85 @@ func Add(s string, ancestors int) string {
 	return s
 }
 
+// timeLayouts are the absolute time formats accepted by ParseTime. Times
+// without a zone are interpreted as UTC.
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// ParseTime parses s for a time reference of the form "ref@{time}", where time
+// is either an absolute time (e.g. "2020-01-01T00:00:00Z" or "2020-01-01") or
+// a duration before 'now' (e.g. "2h ago" or "3d ago"). It returns the base
+// reference and the referenced time, or s and nil if s has no time reference.
+// For example:
+// master@{2020-01-01T12:00:00Z} -> master, 2020-01-01 12:00:00 +0000 UTC
+// master@{2h ago} -> master, now - 2h
+func ParseTime(s string, now time.Time) (string, *time.Time, error) {
+	sepIndex := strings.Index(s, "@{")
+	if sepIndex == -1 {
+		return s, nil, nil
+	}
+	if !strings.HasSuffix(s, "}") {
+		return "", nil, errors.Errorf("invalid time syntax %q, time references must end with }", s)
+	}
+	name, ref := s[:sepIndex], strings.TrimSpace(s[sepIndex+2:len(s)-1])
+	if name == "" {
+		return "", nil, errors.Errorf("invalid time syntax %q, a branch or commit must precede @{", s)
+	}
+	if strings.HasSuffix(ref, " ago") {
+		d, err := parseDuration(strings.TrimSpace(strings.TrimSuffix(ref, " ago")))
+		if err != nil {
+			return "", nil, errors.Wrapf(err, "invalid time syntax %q", s)
+		}
+		t := now.Add(-d)
+		return name, &t, nil
+	}
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, ref); err == nil {
+			return name, &t, nil
+		}
+	}
+	return "", nil, errors.Errorf("invalid time syntax %q, expected a time like 2006-01-02T15:04:05Z or a duration like \"2h ago\"", s)
+}
+
+// parseDuration is like time.ParseDuration, but also accepts days ("d") and
+// weeks ("w") as units, as in "3d" or "1w2d".
+func parseDuration(s string) (time.Duration, error) {
+	var total time.Duration
+	for s != "" {
+		i := strings.IndexAny(s, "dw")
+		if i == -1 {
+			break
+		}
+		n, err := strconv.Atoi(s[:i])
+		if err != nil {
+			// The d or w isn't a unit (or follows a fractional number, which
+			// time.ParseDuration will reject)
+			break
+		}
+		if s[i] == 'w' {
+			n *= 7
+		}
+		total += time.Duration(n) * 24 * time.Hour
+		s = s[i+1:]
+	}
+	if s == "" {
+		return total, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, err
+	}
+	return total + d, nil
+}
+
 var (
-	valid              = regexp.MustCompile("^[a-zA-Z0-9_-]+$") // Matches a valid name
-	invalid            = regexp.MustCompile("[^a-zA-Z0-9_-]")   // matches an invalid character
+	valid              = regexp.MustCompile("^[a-zA-Z0-9_-]+$")               // Matches a valid name
+	validTag           = regexp.MustCompile("^[a-zA-Z0-9_-][a-zA-Z0-9_.-]*$") // Matches a valid tag name
+	invalid            = regexp.MustCompile("[^a-zA-Z0-9_-]")                 // matches an invalid character
 	invalidNameErrorRe = regexp.MustCompile(`name \(.+\) invalid: only alphanumeric characters, underscores, and dashes are allowed`)
 )
 
@@ -87,6 +175,15 @@ func ValidateName(name string) error {
 	return nil
 }
 
+// ValidateTagName validates a commit tag name. Tag names may also contain .
+// characters (but not start with one), as they're parsed with ParseTag.
+func ValidateTagName(name string) error {
+	if !validTag.MatchString(name) {
+		return errors.Errorf("tag name (%v) invalid: only alphanumeric characters, underscores, dashes and dots are allowed", name)
+	}
+	return nil
+}
+
 // SanitizeName forces a name to pass ValidateName, by replacing offending
 // characters with _s
 func SanitizeName(name string) string {
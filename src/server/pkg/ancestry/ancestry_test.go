@@ -2,6 +2,7 @@ package ancestry
 
 import (
 	"testing"
+	"time"
 
 	"github.com/pachyderm/pachyderm/src/client/pkg/require"
 )
@@ -63,3 +64,67 @@ func TestValidate(t *testing.T) {
 		require.NoError(t, ValidateName(SanitizeName(name)), "invalidNames[%d]", i)
 	}
 }
+
+var tagAncestryTests = []struct {
+	in        string
+	name      string
+	ancestors int
+}{
+	{"v1", "v1", 0},
+	{"v1.2", "v1.2", 0},
+	{"v1.2^", "v1.2", 1},
+	{"v1.2~3", "v1.2", 3},
+	{"v1.2.3^^", "v1.2.3", 2},
+}
+
+func TestTagAncestry(t *testing.T) {
+	for i, test := range tagAncestryTests {
+		name, ancestors, err := ParseTag(test.in)
+		require.NoError(t, err, "tagAncestryTests[%d]", i)
+		require.Equal(t, test.name, name, "tagAncestryTests[%d]", i)
+		require.Equal(t, test.ancestors, ancestors, "tagAncestryTests[%d]", i)
+	}
+}
+
+func TestValidateTag(t *testing.T) {
+	for i, name := range append(validNames, "v1.2", "release-1.2.3") {
+		require.NoError(t, ValidateTagName(name), "validNames[%d]", i)
+	}
+	for i, name := range []string{".v1", "v1^", "v1~2", "v 1"} {
+		require.YesError(t, ValidateTagName(name), "invalidTagNames[%d]", i)
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	now := time.Date(2020, 6, 15, 12, 0, 0, 0, time.UTC)
+	var timeTests = []struct {
+		in   string
+		name string
+		time time.Time
+	}{
+		{"master@{2020-01-01T12:30:00Z}", "master", time.Date(2020, 1, 1, 12, 30, 0, 0, time.UTC)},
+		{"master@{2020-01-01T12:30:00.5+01:00}", "master", time.Date(2020, 1, 1, 11, 30, 0, 500000000, time.UTC)},
+		{"master@{2020-01-01 12:30:00}", "master", time.Date(2020, 1, 1, 12, 30, 0, 0, time.UTC)},
+		{"master@{2020-01-01}", "master", time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"master@{2h ago}", "master", now.Add(-2 * time.Hour)},
+		{"master@{ 90m ago }", "master", now.Add(-90 * time.Minute)},
+		{"v1.2@{3d ago}", "v1.2", now.Add(-72 * time.Hour)},
+		{"master@{1w2d12h ago}", "master", now.Add(-(9*24 + 12) * time.Hour)},
+	}
+	for i, test := range timeTests {
+		name, at, err := ParseTime(test.in, now)
+		require.NoError(t, err, "timeTests[%d]", i)
+		require.Equal(t, test.name, name, "timeTests[%d]", i)
+		require.True(t, test.time.Equal(*at), "timeTests[%d]: expected %v, got %v", i, test.time, *at)
+	}
+
+	name, at, err := ParseTime("master^", now)
+	require.NoError(t, err)
+	require.Equal(t, "master^", name)
+	require.Nil(t, at)
+
+	for i, in := range []string{"master@{yesterday}", "master@{2h}", "master@{2h ago", "@{2h ago}", "master@{2020-01-01}^"} {
+		_, _, err := ParseTime(in, now)
+		require.YesError(t, err, "invalidTimes[%d]", i)
+	}
+}
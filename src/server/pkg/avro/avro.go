@@ -0,0 +1,153 @@
+package avro
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+
+	"github.com/pachyderm/pachyderm/src/client/pkg/errors"
+)
+
+const (
+	magic    = "Obj\x01"
+	syncSize = 16
+	// maxVarintLen is the maximum length of a zig-zag encoded avro long
+	maxVarintLen = 10
+)
+
+// Reader parses an avro object container file into its header and its data
+// blocks, without decoding the objects in them. Any block can be appended to
+// the header to form a valid container file holding just that block's objects.
+type Reader struct {
+	// Header is the container file's header, which contains its schema and
+	// codec. It's populated by the first call to ReadBlock.
+	Header []byte
+	rd     *bufio.Reader
+	sync   []byte
+}
+
+// NewReader creates a new Reader
+func NewReader(r *bufio.Reader) *Reader {
+	return &Reader{
+		rd: r,
+	}
+}
+
+// ReadBlock returns the next data block in the container file, including its
+// object count, size and trailing sync marker, along with the number of
+// objects in it. It returns io.EOF after the last block.
+func (r *Reader) ReadBlock() ([]byte, int64, error) {
+	if r.Header == nil {
+		if err := r.readHeader(); err != nil {
+			return nil, 0, err
+		}
+	}
+	var block []byte
+	count, err := readLong(r.rd, &block)
+	if err != nil {
+		if errors.Is(err, io.EOF) && len(block) == 0 {
+			return nil, 0, io.EOF
+		}
+		return nil, 0, errors.Wrapf(err, "error reading avro block count")
+	}
+	size, err := readLong(r.rd, &block)
+	if err != nil {
+		return nil, 0, errors.Wrapf(noEOF(err), "error reading avro block size")
+	}
+	if count < 0 || size < 0 {
+		return nil, 0, errors.Errorf("invalid avro block (count %d, size %d)", count, size)
+	}
+	data := make([]byte, int(size)+syncSize)
+	if _, err := io.ReadFull(r.rd, data); err != nil {
+		return nil, 0, errors.Wrapf(noEOF(err), "error reading avro block")
+	}
+	if !bytes.Equal(data[size:], r.sync) {
+		return nil, 0, errors.Errorf("invalid avro block - sync marker doesn't match the header's")
+	}
+	return append(block, data...), count, nil
+}
+
+func (r *Reader) readHeader() error {
+	header := make([]byte, len(magic))
+	if _, err := io.ReadFull(r.rd, header); err != nil {
+		return errors.Wrapf(noEOF(err), "invalid avro file - missing header")
+	}
+	if string(header) != magic {
+		return errors.Errorf("invalid avro file - bad magic bytes %q", header)
+	}
+	// The metadata is a map from strings to bytes, encoded as a series of
+	// blocks of key/value pairs that ends with an empty block
+	for {
+		count, err := readLong(r.rd, &header)
+		if err != nil {
+			return errors.Wrapf(noEOF(err), "error reading avro metadata")
+		}
+		if count == 0 {
+			break
+		}
+		if count < 0 {
+			// A negative count is followed by the block's size in bytes
+			count = -count
+			if _, err := readLong(r.rd, &header); err != nil {
+				return errors.Wrapf(noEOF(err), "error reading avro metadata")
+			}
+		}
+		for i := int64(0); i < 2*count; i++ {
+			if err := readBytes(r.rd, &header); err != nil {
+				return errors.Wrapf(err, "error reading avro metadata")
+			}
+		}
+	}
+	sync := make([]byte, syncSize)
+	if _, err := io.ReadFull(r.rd, sync); err != nil {
+		return errors.Wrapf(noEOF(err), "error reading avro sync marker")
+	}
+	r.sync = sync
+	r.Header = append(header, sync...)
+	return nil
+}
+
+// readLong reads a zig-zag encoded long from 'rd', appending its encoding to
+// 'buf'.
+func readLong(rd *bufio.Reader, buf *[]byte) (int64, error) {
+	var u uint64
+	for i := uint(0); i < maxVarintLen; i++ {
+		b, err := rd.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		*buf = append(*buf, b)
+		u |= uint64(b&0x7f) << (7 * i)
+		if b&0x80 == 0 {
+			return int64(u>>1) ^ -int64(u&1), nil
+		}
+	}
+	return 0, errors.New("invalid avro long - too many bytes")
+}
+
+// readBytes reads length-prefixed bytes (or a string) from 'rd', appending
+// them and their length to 'buf'.
+func readBytes(rd *bufio.Reader, buf *[]byte) error {
+	n, err := readLong(rd, buf)
+	if err != nil {
+		return noEOF(err)
+	}
+	if n < 0 {
+		return errors.Errorf("invalid avro bytes length %d", n)
+	}
+	data := make([]byte, n)
+	if _, err := io.ReadFull(rd, data); err != nil {
+		return noEOF(err)
+	}
+	*buf = append(*buf, data...)
+	return nil
+}
+
+// noEOF converts io.EOF to io.ErrUnexpectedEOF, for use when the input ends
+// in the middle of a structure.
+func noEOF(err error) error {
+	if errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
@@ -0,0 +1,88 @@
+package avro
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/pachyderm/pachyderm/src/client/pkg/errors"
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+)
+
+func writeLong(buf *bytes.Buffer, n int64) {
+	u := uint64((n << 1) ^ (n >> 63))
+	for u >= 0x80 {
+		buf.WriteByte(byte(u) | 0x80)
+		u >>= 7
+	}
+	buf.WriteByte(byte(u))
+}
+
+func writeBytes(buf *bytes.Buffer, b string) {
+	writeLong(buf, int64(len(b)))
+	buf.WriteString(b)
+}
+
+var sync = []byte("0123456789abcdef")
+
+func header() []byte {
+	var buf bytes.Buffer
+	buf.WriteString(magic)
+	writeLong(&buf, 2)
+	writeBytes(&buf, "avro.schema")
+	writeBytes(&buf, `{"type": "long"}`)
+	writeBytes(&buf, "avro.codec")
+	writeBytes(&buf, "null")
+	writeLong(&buf, 0)
+	buf.Write(sync)
+	return buf.Bytes()
+}
+
+func block(values ...int64) []byte {
+	var data bytes.Buffer
+	for _, v := range values {
+		writeLong(&data, v)
+	}
+	var buf bytes.Buffer
+	writeLong(&buf, int64(len(values)))
+	writeLong(&buf, int64(data.Len()))
+	buf.Write(data.Bytes())
+	buf.Write(sync)
+	return buf.Bytes()
+}
+
+func TestReader(t *testing.T) {
+	blocks := [][]byte{block(1, 2, 3), block(-1000000), block()}
+	file := header()
+	for _, b := range blocks {
+		file = append(file, b...)
+	}
+	r := NewReader(bufio.NewReader(bytes.NewReader(file)))
+	for i, expected := range []int64{3, 1, 0} {
+		b, count, err := r.ReadBlock()
+		require.NoError(t, err)
+		require.Equal(t, expected, count)
+		require.Equal(t, blocks[i], b)
+	}
+	_, _, err := r.ReadBlock()
+	require.True(t, errors.Is(err, io.EOF))
+	require.Equal(t, header(), r.Header)
+}
+
+func TestReaderErrors(t *testing.T) {
+	for _, file := range [][]byte{
+		nil,
+		[]byte("not an avro file"),
+		header()[:10],
+		append(header(), block(1, 2)[:4]...),
+		append(header(), bytes.Replace(block(1), sync, []byte("fedcba9876543210"), 1)...),
+	} {
+		r := NewReader(bufio.NewReader(bytes.NewReader(file)))
+		var err error
+		for err == nil {
+			_, _, err = r.ReadBlock()
+		}
+		require.False(t, errors.Is(err, io.EOF))
+	}
+}
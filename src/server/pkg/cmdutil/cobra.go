@@ -229,18 +229,43 @@ func ParseFile(arg string) (*pfs.File, error) {
 		Path: "",
 	}
 	if len(repoAndRest) > 1 {
-		commitAndPath := strings.SplitN(repoAndRest[1], ":", 2)
-		if commitAndPath[0] == "" {
-			return nil, errors.Errorf("invalid format \"%s\": commit cannot be empty", arg)
+		// Time references (e.g. master@{2020-01-01T00:00:00Z}) may contain
+		// ':', so the path separator is the first ':' outside of braces. Any
+		// '@{' after it is part of the path.
+		rest := repoAndRest[1]
+		file.Commit.ID = rest
+		if sepIndex := pathSeparatorIndex(rest); sepIndex != -1 {
+			file.Commit.ID = rest[:sepIndex]
+			file.Path = rest[sepIndex+1:]
 		}
-		file.Commit.ID = commitAndPath[0]
-		if len(commitAndPath) > 1 {
-			file.Path = commitAndPath[1]
+		if file.Commit.ID == "" {
+			return nil, errors.Errorf("invalid format \"%s\": commit cannot be empty", arg)
 		}
 	}
 	return file, nil
 }
 
+// pathSeparatorIndex returns the index of the first ':' in 'commitAndPath'
+// that isn't inside braces, or -1 if there is none
+func pathSeparatorIndex(commitAndPath string) int {
+	depth := 0
+	for i, c := range commitAndPath {
+		switch c {
+		case '{':
+			depth++
+		case '}':
+			if depth > 0 {
+				depth--
+			}
+		case ':':
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
 // ParsePartialFile returns the same thing as ParseFile, unless ParseFile would
 // error on this input, in which case it returns as much as it was able to
 // parse.
@@ -286,6 +311,23 @@ func ParseHistory(history string) (int64, error) {
 	return int64(result), err
 }
 
+// ParseKeyValues parses arguments of the form "key=value" into a map. It
+// returns nil if 'args' is empty.
+func ParseKeyValues(args []string) (map[string]string, error) {
+	if len(args) == 0 {
+		return nil, nil
+	}
+	result := make(map[string]string)
+	for _, arg := range args {
+		parts := strings.SplitN(arg, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, errors.Errorf("invalid format \"%s\": expected key=value", arg)
+		}
+		result[parts[0]] = parts[1]
+	}
+	return result, nil
+}
+
 // RepeatedStringArg is an alias for []string
 type RepeatedStringArg []string
 
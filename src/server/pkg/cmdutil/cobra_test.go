@@ -0,0 +1,34 @@
+package cmdutil
+
+import (
+	"testing"
+
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+)
+
+var parseFileTests = []struct {
+	in     string
+	repo   string
+	commit string
+	path   string
+}{
+	{"repo", "repo", "", ""},
+	{"repo@master", "repo", "master", ""},
+	{"repo@master:dir/x", "repo", "master", "dir/x"},
+	{"repo@master:dir/x:y", "repo", "master", "dir/x:y"},
+	{"repo@master@{2h ago}:dir/x", "repo", "master@{2h ago}", "dir/x"},
+	{"repo@master@{2020-01-01T00:00:00Z}", "repo", "master@{2020-01-01T00:00:00Z}", ""},
+	{"repo@master@{2020-01-01T00:00:00Z}:dir/x", "repo", "master@{2020-01-01T00:00:00Z}", "dir/x"},
+	{"repo@master:dir/x@{y}", "repo", "master", "dir/x@{y}"},
+	{"repo@master:dir/x@{1:2}", "repo", "master", "dir/x@{1:2}"},
+}
+
+func TestParseFile(t *testing.T) {
+	for i, test := range parseFileTests {
+		file, err := ParseFile(test.in)
+		require.NoError(t, err, "parseFileTests[%d]", i)
+		require.Equal(t, test.repo, file.Commit.Repo.Name, "parseFileTests[%d]", i)
+		require.Equal(t, test.commit, file.Commit.ID, "parseFileTests[%d]", i)
+		require.Equal(t, test.path, file.Path, "parseFileTests[%d]", i)
+	}
+}
@@ -401,6 +401,48 @@ func (c *readWriteCollection) Delete(key string) error {
 	return nil
 }
 
+// List iterates over the items in the collection in key order, calling f
+// with each key after unmarshalling its value into val. Unlike the read-only
+// List, items written by the transaction are included, and the transaction is
+// retried if an item is added or modified concurrently. You can break out of
+// iteration by returning errutil.ErrBreak.
+func (c *readWriteCollection) List(val proto.Message, f func(key string) error) error {
+	span, _ := tracing.AddSpanToAnyExisting(c.stm.Context(), "/etcd.RW/List", "col", c.prefix)
+	defer tracing.FinishAnySpan(span)
+	return c.list(c.prefix, val, f)
+}
+
+// ListPrefix is like List, but only iterates over the items whose keys begin
+// with 'prefix'. As with the read-only ListPrefix, f is called with each key
+// with 'prefix' trimmed.
+func (c *readWriteCollection) ListPrefix(prefix string, val proto.Message, f func(key string) error) error {
+	span, _ := tracing.AddSpanToAnyExisting(c.stm.Context(), "/etcd.RW/ListPrefix", "col", c.prefix, "prefix", prefix)
+	defer tracing.FinishAnySpan(span)
+	queryPrefix := c.prefix
+	if prefix != "" {
+		queryPrefix = path.Join(c.prefix, prefix)
+	}
+	return c.list(queryPrefix, val, f)
+}
+
+func (c *readWriteCollection) list(queryPrefix string, val proto.Message, f func(key string) error) error {
+	if err := watch.CheckType(c.template, val); err != nil {
+		return err
+	}
+	for _, fullKey := range c.stm.ListKeys(queryPrefix) {
+		if err := c.Get(strings.TrimPrefix(fullKey, c.prefix), val); err != nil {
+			return err
+		}
+		if err := f(strings.TrimPrefix(fullKey, queryPrefix)); err != nil {
+			if errors.Is(err, errutil.ErrBreak) {
+				return nil
+			}
+			return err
+		}
+	}
+	return nil
+}
+
 func (c *readWriteCollection) DeleteAll() {
 	// Delete indexes
 	for _, index := range c.indexes {
@@ -222,6 +222,102 @@ func TestDeletePrefix(t *testing.T) {
 	require.Equal(t, j4, job)
 }
 
+func TestReadWriteList(t *testing.T) {
+	require.NoError(t, testetcd.WithEnv(func(e *testetcd.Env) error {
+		c := e.EtcdClient
+		uuidPrefix := uuid.NewWithoutDashes()
+
+		jobInfos := NewCollection(c, uuidPrefix, nil, &pps.JobInfo{}, nil, nil)
+
+		_, err := NewSTM(context.Background(), c, func(stm STM) error {
+			jobInfos := jobInfos.ReadWrite(stm)
+			for _, id := range []string{"j1", "j2"} {
+				if err := jobInfos.Put(id, &pps.JobInfo{Job: client.NewJob(id)}); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		require.NoError(t, err)
+
+		// Writes made in the txn are visible to List
+		var listed []string
+		_, err = NewSTM(context.Background(), c, func(stm STM) error {
+			listed = nil
+			jobInfos := jobInfos.ReadWrite(stm)
+			if err := jobInfos.Put("j3", &pps.JobInfo{Job: client.NewJob("j3")}); err != nil {
+				return err
+			}
+			if err := jobInfos.Delete("j1"); err != nil {
+				return err
+			}
+			job := &pps.JobInfo{}
+			return jobInfos.List(job, func(key string) error {
+				require.Equal(t, key, job.Job.ID)
+				listed = append(listed, key)
+				return nil
+			})
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{"j2", "j3"}, listed)
+
+		// An item added after the list forces the txn to retry
+		attempts := 0
+		_, err = NewSTM(context.Background(), c, func(stm STM) error {
+			attempts++
+			listed = nil
+			job := &pps.JobInfo{}
+			if err := jobInfos.ReadWrite(stm).List(job, func(key string) error {
+				listed = append(listed, key)
+				return nil
+			}); err != nil {
+				return err
+			}
+			if attempts == 1 {
+				if _, err := NewSTM(context.Background(), c, func(stm STM) error {
+					return jobInfos.ReadWrite(stm).Put("j4", &pps.JobInfo{Job: client.NewJob("j4")})
+				}); err != nil {
+					return err
+				}
+			}
+			return jobInfos.ReadWrite(stm).Put("j5", &pps.JobInfo{Job: client.NewJob("j5")})
+		})
+		require.NoError(t, err)
+		require.Equal(t, 2, attempts)
+		require.Equal(t, []string{"j2", "j3", "j4"}, listed)
+		return nil
+	}))
+}
+
+func TestReadWriteListPrefix(t *testing.T) {
+	require.NoError(t, testetcd.WithEnv(func(e *testetcd.Env) error {
+		c := e.EtcdClient
+		uuidPrefix := uuid.NewWithoutDashes()
+
+		jobInfos := NewCollection(c, uuidPrefix, nil, &pps.JobInfo{}, nil, nil)
+
+		var listed []string
+		_, err := NewSTM(context.Background(), c, func(stm STM) error {
+			listed = nil
+			jobInfos := jobInfos.ReadWrite(stm)
+			for _, id := range []string{"a/j1", "a/j2", "b/j3"} {
+				if err := jobInfos.Put(id, &pps.JobInfo{Job: client.NewJob(id)}); err != nil {
+					return err
+				}
+			}
+			job := &pps.JobInfo{}
+			return jobInfos.ListPrefix("a", job, func(key string) error {
+				require.Equal(t, "a"+key, job.Job.ID)
+				listed = append(listed, key)
+				return nil
+			})
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{"/j1", "/j2"}, listed)
+		return nil
+	}))
+}
+
 func TestIndex(t *testing.T) {
 	etcdClient := getEtcdClient()
 	uuidPrefix := uuid.NewWithoutDashes()
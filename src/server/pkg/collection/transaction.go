@@ -47,6 +47,10 @@ type STM interface {
 	// To use DelAll safely, do not issue any Get/Put operations after
 	// DelAll is called.
 	DelAll(key string)
+	// ListKeys returns the keys with the given prefix, including keys put and
+	// excluding keys deleted by this txn. The txn is only committed if no key
+	// with the prefix has been created or modified since it was listed.
+	ListKeys(prefix string) []string
 	Context() context.Context
 	// SetSafePutCheck sets the bit pattern to check if a put is safe.
 	SetSafePutCheck(key string, ptr uintptr)
@@ -130,6 +134,9 @@ type stm struct {
 	wset map[string]stmPut
 	// deletedPrefixes holds the set of prefixes that have been deleted
 	deletedPrefixes []string
+	// rangeReads holds listed prefixes and the latest revision at which a key
+	// under each was modified when it was listed
+	rangeReads map[string]int64
 	// getOpts are the opts used for gets. Includes revision of first read for
 	// stmSerializable
 	getOpts []v3.OpOption
@@ -242,6 +249,50 @@ func (s *stm) DelAll(prefix string) {
 	}
 }
 
+func (s *stm) ListKeys(prefix string) []string {
+	keys, _ := s.listKeys(prefix)
+	return keys
+}
+
+// listKeys contains the essential implementation of ListKeys(). It also
+// returns the header revision of the range read, so that stmSerializable can
+// pin its base revision if this is the txn's first read.
+func (s *stm) listKeys(prefix string) ([]string, int64) {
+	span, ctx := tracing.AddSpanToAnyExisting(s.ctx, "/etcd.stm/List", "prefix", prefix)
+	defer tracing.FinishAnySpan(span)
+	opts := append([]v3.OpOption{v3.WithPrefix(), v3.WithKeysOnly()}, s.getOpts...)
+	resp, err := s.client.Get(ctx, prefix, opts...)
+	if err != nil {
+		panic(stmError{err})
+	}
+	var maxRev int64
+	listed := make(map[string]bool)
+	for _, kv := range resp.Kvs {
+		if kv.ModRevision > maxRev {
+			maxRev = kv.ModRevision
+		}
+		if !s.isKeyRangeDeleted(string(kv.Key)) {
+			listed[string(kv.Key)] = true
+		}
+	}
+	if rev, ok := s.rangeReads[prefix]; !ok || maxRev > rev {
+		s.rangeReads[prefix] = maxRev
+	}
+	for key, wv := range s.wset {
+		if strings.HasPrefix(key, prefix) {
+			listed[key] = !wv.op.IsDelete()
+		}
+	}
+	keys := make([]string, 0, len(listed))
+	for key, ok := range listed {
+		if ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys, resp.Header.Revision
+}
+
 func (s *stm) Rev(key string) int64 {
 	if resp := s.fetch(key); resp != nil && len(resp.Kvs) != 0 {
 		return resp.Kvs[0].ModRevision
@@ -278,6 +329,9 @@ func (s *stm) cmps() []v3.Cmp {
 	for k, rk := range s.rset {
 		cmps = append(cmps, isKeyCurrent(k, rk))
 	}
+	for prefix, rev := range s.rangeReads {
+		cmps = append(cmps, v3.Compare(v3.ModRevision(prefix), "<", rev+1).WithPrefix())
+	}
 	return cmps
 }
 
@@ -343,6 +397,7 @@ func (s *stm) reset() {
 	s.rset = make(map[string]*v3.GetResponse)
 	s.wset = make(map[string]stmPut)
 	s.deletedPrefixes = []string{}
+	s.rangeReads = make(map[string]int64)
 	s.ttlset = make(map[string]int64)
 	s.newLeases = make(map[int64]v3.LeaseID)
 }
@@ -363,7 +418,7 @@ func (s *stmSerializable) Get(key string) (string, error) {
 }
 
 func (s *stmSerializable) fetch(key string) *v3.GetResponse {
-	firstRead := len(s.rset) == 0
+	firstRead := len(s.rset) == 0 && len(s.rangeReads) == 0
 	if resp, ok := s.prefetch[key]; ok {
 		delete(s.prefetch, key)
 		s.rset[key] = resp
@@ -379,6 +434,19 @@ func (s *stmSerializable) fetch(key string) *v3.GetResponse {
 	return resp
 }
 
+func (s *stmSerializable) ListKeys(prefix string) []string {
+	firstRead := len(s.rset) == 0 && len(s.rangeReads) == 0
+	keys, rev := s.stm.listKeys(prefix)
+	if firstRead {
+		// txn's base revision is defined by the first read
+		s.getOpts = []v3.OpOption{
+			v3.WithRev(rev),
+			v3.WithSerializable(),
+		}
+	}
+	return keys
+}
+
 func (s *stmSerializable) Rev(key string) int64 {
 	s.Get(key)
 	return s.stm.Rev(key)
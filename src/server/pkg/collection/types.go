@@ -80,6 +80,12 @@ type ReadWriteCollection interface {
 	// Upsert is like Update but 'key' is not required to be present
 	Upsert(key string, val proto.Message, f func() error) error
 	Create(key string, val proto.Message) error
+	// List iterates over the items in the collection, including those written
+	// in this transaction
+	List(val proto.Message, f func(key string) error) error
+	// ListPrefix is like List, but only iterates over the items whose keys
+	// begin with 'prefix'
+	ListPrefix(prefix string, val proto.Message, f func(key string) error) error
 	Delete(key string) error
 	DeleteAll()
 	DeleteAllPrefix(prefix string)
@@ -387,7 +387,7 @@ func diff(newTx, oldTx *bolt.Tx, newPath string, oldPath string, recursiveDepth
 	}
 	var newC *ChildCursor
 	if newNode != nil {
-		if newNode.FileNode != nil || recursiveDepth == 0 {
+		if newNode.DirNode == nil || recursiveDepth == 0 {
 			if err := f(newPath, newNode, true); err != nil {
 				return err
 			}
@@ -397,7 +397,7 @@ func diff(newTx, oldTx *bolt.Tx, newPath string, oldPath string, recursiveDepth
 	}
 	var oldC *ChildCursor
 	if oldNode != nil {
-		if oldNode.FileNode != nil || recursiveDepth == 0 {
+		if oldNode.DirNode == nil || recursiveDepth == 0 {
 			if err := f(oldPath, oldNode, false); err != nil {
 				return err
 			}
@@ -781,6 +781,11 @@ func (h *dbHashTree) putFile(path string, objects []*pfs.Object, brs []*pfs.Bloc
 		if err != nil && Code(err) != PathNotFound {
 			return errorf(Internal, "could not get node at %q: %v", path, err)
 		}
+		if node != nil && node.nodetype() == symlink {
+			// Writing to a symlink replaces it with a regular file (symlinks have
+			// no content, so the ancestors' sizes are unaffected)
+			node = nil
+		}
 		if node != nil && node.nodetype() != file {
 			return errorf(PathConflict, "could not put file at %q; a file of "+
 				"type %s is already there", path, node.nodetype())
@@ -862,6 +867,76 @@ func (h *dbHashTree) putFile(path string, objects []*pfs.Object, brs []*pfs.Bloc
 	return errors.EnsureStack(err)
 }
 
+// PutFileMetadata merges 'metadata' into the user-defined metadata of the
+// regular file at 'path', overwriting any existing keys.
+func (h *dbHashTree) PutFileMetadata(path string, metadata map[string]string) error {
+	path = clean(path)
+	err := h.Batch(func(tx *bolt.Tx) error {
+		node, err := get(tx, path)
+		if err != nil {
+			return err
+		}
+		if node.nodetype() != file {
+			return errorf(PathConflict, "could not put metadata on %q; it is a "+
+				"file of type %s, not a regular file", path, node.nodetype())
+		}
+		if node.FileNode.Metadata == nil {
+			node.FileNode.Metadata = make(map[string]string)
+		}
+		for k, v := range metadata {
+			node.FileNode.Metadata[k] = v
+		}
+		if err := put(tx, path, node); err != nil {
+			return err
+		}
+		// Mark the ancestors of 'path' as changed, so that their hashes are
+		// recomputed
+		return visit(tx, path, func(node *NodeProto, parent, child string) error {
+			return nil
+		})
+	})
+	return errors.EnsureStack(err)
+}
+
+// PutSymlink creates a symlink at 'path' that points to 'target', replacing
+// any regular file or symlink that's already there.
+func (h *dbHashTree) PutSymlink(path, target string) error {
+	path = clean(path)
+	err := h.Batch(func(tx *bolt.Tx) error {
+		node, err := get(tx, path)
+		if err != nil && Code(err) != PathNotFound {
+			return errorf(Internal, "could not get node at %q: %v", path, err)
+		}
+		if node != nil && node.nodetype() == directory {
+			return errorf(PathConflict, "could not put symlink at %q; a file of "+
+				"type %s is already there", path, node.nodetype())
+		}
+		// Symlinks have no content, so any content of the file being replaced
+		// must be subtracted from its ancestors' sizes
+		var sizeDelta int64
+		if node != nil {
+			sizeDelta = -node.SubtreeSize
+		}
+		node = &NodeProto{
+			Name:        base(path),
+			SymlinkNode: &SymlinkNodeProto{Target: target},
+		}
+		if err := put(tx, path, node); err != nil {
+			return err
+		}
+		return visit(tx, path, func(node *NodeProto, parent, child string) error {
+			if node.DirNode == nil {
+				// node created as part of this visit call, fill in the basics
+				node.Name = base(parent)
+				node.DirNode = &DirectoryNodeProto{}
+			}
+			node.SubtreeSize += sizeDelta
+			return nil
+		})
+	})
+	return errors.EnsureStack(err)
+}
+
 // PutDir creates a directory (or does nothing if one exists).
 func (h *dbHashTree) PutDir(path string) error {
 	path = clean(path)
@@ -1265,7 +1340,12 @@ func merge(ns []*MergeNode) (*MergeNode, error) {
 		// Merge file content
 		if base.nodeProto.nodetype() == file {
 			base.nodeProto.FileNode.BlockRefs = append(base.nodeProto.FileNode.BlockRefs, n.nodeProto.FileNode.BlockRefs...)
-
+			for k, v := range n.nodeProto.FileNode.Metadata {
+				if base.nodeProto.FileNode.Metadata == nil {
+					base.nodeProto.FileNode.Metadata = make(map[string]string)
+				}
+				base.nodeProto.FileNode.Metadata[k] = v
+			}
 		}
 		hasher := pfs.NewHash()
 		hasher.Write(append(base.nodeProto.Hash, n.nodeProto.Hash...))
@@ -1381,6 +1461,24 @@ func HashFileNode(n *FileNodeProto) []byte {
 	for _, object := range n.Objects {
 		hash.Write([]byte(object.Hash))
 	}
+	// Include metadata (if any) in sorted order, so that changing a file's
+	// metadata changes its hash
+	keys := make([]string, 0, len(n.Metadata))
+	for k := range n.Metadata {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		hash.Write([]byte(fmt.Sprintf("%s=%s:", k, n.Metadata[k])))
+	}
+	return hash.Sum(nil)
+}
+
+// hashSymlinkNode computes the hash of a symlink, which only depends on its
+// target
+func hashSymlinkNode(n *SymlinkNodeProto) []byte {
+	hash := sha256.New()
+	hash.Write([]byte("symlink:" + n.Target))
 	return hash.Sum(nil)
 }
 
@@ -1426,6 +1524,8 @@ func canonicalize(tx *bolt.Tx, path string) error {
 		n.Hash = hash.Sum(nil)
 	case file:
 		n.Hash = HashFileNode(n.FileNode)
+	case symlink:
+		n.Hash = hashSymlinkNode(n.SymlinkNode)
 	default:
 		return errorf(Internal,
 			"malformed file at \"%s\" is neither a file nor a directory", path)
@@ -1451,17 +1551,20 @@ const (
 	none         nodetype = iota // No file is present at this point in the tree
 	directory                    // The file at this point in the tree is a directory
 	file                         // ... is a regular file
+	symlink                      // ... is a symlink
 	unrecognized                 // ... is an an unknown type
 )
 
 func (n *NodeProto) nodetype() nodetype {
 	switch {
-	case n == nil || (n.DirNode == nil && n.FileNode == nil):
+	case n == nil || (n.DirNode == nil && n.FileNode == nil && n.SymlinkNode == nil):
 		return none
 	case n.DirNode != nil:
 		return directory
 	case n.FileNode != nil:
 		return file
+	case n.SymlinkNode != nil:
+		return symlink
 	default:
 		return unrecognized
 	}
@@ -1475,6 +1578,8 @@ func (n nodetype) String() string {
 		return "directory"
 	case file:
 		return "file"
+	case symlink:
+		return "symlink"
 	default:
 		return "unknown"
 	}
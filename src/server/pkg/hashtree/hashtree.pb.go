@@ -40,10 +40,13 @@ type FileNodeProto struct {
 	// block_refs/objects. Without this signal, all calls to pfs.GetFile() would
 	// need to check the parent directory's metadata before beginning to return
 	// the file's contents, which would be slow.)
-	HasHeaderFooter      bool     `protobuf:"varint,6,opt,name=has_header_footer,json=hasHeaderFooter,proto3" json:"has_header_footer,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+	HasHeaderFooter bool `protobuf:"varint,6,opt,name=has_header_footer,json=hasHeaderFooter,proto3" json:"has_header_footer,omitempty"`
+	// metadata is user-defined key/value metadata attached to this file (e.g. a
+	// content type or an upstream checksum). It's included in the file's hash.
+	Metadata             map[string]string `protobuf:"bytes,7,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
 }
 
 func (m *FileNodeProto) Reset()         { *m = FileNodeProto{} }
@@ -100,6 +103,13 @@ func (m *FileNodeProto) GetHasHeaderFooter() bool {
 	return false
 }
 
+func (m *FileNodeProto) GetMetadata() map[string]string {
+	if m != nil {
+		return m.Metadata
+	}
+	return nil
+}
+
 // Shared refers to data common to all direct children of a directory (i.e.
 // headers and footers)
 type Shared struct {
@@ -239,7 +249,60 @@ func (m *DirectoryNodeProto) GetShared() *Shared {
 	return nil
 }
 
-// NodeProto is a node in the file tree (either a file or a directory)
+// SymlinkNodeProto is a node corresponding to a symbolic link.
+type SymlinkNodeProto struct {
+	// target is the path that the link points to, exactly as it was created. If
+	// it's relative, it's relative to the directory containing the link, and if
+	// it's absolute, it's relative to the root of the commit containing the
+	// link (links never point outside of their commit).
+	Target               string   `protobuf:"bytes,1,opt,name=target,proto3" json:"target,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SymlinkNodeProto) Reset()         { *m = SymlinkNodeProto{} }
+func (m *SymlinkNodeProto) String() string { return proto.CompactTextString(m) }
+func (*SymlinkNodeProto) ProtoMessage()    {}
+func (*SymlinkNodeProto) Descriptor() ([]byte, []int) {
+	return fileDescriptor_4bd44075bd9a7a70, []int{3}
+}
+func (m *SymlinkNodeProto) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *SymlinkNodeProto) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_SymlinkNodeProto.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *SymlinkNodeProto) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SymlinkNodeProto.Merge(m, src)
+}
+func (m *SymlinkNodeProto) XXX_Size() int {
+	return m.Size()
+}
+func (m *SymlinkNodeProto) XXX_DiscardUnknown() {
+	xxx_messageInfo_SymlinkNodeProto.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SymlinkNodeProto proto.InternalMessageInfo
+
+func (m *SymlinkNodeProto) GetTarget() string {
+	if m != nil {
+		return m.Target
+	}
+	return ""
+}
+
+// NodeProto is a node in the file tree (either a file, a directory or a
+// symlink)
 type NodeProto struct {
 	// Name is the name (not path) of the file/directory (e.g. /lib).
 	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
@@ -254,6 +317,7 @@ type NodeProto struct {
 	// be determined by which field is set.
 	FileNode             *FileNodeProto      `protobuf:"bytes,4,opt,name=file_node,json=fileNode,proto3" json:"file_node,omitempty"`
 	DirNode              *DirectoryNodeProto `protobuf:"bytes,5,opt,name=dir_node,json=dirNode,proto3" json:"dir_node,omitempty"`
+	SymlinkNode          *SymlinkNodeProto   `protobuf:"bytes,6,opt,name=symlink_node,json=symlinkNode,proto3" json:"symlink_node,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}            `json:"-"`
 	XXX_unrecognized     []byte              `json:"-"`
 	XXX_sizecache        int32               `json:"-"`
@@ -263,7 +327,7 @@ func (m *NodeProto) Reset()         { *m = NodeProto{} }
 func (m *NodeProto) String() string { return proto.CompactTextString(m) }
 func (*NodeProto) ProtoMessage()    {}
 func (*NodeProto) Descriptor() ([]byte, []int) {
-	return fileDescriptor_4bd44075bd9a7a70, []int{3}
+	return fileDescriptor_4bd44075bd9a7a70, []int{4}
 }
 func (m *NodeProto) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -327,6 +391,13 @@ func (m *NodeProto) GetDirNode() *DirectoryNodeProto {
 	return nil
 }
 
+func (m *NodeProto) GetSymlinkNode() *SymlinkNodeProto {
+	if m != nil {
+		return m.SymlinkNode
+	}
+	return nil
+}
+
 // HashTreeProto is a tree corresponding to the complete file contents of a
 // pachyderm repo at a given commit (based on a Merkle Tree). We store one
 // HashTree for every PFS commit.
@@ -352,7 +423,7 @@ func (m *HashTreeProto) Reset()         { *m = HashTreeProto{} }
 func (m *HashTreeProto) String() string { return proto.CompactTextString(m) }
 func (*HashTreeProto) ProtoMessage()    {}
 func (*HashTreeProto) Descriptor() ([]byte, []int) {
-	return fileDescriptor_4bd44075bd9a7a70, []int{4}
+	return fileDescriptor_4bd44075bd9a7a70, []int{5}
 }
 func (m *HashTreeProto) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -406,7 +477,7 @@ func (m *BucketHeader) Reset()         { *m = BucketHeader{} }
 func (m *BucketHeader) String() string { return proto.CompactTextString(m) }
 func (*BucketHeader) ProtoMessage()    {}
 func (*BucketHeader) Descriptor() ([]byte, []int) {
-	return fileDescriptor_4bd44075bd9a7a70, []int{5}
+	return fileDescriptor_4bd44075bd9a7a70, []int{6}
 }
 func (m *BucketHeader) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -454,7 +525,7 @@ func (m *Index) Reset()         { *m = Index{} }
 func (m *Index) String() string { return proto.CompactTextString(m) }
 func (*Index) ProtoMessage()    {}
 func (*Index) Descriptor() ([]byte, []int) {
-	return fileDescriptor_4bd44075bd9a7a70, []int{6}
+	return fileDescriptor_4bd44075bd9a7a70, []int{7}
 }
 func (m *Index) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -499,8 +570,10 @@ func (m *Index) GetOffset() uint64 {
 
 func init() {
 	proto.RegisterType((*FileNodeProto)(nil), "hashtree.FileNodeProto")
+	proto.RegisterMapType((map[string]string)(nil), "hashtree.FileNodeProto.MetadataEntry")
 	proto.RegisterType((*Shared)(nil), "hashtree.Shared")
 	proto.RegisterType((*DirectoryNodeProto)(nil), "hashtree.DirectoryNodeProto")
+	proto.RegisterType((*SymlinkNodeProto)(nil), "hashtree.SymlinkNodeProto")
 	proto.RegisterType((*NodeProto)(nil), "hashtree.NodeProto")
 	proto.RegisterType((*HashTreeProto)(nil), "hashtree.HashTreeProto")
 	proto.RegisterMapType((map[string]*NodeProto)(nil), "hashtree.HashTreeProto.FsEntry")
@@ -513,43 +586,49 @@ func init() {
 }
 
 var fileDescriptor_4bd44075bd9a7a70 = []byte{
-	// 576 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x6c, 0x53, 0xcb, 0x6e, 0xd3, 0x40,
-	0x14, 0xd5, 0xd8, 0x4e, 0xe2, 0xdc, 0xa4, 0x22, 0x0c, 0x08, 0xac, 0x0a, 0xb5, 0xc1, 0x08, 0x14,
-	0x10, 0x24, 0x52, 0x41, 0x80, 0x58, 0x56, 0xa5, 0x2a, 0x59, 0x00, 0x9a, 0xb2, 0x62, 0x13, 0xf9,
-	0x71, 0x5d, 0x1b, 0xbb, 0x9e, 0x68, 0xc6, 0xa9, 0x48, 0xbf, 0x83, 0x2f, 0x60, 0xc1, 0x9f, 0x20,
-	0xb1, 0xe4, 0x13, 0x50, 0xbf, 0x04, 0xcd, 0xa3, 0x75, 0x0b, 0x5d, 0x44, 0xba, 0xe7, 0xdc, 0x73,
-	0x8f, 0xef, 0x99, 0xcc, 0x40, 0x28, 0x51, 0x9c, 0xa0, 0x98, 0x2d, 0xcb, 0xa3, 0x59, 0x1e, 0xc9,
-	0xbc, 0x11, 0x88, 0x17, 0xc5, 0x74, 0x29, 0x78, 0xc3, 0xa9, 0x7f, 0x8e, 0x37, 0x6f, 0x27, 0x55,
-	0x81, 0x75, 0x33, 0x5b, 0x66, 0x52, 0xfd, 0x4c, 0x3f, 0xfc, 0x4e, 0x60, 0x63, 0xbf, 0xa8, 0xf0,
-	0x3d, 0x4f, 0xf1, 0xa3, 0x9e, 0x78, 0x08, 0x3d, 0x1e, 0x7f, 0xc1, 0xa4, 0x91, 0x81, 0x37, 0x76,
-	0x27, 0x83, 0x9d, 0xc1, 0x54, 0xc9, 0x3f, 0x68, 0x8e, 0x9d, 0xf7, 0xe8, 0x53, 0x80, 0xb8, 0xe2,
-	0x49, 0xb9, 0x10, 0x98, 0xc9, 0xa0, 0xa3, 0x95, 0x1b, 0x5a, 0xb9, 0xab, 0x68, 0x86, 0x19, 0xeb,
-	0xc7, 0xb6, 0x92, 0xf4, 0x09, 0xdc, 0xcc, 0x23, 0xb9, 0xc8, 0x31, 0x4a, 0x51, 0x2c, 0x32, 0xce,
-	0x1b, 0x14, 0x41, 0x77, 0x4c, 0x26, 0x3e, 0xbb, 0x91, 0x47, 0xf2, 0x40, 0xf3, 0xfb, 0x9a, 0x9e,
-	0x7b, 0x3e, 0x19, 0x39, 0x73, 0xcf, 0x77, 0x46, 0xee, 0xdc, 0xf3, 0xdd, 0x91, 0x17, 0x7e, 0x23,
-	0xd0, 0x3d, 0xcc, 0x23, 0x81, 0x29, 0x7d, 0x00, 0x5d, 0x63, 0x12, 0x90, 0x31, 0xf9, 0x77, 0x39,
-	0xdb, 0x52, 0x22, 0xfb, 0x09, 0xe7, 0x1a, 0x91, 0x69, 0xd1, 0x6d, 0x18, 0xd8, 0x75, 0x64, 0x71,
-	0x8a, 0x81, 0x3b, 0x26, 0x13, 0x97, 0x81, 0xa1, 0x0e, 0x8b, 0x53, 0x54, 0x02, 0x23, 0x35, 0x02,
-	0xcf, 0x08, 0x0c, 0xa5, 0x04, 0x61, 0x06, 0x74, 0xaf, 0x10, 0x98, 0x34, 0x5c, 0xac, 0xdb, 0xf3,
-	0xdb, 0x04, 0x3f, 0xc9, 0x8b, 0x2a, 0x15, 0x58, 0x07, 0xee, 0xd8, 0x9d, 0xf4, 0xd9, 0x05, 0xa6,
-	0x13, 0xe8, 0x4a, 0x9d, 0x43, 0xbb, 0x0d, 0x76, 0x46, 0xd3, 0x8b, 0xbf, 0xcb, 0xe4, 0x63, 0xb6,
-	0x7f, 0xf9, 0x10, 0xc2, 0x9f, 0x04, 0xfa, 0xad, 0x3f, 0x05, 0xaf, 0x8e, 0x8e, 0x51, 0xe7, 0xef,
-	0x33, 0x5d, 0x2b, 0x4e, 0x19, 0xe9, 0xb8, 0x43, 0xa6, 0x6b, 0x7a, 0x1f, 0x86, 0x72, 0x15, 0x2b,
-	0xef, 0xcb, 0x01, 0x07, 0x96, 0xd3, 0x09, 0x5f, 0x40, 0x3f, 0x2b, 0x2a, 0x5c, 0xd4, 0x3c, 0x45,
-	0xbb, 0xd1, 0xdd, 0x76, 0xa3, 0x2b, 0xd7, 0x82, 0xf9, 0x99, 0x85, 0xf4, 0x15, 0xf8, 0x69, 0x21,
-	0xcc, 0x50, 0x47, 0x0f, 0xdd, 0x6b, 0x87, 0xfe, 0x3f, 0x10, 0xd6, 0x4b, 0x0b, 0xa1, 0x50, 0xf8,
-	0x83, 0xc0, 0xc6, 0x41, 0x24, 0xf3, 0x4f, 0x02, 0x6d, 0x96, 0x00, 0x7a, 0x27, 0x28, 0x64, 0xc1,
-	0x6b, 0x1d, 0xa7, 0xc3, 0xce, 0x21, 0x9d, 0x81, 0x93, 0xc9, 0xc0, 0xd1, 0xd7, 0x6a, 0xbb, 0xb5,
-	0xbf, 0x32, 0x3e, 0xdd, 0x97, 0x6f, 0xeb, 0x46, 0xac, 0x99, 0x93, 0xc9, 0xcd, 0x39, 0xf4, 0x2c,
-	0xa4, 0x23, 0x70, 0x4b, 0x5c, 0xdb, 0x03, 0x52, 0x25, 0x7d, 0x0c, 0x9d, 0x93, 0xa8, 0x5a, 0xa1,
-	0xbd, 0x0f, 0xb7, 0x5a, 0xc3, 0x76, 0x4d, 0xa3, 0x78, 0xe3, 0xbc, 0x26, 0xe1, 0x23, 0x18, 0xee,
-	0xae, 0x92, 0x12, 0x1b, 0x73, 0x2f, 0xe9, 0x1d, 0xe8, 0xc6, 0x1a, 0x5b, 0x4f, 0x8b, 0xc2, 0x67,
-	0xd0, 0x79, 0x57, 0xa7, 0xf8, 0x95, 0x0e, 0x81, 0x94, 0xba, 0x37, 0x64, 0xa4, 0x54, 0x72, 0x9e,
-	0x65, 0x12, 0x1b, 0xfd, 0x39, 0x8f, 0x59, 0xb4, 0xbb, 0xf7, 0xeb, 0x6c, 0x8b, 0xfc, 0x3e, 0xdb,
-	0x22, 0x7f, 0xce, 0xb6, 0xc8, 0xe7, 0x97, 0x47, 0x45, 0x93, 0xaf, 0xe2, 0x69, 0xc2, 0x8f, 0x67,
-	0xcb, 0x28, 0xc9, 0xd7, 0x29, 0x8a, 0xcb, 0x95, 0x14, 0xc9, 0xec, 0x9a, 0x07, 0x1e, 0x77, 0xf5,
-	0xc3, 0x7d, 0xfe, 0x37, 0x00, 0x00, 0xff, 0xff, 0x1c, 0x5c, 0xcd, 0xd9, 0xfe, 0x03, 0x00, 0x00,
+	// 659 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x74, 0x54, 0xcd, 0x6e, 0xd3, 0x40,
+	0x10, 0x96, 0x7f, 0x92, 0x38, 0x93, 0x44, 0x84, 0xa5, 0x02, 0x2b, 0x42, 0x6d, 0x30, 0x2a, 0x0a,
+	0x15, 0x24, 0x52, 0x41, 0x80, 0x40, 0x1c, 0xa8, 0x4a, 0x54, 0x22, 0xf1, 0xa3, 0x2d, 0x27, 0x2e,
+	0x91, 0x63, 0x8f, 0x6b, 0x13, 0xc7, 0x8e, 0x76, 0x37, 0x15, 0xe9, 0x99, 0x47, 0xe0, 0x09, 0x38,
+	0xf0, 0x2c, 0x1c, 0x79, 0x04, 0xd4, 0x27, 0x41, 0xde, 0xdd, 0xc4, 0x69, 0x69, 0x0f, 0x91, 0x66,
+	0xbe, 0xf9, 0xbe, 0xdd, 0x99, 0x6f, 0x27, 0x06, 0x8f, 0x23, 0x3b, 0x45, 0x36, 0x98, 0x4f, 0x4f,
+	0x06, 0xb1, 0xcf, 0x63, 0xc1, 0x10, 0xd7, 0x41, 0x7f, 0xce, 0x72, 0x91, 0x13, 0x67, 0x95, 0x77,
+	0xb6, 0x82, 0x34, 0xc1, 0x4c, 0x0c, 0xe6, 0x11, 0x2f, 0x7e, 0xaa, 0xee, 0xfd, 0x34, 0xa1, 0x35,
+	0x4c, 0x52, 0xfc, 0x90, 0x87, 0xf8, 0x49, 0x2a, 0x76, 0xa1, 0x96, 0x4f, 0xbe, 0x62, 0x20, 0xb8,
+	0x6b, 0x77, 0xad, 0x5e, 0x63, 0xbf, 0xd1, 0x2f, 0xe8, 0x1f, 0x25, 0x46, 0x57, 0x35, 0xf2, 0x08,
+	0x60, 0x92, 0xe6, 0xc1, 0x74, 0xcc, 0x30, 0xe2, 0x6e, 0x45, 0x32, 0x5b, 0x92, 0x79, 0x50, 0xc0,
+	0x14, 0x23, 0x5a, 0x9f, 0xe8, 0x88, 0x93, 0x3d, 0xb8, 0x19, 0xfb, 0x7c, 0x1c, 0xa3, 0x1f, 0x22,
+	0x1b, 0x47, 0x79, 0x2e, 0x90, 0xb9, 0xd5, 0xae, 0xd1, 0x73, 0xe8, 0x8d, 0xd8, 0xe7, 0x47, 0x12,
+	0x1f, 0x4a, 0x98, 0xbc, 0x01, 0x67, 0x86, 0xc2, 0x0f, 0x7d, 0xe1, 0xbb, 0x35, 0x79, 0xee, 0x6e,
+	0x7f, 0x3d, 0xd5, 0x85, 0x5e, 0xfb, 0xef, 0x35, 0xef, 0x6d, 0x26, 0xd8, 0x92, 0xae, 0x65, 0x9d,
+	0x57, 0xd0, 0xba, 0x50, 0x22, 0x6d, 0xb0, 0xa6, 0xb8, 0x74, 0x8d, 0xae, 0xd1, 0xab, 0xd3, 0x22,
+	0x24, 0x5b, 0x50, 0x39, 0xf5, 0xd3, 0x05, 0xba, 0xa6, 0xc4, 0x54, 0xf2, 0xd2, 0x7c, 0x61, 0x8c,
+	0x6c, 0xc7, 0x68, 0x9b, 0x23, 0xdb, 0x31, 0xdb, 0xd6, 0xc8, 0x76, 0xac, 0xb6, 0xed, 0xfd, 0x30,
+	0xa0, 0x7a, 0x1c, 0xfb, 0x0c, 0x43, 0x72, 0x1f, 0xaa, 0x6a, 0x08, 0x79, 0xd6, 0x25, 0x73, 0x74,
+	0xa9, 0x20, 0xe9, 0x11, 0xcd, 0x2b, 0x48, 0xaa, 0x44, 0x76, 0xa0, 0xa1, 0xed, 0xe0, 0xc9, 0x19,
+	0xba, 0x56, 0xd7, 0xe8, 0x59, 0x14, 0x14, 0x74, 0x9c, 0x9c, 0x61, 0x41, 0x50, 0x54, 0x45, 0xb0,
+	0x15, 0x41, 0x41, 0x05, 0xc1, 0x8b, 0x80, 0x1c, 0x26, 0x0c, 0x03, 0x91, 0xb3, 0x65, 0xf9, 0x7e,
+	0x1d, 0x70, 0x82, 0x38, 0x49, 0x43, 0x86, 0x99, 0x6b, 0x75, 0xad, 0x5e, 0x9d, 0xae, 0x73, 0xd2,
+	0x83, 0x2a, 0x97, 0x73, 0xc8, 0xd3, 0x1a, 0xfb, 0xed, 0xd2, 0x58, 0x35, 0x1f, 0xd5, 0xf5, 0x4d,
+	0x13, 0xbc, 0x3d, 0x68, 0x1f, 0x2f, 0x67, 0x69, 0x92, 0x4d, 0xcb, 0x5b, 0x6e, 0x43, 0x55, 0xf8,
+	0xec, 0x04, 0x85, 0xf6, 0x54, 0x67, 0xde, 0x77, 0x13, 0xea, 0x25, 0x8b, 0x80, 0x9d, 0xf9, 0x33,
+	0xd4, 0x1c, 0x19, 0x17, 0x58, 0x71, 0xa9, 0xb4, 0xa6, 0x49, 0x65, 0x4c, 0xee, 0x41, 0x93, 0x2f,
+	0x26, 0x45, 0x1f, 0x9b, 0x66, 0x34, 0x34, 0x26, 0xdd, 0x78, 0x0a, 0xf5, 0x28, 0x49, 0x71, 0x9c,
+	0xe5, 0x21, 0xea, 0xee, 0xef, 0x5c, 0xb3, 0x16, 0xd4, 0x89, 0x74, 0x4a, 0x9e, 0x83, 0x13, 0x26,
+	0x4c, 0x89, 0x2a, 0x52, 0x74, 0xb7, 0x14, 0xfd, 0x6f, 0x1e, 0xad, 0x85, 0x09, 0x93, 0xc2, 0xd7,
+	0xd0, 0xe4, 0x6a, 0x66, 0x25, 0xae, 0x4a, 0x71, 0x67, 0xc3, 0xaf, 0x4b, 0x8e, 0xd0, 0x06, 0x2f,
+	0x11, 0xef, 0x97, 0x01, 0xad, 0x23, 0x9f, 0xc7, 0x9f, 0x19, 0x6a, 0x2b, 0x5c, 0xa8, 0x9d, 0x22,
+	0xe3, 0x49, 0x9e, 0x49, 0x37, 0x2a, 0x74, 0x95, 0x92, 0x01, 0x98, 0x11, 0x77, 0x4d, 0xb9, 0xe9,
+	0x3b, 0xe5, 0x05, 0x17, 0xe4, 0xfd, 0x21, 0x57, 0x3b, 0x6e, 0x46, 0xbc, 0x33, 0x82, 0xda, 0x90,
+	0x5f, 0xb7, 0xd7, 0x0f, 0x37, 0xf7, 0xba, 0xb1, 0x7f, 0xab, 0x3c, 0xb0, 0x6c, 0xb5, 0x5c, 0x76,
+	0xef, 0x01, 0x34, 0x0f, 0x16, 0xc1, 0x14, 0x85, 0xfa, 0x0b, 0x16, 0xef, 0x3a, 0x91, 0xf9, 0xea,
+	0x5d, 0x55, 0xe6, 0x3d, 0x86, 0xca, 0xbb, 0x2c, 0xc4, 0x6f, 0xa4, 0x09, 0xc6, 0x54, 0xd6, 0x9a,
+	0xd4, 0x98, 0x16, 0xf4, 0x3c, 0x8a, 0x38, 0x0a, 0x79, 0x9d, 0x4d, 0x75, 0x76, 0x70, 0xf8, 0xfb,
+	0x7c, 0xdb, 0xf8, 0x73, 0xbe, 0x6d, 0xfc, 0x3d, 0xdf, 0x36, 0xbe, 0x3c, 0x3b, 0x49, 0x44, 0xbc,
+	0x98, 0xf4, 0x83, 0x7c, 0x36, 0x98, 0xfb, 0x41, 0xbc, 0x0c, 0x91, 0x6d, 0x46, 0x9c, 0x05, 0x83,
+	0x2b, 0xbe, 0x65, 0x93, 0xaa, 0xfc, 0x46, 0x3d, 0xf9, 0x37, 0x00, 0xdc, 0x10, 0xcc, 0xe7, 0xe9,
+	0x04, 0x00, 0x00,
 }
 
 func (m *FileNodeProto) Marshal() (dAtA []byte, err error) {
@@ -576,6 +655,25 @@ func (m *FileNodeProto) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 		i -= len(m.XXX_unrecognized)
 		copy(dAtA[i:], m.XXX_unrecognized)
 	}
+	if len(m.Metadata) > 0 {
+		for k := range m.Metadata {
+			v := m.Metadata[k]
+			baseI := i
+			i -= len(v)
+			copy(dAtA[i:], v)
+			i = encodeVarintHashtree(dAtA, i, uint64(len(v)))
+			i--
+			dAtA[i] = 0x12
+			i -= len(k)
+			copy(dAtA[i:], k)
+			i = encodeVarintHashtree(dAtA, i, uint64(len(k)))
+			i--
+			dAtA[i] = 0xa
+			i = encodeVarintHashtree(dAtA, i, uint64(baseI-i))
+			i--
+			dAtA[i] = 0x3a
+		}
+	}
 	if m.HasHeaderFooter {
 		i--
 		if m.HasHeaderFooter {
@@ -726,6 +824,40 @@ func (m *DirectoryNodeProto) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	return len(dAtA) - i, nil
 }
 
+func (m *SymlinkNodeProto) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *SymlinkNodeProto) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *SymlinkNodeProto) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if len(m.Target) > 0 {
+		i -= len(m.Target)
+		copy(dAtA[i:], m.Target)
+		i = encodeVarintHashtree(dAtA, i, uint64(len(m.Target)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
 func (m *NodeProto) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
@@ -750,6 +882,18 @@ func (m *NodeProto) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 		i -= len(m.XXX_unrecognized)
 		copy(dAtA[i:], m.XXX_unrecognized)
 	}
+	if m.SymlinkNode != nil {
+		{
+			size, err := m.SymlinkNode.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintHashtree(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x32
+	}
 	if m.DirNode != nil {
 		{
 			size, err := m.DirNode.MarshalToSizedBuffer(dAtA[:i])
@@ -959,6 +1103,14 @@ func (m *FileNodeProto) Size() (n int) {
 	if m.HasHeaderFooter {
 		n += 2
 	}
+	if len(m.Metadata) > 0 {
+		for k, v := range m.Metadata {
+			_ = k
+			_ = v
+			mapEntrySize := 1 + len(k) + sovHashtree(uint64(len(k))) + 1 + len(v) + sovHashtree(uint64(len(v)))
+			n += mapEntrySize + 1 + sovHashtree(uint64(mapEntrySize))
+		}
+	}
 	if m.XXX_unrecognized != nil {
 		n += len(m.XXX_unrecognized)
 	}
@@ -1013,6 +1165,22 @@ func (m *DirectoryNodeProto) Size() (n int) {
 	return n
 }
 
+func (m *SymlinkNodeProto) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Target)
+	if l > 0 {
+		n += 1 + l + sovHashtree(uint64(l))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
 func (m *NodeProto) Size() (n int) {
 	if m == nil {
 		return 0
@@ -1038,6 +1206,10 @@ func (m *NodeProto) Size() (n int) {
 		l = m.DirNode.Size()
 		n += 1 + l + sovHashtree(uint64(l))
 	}
+	if m.SymlinkNode != nil {
+		l = m.SymlinkNode.Size()
+		n += 1 + l + sovHashtree(uint64(l))
+	}
 	if m.XXX_unrecognized != nil {
 		n += len(m.XXX_unrecognized)
 	}
@@ -1230,6 +1402,133 @@ func (m *FileNodeProto) Unmarshal(dAtA []byte) error {
 				}
 			}
 			m.HasHeaderFooter = bool(v != 0)
+		case 7:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Metadata", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowHashtree
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthHashtree
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthHashtree
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Metadata == nil {
+				m.Metadata = make(map[string]string)
+			}
+			var mapkey string
+			var mapvalue string
+			for iNdEx < postIndex {
+				entryPreIndex := iNdEx
+				var wire uint64
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowHashtree
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					wire |= uint64(b&0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				fieldNum := int32(wire >> 3)
+				if fieldNum == 1 {
+					var stringLenmapkey uint64
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowHashtree
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						stringLenmapkey |= uint64(b&0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					intStringLenmapkey := int(stringLenmapkey)
+					if intStringLenmapkey < 0 {
+						return ErrInvalidLengthHashtree
+					}
+					postStringIndexmapkey := iNdEx + intStringLenmapkey
+					if postStringIndexmapkey < 0 {
+						return ErrInvalidLengthHashtree
+					}
+					if postStringIndexmapkey > l {
+						return io.ErrUnexpectedEOF
+					}
+					mapkey = string(dAtA[iNdEx:postStringIndexmapkey])
+					iNdEx = postStringIndexmapkey
+				} else if fieldNum == 2 {
+					var stringLenmapvalue uint64
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowHashtree
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						stringLenmapvalue |= uint64(b&0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					intStringLenmapvalue := int(stringLenmapvalue)
+					if intStringLenmapvalue < 0 {
+						return ErrInvalidLengthHashtree
+					}
+					postStringIndexmapvalue := iNdEx + intStringLenmapvalue
+					if postStringIndexmapvalue < 0 {
+						return ErrInvalidLengthHashtree
+					}
+					if postStringIndexmapvalue > l {
+						return io.ErrUnexpectedEOF
+					}
+					mapvalue = string(dAtA[iNdEx:postStringIndexmapvalue])
+					iNdEx = postStringIndexmapvalue
+				} else {
+					iNdEx = entryPreIndex
+					skippy, err := skipHashtree(dAtA[iNdEx:])
+					if err != nil {
+						return err
+					}
+					if skippy < 0 {
+						return ErrInvalidLengthHashtree
+					}
+					if (iNdEx + skippy) > postIndex {
+						return io.ErrUnexpectedEOF
+					}
+					iNdEx += skippy
+				}
+			}
+			m.Metadata[mapkey] = mapvalue
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipHashtree(dAtA[iNdEx:])
@@ -1541,6 +1840,92 @@ func (m *DirectoryNodeProto) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
+func (m *SymlinkNodeProto) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowHashtree
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: SymlinkNodeProto: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: SymlinkNodeProto: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Target", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowHashtree
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthHashtree
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthHashtree
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Target = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipHashtree(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthHashtree
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthHashtree
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
 func (m *NodeProto) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
@@ -1727,6 +2112,42 @@ func (m *NodeProto) Unmarshal(dAtA []byte) error {
 				return err
 			}
 			iNdEx = postIndex
+		case 6:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field SymlinkNode", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowHashtree
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthHashtree
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthHashtree
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.SymlinkNode == nil {
+				m.SymlinkNode = &SymlinkNodeProto{}
+			}
+			if err := m.SymlinkNode.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipHashtree(dAtA[iNdEx:])
@@ -409,6 +409,83 @@ func TestRewriteChangesHash(t *testing.T) {
 	require.Equal(t, rootPre.SubtreeSize, rootPost.SubtreeSize)
 }
 
+func TestPutFileMetadata(t *testing.T) {
+	h := newHashTree(t)
+	require.NoError(t, h.PutFile("/dir/foo", obj(`hash:"ebc57"`), 1))
+	require.NoError(t, h.Hash())
+	rootPre, err := h.Get("/")
+	require.NoError(t, err)
+
+	require.NoError(t, h.PutFileMetadata("/dir/foo", map[string]string{"content-type": "text/plain", "a": "1"}))
+	require.NoError(t, h.PutFileMetadata("/dir/foo", map[string]string{"a": "2"}))
+	require.NoError(t, h.Hash())
+	node, err := h.Get("/dir/foo")
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"content-type": "text/plain", "a": "2"}, node.FileNode.Metadata)
+	require.Equal(t, int64(1), node.SubtreeSize)
+	rootPost, err := h.Get("/")
+	require.NoError(t, err)
+	require.NotEqual(t, rootPre.Hash, rootPost.Hash)
+
+	// Metadata can only be put on existing regular files
+	require.YesError(t, h.PutFileMetadata("/dir", map[string]string{"a": "1"}))
+	require.YesError(t, h.PutFileMetadata("/dir/bar", map[string]string{"a": "1"}))
+}
+
+func TestSymlinks(t *testing.T) {
+	h := newHashTree(t)
+	require.NoError(t, h.PutFile("/dir/foo", obj(`hash:"ebc57"`), 1))
+	require.NoError(t, h.PutSymlink("/dir/rel", "foo"))
+	require.NoError(t, h.PutSymlink("/abs", "/dir/foo"))
+	require.NoError(t, h.PutSymlink("/dirlink", "dir"))
+	require.NoError(t, h.PutSymlink("/chain", "dirlink/rel"))
+	require.NoError(t, h.PutSymlink("/loop1", "loop2"))
+	require.NoError(t, h.PutSymlink("/loop2", "loop1"))
+	require.NoError(t, h.PutSymlink("/dangling", "nothing"))
+	require.NoError(t, h.Hash())
+
+	node, err := h.Get("/dir/rel")
+	require.NoError(t, err)
+	require.Equal(t, "foo", node.SymlinkNode.Target)
+	require.Equal(t, int64(0), node.SubtreeSize)
+	for _, p := range []string{"/dir/foo", "/dir/rel", "/abs", "/dirlink/foo", "/dirlink/rel", "/chain"} {
+		resolved, node, err := Resolve(h, p)
+		require.NoError(t, err)
+		require.Equal(t, "/dir/foo", resolved)
+		require.NotNil(t, node.FileNode)
+	}
+	_, _, err = Resolve(h, "/loop1")
+	require.Equal(t, SymlinkLoop, Code(err))
+	_, _, err = Resolve(h, "/dangling")
+	require.Equal(t, PathNotFound, Code(err))
+
+	// Changing a symlink's target changes its hash, and replacing a file with
+	// a symlink removes the file's content
+	rootPre, err := h.Get("/")
+	require.NoError(t, err)
+	require.NoError(t, h.PutSymlink("/dir/rel", "/abs"))
+	require.NoError(t, h.PutSymlink("/dir/foo", "rel"))
+	require.NoError(t, h.Hash())
+	rootPost, err := h.Get("/")
+	require.NoError(t, err)
+	require.NotEqual(t, rootPre.Hash, rootPost.Hash)
+	require.Equal(t, int64(0), rootPost.SubtreeSize)
+
+	// Symlinks can't replace directories
+	require.Equal(t, PathConflict, Code(h.PutSymlink("/dir", "abs")))
+
+	// Putting a file over a symlink replaces it
+	require.NoError(t, h.PutFile("/dir/rel", obj(`hash:"ebc57"`), 1))
+	require.NoError(t, h.Hash())
+	node, err = h.Get("/dir/rel")
+	require.NoError(t, err)
+	require.Nil(t, node.SymlinkNode)
+	require.NotNil(t, node.FileNode)
+	rootPost, err = h.Get("/")
+	require.NoError(t, err)
+	require.Equal(t, int64(1), rootPost.SubtreeSize)
+}
+
 func TestIsGlob(t *testing.T) {
 	require.True(t, IsGlob(`*`))
 	require.True(t, IsGlob(`path/to*/file`))
@@ -769,3 +846,74 @@ func TestMergeFiles(t *testing.T) {
 
 	require.Equal(t, expectedBuf, resultBuf)
 }
+
+func TestThreeWayMerge(t *testing.T) {
+	base := newHashTree(t)
+	require.NoError(t, base.PutFile("/foo", obj(`hash:"20c27"`), 1))
+	require.NoError(t, base.PutFile("/dir/bar", obj(`hash:"ebc57"`), 1))
+	require.NoError(t, base.PutFile("/dir/buzz", obj(`hash:"8e02c"`), 1))
+	require.NoError(t, base.Hash())
+
+	ours, err := base.Copy()
+	require.NoError(t, err)
+	require.NoError(t, ours.PutFile("/ours", obj(`hash:"4ab7d"`), 1))
+	require.NoError(t, ours.DeleteFile("/dir/buzz"))
+	require.NoError(t, ours.Hash())
+
+	theirs, err := base.Copy()
+	require.NoError(t, err)
+	require.NoError(t, theirs.PutFile("/theirs", obj(`hash:"413e7"`), 1))
+	require.NoError(t, theirs.PutFile("/dir/bar", obj(`hash:"10ead"`), 1))
+	require.NoError(t, theirs.DeleteFile("/foo"))
+	require.NoError(t, theirs.Hash())
+
+	conflicts, err := ThreeWayMerge(ours, theirs, base, pfs.MergeStrategy_FAIL)
+	require.NoError(t, err)
+	require.Equal(t, 0, len(conflicts))
+	getT(t, ours, "/ours")
+	getT(t, ours, "/theirs")
+	require.Equal(t, int64(2), getT(t, ours, "/dir/bar").SubtreeSize)
+	_, err = ours.Get("/foo")
+	require.Equal(t, PathNotFound, Code(err))
+	_, err = ours.Get("/dir/buzz")
+	require.Equal(t, PathNotFound, Code(err))
+}
+
+func TestThreeWayMergeConflict(t *testing.T) {
+	base := newHashTree(t)
+	require.NoError(t, base.PutFile("/foo", obj(`hash:"20c27"`), 1))
+	require.NoError(t, base.Hash())
+	newSide := func(hash string) HashTree {
+		h, err := base.Copy()
+		require.NoError(t, err)
+		require.NoError(t, h.PutFile("/foo", obj(fmt.Sprintf(`hash:"%s"`, hash)), 1))
+		require.NoError(t, h.Hash())
+		return h
+	}
+
+	// A conflict leaves 'ours' untouched by default
+	ours, theirs := newSide("ebc57"), newSide("8e02c")
+	oursCopy, err := ours.Copy()
+	require.NoError(t, err)
+	conflicts, err := ThreeWayMerge(ours, theirs, base, pfs.MergeStrategy_FAIL)
+	require.NoError(t, err)
+	require.Equal(t, []string{"/foo"}, conflicts)
+	requireSame(t, oursCopy, ours)
+
+	// Identical changes on both sides don't conflict
+	conflicts, err = ThreeWayMerge(newSide("ebc57"), newSide("ebc57"), base, pfs.MergeStrategy_FAIL)
+	require.NoError(t, err)
+	require.Equal(t, 0, len(conflicts))
+
+	// OURS keeps our version of the file
+	conflicts, err = ThreeWayMerge(ours, theirs, base, pfs.MergeStrategy_OURS)
+	require.NoError(t, err)
+	require.Equal(t, 0, len(conflicts))
+	requireSame(t, oursCopy, ours)
+
+	// THEIRS takes their version of the file
+	conflicts, err = ThreeWayMerge(ours, theirs, base, pfs.MergeStrategy_THEIRS)
+	require.NoError(t, err)
+	require.Equal(t, 0, len(conflicts))
+	requireSame(t, theirs, ours)
+}
@@ -68,6 +68,10 @@ const (
 	// to write to an input file that was created by copying from an output
 	// file.
 	MixedObjectsAndBlockRefs
+
+	// SymlinkLoop is returned when resolving a path requires following more
+	// than MaxSymlinks symlinks, which usually means the symlinks form a cycle.
+	SymlinkLoop
 )
 
 // HashTree is the signature of a hash tree provided by this library. To get a
@@ -161,6 +165,14 @@ type HashTree interface {
 	// uses Block Refs instead of objects.
 	PutFileOverwriteBlockRefs(path string, brs []*pfs.BlockRef, overwriteIndex *pfs.OverwriteIndex, sizeDelta int64) error
 
+	// PutFileMetadata merges 'metadata' into the user-defined metadata of the
+	// regular file at 'path', overwriting any existing keys.
+	PutFileMetadata(path string, metadata map[string]string) error
+
+	// PutSymlink creates a symlink at 'path' that points to 'target', replacing
+	// any regular file or symlink that's already there.
+	PutSymlink(path, target string) error
+
 	// PutDir creates a directory (or does nothing if one exists).
 	PutDir(path string) error
 
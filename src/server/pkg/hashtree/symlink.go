@@ -0,0 +1,63 @@
+package hashtree
+
+import (
+	pathlib "path"
+	"strings"
+)
+
+// MaxSymlinks is the maximum number of symlinks that Resolve will follow
+// while resolving a single path (the same limit that Linux uses).
+const MaxSymlinks = 40
+
+// SymlinkTarget returns the path in the commit that the symlink at
+// 'linkPath' points to. Relative targets are relative to the directory
+// containing the link, and absolute targets are relative to the root of the
+// commit, so the result never points outside of the commit.
+func SymlinkTarget(linkPath string, node *SymlinkNodeProto) string {
+	if pathlib.IsAbs(node.Target) {
+		return externalDefault(clean(node.Target))
+	}
+	return externalDefault(join(pathlib.Dir(clean(linkPath)), node.Target))
+}
+
+// Resolve follows any symlinks in 'path' (including in its last component)
+// and returns the path that it ultimately refers to in 'h', along with the
+// node at that path. It returns a PathNotFound error if that path doesn't
+// exist, and a SymlinkLoop error if resolving 'path' requires following more
+// than MaxSymlinks symlinks.
+func Resolve(h HashTree, path string) (string, *NodeProto, error) {
+	var followed int
+	resolved := ""
+	rest := strings.Split(strings.TrimPrefix(clean(path), "/"), "/")
+	for len(rest) > 0 {
+		if rest[0] == "" {
+			rest = rest[1:]
+			continue
+		}
+		next := join(resolved, rest[0])
+		rest = rest[1:]
+		node, err := h.Get(next)
+		if err != nil {
+			return "", nil, err
+		}
+		if node.SymlinkNode == nil {
+			resolved = next
+			continue
+		}
+		followed++
+		if followed > MaxSymlinks {
+			return "", nil, errorf(SymlinkLoop, "too many levels of symlinks "+
+				"resolving \"%s\"", externalDefault(clean(path)))
+		}
+		// Restart resolution from the link's target, followed by whatever's
+		// left of 'path'
+		target := SymlinkTarget(next, node.SymlinkNode)
+		rest = append(strings.Split(strings.TrimPrefix(clean(target), "/"), "/"), rest...)
+		resolved = ""
+	}
+	node, err := h.Get(resolved)
+	if err != nil {
+		return "", nil, err
+	}
+	return externalDefault(resolved), node, nil
+}
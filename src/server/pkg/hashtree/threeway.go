@@ -0,0 +1,144 @@
+package hashtree
+
+import (
+	"bytes"
+	pathlib "path"
+	"sort"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/pachyderm/pachyderm/src/client/pfs"
+)
+
+// ThreeWayMerge applies the changes that were made between 'base' and
+// 'theirs' to 'ours'. 'ours' is modified in place, so callers that don't own
+// it should pass in a copy. All three trees must have been hashed.
+//
+// A path conflicts if it was changed differently in 'ours' and 'theirs'
+// relative to 'base'. Conflicts are resolved according to 'strategy': OURS
+// keeps the version in 'ours', THEIRS takes the version in 'theirs' and FAIL
+// leaves 'ours' unmodified and returns the conflicting paths.
+func ThreeWayMerge(ours, theirs, base HashTree, strategy pfs.MergeStrategy) ([]string, error) {
+	// theirsChanges maps each file changed in 'theirs' to its new node (or nil
+	// if the file was deleted in 'theirs')
+	theirsChanges := make(map[string]*NodeProto)
+	if err := theirs.Diff(base, "/", "/", -1, func(path string, node *NodeProto, new bool) error {
+		if new {
+			theirsChanges[path] = node
+		} else if _, ok := theirsChanges[path]; !ok {
+			theirsChanges[path] = nil
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	var paths []string
+	for path := range theirsChanges {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var apply, conflicts []string
+	for _, path := range paths {
+		ourNode, err := getNode(ours, path)
+		if err != nil {
+			return nil, err
+		}
+		baseNode, err := getNode(base, path)
+		if err != nil {
+			return nil, err
+		}
+		theirNode := theirsChanges[path]
+		switch {
+		case sameNode(ourNode, baseNode):
+			apply = append(apply, path)
+		case sameNode(ourNode, theirNode):
+			// Both sides made the same change, nothing to do
+		case strategy == pfs.MergeStrategy_OURS:
+		case strategy == pfs.MergeStrategy_THEIRS:
+			apply = append(apply, path)
+		default:
+			conflicts = append(conflicts, path)
+		}
+	}
+	if len(conflicts) > 0 {
+		return conflicts, nil
+	}
+	// Apply deletions before writes, so that a file in 'theirs' that replaced
+	// a directory (or vice versa) doesn't collide with the old path
+	for _, path := range apply {
+		if theirsChanges[path] != nil {
+			continue
+		}
+		if err := ours.DeleteFile(path); err != nil && Code(err) != PathNotFound {
+			return nil, err
+		}
+	}
+	for _, path := range apply {
+		if theirsChanges[path] == nil {
+			continue
+		}
+		if err := putNode(ours, theirs, path, theirsChanges[path]); err != nil {
+			return nil, err
+		}
+	}
+	return nil, ours.Hash()
+}
+
+// getNode is like h.Get, but returns a nil node rather than an error if
+// 'path' doesn't exist.
+func getNode(h HashTree, path string) (*NodeProto, error) {
+	node, err := h.Get(path)
+	if err != nil {
+		if Code(err) == PathNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return node, nil
+}
+
+// sameNode returns true if 'l' and 'r' have the same content. File hashes
+// only cover objects, so block refs are compared separately.
+func sameNode(l, r *NodeProto) bool {
+	if l == nil || r == nil {
+		return l == nil && r == nil
+	}
+	if !bytes.Equal(l.Hash, r.Hash) || (l.FileNode == nil) != (r.FileNode == nil) {
+		return false
+	}
+	return l.FileNode == nil || proto.Equal(l.FileNode, r.FileNode)
+}
+
+// putNode replaces the file at 'path' in 'h' with 'node', which was read from
+// 'src'.
+func putNode(h, src HashTree, path string, node *NodeProto) error {
+	if err := h.DeleteFile(path); err != nil && Code(err) != PathNotFound {
+		return err
+	}
+	if node.SymlinkNode != nil {
+		return h.PutSymlink(path, node.SymlinkNode.Target)
+	}
+	fileNode := node.FileNode
+	var err error
+	switch {
+	case fileNode.HasHeaderFooter:
+		parent, getErr := src.Get(pathlib.Dir(path))
+		if getErr != nil {
+			return getErr
+		}
+		if shared := parent.DirNode.Shared; shared != nil {
+			if err := h.PutDirHeaderFooter(pathlib.Dir(path), shared.Header, shared.Footer, shared.HeaderSize, shared.FooterSize); err != nil {
+				return err
+			}
+		}
+		err = h.PutFileHeaderFooter(path, fileNode.Objects, node.SubtreeSize)
+	case len(fileNode.BlockRefs) > 0:
+		err = h.PutFileBlockRefs(path, fileNode.BlockRefs, node.SubtreeSize)
+	default:
+		err = h.PutFile(path, fileNode.Objects, node.SubtreeSize)
+	}
+	if err != nil || len(fileNode.Metadata) == 0 {
+		return err
+	}
+	return h.PutFileMetadata(path, fileNode.Metadata)
+}
@@ -0,0 +1,249 @@
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+
+	"github.com/pachyderm/pachyderm/src/client/pkg/errors"
+)
+
+const (
+	magic          = "PAR1"
+	encryptedMagic = "PARE"
+	// footerSize is the size of the trailer after the file metadata: the
+	// metadata's length and the magic bytes
+	footerSize = 8
+)
+
+// thrift field IDs used by the splitter (see parquet-format's parquet.thrift)
+const (
+	// FileMetaData
+	fileNumRows             = 3
+	fileRowGroups           = 4
+	fileEncryptionAlgorithm = 8
+	// RowGroup
+	rowGroupColumns    = 1
+	rowGroupNumRows    = 3
+	rowGroupFileOffset = 5
+	rowGroupOrdinal    = 7
+	// ColumnChunk
+	columnFilePath          = 1
+	columnFileOffset        = 2
+	columnMetaData          = 3
+	columnOffsetIndexOffset = 4
+	columnOffsetIndexLength = 5
+	columnColumnIndexOffset = 6
+	columnColumnIndexLength = 7
+	columnEncryptedMetaData = 9
+	// ColumnMetaData
+	metaTotalCompressedSize  = 7
+	metaDataPageOffset       = 9
+	metaIndexPageOffset      = 10
+	metaDictionaryPageOffset = 11
+	metaBloomFilterOffset    = 14
+	metaBloomFilterLength    = 15
+)
+
+type rowGroup struct {
+	// encoded is the row group's encoded metadata, which is decoded again for
+	// each split file so that it can be changed freely
+	encoded    []byte
+	start, end int64 // the range of the file holding the row group's data
+	numRows    int64
+}
+
+// Splitter splits a parquet file into smaller parquet files, each of which
+// holds some of its row groups. The row groups' data is copied unchanged, and
+// each new file gets its own metadata, describing just its row groups.
+// Column and offset indexes and bloom filters, which are stored outside of
+// row groups, aren't copied.
+type Splitter struct {
+	r           io.ReaderAt
+	metadata    *tStruct
+	rowGroups   []*rowGroup
+	targetRows  int64
+	targetBytes int64
+}
+
+// NewSplitter creates a Splitter for the parquet file in 'r', which is 'size'
+// bytes long. Each split file holds row groups until it has at least
+// 'targetRows' rows or 'targetBytes' bytes of row group data (whichever comes
+// first). If both are 0, each split file holds one row group.
+func NewSplitter(r io.ReaderAt, size int64, targetRows, targetBytes int64) (*Splitter, error) {
+	if size < int64(len(magic))+footerSize {
+		return nil, errors.New("invalid parquet file - too short")
+	}
+	head := make([]byte, len(magic))
+	if _, err := r.ReadAt(head, 0); err != nil {
+		return nil, err
+	}
+	switch string(head) {
+	case magic:
+	case encryptedMagic:
+		return nil, errors.New("encrypted parquet files cannot be split")
+	default:
+		return nil, errors.Errorf("invalid parquet file - bad magic bytes %q", head)
+	}
+	footer := make([]byte, footerSize)
+	if _, err := r.ReadAt(footer, size-footerSize); err != nil {
+		return nil, err
+	}
+	if string(footer[4:]) != magic {
+		return nil, errors.Errorf("invalid parquet file - bad trailing magic bytes %q", footer[4:])
+	}
+	metadataSize := int64(binary.LittleEndian.Uint32(footer[:4]))
+	if metadataSize > size-int64(len(magic))-footerSize {
+		return nil, errors.Errorf("invalid parquet file - metadata size %d is larger than the file", metadataSize)
+	}
+	encoded := make([]byte, metadataSize)
+	if _, err := r.ReadAt(encoded, size-footerSize-metadataSize); err != nil {
+		return nil, err
+	}
+	metadata, err := decodeStruct(encoded)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := metadata.get(fileEncryptionAlgorithm); ok {
+		return nil, errors.New("encrypted parquet files cannot be split")
+	}
+	s := &Splitter{
+		r:           r,
+		metadata:    metadata,
+		targetRows:  targetRows,
+		targetBytes: targetBytes,
+	}
+	if rowGroups := metadata.getList(fileRowGroups); rowGroups != nil {
+		for i, elem := range rowGroups.elems {
+			rg, ok := elem.(*tStruct)
+			if !ok {
+				return nil, errors.New("invalid parquet metadata - row group is not a struct")
+			}
+			parsed, err := parseRowGroup(rg, size-footerSize-metadataSize)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid parquet metadata for row group %d", i)
+			}
+			s.rowGroups = append(s.rowGroups, parsed)
+		}
+	}
+	return s, nil
+}
+
+// parseRowGroup finds the range of the file that holds 'rg's column chunks,
+// which must end before 'limit' (where the file's metadata starts).
+func parseRowGroup(rg *tStruct, limit int64) (*rowGroup, error) {
+	result := &rowGroup{encoded: encodeStruct(rg), start: -1}
+	result.numRows, _ = rg.getInt(rowGroupNumRows)
+	columns := rg.getList(rowGroupColumns)
+	if columns == nil || len(columns.elems) == 0 {
+		return nil, errors.New("row group has no columns")
+	}
+	for _, elem := range columns.elems {
+		column, ok := elem.(*tStruct)
+		if !ok {
+			return nil, errors.New("column chunk is not a struct")
+		}
+		if _, ok := column.get(columnFilePath); ok {
+			return nil, errors.New("column chunks stored in other files are not supported")
+		}
+		if _, ok := column.get(columnEncryptedMetaData); ok {
+			return nil, errors.New("encrypted column chunks are not supported")
+		}
+		meta := column.getStruct(columnMetaData)
+		if meta == nil {
+			return nil, errors.New("column chunk has no metadata")
+		}
+		start, ok := meta.getInt(metaDataPageOffset)
+		if !ok {
+			return nil, errors.New("column chunk has no data page offset")
+		}
+		if dictOffset, ok := meta.getInt(metaDictionaryPageOffset); ok && dictOffset > 0 && dictOffset < start {
+			start = dictOffset
+		}
+		if indexOffset, ok := meta.getInt(metaIndexPageOffset); ok && indexOffset > 0 && indexOffset < start {
+			start = indexOffset
+		}
+		size, ok := meta.getInt(metaTotalCompressedSize)
+		if !ok || size < 0 {
+			return nil, errors.New("column chunk has no valid compressed size")
+		}
+		if start < int64(len(magic)) || start+size > limit {
+			return nil, errors.Errorf("column chunk [%d, %d) is outside of the file's data", start, start+size)
+		}
+		if result.start < 0 || start < result.start {
+			result.start = start
+		}
+		if start+size > result.end {
+			result.end = start + size
+		}
+	}
+	return result, nil
+}
+
+// Next returns the next split file, along with the number of rows in it. It
+// returns io.EOF after the last split file.
+func (s *Splitter) Next() ([]byte, int64, error) {
+	if len(s.rowGroups) == 0 {
+		return nil, 0, io.EOF
+	}
+	var rowGroups []*rowGroup
+	var rows, size int64
+	for len(s.rowGroups) > 0 {
+		rg := s.rowGroups[0]
+		rowGroups = append(rowGroups, rg)
+		s.rowGroups = s.rowGroups[1:]
+		rows += rg.numRows
+		size += rg.end - rg.start
+		if (s.targetRows == 0 && s.targetBytes == 0) ||
+			(s.targetRows != 0 && rows >= s.targetRows) ||
+			(s.targetBytes != 0 && size >= s.targetBytes) {
+			break
+		}
+	}
+	file, err := s.writeFile(rowGroups, rows)
+	if err != nil {
+		return nil, 0, err
+	}
+	return file, rows, nil
+}
+
+// writeFile returns a parquet file holding 'rowGroups', which have 'rows'
+// rows in total.
+func (s *Splitter) writeFile(rowGroups []*rowGroup, rows int64) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(magic)
+	list := &tList{typ: typeList, elemType: typeStruct}
+	for _, rg := range rowGroups {
+		delta := int64(buf.Len()) - rg.start
+		if _, err := io.Copy(&buf, io.NewSectionReader(s.r, rg.start, rg.end-rg.start)); err != nil {
+			return nil, err
+		}
+		meta, err := decodeStruct(rg.encoded)
+		if err != nil {
+			return nil, err
+		}
+		meta.shift(rowGroupFileOffset, delta)
+		meta.remove(rowGroupOrdinal)
+		for _, elem := range meta.getList(rowGroupColumns).elems {
+			column := elem.(*tStruct)
+			column.shift(columnFileOffset, delta)
+			column.remove(columnOffsetIndexOffset, columnOffsetIndexLength, columnColumnIndexOffset, columnColumnIndexLength)
+			columnMeta := column.getStruct(columnMetaData)
+			columnMeta.shift(metaDataPageOffset, delta)
+			columnMeta.shift(metaIndexPageOffset, delta)
+			columnMeta.shift(metaDictionaryPageOffset, delta)
+			columnMeta.remove(metaBloomFilterOffset, metaBloomFilterLength)
+		}
+		list.elems = append(list.elems, meta)
+	}
+	metadata := s.metadata.clone()
+	metadata.set(fileNumRows, typeI64, rows)
+	metadata.set(fileRowGroups, typeList, list)
+	encoded := encodeStruct(metadata)
+	buf.Write(encoded)
+	var length [4]byte
+	binary.LittleEndian.PutUint32(length[:], uint32(len(encoded)))
+	buf.Write(length[:])
+	buf.WriteString(magic)
+	return buf.Bytes(), nil
+}
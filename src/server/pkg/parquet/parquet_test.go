@@ -0,0 +1,178 @@
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"testing"
+
+	"github.com/pachyderm/pachyderm/src/client/pkg/errors"
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+)
+
+// testFile returns a parquet file with one column, whose row groups hold
+// 'rows' rows each. The column chunks' data is fake: each chunk is a
+// dictionary page and a data page whose bytes spell out the chunk's index.
+func testFile(t *testing.T, rows ...int64) []byte {
+	var buf bytes.Buffer
+	buf.WriteString(magic)
+	rowGroups := &tList{typ: typeList, elemType: typeStruct}
+	var totalRows int64
+	for i, n := range rows {
+		dictOffset := int64(buf.Len())
+		buf.WriteString("dict")
+		dataOffset := int64(buf.Len())
+		buf.Write(bytes.Repeat([]byte{byte('a' + i)}, 10))
+		size := int64(buf.Len()) - dictOffset
+		meta := &tStruct{fields: []field{
+			{id: 1, typ: typeI32, value: int64(1)}, // type
+			{id: 3, typ: typeList, value: &tList{typ: typeList, elemType: typeBinary, elems: []interface{}{[]byte("col")}}},
+			{id: 5, typ: typeI64, value: n}, // num_values
+			{id: metaTotalCompressedSize, typ: typeI64, value: size},
+			{id: metaDataPageOffset, typ: typeI64, value: dataOffset},
+			{id: metaDictionaryPageOffset, typ: typeI64, value: dictOffset},
+			{id: 12, typ: typeStruct, value: &tStruct{fields: []field{ // statistics
+				{id: 5, typ: typeBinary, value: []byte{0}},
+				{id: 6, typ: typeBinary, value: []byte{byte(n)}},
+			}}},
+			{id: metaBloomFilterOffset, typ: typeI64, value: int64(1 << 20)},
+		}}
+		column := &tStruct{fields: []field{
+			{id: columnFileOffset, typ: typeI64, value: dictOffset},
+			{id: columnMetaData, typ: typeStruct, value: meta},
+			{id: columnColumnIndexOffset, typ: typeI64, value: int64(1 << 20)},
+			{id: columnColumnIndexLength, typ: typeI32, value: int64(10)},
+		}}
+		rowGroups.elems = append(rowGroups.elems, &tStruct{fields: []field{
+			{id: rowGroupColumns, typ: typeList, value: &tList{typ: typeList, elemType: typeStruct, elems: []interface{}{column}}},
+			{id: 2, typ: typeI64, value: size},
+			{id: rowGroupNumRows, typ: typeI64, value: n},
+			{id: rowGroupFileOffset, typ: typeI64, value: dictOffset},
+			{id: rowGroupOrdinal, typ: typeI16, value: int64(i)},
+		}})
+		totalRows += n
+	}
+	metadata := &tStruct{fields: []field{
+		{id: 1, typ: typeI32, value: int64(1)},
+		{id: 2, typ: typeList, value: &tList{typ: typeList, elemType: typeStruct, elems: []interface{}{
+			&tStruct{fields: []field{{id: 4, typ: typeBinary, value: []byte("schema")}, {id: 5, typ: typeI32, value: int64(1)}}},
+			&tStruct{fields: []field{{id: 1, typ: typeI32, value: int64(1)}, {id: 4, typ: typeBinary, value: []byte("col")}}},
+		}}},
+		{id: fileNumRows, typ: typeI64, value: totalRows},
+		{id: fileRowGroups, typ: typeList, value: rowGroups},
+		{id: 5, typ: typeList, value: &tList{typ: typeList, elemType: typeStruct, elems: []interface{}{
+			&tStruct{fields: []field{{id: 1, typ: typeBinary, value: []byte("key")}, {id: 2, typ: typeBinary, value: []byte("value")}}},
+		}}},
+		{id: 6, typ: typeBinary, value: []byte("test")},
+	}}
+	encoded := encodeStruct(metadata)
+	buf.Write(encoded)
+	require.NoError(t, binary.Write(&buf, binary.LittleEndian, uint32(len(encoded))))
+	buf.WriteString(magic)
+	return buf.Bytes()
+}
+
+// checkFile checks that 'file' is a parquet file whose metadata is consistent
+// with its data, and returns the row counts and data of its row groups. If
+// 'isSplit' is set, it also checks that the metadata that splitting removes is
+// gone.
+func checkFile(t *testing.T, file []byte, isSplit bool) ([]int64, []string) {
+	s, err := NewSplitter(bytes.NewReader(file), int64(len(file)), 0, 0)
+	require.NoError(t, err)
+	var rows []int64
+	var data []string
+	var totalRows int64
+	for _, rg := range s.rowGroups {
+		rows = append(rows, rg.numRows)
+		data = append(data, string(file[rg.start:rg.end]))
+		meta, err := decodeStruct(rg.encoded)
+		require.NoError(t, err)
+		offset, _ := meta.getInt(rowGroupFileOffset)
+		require.Equal(t, rg.start, offset)
+		_, ok := meta.get(rowGroupOrdinal)
+		require.Equal(t, !isSplit, ok)
+		column := meta.getList(rowGroupColumns).elems[0].(*tStruct)
+		offset, _ = column.getInt(columnFileOffset)
+		require.Equal(t, rg.start, offset)
+		_, ok = column.get(columnColumnIndexOffset)
+		require.Equal(t, !isSplit, ok)
+		columnMeta := column.getStruct(columnMetaData)
+		offset, _ = columnMeta.getInt(metaDataPageOffset)
+		require.Equal(t, rg.start+4, offset)
+		_, ok = columnMeta.get(metaBloomFilterOffset)
+		require.Equal(t, !isSplit, ok)
+		require.NotNil(t, columnMeta.getStruct(12))
+		totalRows += rg.numRows
+	}
+	numRows, _ := s.metadata.getInt(fileNumRows)
+	require.Equal(t, totalRows, numRows)
+	require.Equal(t, 2, len(s.metadata.getList(2).elems))
+	return rows, data
+}
+
+func split(t *testing.T, file []byte, targetRows, targetBytes int64) [][]byte {
+	s, err := NewSplitter(bytes.NewReader(file), int64(len(file)), targetRows, targetBytes)
+	require.NoError(t, err)
+	var files [][]byte
+	for {
+		f, _, err := s.Next()
+		if errors.Is(err, io.EOF) {
+			return files
+		}
+		require.NoError(t, err)
+		files = append(files, f)
+	}
+}
+
+func TestThriftRoundTrip(t *testing.T) {
+	file := testFile(t, 1, 2, 3)
+	size := binary.LittleEndian.Uint32(file[len(file)-8:])
+	encoded := file[len(file)-8-int(size) : len(file)-8]
+	s, err := decodeStruct(encoded)
+	require.NoError(t, err)
+	require.Equal(t, encoded, encodeStruct(s))
+}
+
+func TestSplit(t *testing.T) {
+	file := testFile(t, 10, 20, 30, 40)
+	rows, data := checkFile(t, file, false)
+	require.Equal(t, []int64{10, 20, 30, 40}, rows)
+
+	// One row group per file by default
+	files := split(t, file, 0, 0)
+	require.Equal(t, 4, len(files))
+	for i, f := range files {
+		splitRows, splitData := checkFile(t, f, true)
+		require.Equal(t, rows[i:i+1], splitRows)
+		require.Equal(t, data[i:i+1], splitData)
+	}
+
+	// Row groups are combined until the target number of rows is reached
+	files = split(t, file, 25, 0)
+	require.Equal(t, 3, len(files))
+	splitRows, splitData := checkFile(t, files[0], true)
+	require.Equal(t, []int64{10, 20}, splitRows)
+	require.Equal(t, data[:2], splitData)
+	splitRows, _ = checkFile(t, files[2], true)
+	require.Equal(t, []int64{40}, splitRows)
+
+	// ...or the target number of bytes (each row group has 14 bytes of data)
+	files = split(t, file, 0, 40)
+	require.Equal(t, 2, len(files))
+	splitRows, _ = checkFile(t, files[0], true)
+	require.Equal(t, []int64{10, 20, 30}, splitRows)
+}
+
+func TestSplitErrors(t *testing.T) {
+	file := testFile(t, 1)
+	for _, bad := range [][]byte{
+		nil,
+		[]byte("PAR1PAR1"),
+		append([]byte("PARE"), file[4:]...),
+		append(append([]byte{}, file[:len(file)-1]...), 'X'),
+		append(append([]byte{}, file[:len(file)-8]...), 0xff, 0xff, 0, 0, 'P', 'A', 'R', '1'),
+	} {
+		_, err := NewSplitter(bytes.NewReader(bad), int64(len(bad)), 0, 0)
+		require.YesError(t, err)
+	}
+}
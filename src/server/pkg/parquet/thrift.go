@@ -0,0 +1,368 @@
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+
+	"github.com/pachyderm/pachyderm/src/client/pkg/errors"
+)
+
+// Parquet's metadata is encoded with thrift's compact protocol. The splitter
+// only needs to change a few fields, so rather than implementing parquet's
+// whole thrift schema, metadata is decoded into generic structs that keep
+// every field (known or not), and re-encoded after being changed.
+
+// compact protocol type IDs
+const (
+	typeStop      = 0
+	typeBoolTrue  = 1
+	typeBoolFalse = 2
+	typeByte      = 3
+	typeI16       = 4
+	typeI32       = 5
+	typeI64       = 6
+	typeDouble    = 7
+	typeBinary    = 8
+	typeList      = 9
+	typeSet       = 10
+	typeMap       = 11
+	typeStruct    = 12
+)
+
+// maxDepth bounds the nesting of decoded structs and containers
+const maxDepth = 64
+
+type field struct {
+	id    int16
+	typ   byte
+	value interface{}
+}
+
+// tStruct is a decoded thrift struct. Its field values are:
+// - bool for booleans
+// - int64 for bytes and integers
+// - []byte for doubles (their encoding) and binary values
+// - *tStruct, *tList and *tMap for structs and containers
+type tStruct struct {
+	fields []field
+}
+
+// tList is a decoded thrift list or set
+type tList struct {
+	typ      byte // typeList or typeSet
+	elemType byte
+	elems    []interface{}
+}
+
+type tMap struct {
+	keyType, valueType byte
+	keys, values       []interface{}
+}
+
+func (s *tStruct) get(id int16) (interface{}, bool) {
+	for _, f := range s.fields {
+		if f.id == id {
+			return f.value, true
+		}
+	}
+	return nil, false
+}
+
+// getInt returns the value of the integer field 'id', if it's set.
+func (s *tStruct) getInt(id int16) (int64, bool) {
+	v, ok := s.get(id)
+	if !ok {
+		return 0, false
+	}
+	i, ok := v.(int64)
+	return i, ok
+}
+
+func (s *tStruct) getStruct(id int16) *tStruct {
+	v, _ := s.get(id)
+	st, _ := v.(*tStruct)
+	return st
+}
+
+func (s *tStruct) getList(id int16) *tList {
+	v, _ := s.get(id)
+	l, _ := v.(*tList)
+	return l
+}
+
+// set sets field 'id' (of type 'typ') to 'value', adding it if it's not set.
+func (s *tStruct) set(id int16, typ byte, value interface{}) {
+	for i, f := range s.fields {
+		if f.id == id {
+			s.fields[i].value = value
+			return
+		}
+	}
+	s.fields = append(s.fields, field{id: id, typ: typ, value: value})
+}
+
+// remove unsets the fields 'ids'
+func (s *tStruct) remove(ids ...int16) {
+	var fields []field
+	for _, f := range s.fields {
+		keep := true
+		for _, id := range ids {
+			if f.id == id {
+				keep = false
+			}
+		}
+		if keep {
+			fields = append(fields, f)
+		}
+	}
+	s.fields = fields
+}
+
+// shift adds 'delta' to the integer field 'id', if it's set and nonzero
+func (s *tStruct) shift(id int16, delta int64) {
+	if v, ok := s.getInt(id); ok && v != 0 {
+		s.set(id, typeI64, v+delta)
+	}
+}
+
+// clone returns a copy of 's' whose top-level fields can be changed without
+// affecting 's'. Nested values are shared.
+func (s *tStruct) clone() *tStruct {
+	return &tStruct{fields: append([]field(nil), s.fields...)}
+}
+
+type decoder struct {
+	r *bytes.Reader
+}
+
+func decodeStruct(data []byte) (*tStruct, error) {
+	d := &decoder{r: bytes.NewReader(data)}
+	s, err := d.readStruct(0)
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, errors.Wrapf(err, "invalid parquet metadata")
+	}
+	return s, nil
+}
+
+func (d *decoder) readVarint() (uint64, error) {
+	return binary.ReadUvarint(d.r)
+}
+
+func (d *decoder) readZigzag() (int64, error) {
+	u, err := d.readVarint()
+	if err != nil {
+		return 0, err
+	}
+	return int64(u>>1) ^ -int64(u&1), nil
+}
+
+func (d *decoder) readStruct(depth int) (*tStruct, error) {
+	if depth > maxDepth {
+		return nil, errors.New("structs are nested too deeply")
+	}
+	s := &tStruct{}
+	var lastID int16
+	for {
+		b, err := d.r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		typ := b & 0x0f
+		if typ == typeStop {
+			return s, nil
+		}
+		id := lastID + int16(b>>4)
+		if b>>4 == 0 {
+			longID, err := d.readZigzag()
+			if err != nil {
+				return nil, err
+			}
+			id = int16(longID)
+		}
+		lastID = id
+		var value interface{}
+		switch typ {
+		case typeBoolTrue, typeBoolFalse:
+			value = typ == typeBoolTrue
+		default:
+			if value, err = d.readValue(typ, depth); err != nil {
+				return nil, err
+			}
+		}
+		s.fields = append(s.fields, field{id: id, typ: typ, value: value})
+	}
+}
+
+// readValue reads a value of type 'typ', other than a boolean struct field
+// (whose value is part of its type).
+func (d *decoder) readValue(typ byte, depth int) (interface{}, error) {
+	switch typ {
+	case typeBoolTrue, typeBoolFalse:
+		// booleans in containers are encoded as a byte
+		b, err := d.r.ReadByte()
+		return b == typeBoolTrue, err
+	case typeByte:
+		b, err := d.r.ReadByte()
+		return int64(int8(b)), err
+	case typeI16, typeI32, typeI64:
+		return d.readZigzag()
+	case typeDouble:
+		b := make([]byte, 8)
+		_, err := io.ReadFull(d.r, b)
+		return b, err
+	case typeBinary:
+		n, err := d.readVarint()
+		if err != nil {
+			return nil, err
+		}
+		if n > uint64(d.r.Len()) {
+			return nil, io.ErrUnexpectedEOF
+		}
+		b := make([]byte, n)
+		_, err = io.ReadFull(d.r, b)
+		return b, err
+	case typeList, typeSet:
+		b, err := d.r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		l := &tList{typ: typ, elemType: b & 0x0f}
+		n := uint64(b >> 4)
+		if n == 15 {
+			if n, err = d.readVarint(); err != nil {
+				return nil, err
+			}
+		}
+		for i := uint64(0); i < n; i++ {
+			elem, err := d.readValue(l.elemType, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			l.elems = append(l.elems, elem)
+		}
+		return l, nil
+	case typeMap:
+		n, err := d.readVarint()
+		if err != nil {
+			return nil, err
+		}
+		m := &tMap{}
+		if n == 0 {
+			return m, nil
+		}
+		b, err := d.r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		m.keyType, m.valueType = b>>4, b&0x0f
+		for i := uint64(0); i < n; i++ {
+			key, err := d.readValue(m.keyType, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			value, err := d.readValue(m.valueType, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			m.keys = append(m.keys, key)
+			m.values = append(m.values, value)
+		}
+		return m, nil
+	case typeStruct:
+		return d.readStruct(depth + 1)
+	default:
+		return nil, errors.Errorf("unknown thrift type %d", typ)
+	}
+}
+
+type encoder struct {
+	buf bytes.Buffer
+}
+
+func encodeStruct(s *tStruct) []byte {
+	e := &encoder{}
+	e.writeStruct(s)
+	return e.buf.Bytes()
+}
+
+func (e *encoder) writeVarint(u uint64) {
+	var b [binary.MaxVarintLen64]byte
+	e.buf.Write(b[:binary.PutUvarint(b[:], u)])
+}
+
+func (e *encoder) writeZigzag(n int64) {
+	e.writeVarint(uint64((n << 1) ^ (n >> 63)))
+}
+
+func (e *encoder) writeStruct(s *tStruct) {
+	var lastID int16
+	for _, f := range s.fields {
+		typ := f.typ
+		if typ == typeBoolTrue || typ == typeBoolFalse {
+			typ = typeBoolFalse
+			if f.value.(bool) {
+				typ = typeBoolTrue
+			}
+		}
+		if delta := f.id - lastID; delta > 0 && delta <= 15 {
+			e.buf.WriteByte(byte(delta)<<4 | typ)
+		} else {
+			e.buf.WriteByte(typ)
+			e.writeZigzag(int64(f.id))
+		}
+		lastID = f.id
+		if typ != typeBoolTrue && typ != typeBoolFalse {
+			e.writeValue(typ, f.value)
+		}
+	}
+	e.buf.WriteByte(typeStop)
+}
+
+func (e *encoder) writeValue(typ byte, value interface{}) {
+	switch typ {
+	case typeBoolTrue, typeBoolFalse:
+		if value.(bool) {
+			e.buf.WriteByte(typeBoolTrue)
+		} else {
+			e.buf.WriteByte(typeBoolFalse)
+		}
+	case typeByte:
+		e.buf.WriteByte(byte(value.(int64)))
+	case typeI16, typeI32, typeI64:
+		e.writeZigzag(value.(int64))
+	case typeDouble:
+		e.buf.Write(value.([]byte))
+	case typeBinary:
+		b := value.([]byte)
+		e.writeVarint(uint64(len(b)))
+		e.buf.Write(b)
+	case typeList, typeSet:
+		l := value.(*tList)
+		if len(l.elems) < 15 {
+			e.buf.WriteByte(byte(len(l.elems))<<4 | l.elemType)
+		} else {
+			e.buf.WriteByte(0xf0 | l.elemType)
+			e.writeVarint(uint64(len(l.elems)))
+		}
+		for _, elem := range l.elems {
+			e.writeValue(l.elemType, elem)
+		}
+	case typeMap:
+		m := value.(*tMap)
+		e.writeVarint(uint64(len(m.keys)))
+		if len(m.keys) == 0 {
+			return
+		}
+		e.buf.WriteByte(m.keyType<<4 | m.valueType)
+		for i := range m.keys {
+			e.writeValue(m.keyType, m.keys[i])
+			e.writeValue(m.valueType, m.values[i])
+		}
+	case typeStruct:
+		e.writeStruct(value.(*tStruct))
+	}
+}
@@ -16,7 +16,9 @@ const (
 	putFileRecordsPrefix = "/putFileRecords"
 	commitsPrefix        = "/commits"
 	branchesPrefix       = "/branches"
+	commitTagsPrefix     = "/commitTags"
 	openCommitsPrefix    = "/openCommits"
+	uploadsPrefix        = "/uploads"
 	mergesPrefix         = "/merges"
 	shardsPrefix         = "/shards"
 )
@@ -79,6 +81,35 @@ func Branches(etcdClient *etcd.Client, etcdPrefix string, repo string) col.Colle
 	)
 }
 
+// CommitTags returns a collection of commit tags
+func CommitTags(etcdClient *etcd.Client, etcdPrefix string, repo string) col.Collection {
+	return col.NewCollection(
+		etcdClient,
+		path.Join(etcdPrefix, commitTagsPrefix, repo),
+		nil,
+		&pfs.CommitTagInfo{},
+		func(key string) error {
+			if uuid.IsUUIDWithoutDashes(key) {
+				return errors.Errorf("tag name cannot be a UUID V4")
+			}
+			return nil
+		},
+		nil,
+	)
+}
+
+// Uploads returns a collection of upload sessions
+func Uploads(etcdClient *etcd.Client, etcdPrefix string) col.Collection {
+	return col.NewCollection(
+		etcdClient,
+		path.Join(etcdPrefix, uploadsPrefix),
+		nil,
+		&pfs.UploadInfo{},
+		nil,
+		nil,
+	)
+}
+
 // OpenCommits returns a collection of open commits
 func OpenCommits(etcdClient *etcd.Client, etcdPrefix string) col.Collection {
 	return col.NewCollection(
@@ -2,84 +2,344 @@ package sql
 
 import (
 	"bufio"
+	"bytes"
 	"io"
 	"strings"
 
 	"github.com/pachyderm/pachyderm/src/client/pkg/errors"
 )
 
-// PGDumpReader parses a pgdump file into a header, rows, and a footer
+const endLine = "\\.\n" // Trailing '\.' denotes the end of a COPY block's rows
+
+// PGDumpReader parses a SQL dump into a header, rows, and a footer, such that
+// the header, any subset of the rows and the footer form a loadable SQL
+// script. It understands:
+// - pg_dump output with COPY ... FROM stdin blocks (the default). The header
+//   ends with the first COPY statement and the footer begins with its '\.'
+//   terminator. Rows of later COPY blocks are prefixed with a '\.' and their
+//   own COPY statement, and anything between COPY blocks goes in the footer.
+// - pg_dump --inserts and mysqldump output, whose rows are INSERT statements.
+//   Multi-row (extended) INSERTs are split so that each row is an INSERT of a
+//   single tuple. Statements between INSERTs (e.g. mysqldump's CREATE TABLE
+//   for the next table) are moved into the header, so that every table exists
+//   before any rows are inserted, and LOCK TABLES statements are dropped, as a
+//   split file's rows may belong to several tables.
 type PGDumpReader struct {
 	Header []byte
 	Footer []byte
 	rd     *bufio.Reader
+
+	started   bool
+	done      bool
+	inCopy    bool
+	inserts   bool
+	copyStart []byte   // prepended to each row of the current COPY block
+	rows      [][]byte // rows of the current INSERT statement
+	// backslashEscapes is set if backslashes escape characters in quoted
+	// strings, which is true for MySQL but not for Postgres (with
+	// standard_conforming_strings, which pg_dump always sets)
+	backslashEscapes bool
 }
 
 // NewPGDumpReader creates a new PGDumpReader
 func NewPGDumpReader(r *bufio.Reader) *PGDumpReader {
 	return &PGDumpReader{
-		rd: r,
+		rd:               r,
+		backslashEscapes: true,
 	}
 }
 
-// ReadRow parses the pgdump file and populates the header and the footer
+// ReadRow parses the SQL dump and populates the header and the footer
 // It returns EOF when done, and at that time both the Header and Footer will
-// be populated. Both header and footer are required. If either are missing, an
-// error is returned
+// be populated. Both header and footer are required for dumps with COPY
+// blocks. If either are missing, an error is returned
 func (r *PGDumpReader) ReadRow() ([]byte, error) {
-	if len(r.Header) == 0 {
-		err := r.readHeader()
+	if !r.started {
+		r.started = true
+		between, next, err := r.readBetween()
+		r.Header = append(r.Header, between...)
 		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil, errors.Errorf("invalid header - missing row inserts")
+			}
+			return nil, err
+		}
+		if err := r.startData(next); err != nil {
 			return nil, err
 		}
 	}
-	endLine := "\\.\n" // Trailing '\.' denotes the end of the row inserts
-	row, err := r.rd.ReadBytes('\n')
-	if err != nil && !errors.Is(err, io.EOF) {
-		return nil, errors.Wrapf(err, "error reading pgdump row")
+	for {
+		if len(r.rows) > 0 {
+			row := r.rows[0]
+			r.rows = r.rows[1:]
+			return row, nil
+		}
+		if r.done {
+			return nil, io.EOF
+		}
+		if r.inCopy {
+			row, err := r.readLine()
+			if err != nil {
+				if errors.Is(err, io.EOF) {
+					return nil, errors.Errorf("invalid pgdump - missing footer")
+				}
+				return nil, errors.Wrapf(err, "error reading pgdump row")
+			}
+			if string(row) != endLine {
+				if len(r.copyStart) > 0 {
+					row = append(append([]byte{}, r.copyStart...), row...)
+				}
+				return row, nil
+			}
+			// The first '\.' ends the header's COPY block, so it begins the footer.
+			// Later COPY blocks are ended by the '\.' in their rows' prefix
+			r.inCopy = false
+			if len(r.Footer) == 0 {
+				r.Footer = append(r.Footer, row...)
+			}
+		}
+		between, next, err := r.readBetween()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				r.Footer = append(r.Footer, between...)
+				r.done = true
+				continue
+			}
+			return nil, err
+		}
+		if r.inserts {
+			r.Header = append(r.Header, between...)
+		} else {
+			r.Footer = append(r.Footer, between...)
+		}
+		if err := r.startData(next); err != nil {
+			return nil, err
+		}
 	}
-	// corner case: some pgdump files separate lines with \r\n (even on linux),
-	// so clean this case up so all handling below is unified
-	if len(row) >= 2 && row[len(row)-2] == '\r' {
-		row[len(row)-2] = '\n'
-		row = row[:len(row)-1]
+}
+
+// startData starts reading the data statement (COPY or INSERT) whose first
+// line is 'line'
+func (r *PGDumpReader) startData(line []byte) error {
+	if isCopy(line) {
+		if r.inserts {
+			return errors.Errorf("invalid sql dump - COPY and INSERT statements cannot be mixed")
+		}
+		r.inCopy = true
+		if len(r.Footer) == 0 {
+			// This is the first COPY block (none has ended yet), so its statement
+			// ends the header
+			r.Header = append(r.Header, line...)
+			return nil
+		}
+		r.copyStart = append([]byte(endLine), line...)
+		return nil
 	}
-	if string(row) == endLine {
-		r.Footer = append(r.Footer, row...)
-		err = r.readFooter()
-		row = nil // The endline is part of the footer
+	if len(r.Footer) != 0 {
+		return errors.Errorf("invalid sql dump - COPY and INSERT statements cannot be mixed")
 	}
-	if errors.Is(err, io.EOF) && len(r.Footer) == 0 {
-		return nil, errors.Errorf("invalid pgdump - missing footer")
+	r.inserts = true
+	stmt, err := r.readStatement(line)
+	if err != nil {
+		return err
 	}
-	return row, err
+	r.rows, err = splitInsert(stmt, r.backslashEscapes)
+	return err
 }
 
-func (r *PGDumpReader) readHeader() error {
-	done := false
-	for !done {
-		b, err := r.rd.ReadBytes('\n')
+// readBetween reads the lines before the next data statement, and returns
+// them along with the data statement's first line. It returns io.EOF if there
+// are no more data statements.
+func (r *PGDumpReader) readBetween() ([]byte, []byte, error) {
+	var between []byte
+	for {
+		line, err := r.readLine()
+		if err != nil {
+			return between, nil, err
+		}
+		if isCopy(line) || isInsert(line) {
+			return between, line, nil
+		}
+		upper := strings.ToUpper(string(line))
+		if strings.HasPrefix(upper, "LOCK TABLES ") {
+			continue
+		}
+		if strings.Contains(upper, "STANDARD_CONFORMING_STRINGS = ON") {
+			r.backslashEscapes = false
+		}
+		between = append(between, line...)
+	}
+}
+
+// readStatement reads the rest of the statement beginning with 'line', which
+// ends with a semicolon outside of any quotes
+func (r *PGDumpReader) readStatement(line []byte) ([]byte, error) {
+	s := scanner{backslashEscapes: r.backslashEscapes}
+	stmt := line
+	for s.scan(line) < 0 {
+		var err error
+		line, err = r.readLine()
 		if err != nil {
 			if errors.Is(err, io.EOF) {
-				return errors.Errorf("invalid header - missing row inserts")
+				return nil, errors.Errorf("invalid sql dump - unterminated INSERT statement")
 			}
-			return err
+			return nil, err
+		}
+		stmt = append(stmt, line...)
+	}
+	return stmt, nil
+}
+
+// readLine reads the next line of the dump. It only returns io.EOF if there
+// are no more lines.
+func (r *PGDumpReader) readLine() ([]byte, error) {
+	line, err := r.rd.ReadBytes('\n')
+	if err != nil && (!errors.Is(err, io.EOF) || len(line) == 0) {
+		return nil, err
+	}
+	// corner case: some pgdump files separate lines with \r\n (even on linux),
+	// so clean this case up so all handling below is unified
+	if len(line) >= 2 && line[len(line)-2] == '\r' {
+		line[len(line)-2] = '\n'
+		line = line[:len(line)-1]
+	}
+	return line, nil
+}
+
+func isCopy(line []byte) bool {
+	return strings.HasPrefix(string(line), "COPY ")
+}
+
+func isInsert(line []byte) bool {
+	return strings.HasPrefix(strings.ToUpper(string(line)), "INSERT INTO ")
+}
+
+// scanner tracks whether the text it's fed is inside quotes or parentheses
+type scanner struct {
+	backslashEscapes bool
+	quote            byte // the open quote character, if any
+	escaped          bool // the previous character was an escaping backslash
+	depth            int  // the number of open parentheses
+}
+
+// scan feeds 'b' to the scanner, and returns the index in 'b' of the first
+// semicolon outside of quotes and parentheses, or -1 if there isn't one
+func (s *scanner) scan(b []byte) int {
+	for i, c := range b {
+		if s.step(c) && c == ';' && s.depth == 0 {
+			return i
 		}
-		if strings.HasPrefix(string(b), "COPY") {
-			done = true
+	}
+	return -1
+}
+
+// step feeds 'c' to the scanner, and returns true if it's outside of quotes
+func (s *scanner) step(c byte) bool {
+	switch {
+	case s.escaped:
+		s.escaped = false
+	case s.quote != 0:
+		if c == '\\' && s.backslashEscapes && s.quote != '`' {
+			s.escaped = true
+		} else if c == s.quote {
+			// a doubled quote is read as closing and reopening the quote
+			s.quote = 0
 		}
-		r.Header = append(r.Header, b...)
+	case c == '\'' || c == '"' || c == '`':
+		s.quote = c
+	case c == '(':
+		s.depth++
+	case c == ')':
+		s.depth--
+	default:
+		return true
 	}
-	return nil
+	return false
 }
 
-func (r *PGDumpReader) readFooter() error {
+// splitInsert splits the INSERT statement 'stmt' into one INSERT statement
+// per row. Statements that don't insert a list of VALUES (e.g. INSERT ...
+// SELECT) are returned unchanged.
+func splitInsert(stmt []byte, backslashEscapes bool) ([][]byte, error) {
+	// find the VALUES keyword, which starts the list of rows
+	s := scanner{backslashEscapes: backslashEscapes}
+	start := -1
+	for i := 0; i < len(stmt); i++ {
+		if s.step(stmt[i]) && s.depth == 0 && isKeyword(stmt, i, "VALUES") {
+			start = i + len("VALUES")
+			break
+		}
+	}
+	if start < 0 {
+		return [][]byte{stmt}, nil
+	}
+	prefix := append(bytes.TrimRight(stmt[:start], " \t\r\n"), ' ')
+	var tuples [][]byte
+	i := start
 	for {
-		b, err := r.rd.ReadBytes('\n')
-		r.Footer = append(r.Footer, b...)
-		if err != nil {
-			return err
+		for i < len(stmt) && isSpace(stmt[i]) {
+			i++
+		}
+		if i >= len(stmt) || stmt[i] != '(' {
+			return nil, errors.Errorf("invalid INSERT statement - expected a row at %q", truncate(stmt[i:]))
+		}
+		tupleStart := i
+		s.step(stmt[i])
+		for i++; i < len(stmt) && s.depth > 0; i++ {
+			s.step(stmt[i])
+		}
+		if s.depth > 0 {
+			return nil, errors.Errorf("invalid INSERT statement - unterminated row")
+		}
+		tuples = append(tuples, stmt[tupleStart:i])
+		for i < len(stmt) && isSpace(stmt[i]) {
+			i++
 		}
+		if i < len(stmt) && stmt[i] == ',' {
+			i++
+			continue
+		}
+		break
+	}
+	// Anything after the rows (e.g. ON CONFLICT DO NOTHING) applies to each row
+	end := bytes.LastIndexByte(stmt, ';')
+	if end < i {
+		return nil, errors.Errorf("invalid INSERT statement - missing semicolon")
+	}
+	suffix := bytes.TrimSpace(stmt[i:end])
+	rows := make([][]byte, 0, len(tuples))
+	for _, tuple := range tuples {
+		row := make([]byte, 0, len(prefix)+len(tuple)+len(suffix)+3)
+		row = append(append(row, prefix...), tuple...)
+		if len(suffix) > 0 {
+			row = append(append(row, ' '), suffix...)
+		}
+		rows = append(rows, append(row, ";\n"...))
+	}
+	return rows, nil
+}
+
+// isKeyword returns true if 'keyword' appears (case-insensitively) as a whole
+// word in 'b' at index 'i'
+func isKeyword(b []byte, i int, keyword string) bool {
+	if i+len(keyword) > len(b) || !strings.EqualFold(string(b[i:i+len(keyword)]), keyword) {
+		return false
+	}
+	return (i == 0 || !isWordChar(b[i-1])) &&
+		(i+len(keyword) == len(b) || !isWordChar(b[i+len(keyword)]))
+}
+
+func isWordChar(c byte) bool {
+	return c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\n'
+}
+
+func truncate(b []byte) string {
+	if len(b) > 20 {
+		return string(b[:20]) + "..."
 	}
-	return nil
+	return string(b)
 }
@@ -0,0 +1,111 @@
+package sql
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/pachyderm/pachyderm/src/client/pkg/errors"
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+	tu "github.com/pachyderm/pachyderm/src/server/pkg/testutil"
+)
+
+func readAll(t *testing.T, dump string) (*PGDumpReader, []string) {
+	r := NewPGDumpReader(bufio.NewReader(strings.NewReader(dump)))
+	var rows []string
+	for {
+		row, err := r.ReadRow()
+		if errors.Is(err, io.EOF) {
+			return r, rows
+		}
+		require.NoError(t, err)
+		rows = append(rows, string(row))
+	}
+}
+
+func TestPGDumpCopy(t *testing.T) {
+	r, rows := readAll(t, tu.TestPGDump)
+	require.Equal(t, 5, len(rows))
+	require.Equal(t, "Tesla\tRoadster\t2008\tliterally a rocket\n", rows[0])
+	require.True(t, strings.HasSuffix(string(r.Header), "COPY public.cars (make, model, year, note) FROM stdin;\n"))
+	require.True(t, strings.HasPrefix(string(r.Footer), "\\.\n"))
+}
+
+func TestPGDumpMultipleCopies(t *testing.T) {
+	dump := "SET standard_conforming_strings = on;\n" +
+		"CREATE TABLE a (x int);\n" +
+		"CREATE TABLE b (y text);\n" +
+		"COPY public.a (x) FROM stdin;\n" +
+		"1\n" +
+		"2\n" +
+		"\\.\n" +
+		"\n" +
+		"COPY public.b (y) FROM stdin;\n" +
+		"three\n" +
+		"\\.\n" +
+		"SELECT pg_catalog.setval('a_seq', 2, true);\n" +
+		"ALTER TABLE ONLY a ADD CONSTRAINT a_pkey PRIMARY KEY (x);\n"
+	r, rows := readAll(t, dump)
+	require.Equal(t, []string{"1\n", "2\n", "\\.\nCOPY public.b (y) FROM stdin;\nthree\n"}, rows)
+	require.Equal(t, "SET standard_conforming_strings = on;\n"+
+		"CREATE TABLE a (x int);\n"+
+		"CREATE TABLE b (y text);\n"+
+		"COPY public.a (x) FROM stdin;\n", string(r.Header))
+	require.Equal(t, "\\.\n\n"+
+		"SELECT pg_catalog.setval('a_seq', 2, true);\n"+
+		"ALTER TABLE ONLY a ADD CONSTRAINT a_pkey PRIMARY KEY (x);\n", string(r.Footer))
+}
+
+func TestPGDumpInserts(t *testing.T) {
+	dump := "SET standard_conforming_strings = on;\n" +
+		"CREATE TABLE public.a (x int, y text);\n" +
+		"INSERT INTO public.a VALUES (1, 'C:\\');\n" +
+		"INSERT INTO public.a (x, y) VALUES\n" +
+		"\t(2, 'it''s; here'),\n" +
+		"\t(3, NULL) ON CONFLICT DO NOTHING;\n" +
+		"ALTER TABLE ONLY public.a ADD CONSTRAINT a_pkey PRIMARY KEY (x);\n"
+	r, rows := readAll(t, dump)
+	require.Equal(t, []string{
+		"INSERT INTO public.a VALUES (1, 'C:\\');\n",
+		"INSERT INTO public.a (x, y) VALUES (2, 'it''s; here') ON CONFLICT DO NOTHING;\n",
+		"INSERT INTO public.a (x, y) VALUES (3, NULL) ON CONFLICT DO NOTHING;\n",
+	}, rows)
+	require.Equal(t, "SET standard_conforming_strings = on;\nCREATE TABLE public.a (x int, y text);\n", string(r.Header))
+	require.Equal(t, "ALTER TABLE ONLY public.a ADD CONSTRAINT a_pkey PRIMARY KEY (x);\n", string(r.Footer))
+}
+
+func TestMySQLDump(t *testing.T) {
+	r, rows := readAll(t, tu.TestMySQLDump)
+	require.Equal(t, []string{
+		"INSERT INTO `cars` VALUES ('Tesla','Roadster',2008,'literally a rocket');\n",
+		"INSERT INTO `cars` VALUES ('Bugatti','Chiron',2016,'literally a rocket');\n",
+		"INSERT INTO `cars` VALUES ('Honda','Civic',1998,'only a rocket if it\\'s got a spoiler (and wings);');\n",
+		"INSERT INTO `owners` VALUES ('Alice','Tesla');\n",
+		"INSERT INTO `owners` VALUES ('Bob','Honda');\n",
+	}, rows)
+	// Both tables are created by the header, which doesn't lock them
+	header := string(r.Header)
+	require.True(t, strings.Contains(header, "CREATE TABLE `cars`"))
+	require.True(t, strings.Contains(header, "CREATE TABLE `owners`"))
+	require.False(t, strings.Contains(header, "\nLOCK TABLES"))
+	require.True(t, strings.HasPrefix(string(r.Footer), "/*!40000 ALTER TABLE `owners` ENABLE KEYS */;\nUNLOCK TABLES;\n"))
+}
+
+func TestSQLDumpErrors(t *testing.T) {
+	for _, dump := range []string{
+		"",
+		"CREATE TABLE a (x int);\n",
+		"COPY public.a (x) FROM stdin;\n1\n",
+		"INSERT INTO a VALUES (1, 'unterminated);\n",
+		"INSERT INTO a VALUES 1;\n",
+		"COPY public.a (x) FROM stdin;\n1\n\\.\nINSERT INTO a VALUES (2);\n",
+	} {
+		r := NewPGDumpReader(bufio.NewReader(strings.NewReader(dump)))
+		var err error
+		for err == nil {
+			_, err = r.ReadRow()
+		}
+		require.False(t, errors.Is(err, io.EOF), "dump %q", dump)
+	}
+}
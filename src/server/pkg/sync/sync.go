@@ -77,6 +77,32 @@ func (p *Puller) makeFile(path string, f func(io.Writer) error) (retErr error) {
 	return nil
 }
 
+// LocalSymlinkTarget returns the target that a local symlink corresponding to
+// the PFS symlink described by 'fileInfo' should have. PFS targets never point
+// outside of the commit (absolute targets are relative to its root, and '..'
+// stops there), so the target is resolved to a path in the commit and then
+// made relative to the directory containing the link.
+func LocalSymlinkTarget(fileInfo *pfs.FileInfo) (string, error) {
+	linkPath := path.Clean("/" + fileInfo.File.Path)
+	target := hashtree.SymlinkTarget(linkPath, &hashtree.SymlinkNodeProto{Target: fileInfo.SymlinkTarget})
+	target, err := filepath.Rel(path.Dir(linkPath), target)
+	if err != nil {
+		return "", errors.EnsureStack(err)
+	}
+	return filepath.FromSlash(target), nil
+}
+
+func makeSymlink(path string, fileInfo *pfs.FileInfo) error {
+	target, err := LocalSymlinkTarget(fileInfo)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	return os.Symlink(target, path)
+}
+
 // Pull clones an entire repo at a certain commit.
 // root is the local path you want to clone to.
 // repo, commit, file specify the file/dir we are pulling.
@@ -99,7 +125,7 @@ func (p *Puller) Pull(client *pachclient.APIClient, root string, repo, commit, f
 			statsPath := filepath.Join(statsRoot, basepath)
 			if fileInfo.FileType == pfs.FileType_DIR {
 				statsTree.PutDir(statsPath)
-			} else {
+			} else if fileInfo.FileType == pfs.FileType_FILE {
 				var blockRefs []*pfs.BlockRef
 				for _, object := range fileInfo.Objects {
 					objectInfo, err := client.InspectObject(object.Hash)
@@ -116,6 +142,9 @@ func (p *Puller) Pull(client *pachclient.APIClient, root string, repo, commit, f
 		if fileInfo.FileType == pfs.FileType_DIR {
 			return os.MkdirAll(path, 0700)
 		}
+		if fileInfo.FileType == pfs.FileType_SYMLINK {
+			return makeSymlink(path, fileInfo)
+		}
 		if pipes {
 			return p.makePipe(path, func(w io.Writer) error {
 				return client.GetFile(repo, commit, fileInfo.File.Path, 0, 0, w)
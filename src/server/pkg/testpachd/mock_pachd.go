@@ -401,6 +401,8 @@ type createRepoFunc func(context.Context, *pfs.CreateRepoRequest) (*types.Empty,
 type inspectRepoFunc func(context.Context, *pfs.InspectRepoRequest) (*pfs.RepoInfo, error)
 type listRepoFunc func(context.Context, *pfs.ListRepoRequest) (*pfs.ListRepoResponse, error)
 type deleteRepoFunc func(context.Context, *pfs.DeleteRepoRequest) (*types.Empty, error)
+type renameRepoFunc func(context.Context, *pfs.RenameRepoRequest) (*types.Empty, error)
+type forkRepoFunc func(context.Context, *pfs.ForkRepoRequest) (*types.Empty, error)
 type startCommitFunc func(context.Context, *pfs.StartCommitRequest) (*pfs.Commit, error)
 type finishCommitFunc func(context.Context, *pfs.FinishCommitRequest) (*types.Empty, error)
 type inspectCommitFunc func(context.Context, *pfs.InspectCommitRequest) (*pfs.CommitInfo, error)
@@ -409,13 +411,24 @@ type listCommitStreamFunc func(*pfs.ListCommitRequest, pfs.API_ListCommitStreamS
 type deleteCommitFunc func(context.Context, *pfs.DeleteCommitRequest) (*types.Empty, error)
 type flushCommitFunc func(*pfs.FlushCommitRequest, pfs.API_FlushCommitServer) error
 type subscribeCommitFunc func(*pfs.SubscribeCommitRequest, pfs.API_SubscribeCommitServer) error
+type subscribeFileFunc func(*pfs.SubscribeFileRequest, pfs.API_SubscribeFileServer) error
 type buildCommitFunc func(context.Context, *pfs.BuildCommitRequest) (*pfs.Commit, error)
+type revertCommitFunc func(context.Context, *pfs.RevertCommitRequest) (*pfs.Commit, error)
+type cherryPickCommitFunc func(context.Context, *pfs.CherryPickCommitRequest) (*pfs.Commit, error)
+type squashCommitsFunc func(context.Context, *pfs.SquashCommitsRequest) (*pfs.Commit, error)
 type createBranchFunc func(context.Context, *pfs.CreateBranchRequest) (*types.Empty, error)
 type inspectBranchFunc func(context.Context, *pfs.InspectBranchRequest) (*pfs.BranchInfo, error)
 type listBranchFunc func(context.Context, *pfs.ListBranchRequest) (*pfs.BranchInfos, error)
 type deleteBranchFunc func(context.Context, *pfs.DeleteBranchRequest) (*types.Empty, error)
+type renameBranchFunc func(context.Context, *pfs.RenameBranchRequest) (*types.Empty, error)
+type mergeBranchFunc func(context.Context, *pfs.MergeBranchRequest) (*pfs.Commit, error)
+type createCommitTagFunc func(context.Context, *pfs.CreateCommitTagRequest) (*types.Empty, error)
+type inspectCommitTagFunc func(context.Context, *pfs.InspectCommitTagRequest) (*pfs.CommitTagInfo, error)
+type listCommitTagFunc func(context.Context, *pfs.ListCommitTagRequest) (*pfs.CommitTagInfos, error)
+type deleteCommitTagFunc func(context.Context, *pfs.DeleteCommitTagRequest) (*types.Empty, error)
 type putFileFunc func(pfs.API_PutFileServer) error
 type copyFileFunc func(context.Context, *pfs.CopyFileRequest) (*types.Empty, error)
+type moveFileFunc func(context.Context, *pfs.MoveFileRequest) (*types.Empty, error)
 type getFileFunc func(*pfs.GetFileRequest, pfs.API_GetFileServer) error
 type inspectFileFunc func(context.Context, *pfs.InspectFileRequest) (*pfs.FileInfo, error)
 type listFileFunc func(context.Context, *pfs.ListFileRequest) (*pfs.FileInfos, error)
@@ -423,8 +436,16 @@ type listFileStreamFunc func(*pfs.ListFileRequest, pfs.API_ListFileStreamServer)
 type walkFileFunc func(*pfs.WalkFileRequest, pfs.API_WalkFileServer) error
 type globFileFunc func(context.Context, *pfs.GlobFileRequest) (*pfs.FileInfos, error)
 type globFileStreamFunc func(*pfs.GlobFileRequest, pfs.API_GlobFileStreamServer) error
+type grepFileFunc func(*pfs.GrepFileRequest, pfs.API_GrepFileServer) error
 type diffFileFunc func(context.Context, *pfs.DiffFileRequest) (*pfs.DiffFileResponse, error)
 type deleteFileFunc func(context.Context, *pfs.DeleteFileRequest) (*types.Empty, error)
+type purgeFileFunc func(context.Context, *pfs.PurgeFileRequest) (*pfs.PurgeFileResponse, error)
+type startUploadFunc func(context.Context, *pfs.StartUploadRequest) (*pfs.UploadInfo, error)
+type putChunkFunc func(pfs.API_PutChunkServer) error
+type inspectUploadFunc func(context.Context, *pfs.InspectUploadRequest) (*pfs.UploadInfo, error)
+type listUploadFunc func(context.Context, *pfs.ListUploadRequest) (*pfs.UploadInfos, error)
+type completeUploadFunc func(context.Context, *pfs.CompleteUploadRequest) (*types.Empty, error)
+type deleteUploadFunc func(context.Context, *pfs.DeleteUploadRequest) (*types.Empty, error)
 type deleteAllPFSFunc func(context.Context, *types.Empty) (*types.Empty, error)
 type fsckFunc func(*pfs.FsckRequest, pfs.API_FsckServer) error
 type fileOperationFuncV2 func(pfs.API_FileOperationV2Server) error
@@ -437,6 +458,8 @@ type mockCreateRepo struct{ handler createRepoFunc }
 type mockInspectRepo struct{ handler inspectRepoFunc }
 type mockListRepo struct{ handler listRepoFunc }
 type mockDeleteRepo struct{ handler deleteRepoFunc }
+type mockRenameRepo struct{ handler renameRepoFunc }
+type mockForkRepo struct{ handler forkRepoFunc }
 type mockStartCommit struct{ handler startCommitFunc }
 type mockFinishCommit struct{ handler finishCommitFunc }
 type mockInspectCommit struct{ handler inspectCommitFunc }
@@ -445,13 +468,24 @@ type mockListCommitStream struct{ handler listCommitStreamFunc }
 type mockDeleteCommit struct{ handler deleteCommitFunc }
 type mockFlushCommit struct{ handler flushCommitFunc }
 type mockSubscribeCommit struct{ handler subscribeCommitFunc }
+type mockSubscribeFile struct{ handler subscribeFileFunc }
 type mockBuildCommit struct{ handler buildCommitFunc }
+type mockRevertCommit struct{ handler revertCommitFunc }
+type mockCherryPickCommit struct{ handler cherryPickCommitFunc }
+type mockSquashCommits struct{ handler squashCommitsFunc }
 type mockCreateBranch struct{ handler createBranchFunc }
 type mockInspectBranch struct{ handler inspectBranchFunc }
 type mockListBranch struct{ handler listBranchFunc }
 type mockDeleteBranch struct{ handler deleteBranchFunc }
+type mockRenameBranch struct{ handler renameBranchFunc }
+type mockMergeBranch struct{ handler mergeBranchFunc }
+type mockCreateCommitTag struct{ handler createCommitTagFunc }
+type mockInspectCommitTag struct{ handler inspectCommitTagFunc }
+type mockListCommitTag struct{ handler listCommitTagFunc }
+type mockDeleteCommitTag struct{ handler deleteCommitTagFunc }
 type mockPutFile struct{ handler putFileFunc }
 type mockCopyFile struct{ handler copyFileFunc }
+type mockMoveFile struct{ handler moveFileFunc }
 type mockGetFile struct{ handler getFileFunc }
 type mockInspectFile struct{ handler inspectFileFunc }
 type mockListFile struct{ handler listFileFunc }
@@ -459,8 +493,16 @@ type mockListFileStream struct{ handler listFileStreamFunc }
 type mockWalkFile struct{ handler walkFileFunc }
 type mockGlobFile struct{ handler globFileFunc }
 type mockGlobFileStream struct{ handler globFileStreamFunc }
+type mockGrepFile struct{ handler grepFileFunc }
 type mockDiffFile struct{ handler diffFileFunc }
 type mockDeleteFile struct{ handler deleteFileFunc }
+type mockPurgeFile struct{ handler purgeFileFunc }
+type mockStartUpload struct{ handler startUploadFunc }
+type mockPutChunk struct{ handler putChunkFunc }
+type mockInspectUpload struct{ handler inspectUploadFunc }
+type mockListUpload struct{ handler listUploadFunc }
+type mockCompleteUpload struct{ handler completeUploadFunc }
+type mockDeleteUpload struct{ handler deleteUploadFunc }
 type mockDeleteAllPFS struct{ handler deleteAllPFSFunc }
 type mockFsck struct{ handler fsckFunc }
 type mockFileOperationV2 struct{ handler fileOperationFuncV2 }
@@ -473,6 +515,8 @@ func (mock *mockCreateRepo) Use(cb createRepoFunc)                   { mock.hand
 func (mock *mockInspectRepo) Use(cb inspectRepoFunc)                 { mock.handler = cb }
 func (mock *mockListRepo) Use(cb listRepoFunc)                       { mock.handler = cb }
 func (mock *mockDeleteRepo) Use(cb deleteRepoFunc)                   { mock.handler = cb }
+func (mock *mockRenameRepo) Use(cb renameRepoFunc)                   { mock.handler = cb }
+func (mock *mockForkRepo) Use(cb forkRepoFunc)                       { mock.handler = cb }
 func (mock *mockStartCommit) Use(cb startCommitFunc)                 { mock.handler = cb }
 func (mock *mockFinishCommit) Use(cb finishCommitFunc)               { mock.handler = cb }
 func (mock *mockInspectCommit) Use(cb inspectCommitFunc)             { mock.handler = cb }
@@ -481,13 +525,24 @@ func (mock *mockListCommitStream) Use(cb listCommitStreamFunc)       { mock.hand
 func (mock *mockDeleteCommit) Use(cb deleteCommitFunc)               { mock.handler = cb }
 func (mock *mockFlushCommit) Use(cb flushCommitFunc)                 { mock.handler = cb }
 func (mock *mockSubscribeCommit) Use(cb subscribeCommitFunc)         { mock.handler = cb }
+func (mock *mockSubscribeFile) Use(cb subscribeFileFunc)             { mock.handler = cb }
 func (mock *mockBuildCommit) Use(cb buildCommitFunc)                 { mock.handler = cb }
+func (mock *mockRevertCommit) Use(cb revertCommitFunc)               { mock.handler = cb }
+func (mock *mockCherryPickCommit) Use(cb cherryPickCommitFunc)       { mock.handler = cb }
+func (mock *mockSquashCommits) Use(cb squashCommitsFunc)             { mock.handler = cb }
 func (mock *mockCreateBranch) Use(cb createBranchFunc)               { mock.handler = cb }
 func (mock *mockInspectBranch) Use(cb inspectBranchFunc)             { mock.handler = cb }
 func (mock *mockListBranch) Use(cb listBranchFunc)                   { mock.handler = cb }
 func (mock *mockDeleteBranch) Use(cb deleteBranchFunc)               { mock.handler = cb }
+func (mock *mockRenameBranch) Use(cb renameBranchFunc)               { mock.handler = cb }
+func (mock *mockMergeBranch) Use(cb mergeBranchFunc)                 { mock.handler = cb }
+func (mock *mockCreateCommitTag) Use(cb createCommitTagFunc)         { mock.handler = cb }
+func (mock *mockInspectCommitTag) Use(cb inspectCommitTagFunc)       { mock.handler = cb }
+func (mock *mockListCommitTag) Use(cb listCommitTagFunc)             { mock.handler = cb }
+func (mock *mockDeleteCommitTag) Use(cb deleteCommitTagFunc)         { mock.handler = cb }
 func (mock *mockPutFile) Use(cb putFileFunc)                         { mock.handler = cb }
 func (mock *mockCopyFile) Use(cb copyFileFunc)                       { mock.handler = cb }
+func (mock *mockMoveFile) Use(cb moveFileFunc)                       { mock.handler = cb }
 func (mock *mockGetFile) Use(cb getFileFunc)                         { mock.handler = cb }
 func (mock *mockInspectFile) Use(cb inspectFileFunc)                 { mock.handler = cb }
 func (mock *mockListFile) Use(cb listFileFunc)                       { mock.handler = cb }
@@ -495,8 +550,16 @@ func (mock *mockListFileStream) Use(cb listFileStreamFunc)           { mock.hand
 func (mock *mockWalkFile) Use(cb walkFileFunc)                       { mock.handler = cb }
 func (mock *mockGlobFile) Use(cb globFileFunc)                       { mock.handler = cb }
 func (mock *mockGlobFileStream) Use(cb globFileStreamFunc)           { mock.handler = cb }
+func (mock *mockGrepFile) Use(cb grepFileFunc)                       { mock.handler = cb }
 func (mock *mockDiffFile) Use(cb diffFileFunc)                       { mock.handler = cb }
 func (mock *mockDeleteFile) Use(cb deleteFileFunc)                   { mock.handler = cb }
+func (mock *mockPurgeFile) Use(cb purgeFileFunc)                     { mock.handler = cb }
+func (mock *mockStartUpload) Use(cb startUploadFunc)                 { mock.handler = cb }
+func (mock *mockPutChunk) Use(cb putChunkFunc)                       { mock.handler = cb }
+func (mock *mockInspectUpload) Use(cb inspectUploadFunc)             { mock.handler = cb }
+func (mock *mockListUpload) Use(cb listUploadFunc)                   { mock.handler = cb }
+func (mock *mockCompleteUpload) Use(cb completeUploadFunc)           { mock.handler = cb }
+func (mock *mockDeleteUpload) Use(cb deleteUploadFunc)               { mock.handler = cb }
 func (mock *mockDeleteAllPFS) Use(cb deleteAllPFSFunc)               { mock.handler = cb }
 func (mock *mockFsck) Use(cb fsckFunc)                               { mock.handler = cb }
 func (mock *mockFileOperationV2) Use(cb fileOperationFuncV2)         { mock.handler = cb }
@@ -515,6 +578,8 @@ type mockPFSServer struct {
 	InspectRepo         mockInspectRepo
 	ListRepo            mockListRepo
 	DeleteRepo          mockDeleteRepo
+	RenameRepo          mockRenameRepo
+	ForkRepo            mockForkRepo
 	StartCommit         mockStartCommit
 	FinishCommit        mockFinishCommit
 	InspectCommit       mockInspectCommit
@@ -523,13 +588,24 @@ type mockPFSServer struct {
 	DeleteCommit        mockDeleteCommit
 	FlushCommit         mockFlushCommit
 	SubscribeCommit     mockSubscribeCommit
+	SubscribeFile       mockSubscribeFile
 	BuildCommit         mockBuildCommit
+	RevertCommit        mockRevertCommit
+	CherryPickCommit    mockCherryPickCommit
+	SquashCommits       mockSquashCommits
 	CreateBranch        mockCreateBranch
 	InspectBranch       mockInspectBranch
 	ListBranch          mockListBranch
 	DeleteBranch        mockDeleteBranch
+	RenameBranch        mockRenameBranch
+	MergeBranch         mockMergeBranch
+	CreateCommitTag     mockCreateCommitTag
+	InspectCommitTag    mockInspectCommitTag
+	ListCommitTag       mockListCommitTag
+	DeleteCommitTag     mockDeleteCommitTag
 	PutFile             mockPutFile
 	CopyFile            mockCopyFile
+	MoveFile            mockMoveFile
 	GetFile             mockGetFile
 	InspectFile         mockInspectFile
 	ListFile            mockListFile
@@ -537,8 +613,16 @@ type mockPFSServer struct {
 	WalkFile            mockWalkFile
 	GlobFile            mockGlobFile
 	GlobFileStream      mockGlobFileStream
+	GrepFile            mockGrepFile
 	DiffFile            mockDiffFile
 	DeleteFile          mockDeleteFile
+	PurgeFile           mockPurgeFile
+	StartUpload         mockStartUpload
+	PutChunk            mockPutChunk
+	InspectUpload       mockInspectUpload
+	ListUpload          mockListUpload
+	CompleteUpload      mockCompleteUpload
+	DeleteUpload        mockDeleteUpload
 	DeleteAll           mockDeleteAllPFS
 	Fsck                mockFsck
 	FileOperationV2     mockFileOperationV2
@@ -572,6 +656,18 @@ func (api *pfsServerAPI) DeleteRepo(ctx context.Context, req *pfs.DeleteRepoRequ
 	}
 	return nil, errors.Errorf("unhandled pachd mock pfs.DeleteRepo")
 }
+func (api *pfsServerAPI) RenameRepo(ctx context.Context, req *pfs.RenameRepoRequest) (*types.Empty, error) {
+	if api.mock.RenameRepo.handler != nil {
+		return api.mock.RenameRepo.handler(ctx, req)
+	}
+	return nil, errors.Errorf("unhandled pachd mock pfs.RenameRepo")
+}
+func (api *pfsServerAPI) ForkRepo(ctx context.Context, req *pfs.ForkRepoRequest) (*types.Empty, error) {
+	if api.mock.ForkRepo.handler != nil {
+		return api.mock.ForkRepo.handler(ctx, req)
+	}
+	return nil, errors.Errorf("unhandled pachd mock pfs.ForkRepo")
+}
 func (api *pfsServerAPI) StartCommit(ctx context.Context, req *pfs.StartCommitRequest) (*pfs.Commit, error) {
 	if api.mock.StartCommit.handler != nil {
 		return api.mock.StartCommit.handler(ctx, req)
@@ -620,12 +716,36 @@ func (api *pfsServerAPI) SubscribeCommit(req *pfs.SubscribeCommitRequest, serv p
 	}
 	return errors.Errorf("unhandled pachd mock pfs.SubscribeCommit")
 }
+func (api *pfsServerAPI) SubscribeFile(req *pfs.SubscribeFileRequest, serv pfs.API_SubscribeFileServer) error {
+	if api.mock.SubscribeFile.handler != nil {
+		return api.mock.SubscribeFile.handler(req, serv)
+	}
+	return errors.Errorf("unhandled pachd mock pfs.SubscribeFile")
+}
 func (api *pfsServerAPI) BuildCommit(ctx context.Context, req *pfs.BuildCommitRequest) (*pfs.Commit, error) {
 	if api.mock.BuildCommit.handler != nil {
 		return api.mock.BuildCommit.handler(ctx, req)
 	}
 	return nil, errors.Errorf("unhandled pachd mock pfs.BuildCommit")
 }
+func (api *pfsServerAPI) RevertCommit(ctx context.Context, req *pfs.RevertCommitRequest) (*pfs.Commit, error) {
+	if api.mock.RevertCommit.handler != nil {
+		return api.mock.RevertCommit.handler(ctx, req)
+	}
+	return nil, errors.Errorf("unhandled pachd mock pfs.RevertCommit")
+}
+func (api *pfsServerAPI) CherryPickCommit(ctx context.Context, req *pfs.CherryPickCommitRequest) (*pfs.Commit, error) {
+	if api.mock.CherryPickCommit.handler != nil {
+		return api.mock.CherryPickCommit.handler(ctx, req)
+	}
+	return nil, errors.Errorf("unhandled pachd mock pfs.CherryPickCommit")
+}
+func (api *pfsServerAPI) SquashCommits(ctx context.Context, req *pfs.SquashCommitsRequest) (*pfs.Commit, error) {
+	if api.mock.SquashCommits.handler != nil {
+		return api.mock.SquashCommits.handler(ctx, req)
+	}
+	return nil, errors.Errorf("unhandled pachd mock pfs.SquashCommits")
+}
 func (api *pfsServerAPI) CreateBranch(ctx context.Context, req *pfs.CreateBranchRequest) (*types.Empty, error) {
 	if api.mock.CreateBranch.handler != nil {
 		return api.mock.CreateBranch.handler(ctx, req)
@@ -650,6 +770,42 @@ func (api *pfsServerAPI) DeleteBranch(ctx context.Context, req *pfs.DeleteBranch
 	}
 	return nil, errors.Errorf("unhandled pachd mock pfs.DeleteBranch")
 }
+func (api *pfsServerAPI) RenameBranch(ctx context.Context, req *pfs.RenameBranchRequest) (*types.Empty, error) {
+	if api.mock.RenameBranch.handler != nil {
+		return api.mock.RenameBranch.handler(ctx, req)
+	}
+	return nil, errors.Errorf("unhandled pachd mock pfs.RenameBranch")
+}
+func (api *pfsServerAPI) MergeBranch(ctx context.Context, req *pfs.MergeBranchRequest) (*pfs.Commit, error) {
+	if api.mock.MergeBranch.handler != nil {
+		return api.mock.MergeBranch.handler(ctx, req)
+	}
+	return nil, errors.Errorf("unhandled pachd mock pfs.MergeBranch")
+}
+func (api *pfsServerAPI) CreateCommitTag(ctx context.Context, req *pfs.CreateCommitTagRequest) (*types.Empty, error) {
+	if api.mock.CreateCommitTag.handler != nil {
+		return api.mock.CreateCommitTag.handler(ctx, req)
+	}
+	return nil, errors.Errorf("unhandled pachd mock pfs.CreateCommitTag")
+}
+func (api *pfsServerAPI) InspectCommitTag(ctx context.Context, req *pfs.InspectCommitTagRequest) (*pfs.CommitTagInfo, error) {
+	if api.mock.InspectCommitTag.handler != nil {
+		return api.mock.InspectCommitTag.handler(ctx, req)
+	}
+	return nil, errors.Errorf("unhandled pachd mock pfs.InspectCommitTag")
+}
+func (api *pfsServerAPI) ListCommitTag(ctx context.Context, req *pfs.ListCommitTagRequest) (*pfs.CommitTagInfos, error) {
+	if api.mock.ListCommitTag.handler != nil {
+		return api.mock.ListCommitTag.handler(ctx, req)
+	}
+	return nil, errors.Errorf("unhandled pachd mock pfs.ListCommitTag")
+}
+func (api *pfsServerAPI) DeleteCommitTag(ctx context.Context, req *pfs.DeleteCommitTagRequest) (*types.Empty, error) {
+	if api.mock.DeleteCommitTag.handler != nil {
+		return api.mock.DeleteCommitTag.handler(ctx, req)
+	}
+	return nil, errors.Errorf("unhandled pachd mock pfs.DeleteCommitTag")
+}
 func (api *pfsServerAPI) PutFile(serv pfs.API_PutFileServer) error {
 	if api.mock.PutFile.handler != nil {
 		return api.mock.PutFile.handler(serv)
@@ -662,6 +818,12 @@ func (api *pfsServerAPI) CopyFile(ctx context.Context, req *pfs.CopyFileRequest)
 	}
 	return nil, errors.Errorf("unhandled pachd mock pfs.CopyFile")
 }
+func (api *pfsServerAPI) MoveFile(ctx context.Context, req *pfs.MoveFileRequest) (*types.Empty, error) {
+	if api.mock.MoveFile.handler != nil {
+		return api.mock.MoveFile.handler(ctx, req)
+	}
+	return nil, errors.Errorf("unhandled pachd mock pfs.MoveFile")
+}
 func (api *pfsServerAPI) GetFile(req *pfs.GetFileRequest, serv pfs.API_GetFileServer) error {
 	if api.mock.GetFile.handler != nil {
 		return api.mock.GetFile.handler(req, serv)
@@ -704,6 +866,12 @@ func (api *pfsServerAPI) GlobFileStream(req *pfs.GlobFileRequest, serv pfs.API_G
 	}
 	return errors.Errorf("unhandled pachd mock pfs.GlobFileStream")
 }
+func (api *pfsServerAPI) GrepFile(req *pfs.GrepFileRequest, serv pfs.API_GrepFileServer) error {
+	if api.mock.GrepFile.handler != nil {
+		return api.mock.GrepFile.handler(req, serv)
+	}
+	return errors.Errorf("unhandled pachd mock pfs.GrepFile")
+}
 func (api *pfsServerAPI) DiffFile(ctx context.Context, req *pfs.DiffFileRequest) (*pfs.DiffFileResponse, error) {
 	if api.mock.DiffFile.handler != nil {
 		return api.mock.DiffFile.handler(ctx, req)
@@ -716,6 +884,48 @@ func (api *pfsServerAPI) DeleteFile(ctx context.Context, req *pfs.DeleteFileRequ
 	}
 	return nil, errors.Errorf("unhandled pachd mock pfs.DeleteFile")
 }
+func (api *pfsServerAPI) PurgeFile(ctx context.Context, req *pfs.PurgeFileRequest) (*pfs.PurgeFileResponse, error) {
+	if api.mock.PurgeFile.handler != nil {
+		return api.mock.PurgeFile.handler(ctx, req)
+	}
+	return nil, errors.Errorf("unhandled pachd mock pfs.PurgeFile")
+}
+func (api *pfsServerAPI) StartUpload(ctx context.Context, req *pfs.StartUploadRequest) (*pfs.UploadInfo, error) {
+	if api.mock.StartUpload.handler != nil {
+		return api.mock.StartUpload.handler(ctx, req)
+	}
+	return nil, errors.Errorf("unhandled pachd mock pfs.StartUpload")
+}
+func (api *pfsServerAPI) PutChunk(serv pfs.API_PutChunkServer) error {
+	if api.mock.PutChunk.handler != nil {
+		return api.mock.PutChunk.handler(serv)
+	}
+	return errors.Errorf("unhandled pachd mock pfs.PutChunk")
+}
+func (api *pfsServerAPI) InspectUpload(ctx context.Context, req *pfs.InspectUploadRequest) (*pfs.UploadInfo, error) {
+	if api.mock.InspectUpload.handler != nil {
+		return api.mock.InspectUpload.handler(ctx, req)
+	}
+	return nil, errors.Errorf("unhandled pachd mock pfs.InspectUpload")
+}
+func (api *pfsServerAPI) ListUpload(ctx context.Context, req *pfs.ListUploadRequest) (*pfs.UploadInfos, error) {
+	if api.mock.ListUpload.handler != nil {
+		return api.mock.ListUpload.handler(ctx, req)
+	}
+	return nil, errors.Errorf("unhandled pachd mock pfs.ListUpload")
+}
+func (api *pfsServerAPI) CompleteUpload(ctx context.Context, req *pfs.CompleteUploadRequest) (*types.Empty, error) {
+	if api.mock.CompleteUpload.handler != nil {
+		return api.mock.CompleteUpload.handler(ctx, req)
+	}
+	return nil, errors.Errorf("unhandled pachd mock pfs.CompleteUpload")
+}
+func (api *pfsServerAPI) DeleteUpload(ctx context.Context, req *pfs.DeleteUploadRequest) (*types.Empty, error) {
+	if api.mock.DeleteUpload.handler != nil {
+		return api.mock.DeleteUpload.handler(ctx, req)
+	}
+	return nil, errors.Errorf("unhandled pachd mock pfs.DeleteUpload")
+}
 func (api *pfsServerAPI) DeleteAll(ctx context.Context, req *types.Empty) (*types.Empty, error) {
 	if api.mock.DeleteAll.handler != nil {
 		return api.mock.DeleteAll.handler(ctx, req)
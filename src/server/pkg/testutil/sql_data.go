@@ -157,3 +157,61 @@ COPY public.cars (make, model, year, note) FROM stdin;
 -- PostgreSQL database dump complete
 --
 `
+
+// TestMySQLDump is a simple example mysqldump file for two tables, whose rows
+// are inserted with extended (multi-row) INSERT statements
+const TestMySQLDump = "-- MySQL dump 10.13  Distrib 8.0.19, for Linux (x86_64)\n" +
+	"--\n" +
+	"-- Host: localhost    Database: test\n" +
+	"-- ------------------------------------------------------\n" +
+	"-- Server version\t8.0.19\n" +
+	"\n" +
+	"/*!40101 SET @OLD_CHARACTER_SET_CLIENT=@@CHARACTER_SET_CLIENT */;\n" +
+	"/*!40101 SET NAMES utf8mb4 */;\n" +
+	"/*!40014 SET @OLD_UNIQUE_CHECKS=@@UNIQUE_CHECKS, UNIQUE_CHECKS=0 */;\n" +
+	"\n" +
+	"--\n" +
+	"-- Table structure for table `cars`\n" +
+	"--\n" +
+	"\n" +
+	"DROP TABLE IF EXISTS `cars`;\n" +
+	"CREATE TABLE `cars` (\n" +
+	"  `make` varchar(50) DEFAULT NULL,\n" +
+	"  `model` varchar(50) DEFAULT NULL,\n" +
+	"  `year` smallint DEFAULT NULL,\n" +
+	"  `note` varchar(100) DEFAULT 'literally a rocket'\n" +
+	") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;\n" +
+	"\n" +
+	"--\n" +
+	"-- Dumping data for table `cars`\n" +
+	"--\n" +
+	"\n" +
+	"LOCK TABLES `cars` WRITE;\n" +
+	"/*!40000 ALTER TABLE `cars` DISABLE KEYS */;\n" +
+	"INSERT INTO `cars` VALUES ('Tesla','Roadster',2008,'literally a rocket'),('Bugatti','Chiron',2016,'literally a rocket'),('Honda','Civic',1998,'only a rocket if it\\'s got a spoiler (and wings);');\n" +
+	"/*!40000 ALTER TABLE `cars` ENABLE KEYS */;\n" +
+	"UNLOCK TABLES;\n" +
+	"\n" +
+	"--\n" +
+	"-- Table structure for table `owners`\n" +
+	"--\n" +
+	"\n" +
+	"DROP TABLE IF EXISTS `owners`;\n" +
+	"CREATE TABLE `owners` (\n" +
+	"  `name` varchar(50) DEFAULT NULL,\n" +
+	"  `make` varchar(50) DEFAULT NULL\n" +
+	") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;\n" +
+	"\n" +
+	"--\n" +
+	"-- Dumping data for table `owners`\n" +
+	"--\n" +
+	"\n" +
+	"LOCK TABLES `owners` WRITE;\n" +
+	"/*!40000 ALTER TABLE `owners` DISABLE KEYS */;\n" +
+	"INSERT INTO `owners` VALUES ('Alice','Tesla'),('Bob','Honda');\n" +
+	"/*!40000 ALTER TABLE `owners` ENABLE KEYS */;\n" +
+	"UNLOCK TABLES;\n" +
+	"/*!40014 SET UNIQUE_CHECKS=@OLD_UNIQUE_CHECKS */;\n" +
+	"/*!40101 SET CHARACTER_SET_CLIENT=@OLD_CHARACTER_SET_CLIENT */;\n" +
+	"\n" +
+	"-- Dump completed on 2020-04-01 12:00:00\n"
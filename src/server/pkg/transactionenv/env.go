@@ -28,6 +28,8 @@ type PfsWrites interface {
 
 	CreateBranch(*pfs.CreateBranchRequest) error
 	DeleteBranch(*pfs.DeleteBranchRequest) error
+
+	MoveFile(*pfs.MoveFileRequest) error
 }
 
 // PpsWrites is an interface providing a wrapper for each operation that
@@ -137,6 +139,8 @@ type PfsTransactionServer interface {
 
 	CreateBranchInTransaction(*TransactionContext, *pfs.CreateBranchRequest) error
 	DeleteBranchInTransaction(*TransactionContext, *pfs.DeleteBranchRequest) error
+
+	MoveFileInTransaction(*TransactionContext, *pfs.MoveFileRequest) error
 }
 
 // PpsTransactionServer is an interface for the transactionally-supported
@@ -235,6 +239,11 @@ func (t *directTransaction) DeleteBranch(original *pfs.DeleteBranchRequest) erro
 	return t.txnCtx.txnEnv.pfsServer.DeleteBranchInTransaction(t.txnCtx, req)
 }
 
+func (t *directTransaction) MoveFile(original *pfs.MoveFileRequest) error {
+	req := proto.Clone(original).(*pfs.MoveFileRequest)
+	return t.txnCtx.txnEnv.pfsServer.MoveFileInTransaction(t.txnCtx, req)
+}
+
 func (t *directTransaction) UpdateJobState(original *pps.UpdateJobStateRequest) error {
 	req := proto.Clone(original).(*pps.UpdateJobStateRequest)
 	return t.txnCtx.txnEnv.ppsServer.UpdateJobStateInTransaction(t.txnCtx, req)
@@ -302,6 +311,11 @@ func (t *appendTransaction) DeleteBranch(req *pfs.DeleteBranchRequest) error {
 	return err
 }
 
+func (t *appendTransaction) MoveFile(req *pfs.MoveFileRequest) error {
+	_, err := t.txnEnv.txnServer.AppendRequest(t.ctx, t.activeTxn, &transaction.TransactionRequest{MoveFile: req})
+	return err
+}
+
 func (t *appendTransaction) UpdateJobState(req *pps.UpdateJobStateRequest) error {
 	_, err := t.txnEnv.txnServer.AppendRequest(t.ctx, t.activeTxn, &transaction.TransactionRequest{UpdateJobState: req})
 	return err
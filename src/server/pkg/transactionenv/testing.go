@@ -119,6 +119,11 @@ func (mpts *MockPfsTransactionServer) DeleteBranchInTransaction(*TransactionCont
 	return unimplementedError("PfsTransactionServer.DeleteBranchInTransaction")
 }
 
+// MoveFileInTransaction always errors
+func (mpts *MockPfsTransactionServer) MoveFileInTransaction(*TransactionContext, *pfs.MoveFileRequest) error {
+	return unimplementedError("PfsTransactionServer.MoveFileInTransaction")
+}
+
 // MockPpsTransactionServer is a simple mock that can be used to satisfy the
 // PpsTransactionServer interface
 type MockPpsTransactionServer struct{}
@@ -219,10 +219,13 @@ func (a *apiServer) validateInput(pachClient *client.APIClient, pipelineName str
 				// Note that input.Pfs.Commit is empty if a) this is a job b) one of
 				// the job pipeline's input branches has no commits yet
 				if job && input.Pfs.Commit != "" {
-					// for jobs we check that the input commit exists
-					if _, err := pachClient.InspectCommit(input.Pfs.Repo, input.Pfs.Commit); err != nil {
+					// for jobs we check that the input commit exists, and resolve it
+					// to a commit ID in case it's a branch or tag
+					ci, err := pachClient.InspectCommit(input.Pfs.Repo, input.Pfs.Commit)
+					if err != nil {
 						return err
 					}
+					input.Pfs.Commit = ci.Commit.ID
 				} else {
 					// for pipelines we only check that the repo exists
 					if _, err := pachClient.InspectRepo(input.Pfs.Repo); err != nil {
@@ -3357,6 +3360,19 @@ func CollectActiveObjectsAndTags(ctx context.Context, pachClient *client.APIClie
 		})
 	}
 
+	// Objects in upload sessions haven't been written to any commit yet
+	uploadInfos, err := pachClient.PfsAPIClient.ListUpload(ctx, &pfs.ListUploadRequest{})
+	if err != nil {
+		return nil, grpcutil.ScrubGRPC(err)
+	}
+	for _, uploadInfo := range uploadInfos.UploadInfo {
+		for _, chunk := range uploadInfo.Chunks {
+			for _, record := range chunk.Records {
+				addActiveObjects(client.NewObject(record.ObjectHash))
+			}
+		}
+	}
+
 	// Get all commit trees
 	limiter := limit.New(100)
 	var eg errgroup.Group
@@ -106,6 +106,12 @@ func sprintDeleteBranch(request *pfs.DeleteBranchRequest) string {
 	return fmt.Sprintf("delete branch %s@%s%s", request.Branch.Repo.Name, request.Branch.Name, force)
 }
 
+func sprintMoveFile(request *pfs.MoveFileRequest) string {
+	return fmt.Sprintf("move file %s@%s:%s %s@%s:%s",
+		request.Src.Commit.Repo.Name, request.Src.Commit.ID, request.Src.Path,
+		request.Dst.Commit.Repo.Name, request.Dst.Commit.ID, request.Dst.Path)
+}
+
 func sprintUpdateJobState(request *pps.UpdateJobStateRequest) string {
 	state := func() string {
 		switch request.State {
@@ -160,6 +166,8 @@ func transactionRequests(
 			line = sprintCreateBranch(request.CreateBranch)
 		} else if request.DeleteBranch != nil {
 			line = sprintDeleteBranch(request.DeleteBranch)
+		} else if request.MoveFile != nil {
+			line = sprintMoveFile(request.MoveFile)
 		} else if request.UpdateJobState != nil {
 			line = sprintUpdateJobState(request.UpdateJobState)
 		} else {
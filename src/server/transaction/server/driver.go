@@ -191,6 +191,9 @@ func (d *driver) runTransaction(txnCtx *txnenv.TransactionContext, info *transac
 		} else if request.DeleteBranch != nil {
 			err = directTxn.DeleteBranch(request.DeleteBranch)
 			response = &transaction.TransactionResponse{}
+		} else if request.MoveFile != nil {
+			err = directTxn.MoveFile(request.MoveFile)
+			response = &transaction.TransactionResponse{}
 		} else if request.UpdateJobState != nil {
 			err = directTxn.UpdateJobState(request.UpdateJobState)
 			response = &transaction.TransactionResponse{}
@@ -2,6 +2,7 @@ package testing
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/pachyderm/pachyderm/src/client"
@@ -567,3 +568,30 @@ func TestBatchTransaction(t *testing.T) {
 	})
 	require.NoError(t, err)
 }
+
+func TestMoveFileInTransaction(t *testing.T) {
+	t.Parallel()
+	err := testpachd.WithRealEnv(func(env *testpachd.RealEnv) error {
+		repo := "foo"
+		require.NoError(t, env.PachClient.CreateRepo(repo))
+		_, err := env.PachClient.PutFile(repo, "master", "old", strings.NewReader("foo\n"))
+		require.NoError(t, err)
+
+		// Rename a file and move a branch to the resulting commit atomically
+		info, err := env.PachClient.RunBatchInTransaction(func(builder *client.TransactionBuilder) error {
+			require.NoError(t, builder.MoveFile(repo, "master", "old", "new"))
+			require.NoError(t, builder.CreateBranch(repo, "release", "master", nil))
+			return nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, 2, len(info.Responses))
+		requireEmptyResponse(t, info.Responses[0])
+
+		fileInfos, err := env.PachClient.ListFile(repo, "release", "/")
+		require.NoError(t, err)
+		require.Equal(t, 1, len(fileInfos))
+		require.Equal(t, "/new", fileInfos[0].File.Path)
+		return nil
+	})
+	require.NoError(t, err)
+}